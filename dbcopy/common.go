@@ -4,8 +4,11 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
 	"os"
 
+	"github.com/openmpp/go/ompp/db"
 	"github.com/openmpp/go/ompp/helper"
 	"github.com/openmpp/go/ompp/omppLog"
 )
@@ -29,3 +32,17 @@ func dirDeleteAndLog(path string) bool {
 	}
 	return true // OK: deleted successfully
 }
+
+// Return parameter validation rules of the model or empty list if isValidate is false.
+// Rules are from modelName.validation.json file in dbcopy.RulesDir directory and from model profile.
+func paramRules(dbConn *sql.DB, modelDef *db.ModelMeta, isValidate bool) ([]db.ParamRule, error) {
+
+	if !isValidate {
+		return []db.ParamRule{}, nil
+	}
+	rules, err := db.ModelParamRules(dbConn, modelDef, theCfg.rulesDir, "")
+	if err != nil {
+		return nil, errors.New("failed to get parameter validation rules: " + modelDef.Model.Name + ": " + err.Error())
+	}
+	return rules, nil
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// copyFilter is selection of model runs, worksets and modeling tasks to copy entire model:
+// by date-time range, by run status, by name pattern and last N model runs.
+// If filter is not active then all successfully completed model runs, all readonly worksets and all tasks are copied.
+type copyFilter struct {
+	isActive  bool           // if true then filter is applied to model runs, worksets and tasks
+	isRun     bool           // if true then copy model runs
+	isSet     bool           // if true then copy worksets
+	isTask    bool           // if true then copy modeling tasks
+	fromDate  string         // if not empty then select run created or workset updated at or after this date-time
+	toDate    string         // if not empty then select run created or workset updated at or before this date-time
+	status    []string       // model run status to select, default: success
+	nameGlob  string         // if not empty then name must match glob pattern, ex.: Q3*
+	nameRe    *regexp.Regexp // if not nil then name must match regular expression
+	lastCount int            // if positive then select only last N model runs
+	runIds    map[int]bool   // if model runs copied then source id's of selected model runs, model runs must be selected before tasks
+	setIds    map[int]bool   // if worksets copied then source id's of selected worksets, worksets must be selected before tasks
+}
+
+// return true if any of filter options specified
+func isCopyFilterOptions(runOpts *config.RunOptions) bool {
+	return runOpts.IsExist(selectArgKey) ||
+		runOpts.IsExist(fromDateArgKey) || runOpts.IsExist(toDateArgKey) ||
+		runOpts.IsExist(runStatusArgKey) || runOpts.IsExist(lastRunsArgKey) ||
+		runOpts.IsExist(nameMatchArgKey) || runOpts.IsExist(nameRegexArgKey)
+}
+
+// parse and validate filter options to select model runs, worksets and tasks.
+func parseCopyFilter(runOpts *config.RunOptions) (copyFilter, error) {
+
+	f := copyFilter{isRun: true, isSet: true, isTask: true, status: []string{db.DoneRunStatus}}
+
+	if !isCopyFilterOptions(runOpts) {
+		return f, nil // filter is not active
+	}
+	f.isActive = true
+
+	// kind of objects to copy: run, set, task
+	if sl := helper.ParseCsvLine(runOpts.String(selectArgKey), ','); len(sl) > 0 {
+
+		f.isRun, f.isSet, f.isTask = false, false, false
+
+		for _, s := range sl {
+			switch strings.ToLower(s) {
+			case "":
+			case "run", "runs":
+				f.isRun = true
+			case "set", "sets", "workset", "worksets":
+				f.isSet = true
+			case "task", "tasks":
+				f.isTask = true
+			default:
+				return f, errors.New("dbcopy invalid argument " + selectArgKey + ": " + s + ", expected any of: run, set, task")
+			}
+		}
+	}
+
+	// date-time range, it can be a date: 2024-07-01 or date-time: 2024-07-01 12:30:00.000
+	f.fromDate = runOpts.String(fromDateArgKey)
+	f.toDate = runOpts.String(toDateArgKey)
+
+	for _, dt := range []string{f.fromDate, f.toDate} {
+		if dt != "" {
+			if _, e := time.Parse("2006-01-02", dt[:min(len(dt), 10)]); e != nil {
+				return f, errors.New("dbcopy invalid date, expected: YYYY-MM-DD or YYYY-MM-DD hh:mm:ss: " + dt)
+			}
+		}
+	}
+	if f.fromDate != "" && f.toDate != "" && f.fromDate > f.toDate {
+		return f, errors.New("dbcopy invalid date range: " + f.fromDate + " " + f.toDate)
+	}
+
+	// model run status: success, error, exit
+	if sl := helper.ParseCsvLine(runOpts.String(runStatusArgKey), ','); len(sl) > 0 {
+
+		f.status = []string{}
+
+		for _, s := range sl {
+			switch strings.ToLower(s) {
+			case "":
+			case "s", "success":
+				f.status = append(f.status, db.DoneRunStatus)
+			case "e", "error":
+				f.status = append(f.status, db.ErrorRunStatus)
+			case "x", "exit":
+				f.status = append(f.status, db.ExitRunStatus)
+			case "completed":
+				f.status = append(f.status, db.DoneRunStatus, db.ErrorRunStatus, db.ExitRunStatus)
+			default:
+				return f, errors.New("dbcopy invalid argument " + runStatusArgKey + ": " + s + ", expected any of: success, error, exit, completed")
+			}
+		}
+		if len(f.status) <= 0 {
+			return f, errors.New("dbcopy invalid (empty) argument " + runStatusArgKey)
+		}
+	}
+
+	// name glob pattern and regular expression
+	if f.nameGlob = runOpts.String(nameMatchArgKey); f.nameGlob != "" {
+		if _, e := path.Match(f.nameGlob, ""); e != nil {
+			return f, errors.New("dbcopy invalid name pattern: " + f.nameGlob + ": " + e.Error())
+		}
+	}
+	if sr := runOpts.String(nameRegexArgKey); sr != "" {
+		re, e := regexp.Compile(sr)
+		if e != nil {
+			return f, errors.New("dbcopy invalid name regular expression: " + sr + ": " + e.Error())
+		}
+		f.nameRe = re
+	}
+
+	// last N model runs
+	if runOpts.IsExist(lastRunsArgKey) {
+		if f.lastCount = runOpts.Int(lastRunsArgKey, 0); f.lastCount <= 0 {
+			return f, errors.New("dbcopy invalid argument " + lastRunsArgKey + ": " + runOpts.String(lastRunsArgKey))
+		}
+	}
+
+	return f, nil
+}
+
+// return true if only successfully completed model runs can be selected
+func (f *copyFilter) isSuccessOnly() bool {
+	return len(f.status) == 1 && f.status[0] == db.DoneRunStatus
+}
+
+// return true if name match to name pattern and regular expression
+func (f *copyFilter) isNameMatch(name string) bool {
+
+	if f.nameGlob != "" {
+		if ok, _ := path.Match(f.nameGlob, name); !ok {
+			return false
+		}
+	}
+	return f.nameRe == nil || f.nameRe.MatchString(name)
+}
+
+// return true if date-time is in the date-time range, range bounds are inclusive, ex.: 2024-09-30 includes 2024-09-30 23:59:59
+func (f *copyFilter) isDateMatch(dt string) bool {
+
+	if f.fromDate != "" && dt < f.fromDate {
+		return false
+	}
+	if f.toDate != "" && dt[:min(len(dt), len(f.toDate))] > f.toDate {
+		return false
+	}
+	return true
+}
+
+// select model runs by status, create date-time and run name and optionally return only last N runs.
+func (f *copyFilter) runs(rl []db.RunMeta) []db.RunMeta {
+
+	if !f.isActive {
+		return rl
+	}
+	if !f.isRun {
+		return []db.RunMeta{}
+	}
+
+	n := 0
+	for k := range rl {
+
+		isStatus := false
+		for j := 0; !isStatus && j < len(f.status); j++ {
+			isStatus = rl[k].Run.Status == f.status[j]
+		}
+		if isStatus && f.isDateMatch(rl[k].Run.CreateDateTime) && f.isNameMatch(rl[k].Run.Name) {
+			rl[n] = rl[k]
+			n++
+		}
+	}
+	rl = rl[:n]
+
+	if f.lastCount > 0 && len(rl) > f.lastCount {
+		rl = rl[len(rl)-f.lastCount:]
+	}
+
+	f.runIds = map[int]bool{}
+	for k := range rl {
+		f.runIds[rl[k].Run.RunId] = true
+	}
+	return rl
+}
+
+// select worksets by update date-time and workset name.
+func (f *copyFilter) worksets(wl []db.WorksetMeta) []db.WorksetMeta {
+
+	if !f.isActive {
+		return wl
+	}
+	if !f.isSet {
+		return []db.WorksetMeta{}
+	}
+
+	f.setIds = map[int]bool{}
+
+	n := 0
+	for k := range wl {
+		if f.isDateMatch(wl[k].Set.UpdateDateTime) && f.isNameMatch(wl[k].Set.Name) {
+			wl[n] = wl[k]
+			f.setIds[wl[k].Set.SetId] = true
+			n++
+		}
+	}
+	return wl[:n]
+}
+
+// select modeling tasks by task name.
+// If model runs or worksets are copied then task is skipped if any of task worksets or task run model runs or worksets are not selected.
+func (f *copyFilter) tasks(tl []db.TaskMeta) []db.TaskMeta {
+
+	if !f.isActive {
+		return tl
+	}
+	if !f.isTask {
+		return []db.TaskMeta{}
+	}
+
+	n := 0
+	for k := range tl {
+		if !f.isNameMatch(tl[k].Task.Name) {
+			continue
+		}
+		if !f.isTaskSelected(&tl[k]) {
+			omppLog.Log("Warning: skip task ", tl[k].Task.Name, ", task worksets or model runs are not selected")
+			continue
+		}
+		tl[n] = tl[k]
+		n++
+	}
+	return tl[:n]
+}
+
+// return true if all task worksets and all model runs and worksets of task run history are selected.
+// Only references to the kind of objects which are part of the copy are checked:
+// if model runs are not copied then task run model runs are not checked, if worksets are not copied then task worksets are not checked.
+func (f *copyFilter) isTaskSelected(t *db.TaskMeta) bool {
+
+	if f.isSet {
+		for _, id := range t.Set {
+			if !f.setIds[id] {
+				return false
+			}
+		}
+	}
+	for j := range t.TaskRun {
+		for _, trs := range t.TaskRun[j].TaskRunSet {
+			if f.isRun && trs.RunId > 0 && !f.runIds[trs.RunId] || f.isSet && trs.SetId > 0 && !f.setIds[trs.SetId] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// return short description of filter to log
+func (f *copyFilter) String() string {
+
+	s := ""
+	if f.isRun {
+		s += " runs"
+	}
+	if f.isSet {
+		s += " sets"
+	}
+	if f.isTask {
+		s += " tasks"
+	}
+	if f.fromDate != "" {
+		s += " from: " + f.fromDate
+	}
+	if f.toDate != "" {
+		s += " to: " + f.toDate
+	}
+	s += " status: " + strings.Join(f.status, ",")
+
+	if f.nameGlob != "" {
+		s += " name: " + f.nameGlob
+	}
+	if f.nameRe != nil {
+		s += " regex: " + f.nameRe.String()
+	}
+	if f.lastCount > 0 {
+		s += " last: " + strconv.Itoa(f.lastCount)
+	}
+	return s
+}
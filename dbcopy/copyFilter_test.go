@@ -0,0 +1,79 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+)
+
+func TestCopyFilterTasks(t *testing.T) {
+
+	// task 1 use workset 10 and run history of workset 10 and model run 100,
+	// task 2 use workset 20 and run history of workset 20 and model run 200,
+	// task 3 is empty: no worksets and no run history
+	tasks := func() []db.TaskMeta {
+		var tl []db.TaskMeta
+		err := json.Unmarshal([]byte(`[
+			{"Task": {"TaskId": 1, "Name": "task1"}, "Set": [10], "TaskRun": [{"TaskRunId": 1, "TaskRunSet": [{"TaskRunId": 1, "RunId": 100, "SetId": 10, "TaskId": 1}]}]},
+			{"Task": {"TaskId": 2, "Name": "task2"}, "Set": [20], "TaskRun": [{"TaskRunId": 2, "TaskRunSet": [{"TaskRunId": 2, "RunId": 200, "SetId": 20, "TaskId": 2}]}]},
+			{"Task": {"TaskId": 3, "Name": "task3"}}
+		]`), &tl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tl
+	}
+	runs := func() []db.RunMeta {
+		return []db.RunMeta{
+			{Run: db.RunRow{RunId: 100, Name: "run100", Status: db.DoneRunStatus, CreateDateTime: "2024-07-01 10:00:00.000"}},
+			{Run: db.RunRow{RunId: 200, Name: "run200", Status: db.DoneRunStatus, CreateDateTime: "2024-09-01 10:00:00.000"}},
+		}
+	}
+	worksets := func() []db.WorksetMeta {
+		return []db.WorksetMeta{
+			{Set: db.WorksetRow{SetId: 10, Name: "set10", UpdateDateTime: "2024-07-01 10:00:00.000"}},
+			{Set: db.WorksetRow{SetId: 20, Name: "set20", UpdateDateTime: "2024-09-01 10:00:00.000"}},
+		}
+	}
+
+	for _, c := range []struct {
+		opts  map[string]string
+		names []string
+	}{
+		{map[string]string{selectArgKey: "task"}, []string{"task1", "task2", "task3"}},
+		{map[string]string{selectArgKey: "run,task"}, []string{"task1", "task2", "task3"}},
+		{map[string]string{selectArgKey: "run,task", toDateArgKey: "2024-07-31"}, []string{"task1", "task3"}},
+		{map[string]string{selectArgKey: "set,task", fromDateArgKey: "2024-08-01"}, []string{"task2", "task3"}},
+		{map[string]string{selectArgKey: "task", toDateArgKey: "2024-07-31"}, []string{"task1", "task2", "task3"}},
+		{map[string]string{toDateArgKey: "2024-07-31"}, []string{"task1", "task3"}},
+	} {
+		f, err := parseCopyFilter(&config.RunOptions{KeyValue: c.opts})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.runs(runs())
+		f.worksets(worksets())
+
+		tl := f.tasks(tasks())
+
+		names := []string{}
+		for k := range tl {
+			names = append(names, tl[k].Task.Name)
+		}
+		if len(names) != len(c.names) {
+			t.Errorf("%v: expected tasks %v, got %v", c.opts, c.names, names)
+			continue
+		}
+		for k := range names {
+			if names[k] != c.names[k] {
+				t.Errorf("%v: expected tasks %v, got %v", c.opts, c.names, names)
+				break
+			}
+		}
+	}
+}
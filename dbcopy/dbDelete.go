@@ -180,7 +180,7 @@ func dbDeleteWorkset(modelName string, modelDigest string, runOpts *config.RunOp
 	// delete workset metadata and workset parameter values from database
 	omppLog.Log("Delete workset ", wsRow.SetId, " ", wsRow.Name)
 
-	err = db.DeleteWorkset(srcDb, wsRow.SetId)
+	err = db.DeleteWorkset(srcDb, wsRow.SetId, "")
 	if err != nil {
 		return errors.New("failed to delete workset " + strconv.Itoa(wsRow.SetId) + " " + wsRow.Name + " " + err.Error())
 	}
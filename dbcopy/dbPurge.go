@@ -0,0 +1,118 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// delete old model runs by retention policy: keep last N runs for each run name and all runs referenced by worksets or modeling tasks.
+// Model runs to delete are archived into modelName.purge.timestamp.zip, deleted from database and SQLite database is compacted by VACUUM.
+// If dry run option specified then only log model runs which would be deleted.
+func dbPurgeRuns(modelName string, modelDigest string, runOpts *config.RunOptions) error {
+
+	// retention policy: number of last runs to keep for each run name
+	keepLast := runOpts.Int(keepLastArgKey, -1)
+	if keepLast < 0 {
+		return errors.New("dbcopy invalid argument(s) number of model runs to keep: " + runOpts.String(keepLastArgKey))
+	}
+	isDryRun := runOpts.Bool(dryRunArgKey)
+
+	// open source database connection and check is it valid
+	cs, dn := db.IfEmptyMakeDefault(modelName, runOpts.String(fromSqliteArgKey), runOpts.String(dbConnStrArgKey), runOpts.String(dbDriverArgKey))
+
+	srcDb, dbFacet, err := db.Open(cs, dn, false)
+	if err != nil {
+		return err
+	}
+	defer srcDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(srcDb); err != nil {
+		return err
+	}
+
+	// get model metadata
+	modelDef, err := db.GetModel(srcDb, modelName, modelDigest)
+	if err != nil {
+		return err
+	}
+	modelName = modelDef.Model.Name // set model name: it can be empty and only model digest specified
+
+	// find model runs to delete
+	rl, err := db.SelectRunsToPurge(srcDb, modelDef.Model.ModelId, db.RunPurgePolicy{KeepLast: keepLast})
+	if err != nil {
+		return err
+	}
+	if isDryRun {
+		omppLog.Log("Dry run: model runs to delete: ", len(rl), ", keep last ", keepLast, " runs of each name")
+	} else {
+		omppLog.Log("Model runs to delete: ", len(rl), ", keep last ", keepLast, " runs of each name")
+	}
+	for k := range rl {
+		omppLog.Log("  ", rl[k].RunId, " ", rl[k].Name, " ", db.NameOfRunStatus(rl[k].Status), " ", rl[k].CreateDateTime, " ", rl[k].RunDigest)
+	}
+	if isDryRun || len(rl) <= 0 {
+		return nil // dry run or nothing to delete
+	}
+
+	// archive model runs: write model metadata and each model run text files directly into modelName.purge.timestamp.zip
+	// archive can be restored by: dbcopy -m modelName -dbcopy.To db -dbcopy.InputDir modelName.purge.timestamp
+	arcDir := filepath.Join(runOpts.String(outputDirArgKey), modelName+".purge."+helper.MakeTimeStamp(time.Now()))
+	outDir := filepath.Join(arcDir, modelName)
+
+	if _, err = createZipOut(arcDir); err != nil {
+		return err
+	}
+	defer closeZipOut()
+
+	if err = mkdirOut(outDir); err != nil {
+		return err
+	}
+
+	if err = toModelJson(srcDb, modelDef, outDir); err != nil {
+		return err
+	}
+
+	fileCreated := make(map[string]bool)
+
+	for k := range rl {
+
+		meta, err := db.GetRunFullText(srcDb, &rl[k], true, "")
+		if err != nil {
+			return err
+		}
+		if err = toRunText(srcDb, modelDef, meta, outDir, "", fileCreated, true); err != nil {
+			return err
+		}
+	}
+
+	if err = closeZipOut(); err != nil {
+		return err
+	}
+
+	// delete model runs
+	for k := range rl {
+
+		omppLog.Log("Delete model run ", rl[k].RunId, " ", rl[k].Name)
+
+		if err = db.DeleteRun(srcDb, rl[k].RunId); err != nil {
+			return errors.New("model run delete failed " + strconv.Itoa(rl[k].RunId) + " " + rl[k].Name + " " + err.Error())
+		}
+	}
+	omppLog.Log("Deleted model runs: ", len(rl))
+
+	// compact SQLite database file
+	if dbFacet == db.SqliteFacet {
+		omppLog.Log("Vacuum database")
+	}
+	return db.Vacuum(srcDb, dbFacet)
+}
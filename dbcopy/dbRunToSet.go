@@ -0,0 +1,84 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// create new workset from model run parameters: parameter values, sub-values and value notes
+func dbRunToWorkset(modelName string, modelDigest string, runOpts *config.RunOptions) error {
+
+	// new workset name argument required and cannot be empty
+	setName := runOpts.String(runToSetArgKey)
+	if setName == "" {
+		return errors.New("dbcopy invalid (empty or missing) argument of: " + runToSetArgKey)
+	}
+
+	// open source database connection and check is it valid
+	cs, dn := db.IfEmptyMakeDefault(modelName, runOpts.String(fromSqliteArgKey), runOpts.String(dbConnStrArgKey), runOpts.String(dbDriverArgKey))
+
+	srcDb, _, err := db.Open(cs, dn, false)
+	if err != nil {
+		return err
+	}
+	defer srcDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(srcDb); err != nil {
+		return err
+	}
+
+	// get model metadata and languages
+	modelDef, err := db.GetModel(srcDb, modelName, modelDigest)
+	if err != nil {
+		return err
+	}
+	langDef, err := db.GetLanguages(srcDb)
+	if err != nil {
+		return err
+	}
+
+	// find model run metadata by id, run digest or name
+	runId, runDigest, runName, isFirst, isLast := runIdDigestNameFromOptions(runOpts)
+	if runId < 0 || runId == 0 && runName == "" && runDigest == "" && !isFirst && !isLast {
+		return errors.New("dbcopy invalid argument(s) run id: " + runOpts.String(runIdArgKey) + ", run name: " + runOpts.String(runNameArgKey) + ", run digest: " + runOpts.String(runDigestArgKey))
+	}
+	runRow, e := findModelRunByIdDigestName(srcDb, modelDef.Model.ModelId, runId, runDigest, runName, isFirst, isLast)
+	if e != nil {
+		return e
+	}
+	if runRow == nil {
+		return errors.New("model run not found: " + runOpts.String(runIdArgKey) + " " + runOpts.String(runNameArgKey) + " " + runOpts.String(runDigestArgKey))
+	}
+
+	// check is this run belong to the model
+	if runRow.ModelId != modelDef.Model.ModelId {
+		return errors.New("model run " + strconv.Itoa(runRow.RunId) + " " + runRow.Name + " " + runRow.RunDigest + " does not belong to model " + modelName + " " + modelDigest)
+	}
+
+	// run must be completed: status success, error or exit
+	if !db.IsRunCompleted(runRow.Status) {
+		return errors.New("model run not completed: " + strconv.Itoa(runRow.RunId) + " " + runRow.Name + " " + runRow.RunDigest)
+	}
+
+	// if parameters list specified then copy only those parameters, all other parameters inherited from the base run
+	pLst := helper.ParseCsvLine(runOpts.String(paramListArgKey), ',')
+
+	omppLog.Log("Create workset ", setName, " from model run ", runRow.RunId, " ", runRow.Name)
+
+	wm := db.WorksetMeta{Set: db.WorksetRow{Name: setName}}
+
+	if err = db.CreateWorksetFromRun(srcDb, modelDef, langDef, runRow, &wm, pLst); err != nil {
+		return errors.New("failed to create workset " + setName + " from model run " + strconv.Itoa(runRow.RunId) + " " + runRow.Name + ": " + err.Error())
+	}
+	omppLog.Log("Created workset ", wm.Set.SetId, " ", wm.Set.Name)
+
+	return nil
+}
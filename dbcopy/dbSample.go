@@ -0,0 +1,40 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"database/sql"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// create worksets from samples of uncertain parameters and modeling task from sampling plan json file
+func dbSampleTask(modelName string, modelDigest string, runOpts *config.RunOptions) error {
+
+	// read sampling plan from json file
+	var sp db.SamplePub
+	if err := taskPlanFromJsonFile(runOpts, sampleArgKey, "sampling plan", &sp, &sp.Name); err != nil {
+		return err
+	}
+
+	// create sample worksets and modeling task
+	return dbWorksetsTask(modelName, modelDigest, runOpts, sp.Name, "sample",
+		func(srcDb *sql.DB, modelDef *db.ModelMeta, langDef *db.LangMeta) (*db.TaskDefPub, error) {
+
+			omppLog.Log("Parameter sampling ", sp.Name)
+
+			rules, err := paramRules(srcDb, modelDef, true)
+			if err != nil {
+				return nil, err
+			}
+			tpd, err := db.CreateSampleWorksets(srcDb, modelDef, langDef, &sp, rules)
+			if err != nil {
+				return nil, err
+			}
+			omppLog.Log("Created worksets: ", len(tpd.Set), " method: ", sp.Method, " seed: ", sp.Seed)
+			return tpd, nil
+		})
+}
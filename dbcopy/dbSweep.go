@@ -0,0 +1,130 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// create parameter sweep worksets and modeling task from sweep json file
+func dbSweepTask(modelName string, modelDigest string, runOpts *config.RunOptions) error {
+
+	// read parameter sweep from json file
+	var sw db.SweepPub
+	if err := taskPlanFromJsonFile(runOpts, sweepArgKey, "parameter sweep", &sw, &sw.Name); err != nil {
+		return err
+	}
+
+	// create sweep worksets and modeling task
+	return dbWorksetsTask(modelName, modelDigest, runOpts, sw.Name, "parameter sweep",
+		func(srcDb *sql.DB, modelDef *db.ModelMeta, langDef *db.LangMeta) (*db.TaskDefPub, error) {
+
+			omppLog.Log("Parameter sweep ", sw.Name)
+
+			rules, err := paramRules(srcDb, modelDef, true)
+			if err != nil {
+				return nil, err
+			}
+			tpd, err := db.CreateSweepWorksets(srcDb, modelDef, langDef, &sw, rules)
+			if err != nil {
+				return nil, err
+			}
+			omppLog.Log("Created worksets: ", len(tpd.Set))
+			return tpd, nil
+		})
+}
+
+// read parameter sweep or sampling plan from json file, path to json file is a value of argKey.
+// Task name from command line take precedence over task name from json file.
+func taskPlanFromJsonFile(runOpts *config.RunOptions, argKey, what string, plan any, taskName *string) error {
+
+	inpPath := runOpts.String(argKey)
+	if inpPath == "" {
+		return errors.New("dbcopy invalid (empty or missing) argument of: " + argKey)
+	}
+
+	isExist, err := helper.FromJsonFile(inpPath, plan)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		return errors.New(what + " file not found or empty: " + inpPath)
+	}
+
+	if runOpts.IsExist(taskNameArgKey) {
+		*taskName = runOpts.String(taskNameArgKey)
+	}
+	if *taskName == "" {
+		return errors.New("dbcopy invalid (empty) " + what + " task name, use: " + taskNameArgKey)
+	}
+	return nil
+}
+
+// create worksets using makeWorksets function and modeling task from all created worksets, modeling task must not exist
+func dbWorksetsTask(
+	modelName, modelDigest string,
+	runOpts *config.RunOptions,
+	taskName, what string,
+	makeWorksets func(srcDb *sql.DB, modelDef *db.ModelMeta, langDef *db.LangMeta) (*db.TaskDefPub, error),
+) error {
+
+	// open source database connection and check is it valid
+	cs, dn := db.IfEmptyMakeDefault(modelName, runOpts.String(fromSqliteArgKey), runOpts.String(dbConnStrArgKey), runOpts.String(dbDriverArgKey))
+
+	srcDb, _, err := db.Open(cs, dn, false)
+	if err != nil {
+		return err
+	}
+	defer srcDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(srcDb); err != nil {
+		return err
+	}
+
+	// get model metadata and languages
+	modelDef, err := db.GetModel(srcDb, modelName, modelDigest)
+	if err != nil {
+		return err
+	}
+	langDef, err := db.GetLanguages(srcDb)
+	if err != nil {
+		return err
+	}
+
+	// modeling task must not exist
+	t, err := db.GetTaskByName(srcDb, modelDef.Model.ModelId, taskName)
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		return errors.New("modeling task already exist: " + taskName)
+	}
+
+	// create worksets
+	tpd, err := makeWorksets(srcDb, modelDef, langDef)
+	if err != nil {
+		return err
+	}
+
+	// create modeling task from worksets
+	tm, isSetNotFound, _, err := (&db.TaskPub{TaskDefPub: *tpd}).FromPublic(srcDb, modelDef, true)
+	if err != nil {
+		return err
+	}
+	if isSetNotFound {
+		return errors.New("failed to create modeling task, " + what + " workset(s) not found: " + taskName)
+	}
+	if err = tm.ReplaceTaskDef(srcDb, modelDef, langDef); err != nil {
+		return errors.New("failed to create modeling task: " + taskName + ": " + err.Error())
+	}
+	omppLog.Log("Created modeling task: ", taskName)
+
+	return nil
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// counters of synchronized objects: new copied, replaced because changed or incomplete and skipped because up to date
+type syncCount struct {
+	copied   int // new objects copied into destination
+	replaced int // objects copied again because source changed or destination copy incomplete
+	skipped  int // objects already up to date in destination
+}
+
+// synchronize model from source database to destination database:
+// copy only missing or changed model runs, worksets and modeling tasks.
+//
+// Source and destination compared by model digest, model run digest and value digest,
+// workset name and update date-time and modeling task definition and task run history.
+// Each object copied independently, if synchronization interrupted then it can be restarted
+// and objects already copied are skipped, incomplete destination objects are copied again.
+func dbSyncDbToDb(modelName string, modelDigest string, runOpts *config.RunOptions) error {
+
+	// validate source and destination
+	csInp, dnInp := db.IfEmptyMakeDefaultReadOnly(modelName, runOpts.String(fromSqliteArgKey), runOpts.String(dbConnStrArgKey), runOpts.String(dbDriverArgKey))
+	csOut, dnOut := db.IfEmptyMakeDefault(modelName, runOpts.String(toSqliteArgKey), runOpts.String(toDbConnStrArgKey), runOpts.String(toDbDriverArgKey))
+
+	if csInp == csOut && dnInp == dnOut {
+		return errors.New("source same as destination: cannot overwrite model in database")
+	}
+
+	// open source database connection and check is it valid
+	srcDb, _, err := db.Open(csInp, dnInp, false)
+	if err != nil {
+		return err
+	}
+	defer srcDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(srcDb); err != nil {
+		return err
+	}
+
+	// open destination database and check is it valid
+	dstDb, dbFacet, err := db.Open(csOut, dnOut, true)
+	if err != nil {
+		return err
+	}
+	defer dstDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(dstDb); err != nil {
+		return err
+	}
+
+	// source to destination: insert model metadata if not exists, update model text and profile
+	srcModel, dstModel, dstLang, isExist, err := copyModelDbToDb(srcDb, dstDb, dbFacet, modelName, modelDigest)
+	if err != nil {
+		return err
+	}
+	if isExist {
+		omppLog.Log("Model ", srcModel.Model.Name, " ", srcModel.Model.Digest, " already exists")
+	} else {
+		omppLog.Log("Model ", srcModel.Model.Name, " ", srcModel.Model.Digest, " copied")
+	}
+
+	// source to destination: copy missing, changed or incomplete model runs
+	runCount, err := syncRunListDbToDb(srcDb, dstDb, dbFacet, srcModel, dstModel, dstLang)
+	if err != nil {
+		return err
+	}
+
+	// source to destination: copy missing or updated readonly worksets
+	setCount, err := syncWorksetListDbToDb(srcDb, dstDb, srcModel, dstModel, dstLang)
+	if err != nil {
+		return err
+	}
+
+	// source to destination: copy missing or changed modeling tasks
+	taskCount, err := syncTaskListDbToDb(srcDb, dstDb, srcModel, dstModel, dstLang)
+	if err != nil {
+		return err
+	}
+
+	// report summary
+	omppLog.Log("Model runs copied: ", runCount.copied, ", replaced: ", runCount.replaced, ", up to date: ", runCount.skipped)
+	omppLog.Log("Worksets   copied: ", setCount.copied, ", replaced: ", setCount.replaced, ", up to date: ", setCount.skipped)
+	omppLog.Log("Tasks      copied: ", taskCount.copied, ", replaced: ", taskCount.replaced, ", up to date: ", taskCount.skipped)
+
+	return nil
+}
+
+// syncRunListDbToDb copy completed model runs which are not exist in destination database,
+// which value digest is different or which copy in destination database is incomplete.
+func syncRunListDbToDb(
+	srcDb *sql.DB, dstDb *sql.DB, dbFacet db.Facet, srcModel *db.ModelMeta, dstModel *db.ModelMeta, dstLang *db.LangMeta) (syncCount, error) {
+
+	cnt := syncCount{}
+
+	// source: get list of model runs
+	rl, err := db.GetRunList(srcDb, srcModel.Model.ModelId)
+	if err != nil {
+		return cnt, err
+	}
+
+	for k := range rl {
+
+		if !db.IsRunCompleted(rl[k].Status) {
+			continue // skip: run not completed
+		}
+
+		// find destination run by digest and check if it is up to date
+		dstRow, isUpToDate, err := isRunSyncDbToDb(srcDb, dstDb, dstModel, &rl[k])
+		if err != nil {
+			return cnt, err
+		}
+		if isUpToDate {
+			cnt.skipped++
+			continue
+		}
+
+		// if destination run exists then it is changed or incomplete: delete it and copy again
+		if dstRow != nil {
+			omppLog.Log("Model run ", rl[k].RunId, " ", rl[k].Name, " changed or incomplete, replace ", dstRow.RunId)
+
+			if err = db.DeleteRun(dstDb, dstRow.RunId); err != nil {
+				return cnt, errors.New("failed to delete model run " + strconv.Itoa(dstRow.RunId) + " " + dstRow.Name + " " + err.Error())
+			}
+		}
+
+		// source: get full model run metadata and convert it into "public" format
+		meta, err := db.GetRunFullText(srcDb, &rl[k], false, "")
+		if err != nil {
+			return cnt, err
+		}
+		pub, err := meta.ToPublic(srcDb, srcModel)
+		if err != nil {
+			return cnt, err
+		}
+
+		// copy source model run metadata, parameter values, output results into destination database
+		if _, err = copyRunDbToDb(srcDb, dstDb, dbFacet, srcModel, dstModel, rl[k].RunId, pub, dstLang); err != nil {
+			return cnt, err
+		}
+		if dstRow != nil {
+			cnt.replaced++
+		} else {
+			cnt.copied++
+		}
+	}
+	return cnt, nil
+}
+
+// isRunSyncDbToDb find destination model run by source run digest and return true if destination run is up to date.
+// Destination run is not up to date if value digest is different
+// or run parameters, output tables or microdata count is not the same as in source model run,
+// for example, if previous copy was interrupted.
+func isRunSyncDbToDb(srcDb *sql.DB, dstDb *sql.DB, dstModel *db.ModelMeta, srcRow *db.RunRow) (*db.RunRow, bool, error) {
+
+	dstRow, err := db.GetRunByDigest(dstDb, srcRow.RunDigest)
+	if err != nil {
+		return nil, false, err
+	}
+	if dstRow == nil || dstRow.ModelId != dstModel.Model.ModelId {
+		return nil, false, nil // run not exist in destination model
+	}
+
+	if srcRow.ValueDigest != "" && dstRow.ValueDigest != "" && srcRow.ValueDigest != dstRow.ValueDigest {
+		return dstRow, false, nil // run values are different
+	}
+
+	// compare count of run parameters, output tables and microdata entities
+	srcMeta, err := db.GetRunFull(srcDb, srcRow)
+	if err != nil {
+		return nil, false, err
+	}
+	dstMeta, err := db.GetRunFull(dstDb, dstRow)
+	if err != nil {
+		return nil, false, err
+	}
+	isUpToDate := len(srcMeta.Param) == len(dstMeta.Param) &&
+		len(srcMeta.Table) == len(dstMeta.Table) &&
+		len(srcMeta.RunEntity) == len(dstMeta.RunEntity)
+
+	return dstRow, isUpToDate, nil
+}
+
+// syncWorksetListDbToDb copy readonly worksets which are not exist in destination database,
+// which updated in source database after it was copied or which copy in destination database is incomplete.
+func syncWorksetListDbToDb(
+	srcDb *sql.DB, dstDb *sql.DB, srcModel *db.ModelMeta, dstModel *db.ModelMeta, dstLang *db.LangMeta) (syncCount, error) {
+
+	cnt := syncCount{}
+
+	// source: get list of worksets
+	wl, err := db.GetWorksetList(srcDb, srcModel.Model.ModelId)
+	if err != nil {
+		return cnt, err
+	}
+
+	for k := range wl {
+
+		if !wl[k].IsReadonly {
+			continue // skip: only readonly worksets can be copied
+		}
+
+		// destination workset is up to date if it is readonly and updated after source workset
+		// if workset copy was interrupted then destination workset is read-write
+		dstRow, err := db.GetWorksetByName(dstDb, dstModel.Model.ModelId, wl[k].Name)
+		if err != nil {
+			return cnt, err
+		}
+		if dstRow != nil && dstRow.IsReadonly && dstRow.UpdateDateTime >= wl[k].UpdateDateTime {
+			cnt.skipped++
+			continue
+		}
+
+		// source: get full workset metadata and convert it into "public" format
+		meta, err := db.GetWorksetFull(srcDb, &wl[k], "")
+		if err != nil {
+			return cnt, err
+		}
+		pub, err := meta.ToPublic(srcDb, srcModel)
+		if err != nil {
+			return cnt, err
+		}
+
+		// copy source workset metadata and parameters into destination database, existing workset parameters replaced
+		if _, err = copyWorksetDbToDb(srcDb, dstDb, srcModel, dstModel, wl[k].SetId, pub, dstLang); err != nil {
+			return cnt, err
+		}
+		if dstRow != nil {
+			cnt.replaced++
+		} else {
+			cnt.copied++
+		}
+	}
+	return cnt, nil
+}
+
+// syncTaskListDbToDb copy modeling tasks which are not exist in destination database
+// or which task definition or task run history is different from destination.
+func syncTaskListDbToDb(
+	srcDb *sql.DB, dstDb *sql.DB, srcModel *db.ModelMeta, dstModel *db.ModelMeta, dstLang *db.LangMeta) (syncCount, error) {
+
+	cnt := syncCount{}
+
+	// source: get all modeling tasks metadata in all languages
+	tl, err := db.GetTaskFullList(srcDb, srcModel.Model.ModelId, true, "")
+	if err != nil {
+		return cnt, err
+	}
+
+	for k := range tl {
+
+		// convert task metadata db rows into "public"" format
+		pub, err := tl[k].ToPublic(srcDb, srcModel)
+		if err != nil {
+			return cnt, err
+		}
+
+		// find destination task and compare task "public" metadata: task text, worksets and task run history
+		dstRow, err := db.GetTaskByName(dstDb, dstModel.Model.ModelId, pub.Name)
+		if err != nil {
+			return cnt, err
+		}
+		if dstRow != nil {
+
+			dstMeta, err := db.GetTaskFull(dstDb, dstRow, true, "")
+			if err != nil {
+				return cnt, err
+			}
+			dstPub, err := dstMeta.ToPublic(dstDb, dstModel)
+			if err != nil {
+				return cnt, err
+			}
+
+			srcJs, err := json.Marshal(pub)
+			if err != nil {
+				return cnt, err
+			}
+			dstJs, err := json.Marshal(dstPub)
+			if err != nil {
+				return cnt, err
+			}
+			if bytes.Equal(srcJs, dstJs) {
+				cnt.skipped++
+				continue
+			}
+		}
+
+		// save into destination database
+		if _, err = copyTaskDbToDb(srcDb, dstDb, srcModel, dstModel, tl[k].Task.TaskId, pub, dstLang); err != nil {
+			return cnt, err
+		}
+		if dstRow != nil {
+			cnt.replaced++
+		} else {
+			cnt.copied++
+		}
+	}
+	return cnt, nil
+}
@@ -7,7 +7,6 @@ import (
 	"database/sql"
 	"encoding/csv"
 	"errors"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -15,7 +14,6 @@ import (
 	"github.com/openmpp/go/ompp/config"
 	"github.com/openmpp/go/ompp/db"
 	"github.com/openmpp/go/ompp/helper"
-	"github.com/openmpp/go/ompp/omppLog"
 )
 
 // lineCsvConverter return csv file row []string or isEof = true
@@ -44,17 +42,16 @@ func dbToCsv(modelName string, modelDigest string, isAllInOne bool, runOpts *con
 	}
 	modelName = modelDef.Model.Name // set model name: it can be empty and only model digest specified
 
-	// create new output directory, use modelName subdirectory
+	// create new output directory or zip archive, use modelName subdirectory
 	outDir := filepath.Join(runOpts.String(outputDirArgKey), modelName)
 
-	if !theCfg.isKeepOutputDir {
-		if ok := dirDeleteAndLog(outDir); !ok {
-			return errors.New("Error: unable to delete: " + outDir)
-		}
-	}
-	if err = os.MkdirAll(outDir, 0750); err != nil {
+	// all-in-one csv files are appended by each model run and workset, it is not possible to do in zip stream
+	isZipStream := runOpts.Bool(zipStreamArgKey) && !isAllInOne
+
+	if err = makeOutDir(outDir, isZipStream); err != nil {
 		return err
 	}
+	defer closeZipOut()
 	fileCreated := make(map[string]bool)
 
 	// write model definition into csv files
@@ -110,13 +107,9 @@ func dbToCsv(modelName string, modelDigest string, isAllInOne bool, runOpts *con
 		return err
 	}
 
-	// pack model metadata, run results and worksets into zip
-	if runOpts.Bool(zipArgKey) {
-		zipPath, err := helper.PackZip(outDir, !theCfg.isKeepOutputDir, "")
-		if err != nil {
-			return err
-		}
-		omppLog.Log("Packed ", zipPath)
+	// close zip archive stream or pack all-in-one csv files into zip
+	if err = closeOutDir(outDir, runOpts.Bool(zipArgKey) || runOpts.Bool(zipStreamArgKey)); err != nil {
+		return err
 	}
 
 	return nil
@@ -669,7 +662,7 @@ func toCsvFile(
 	if theCfg.isTsv && strings.HasSuffix(fileName, ".csv") {
 		fileName = fileName[:len(fileName)-4] + ".tsv"
 	}
-	f, err := os.OpenFile(filepath.Join(csvDir, fileName), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	f, err := createOut(filepath.Join(csvDir, fileName), false)
 	if err != nil {
 		return err
 	}
@@ -6,7 +6,6 @@ package main
 import (
 	"database/sql"
 	"errors"
-	"os"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -55,14 +54,14 @@ func toRunCsv(
 	microCsvDir := filepath.Join(csvTop, "microdata"+dirSuffix)
 	nMd := len(meta.EntityGen)
 
-	if e := os.MkdirAll(paramCsvDir, 0750); e != nil {
+	if e := mkdirOut(paramCsvDir); e != nil {
 		return e
 	}
-	if e := os.MkdirAll(tableCsvDir, 0750); e != nil {
+	if e := mkdirOut(tableCsvDir); e != nil {
 		return e
 	}
 	if !theCfg.isNoMicrodata && nMd > 0 {
-		if e := os.MkdirAll(microCsvDir, 0750); e != nil {
+		if e := mkdirOut(microCsvDir); e != nil {
 			return e
 		}
 	}
@@ -84,6 +83,10 @@ func toRunCsv(
 	omppLog.Log("  Parameters: ", nP)
 	logT := time.Now().Unix()
 
+	// read parameters, output tables and microdata and write csv files concurrently
+	pool := newCsvOutPool()
+	defer pool.Wait() // on error wait until all running jobs completed
+
 	for j := 0; j < nP; j++ {
 
 		cvtParam := &db.CellParamConverter{
@@ -99,7 +102,7 @@ func toRunCsv(
 
 		logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nP, ": ", paramLt.Name)
 
-		err := toCellCsvFile(dbConn, modelDef, paramLt, cvtParam, fileCreated, paramCsvDir, firstCol, firstVal)
+		err := pool.Go(toCellCsvJob(dbConn, modelDef, paramLt, cvtParam, fileCreated, paramCsvDir, firstCol, firstVal))
 		if err != nil {
 			return err
 		}
@@ -175,7 +178,7 @@ func toRunCsv(
 
 		logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nT, ": ", tblLt.Name)
 
-		err := toCellCsvFile(dbConn, modelDef, tblLt, cvtExpr, fileCreated, tableCsvDir, firstCol, firstVal)
+		err := pool.Go(toCellCsvJob(dbConn, modelDef, tblLt, cvtExpr, fileCreated, tableCsvDir, firstCol, firstVal))
 		if err != nil {
 			return err
 		}
@@ -188,7 +191,7 @@ func toRunCsv(
 
 			logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nT, ": ", tblLt.Name, " accumulators")
 
-			err = toCellCsvFile(dbConn, modelDef, tblLt, cvtAcc, fileCreated, tableCsvDir, firstCol, firstVal)
+			err = pool.Go(toCellCsvJob(dbConn, modelDef, tblLt, cvtAcc, fileCreated, tableCsvDir, firstCol, firstVal))
 			if err != nil {
 				return err
 			}
@@ -199,7 +202,7 @@ func toRunCsv(
 
 			logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nT, ": ", tblLt.Name, " all accumulators")
 
-			err = toCellCsvFile(dbConn, modelDef, tblLt, cvtAll, fileCreated, tableCsvDir, firstCol, firstVal)
+			err = pool.Go(toCellCsvJob(dbConn, modelDef, tblLt, cvtAll, fileCreated, tableCsvDir, firstCol, firstVal))
 			if err != nil {
 				return err
 			}
@@ -238,11 +241,13 @@ func toRunCsv(
 
 			logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nMd, ": ", microLt.Name)
 
-			err := toCellCsvFile(dbConn, modelDef, microLt, cvtMicro, fileCreated, microCsvDir, firstCol, firstVal)
+			err := pool.Go(toCellCsvJob(dbConn, modelDef, microLt, cvtMicro, fileCreated, microCsvDir, firstCol, firstVal))
 			if err != nil {
 				return err
 			}
 		}
 	}
-	return nil
+
+	// wait until all csv files are written
+	return pool.Wait()
 }
@@ -22,11 +22,12 @@ func toRunListCsv(
 	isAllInOne bool,
 ) (bool, error) {
 
-	// get all successfully completed model runs
-	rl, err := db.GetRunFullTextList(dbConn, modelDef.Model.ModelId, true, "")
+	// get all successfully completed model runs or model runs selected by filter
+	rl, err := db.GetRunFullTextList(dbConn, modelDef.Model.ModelId, theCfg.filter.isSuccessOnly(), "")
 	if err != nil {
 		return false, err
 	}
+	rl = theCfg.filter.runs(rl)
 	if theCfg.isNoMicrodata { // microdata output disabled
 		for k := range rl {
 			rl[k].EntityGen = []db.EntityGenMeta{}
@@ -19,6 +19,7 @@ func toTaskListCsv(dbConn *sql.DB, modelId int, outDir string) error {
 	if err != nil {
 		return err
 	}
+	tl = theCfg.filter.tasks(tl)
 
 	// write modeling task rows into csv
 	row := make([]string, 3)
@@ -6,7 +6,6 @@ package main
 import (
 	"database/sql"
 	"errors"
-	"os"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -30,6 +29,7 @@ func toWorksetListCsv(
 	if err != nil {
 		return err
 	}
+	wl = theCfg.filter.worksets(wl)
 
 	// read all workset parameters and dump it into csv files
 	for k := range wl {
@@ -244,12 +244,12 @@ func toWorksetCsv(
 		}
 	}
 
-	if !isAllInOne && !theCfg.isKeepOutputDir {
+	if !isAllInOne && theZipOut == nil && !theCfg.isKeepOutputDir {
 		if ok := dirDeleteAndLog(csvDir); !ok {
 			return errors.New("Error: unable to delete: " + csvDir)
 		}
 	}
-	if err := os.MkdirAll(csvDir, 0750); err != nil {
+	if err := mkdirOut(csvDir); err != nil {
 		return err
 	}
 
@@ -62,35 +62,71 @@ func dbToDb(modelName string, modelDigest string, runOpts *config.RunOptions) er
 func copyDbToDb(
 	srcDb *sql.DB, dstDb *sql.DB, dbFacet db.Facet, modelName string, modelDigest string) error {
 
+	// source to destination: copy model metadata, languages, model text and profile
+	srcModel, dstModel, dstLang, _, err := copyModelDbToDb(srcDb, dstDb, dbFacet, modelName, modelDigest)
+	if err != nil {
+		return err
+	}
+
+	// source to destination: copy model runs: parameters, output expressions and accumulators
+	err = copyRunListDbToDb(srcDb, dstDb, dbFacet, srcModel, dstModel, dstLang)
+	if err != nil {
+		return err
+	}
+
+	// source to destination: copy all readonly worksets parameters
+	err = copyWorksetListDbToDb(srcDb, dstDb, srcModel, dstModel, dstLang)
+	if err != nil {
+		return err
+	}
+
+	// source to destination: copy all modeling tasks
+	err = copyTaskListDbToDb(srcDb, dstDb, srcModel, dstModel, dstLang)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// copyModelDbToDb select from source database and insert or update existing
+// model metadata in all languages, model language-specific strings and model default profile.
+//
+// It return source model metadata, destination model metadata with destination database id's,
+// destination languages and true if model already exist in destination database.
+func copyModelDbToDb(
+	srcDb *sql.DB, dstDb *sql.DB, dbFacet db.Facet, modelName string, modelDigest string,
+) (*db.ModelMeta, *db.ModelMeta, *db.LangMeta, bool, error) {
+
 	// source: get model metadata
 	srcModel, err := db.GetModel(srcDb, modelName, modelDigest)
 	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 	modelName = srcModel.Model.Name // set model name: it can be empty and only model digest specified
 
 	// source: get list of languages
 	srcLang, err := db.GetLanguages(srcDb)
 	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// source: get model text (description and notes) in all languages
 	modelTxt, err := db.GetModelText(srcDb, srcModel.Model.ModelId, "", true)
 	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// source: get model laguage-specific strings in all languages
 	mwDef, err := db.GetModelWord(srcDb, srcModel.Model.ModelId, "")
 	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// source: get model profile: default model profile is profile where name = model name
 	modelProfile, err := db.GetProfile(srcDb, modelName)
 	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// deep copy of model metadata and languages is required
@@ -99,63 +135,46 @@ func copyDbToDb(
 	// same for all other id's: type Hid, parameter Hid, table Hid, entity Hid. run id, set id, task id, etc.
 	dstModel, err := srcModel.Clone()
 	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 	dstLang, err := srcLang.Clone()
 	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// destination: insert model metadata into destination database if not exists
-	if _, err = db.UpdateModel(dstDb, dbFacet, dstModel); err != nil {
-		return err
+	isExist, err := db.UpdateModel(dstDb, dbFacet, dstModel)
+	if err != nil {
+		return nil, nil, nil, false, err
 	}
 
 	// destination: insert or update language list
 	if err = db.UpdateLanguage(dstDb, dstLang); err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// destination: get full list of languages in destination database
 	dstLang, err = db.GetLanguages(dstDb)
 	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// destination: insert, update or delete model default profile
 	if err = db.UpdateProfile(dstDb, modelProfile); err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// destination: insert or update model text data (description and notes)
 	if err = db.UpdateModelText(dstDb, dstModel, dstLang, modelTxt); err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
 	// destination: insert or update model language-specific strings
 	if err = db.UpdateModelWord(dstDb, dstModel, dstLang, mwDef); err != nil {
-		return err
-	}
-
-	// source to destination: copy model runs: parameters, output expressions and accumulators
-	err = copyRunListDbToDb(srcDb, dstDb, dbFacet, srcModel, dstModel, dstLang)
-	if err != nil {
-		return err
-	}
-
-	// source to destination: copy all readonly worksets parameters
-	err = copyWorksetListDbToDb(srcDb, dstDb, srcModel, dstModel, dstLang)
-	if err != nil {
-		return err
+		return nil, nil, nil, false, err
 	}
 
-	// source to destination: copy all modeling tasks
-	err = copyTaskListDbToDb(srcDb, dstDb, srcModel, dstModel, dstLang)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return srcModel, dstModel, dstLang, isExist, nil
 }
 
 // return closure to iterate over list until the last element
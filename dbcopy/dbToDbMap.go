@@ -0,0 +1,467 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"container/list"
+	"database/sql"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// copy model run from source model into destination model with different digest, for example, into new version of the model.
+// Parameters, output tables and entities are matched by name and enum id's mapped through enum codes.
+// Source and destination model can be in the same database.
+func dbToDbRunMap(modelName string, modelDigest string, runOpts *config.RunOptions) error {
+
+	// validate source and destination
+	csInp, dnInp := db.IfEmptyMakeDefaultReadOnly(modelName, runOpts.String(fromSqliteArgKey), runOpts.String(dbConnStrArgKey), runOpts.String(dbDriverArgKey))
+	csOut, dnOut := db.IfEmptyMakeDefault(modelName, runOpts.String(toSqliteArgKey), runOpts.String(toDbConnStrArgKey), runOpts.String(toDbDriverArgKey))
+
+	isSameDb := csInp == csOut && dnInp == dnOut
+
+	// open source database connection and check is it valid
+	srcDb, _, err := db.Open(csInp, dnInp, false)
+	if err != nil {
+		return err
+	}
+	defer srcDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(srcDb); err != nil {
+		return err
+	}
+
+	// open destination database and check is it valid
+	dstDb, dbFacet, err := db.Open(csOut, dnOut, true)
+	if err != nil {
+		return err
+	}
+	defer dstDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(dstDb); err != nil {
+		return err
+	}
+
+	// source: get model metadata
+	srcModel, err := db.GetModel(srcDb, modelName, modelDigest)
+	if err != nil {
+		return err
+	}
+	modelName = srcModel.Model.Name // set model name: it can be empty and only model digest specified
+
+	// destination: get model metadata by name and destination model digest
+	dstModel, err := db.GetModel(dstDb, modelName, runOpts.String(toModelDigestArgKey))
+	if err != nil {
+		return err
+	}
+	if isSameDb && srcModel.Model.Digest == dstModel.Model.Digest {
+		return errors.New("source model same as destination: " + modelName + " " + srcModel.Model.Digest + ", use " + toModelDigestArgKey + " to specify destination model")
+	}
+	omppLog.Log("Map model run from ", modelName, " ", srcModel.Model.Digest, " into ", dstModel.Model.Name, " ", dstModel.Model.Digest)
+
+	// compare source and destination models and report mismatches
+	rm, err := db.NewRunMapping(srcModel, dstModel)
+	if err != nil {
+		return err
+	}
+	logMapIssues(rm)
+
+	if err = mapFillDefault(dstDb, rm); err != nil {
+		return err
+	}
+
+	// find source model run metadata by id, run digest or name
+	runId, runDigest, runName, isFirst, isLast := runIdDigestNameFromOptions(runOpts)
+	if runId < 0 || runId == 0 && runName == "" && runDigest == "" && !isFirst && !isLast {
+		return errors.New("dbcopy invalid argument(s) run id: " + runOpts.String(runIdArgKey) + ", run name: " + runOpts.String(runNameArgKey) + ", run digest: " + runOpts.String(runDigestArgKey))
+	}
+	runRow, e := findModelRunByIdDigestName(srcDb, srcModel.Model.ModelId, runId, runDigest, runName, isFirst, isLast)
+	if e != nil {
+		return e
+	}
+	if runRow == nil {
+		return errors.New("model run not found: " + runOpts.String(runIdArgKey) + " " + runOpts.String(runNameArgKey) + " " + runOpts.String(runDigestArgKey))
+	}
+
+	// run must be completed: status success, error or exit
+	if !db.IsRunCompleted(runRow.Status) {
+		return errors.New("model run not completed: " + strconv.Itoa(runRow.RunId) + " " + runRow.Name)
+	}
+
+	// get full model run metadata
+	meta, err := db.GetRunFullText(srcDb, runRow, true, "")
+	if err != nil {
+		return err
+	}
+
+	// destination: get list of languages
+	dstLang, err := db.GetLanguages(dstDb)
+	if err != nil {
+		return err
+	}
+
+	// convert model run db rows into "public" format
+	pub, err := meta.ToPublic(srcDb, srcModel)
+	if err != nil {
+		return err
+	}
+
+	// copy source model run metadata, parameter values, output results into destination model
+	_, err = copyRunMapDbToDb(srcDb, dstDb, dbFacet, rm, meta, pub, dstLang)
+	return err
+}
+
+// log model mapping issues: structural mismatches between source and destination model
+func logMapIssues(rm *db.RunMapping) {
+
+	if len(rm.Issue) <= 0 {
+		return
+	}
+	omppLog.Log("Model mapping issues: ", len(rm.Issue))
+
+	for k := range rm.Issue {
+		omppLog.Log("  ", rm.Issue[k].String())
+	}
+}
+
+// if required then use destination model default workset to fill parameters which are missing in source model run or not compatible
+func mapFillDefault(dbConn *sql.DB, rm *db.RunMapping) error {
+
+	if !theCfg.isMapFillDef {
+		return nil
+	}
+	ws, err := db.GetDefaultWorkset(dbConn, rm.DstModel.Model.ModelId)
+	if err != nil {
+		return err
+	}
+	if ws == nil {
+		return errors.New("default workset not found: " + rm.DstModel.Model.Name + " " + rm.DstModel.Model.Digest)
+	}
+	omppLog.Log("Missing or not compatible parameters are copied from default workset: ", ws.Name)
+
+	return rm.FillFromWorkset(dbConn, ws.SetId)
+}
+
+// log parameters which are copied from destination model workset because it is missing in source model run or not compatible
+func logMapFilled(fLst []string) {
+	for _, name := range fLst {
+		omppLog.Log("  Parameter copied from default workset: ", name)
+	}
+}
+
+// copy parameter values into model run from destination model workset,
+// it is used to fill parameters which are missing in source model run or not compatible
+func writeParamFromWorkset(dbConn *sql.DB, modelDef *db.ModelMeta, setId int, layout db.WriteParamLayout) error {
+
+	paramLt := db.ReadParamLayout{
+		ReadLayout: db.ReadLayout{
+			Name:   layout.Name,
+			FromId: setId,
+		},
+		IsFromSet: true,
+	}
+	cLst := list.New()
+
+	_, err := db.ReadParameterTo(dbConn, modelDef, &paramLt, func(src interface{}) (bool, error) {
+		cLst.PushBack(src)
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	if cLst.Len() <= 0 {
+		return errors.New("missing workset parameter values " + layout.Name + " set id: " + strconv.Itoa(setId))
+	}
+	return db.WriteParameterFrom(dbConn, modelDef, &layout, makeFromList(cLst))
+}
+
+// copyRunMapDbToDb do copy model run metadata, run parameters, output tables and microdata from source model into destination model.
+// Source items converted into destination: parameters, output tables and entities are matched by name, enum id's mapped through enum codes.
+// Output tables and entities which are not compatible with destination model are not copied.
+// It return destination run id (run id in destination database)
+func copyRunMapDbToDb(
+	srcDb *sql.DB, dstDb *sql.DB, dbFacet db.Facet, rm *db.RunMapping, srcMeta *db.RunMeta, srcPub *db.RunPub, dstLang *db.LangMeta) (int, error) {
+
+	// validate parameters
+	if srcMeta == nil || srcPub == nil {
+		return 0, errors.New("invalid (empty) source model run metadata, source run not found or not exists")
+	}
+	srcId := srcMeta.Run.RunId
+
+	// map source run metadata into destination model
+	pub, fLst, err := rm.RunPub(srcPub)
+	if err != nil {
+		return 0, err
+	}
+	logMapFilled(fLst)
+
+	// destination: convert from "public" format into destination db rows
+	dstRun, err := pub.FromPublic(dstDb, rm.DstModel)
+	if err != nil {
+		return 0, err
+	}
+
+	// destination: save model run metadata
+	isExist, err := dstRun.UpdateRun(dstDb, rm.DstModel, dstLang, theCfg.doubleFmt)
+	if err != nil {
+		return 0, err
+	}
+	dstId := dstRun.Run.RunId
+	if isExist { // exit if model run already exist
+		omppLog.Log("Model run ", srcId, " ", pub.Name, " already exists as ", dstId)
+		return dstId, nil
+	}
+
+	// on error delete model run to rollback results of UpdateRun() call above and all completed parameters or tables
+	cleanupOnError := func(err error) (int, error) {
+
+		omppLog.Log("Cleanup on error: delete model run ", pub.Name, " ", dstId)
+
+		e := db.DeleteRun(dstDb, dstId)
+		if e != nil {
+			omppLog.Log("Failed to delete model run: ", pub.Name, " id: ", dstId, ": ", e.Error())
+		}
+		return 0, err // return original error
+	}
+
+	// copy all run parameters, output accumulators and expressions from source to destination
+	omppLog.Log("Model run from ", srcId, " ", pub.Name, " to ", dstId)
+	nP := len(pub.Param)
+	omppLog.Log("  Parameters: ", nP)
+	logT := time.Now().Unix()
+
+	// copy all parameters values for that model run, parameters are in destination model order
+	for j := range pub.Param {
+
+		// destination: parameter is missing in source model run or not compatible, copy it from destination workset
+		if slices.Contains(fLst, pub.Param[j].Name) {
+
+			dstParamLt := db.WriteParamLayout{
+				WriteLayout: db.WriteLayout{
+					Name: pub.Param[j].Name,
+					ToId: dstId,
+				},
+				SubCount:  dstRun.Param[j].SubCount,
+				IsToRun:   true,
+				DoubleFmt: theCfg.doubleFmt,
+			}
+			if err = writeParamFromWorkset(dstDb, rm.DstModel, rm.FillSetId(), dstParamLt); err != nil {
+				omppLog.Log("Error at: ", dstParamLt.Name, ": ", err.Error())
+				return cleanupOnError(err)
+			}
+			continue
+		}
+
+		// source: read parameter values and convert into destination cells
+		paramLt := db.ReadParamLayout{
+			ReadLayout: db.ReadLayout{
+				Name:   pub.Param[j].Name,
+				FromId: srcId,
+			},
+		}
+		logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nP, ": ", paramLt.Name)
+
+		cvt, err := rm.ParamCell(paramLt.Name)
+		if err != nil {
+			return cleanupOnError(err)
+		}
+		cLst := list.New()
+
+		_, err = db.ReadParameterTo(srcDb, rm.SrcModel, &paramLt, func(src interface{}) (bool, error) {
+			c, e := cvt(src)
+			if e != nil {
+				return false, e
+			}
+			cLst.PushBack(c)
+			return true, nil
+		})
+		if err != nil {
+			return cleanupOnError(err)
+		}
+		if cLst.Len() <= 0 { // parameter data must exist for all parameters
+			return cleanupOnError(errors.New("missing run parameter values " + paramLt.Name + " run id: " + strconv.Itoa(paramLt.FromId)))
+		}
+
+		// destination: insert parameter values in model run
+		dstParamLt := db.WriteParamLayout{
+			WriteLayout: db.WriteLayout{
+				Name: pub.Param[j].Name,
+				ToId: dstId,
+			},
+			SubCount:  dstRun.Param[j].SubCount,
+			IsToRun:   true,
+			DoubleFmt: theCfg.doubleFmt,
+		}
+
+		if err = db.WriteParameterFrom(dstDb, rm.DstModel, &dstParamLt, makeFromList(cLst)); err != nil {
+			omppLog.Log("Error at: ", paramLt.Name, ": ", err.Error())
+			return cleanupOnError(err)
+		}
+	}
+
+	// copy output tables values which are compatible with destination model
+	nT := len(pub.Table)
+	omppLog.Log("  Tables: ", nT)
+
+	for j := range pub.Table {
+
+		name := pub.Table[j].Name
+		logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nT, ": ", name)
+
+		cvtAcc, err := rm.TableAccCell(name)
+		if err != nil {
+			return cleanupOnError(err)
+		}
+		cvtExpr, err := rm.TableExprCell(name)
+		if err != nil {
+			return cleanupOnError(err)
+		}
+
+		// source: read output table accumulators and convert into destination cells
+		tblLt := db.ReadTableLayout{
+			ReadLayout: db.ReadLayout{
+				Name:   name,
+				FromId: srcId,
+			},
+			IsAccum: true,
+		}
+		acLst := list.New()
+
+		_, err = db.ReadOutputTableTo(srcDb, rm.SrcModel, &tblLt, func(src interface{}) (bool, error) {
+			c, e := cvtAcc(src)
+			if e != nil {
+				return false, e
+			}
+			acLst.PushBack(c)
+			return true, nil
+		})
+		if err != nil {
+			return cleanupOnError(err)
+		}
+
+		// source: read output table expression values and convert into destination cells
+		tblLt.IsAccum = false
+		ecLst := list.New()
+
+		_, err = db.ReadOutputTableTo(srcDb, rm.SrcModel, &tblLt, func(src interface{}) (bool, error) {
+			c, e := cvtExpr(src)
+			if e != nil {
+				return false, e
+			}
+			ecLst.PushBack(c)
+			return true, nil
+		})
+		if err != nil {
+			return cleanupOnError(err)
+		}
+
+		// insert output table values (accumulators and expressions) in model run
+		dstTblLt := db.WriteTableLayout{
+			WriteLayout: db.WriteLayout{
+				Name: name,
+				ToId: dstId,
+			},
+			SubCount:  dstRun.Run.SubCount,
+			DoubleFmt: theCfg.doubleFmt,
+		}
+
+		err = db.WriteOutputTableFrom(dstDb, rm.DstModel, &dstTblLt, makeFromList(acLst), makeFromList(ecLst))
+		if err != nil {
+			omppLog.Log("Error at: ", name, ": ", err.Error())
+			return cleanupOnError(err)
+		}
+	}
+
+	// update model run digest
+	if dstRun.Run.ValueDigest == "" {
+
+		svd, err := db.UpdateRunValueDigest(dstDb, dstId)
+		if err != nil {
+			return cleanupOnError(err)
+		}
+		dstRun.Run.ValueDigest = svd
+	}
+
+	// copy entity microdata values from source run into destination
+	nMd := len(pub.Entity)
+
+	if nMd > 0 {
+
+		omppLog.Log("  Microdata: ", nMd)
+
+		for j := 0; j < nMd; j++ {
+
+			name := pub.Entity[j].Name
+
+			// find source entity generation, destination entity generation converted from "public" and has the same order
+			var srcGen *db.EntityGenMeta
+			var genDigest string
+			for k := range srcPub.Entity {
+				if srcPub.Entity[k].Name == name {
+					genDigest = srcPub.Entity[k].GenDigest
+					break
+				}
+			}
+			for k := range srcMeta.EntityGen {
+				if srcMeta.EntityGen[k].GenDigest == genDigest {
+					srcGen = &srcMeta.EntityGen[k]
+					break
+				}
+			}
+			if srcGen == nil {
+				return cleanupOnError(errors.New("entity generation not found: " + name + " run id: " + strconv.Itoa(srcId)))
+			}
+
+			cvt, err := rm.MicroCell(name, srcGen, &dstRun.EntityGen[j])
+			if err != nil {
+				return cleanupOnError(err)
+			}
+
+			// source: read microdata values and convert into destination cells
+			microLt := db.ReadMicroLayout{
+				ReadLayout: db.ReadLayout{
+					Name:   name,
+					FromId: srcId},
+				GenDigest: genDigest,
+			}
+			logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nMd, ": ", microLt.Name)
+
+			cLst := list.New()
+
+			_, err = db.ReadMicrodataTo(srcDb, rm.SrcModel, &microLt, func(src interface{}) (bool, error) {
+				c, e := cvt(src)
+				if e != nil {
+					return false, e
+				}
+				cLst.PushBack(c)
+				return true, nil
+			})
+			if err != nil {
+				return cleanupOnError(err)
+			}
+
+			// destination: insert microdata values into model run
+			dstMicroLt := db.WriteMicroLayout{
+				WriteLayout: db.WriteLayout{
+					Name: name,
+					ToId: dstId,
+				},
+				DoubleFmt: theCfg.doubleFmt,
+			}
+
+			if err = db.WriteMicrodataFrom(dstDb, dbFacet, rm.DstModel, dstRun, &dstMicroLt, makeFromList(cLst)); err != nil {
+				omppLog.Log("Error at: ", name, ": ", err.Error())
+				return cleanupOnError(err)
+			}
+		}
+	}
+
+	return dstId, nil
+}
@@ -121,11 +121,12 @@ func dbToDbRun(modelName string, modelDigest string, runOpts *config.RunOptions)
 func copyRunListDbToDb(
 	srcDb *sql.DB, dstDb *sql.DB, dbFacet db.Facet, srcModel *db.ModelMeta, dstModel *db.ModelMeta, dstLang *db.LangMeta) error {
 
-	// source: get all successfully completed model runs in all languages
-	srcRl, err := db.GetRunFullTextList(srcDb, srcModel.Model.ModelId, true, "")
+	// source: get all successfully completed model runs or model runs selected by filter in all languages
+	srcRl, err := db.GetRunFullTextList(srcDb, srcModel.Model.ModelId, theCfg.filter.isSuccessOnly(), "")
 	if err != nil {
 		return err
 	}
+	srcRl = theCfg.filter.runs(srcRl)
 	if len(srcRl) <= 0 {
 		return nil
 	}
@@ -271,6 +271,7 @@ func copyTaskListDbToDb(
 	if err != nil {
 		return err
 	}
+	srcTl = theCfg.filter.tasks(srcTl)
 	if len(srcTl) <= 0 {
 		return nil
 	}
@@ -147,6 +147,7 @@ func copyWorksetListDbToDb(
 	if err != nil {
 		return err
 	}
+	srcWl = theCfg.filter.worksets(srcWl)
 	if len(srcWl) <= 0 {
 		return nil
 	}
@@ -202,7 +203,7 @@ func copyWorksetDbToDb(
 		return 0, err
 	}
 	if wsRow != nil {
-		err = db.UpdateWorksetReadonly(dstDb, wsRow.SetId, false) // make destination workset read-write
+		err = db.UpdateWorksetReadonly(dstDb, dstModel, wsRow.SetId, false, nil, "") // make destination workset read-write
 		if err != nil {
 			return 0, errors.New("failed to clear workset read-only status: " + strconv.Itoa(wsRow.SetId) + " " + wsRow.Name + " " + err.Error())
 		}
@@ -254,8 +255,12 @@ func copyWorksetDbToDb(
 		}
 	}
 
-	// update workset readonly status with actual value
-	err = db.UpdateWorksetReadonly(dstDb, dstId, isReadonly)
+	// update workset readonly status with actual value, read-only workset parameters must satisfy validation rules
+	rules, err := paramRules(dstDb, dstModel, isReadonly)
+	if err != nil {
+		return 0, err
+	}
+	err = db.UpdateWorksetReadonly(dstDb, dstModel, dstId, isReadonly, rules, "")
 	if err != nil {
 		return 0, err
 	}
@@ -7,14 +7,14 @@ import (
 	"database/sql"
 	"encoding/csv"
 	"errors"
-	"os"
+	"io"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/openmpp/go/ompp/config"
 	"github.com/openmpp/go/ompp/db"
 	"github.com/openmpp/go/ompp/helper"
-	"github.com/openmpp/go/ompp/omppLog"
 )
 
 // copy model from database into text json and csv files
@@ -40,17 +40,13 @@ func dbToText(modelName string, modelDigest string, runOpts *config.RunOptions)
 	}
 	modelName = modelDef.Model.Name // set model name: it can be empty and only model digest specified
 
-	// create new output directory, use modelName subdirectory
+	// create new output directory or zip archive, use modelName subdirectory
 	outDir := filepath.Join(runOpts.String(outputDirArgKey), modelName)
 
-	if !theCfg.isKeepOutputDir {
-		if ok := dirDeleteAndLog(outDir); !ok {
-			return errors.New("Error: unable to delete: " + outDir)
-		}
-	}
-	if err = os.MkdirAll(outDir, 0750); err != nil {
+	if err = makeOutDir(outDir, runOpts.Bool(zipStreamArgKey)); err != nil {
 		return err
 	}
+	defer closeZipOut()
 	fileCreated := make(map[string]bool)
 
 	// write model definition to json file
@@ -86,13 +82,9 @@ func dbToText(modelName string, modelDigest string, runOpts *config.RunOptions)
 		return err
 	}
 
-	// pack model metadata, run results and worksets into zip
-	if runOpts.Bool(zipArgKey) {
-		zipPath, err := helper.PackZip(outDir, !theCfg.isKeepOutputDir, "")
-		if err != nil {
-			return err
-		}
-		omppLog.Log("Packed ", zipPath)
+	// close zip archive stream or pack into zip model metadata, run results and worksets
+	if err = closeOutDir(outDir, runOpts.Bool(zipArgKey) || runOpts.Bool(zipStreamArgKey)); err != nil {
+		return err
 	}
 
 	return nil
@@ -127,27 +119,31 @@ func toModelJson(dbConn *sql.DB, modelDef *db.ModelMeta, outDir string) error {
 	}
 
 	// save into model json files
-	if err := helper.ToJsonFile(filepath.Join(outDir, modelName+".model.json"), &modelDef); err != nil {
+	if err := toJsonOut(filepath.Join(outDir, modelName+".model.json"), &modelDef); err != nil {
 		return err
 	}
-	if err := helper.ToJsonFile(filepath.Join(outDir, modelName+".lang.json"), &langDef); err != nil {
+	if err := toJsonOut(filepath.Join(outDir, modelName+".lang.json"), &langDef); err != nil {
 		return err
 	}
-	if err := helper.ToJsonFile(filepath.Join(outDir, modelName+".text.json"), &modelTxt); err != nil {
+	if err := toJsonOut(filepath.Join(outDir, modelName+".text.json"), &modelTxt); err != nil {
 		return err
 	}
-	if err := helper.ToJsonFile(filepath.Join(outDir, modelName+".word.json"), &mwDef); err != nil {
+	if err := toJsonOut(filepath.Join(outDir, modelName+".word.json"), &mwDef); err != nil {
 		return err
 	}
-	if err := helper.ToJsonFile(filepath.Join(outDir, modelName+".profile.json"), &modelProfile); err != nil {
+	if err := toJsonOut(filepath.Join(outDir, modelName+".profile.json"), &modelProfile); err != nil {
 		return err
 	}
 	return nil
 }
 
+// lock to update map of created csv files, csv files can be written concurrently
+var fileCreatedMx sync.Mutex
+
 // toCellCsvFile convert parameter, output table values or microdata and write into csvDir/fileName.csv or csvDir/fileName.tsv file.
 // if IsIdCsv is true then csv contains enum id's, default: enum code
 // if isTsv is true then output into TSV else into CSV
+// if compression specified then output into compressed csvDir/fileName.csv.gz or csvDir/fileName.csv.zst
 func toCellCsvFile(
 	dbConn *sql.DB,
 	modelDef *db.ModelMeta,
@@ -178,28 +174,33 @@ func toCellCsvFile(
 	if theCfg.isTsv && strings.HasSuffix(fn, ".csv") {
 		fn = fn[:len(fn)-4] + ".tsv"
 	}
-	p := filepath.Join(csvDir, fn)
+	p := filepath.Join(csvDir, fn+theCfg.csvCompressExt)
+
+	fileCreatedMx.Lock()
 	_, isAppend := fileCreated[p]
+	fileCreated[p] = true
+	fileCreatedMx.Unlock()
 
-	flag := os.O_CREATE | os.O_TRUNC | os.O_WRONLY
-	if isAppend {
-		flag = os.O_APPEND | os.O_WRONLY
+	f, err := createOut(p, isAppend)
+	if err != nil {
+		return err
 	}
+	defer f.Close()
 
-	f, err := os.OpenFile(p, flag, 0644)
+	// if required then compress csv file content, appended part is compressed as next gzip member or zstd frame
+	cw, err := helper.NewCompressWriter(f, theCfg.csvCompressExt)
 	if err != nil {
 		return err
 	}
-	fileCreated[p] = true
-	defer f.Close()
+	defer cw.Close()
 
 	if theCfg.isWriteUtf8Bom { // if required then write utf-8 bom
-		if _, err = f.Write(helper.Utf8bom); err != nil {
+		if _, err = cw.Write(helper.Utf8bom); err != nil {
 			return err
 		}
 	}
 
-	wr := csv.NewWriter(f)
+	wr := csv.NewWriter(cw)
 	if theCfg.isTsv {
 		wr.Comma = '\t'
 	}
@@ -263,7 +264,10 @@ func toCellCsvFile(
 
 	// flush and return error, if any
 	wr.Flush()
-	return wr.Error()
+	if err = wr.Error(); err != nil {
+		return err
+	}
+	return cw.Close()
 }
 
 // toDotMdFile write parameter value notes or output table values notes into .md file, for example into csvDir/ageSex.FR.md file.
@@ -272,7 +276,7 @@ func toDotMdFile(
 	name string,
 	text string) error {
 
-	f, err := os.OpenFile(filepath.Join(csvDir, name+".md"), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	f, err := createOut(filepath.Join(csvDir, name+".md"), false)
 	if err != nil {
 		return err
 	}
@@ -286,8 +290,6 @@ func toDotMdFile(
 	}
 
 	// write file content
-	if _, err = f.WriteString(text); err != nil {
-		return err
-	}
-	return f.Sync()
+	_, err = io.WriteString(f, text)
+	return err
 }
@@ -6,7 +6,6 @@ package main
 import (
 	"database/sql"
 	"errors"
-	"os"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -97,14 +96,10 @@ func dbToTextRun(modelName string, modelDigest string, runOpts *config.RunOption
 		outDir = filepath.Join(runOpts.String(outputDirArgKey), modelName+".run."+helper.CleanFileName(runRow.Name))
 	}
 
-	if !theCfg.isKeepOutputDir {
-		if ok := dirDeleteAndLog(outDir); !ok {
-			return errors.New("Error: unable to delete: " + outDir)
-		}
-	}
-	if err = os.MkdirAll(outDir, 0750); err != nil {
+	if err = makeOutDir(outDir, runOpts.Bool(zipStreamArgKey)); err != nil {
 		return err
 	}
+	defer closeZipOut()
 	fileCreated := make(map[string]bool)
 
 	// write model run metadata into json, parameters and output result values into csv files
@@ -112,13 +107,9 @@ func dbToTextRun(modelName string, modelDigest string, runOpts *config.RunOption
 		return err
 	}
 
-	// pack model run metadata and results into zip
-	if runOpts.Bool(zipArgKey) {
-		zipPath, err := helper.PackZip(outDir, !theCfg.isKeepOutputDir, "")
-		if err != nil {
-			return err
-		}
-		omppLog.Log("Packed ", zipPath)
+	// close zip archive stream or pack into zip model run metadata and results
+	if err = closeOutDir(outDir, runOpts.Bool(zipArgKey) || runOpts.Bool(zipStreamArgKey)); err != nil {
+		return err
 	}
 
 	return nil
@@ -133,11 +124,12 @@ func toRunListText(
 	doUseIdNames useIdNames,
 ) (bool, error) {
 
-	// get all successfully completed model runs
-	rl, err := db.GetRunFullTextList(dbConn, modelDef.Model.ModelId, true, "")
+	// get all successfully completed model runs or model runs selected by filter
+	rl, err := db.GetRunFullTextList(dbConn, modelDef.Model.ModelId, theCfg.filter.isSuccessOnly(), "")
 	if err != nil {
 		return false, err
 	}
+	rl = theCfg.filter.runs(rl)
 
 	// use of run and set id's in directory names:
 	// if explicitly required then always use id's in the names
@@ -206,19 +198,23 @@ func toRunText(
 	microCsvDir := filepath.Join(outDir, csvName, "microdata")
 	nMd := len(meta.EntityGen)
 
-	if err = os.MkdirAll(paramCsvDir, 0750); err != nil {
+	if err = mkdirOut(paramCsvDir); err != nil {
 		return err
 	}
-	if err = os.MkdirAll(tableCsvDir, 0750); err != nil {
+	if err = mkdirOut(tableCsvDir); err != nil {
 		return err
 	}
 	if !theCfg.isNoMicrodata && nMd > 0 {
-		if err = os.MkdirAll(microCsvDir, 0750); err != nil {
+		if err = mkdirOut(microCsvDir); err != nil {
 			return err
 		}
 	}
 	logT := time.Now().Unix()
 
+	// read parameters, output tables and microdata and write csv files concurrently
+	pool := newCsvOutPool()
+	defer pool.Wait() // on error wait until all running jobs completed
+
 	// write all parameters into csv files
 	nP := len(modelDef.Param)
 	omppLog.Log("  Parameters: ", nP)
@@ -238,7 +234,7 @@ func toRunText(
 
 		logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nP, ": ", paramLt.Name)
 
-		err = toCellCsvFile(dbConn, modelDef, paramLt, cvtParam, fileCreated, paramCsvDir, "", "")
+		err = pool.Go(toCellCsvJob(dbConn, modelDef, paramLt, cvtParam, fileCreated, paramCsvDir, "", ""))
 		if err != nil {
 			return err
 		}
@@ -283,7 +279,7 @@ func toRunText(
 
 		logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nT, ": ", tblLt.Name)
 
-		err = toCellCsvFile(dbConn, modelDef, tblLt, cvtExpr, fileCreated, tableCsvDir, "", "")
+		err = pool.Go(toCellCsvJob(dbConn, modelDef, tblLt, cvtExpr, fileCreated, tableCsvDir, "", ""))
 		if err != nil {
 			return err
 		}
@@ -296,7 +292,7 @@ func toRunText(
 
 			logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nT, ": ", tblLt.Name, " accumulators")
 
-			err = toCellCsvFile(dbConn, modelDef, tblLt, cvtAcc, fileCreated, tableCsvDir, "", "")
+			err = pool.Go(toCellCsvJob(dbConn, modelDef, tblLt, cvtAcc, fileCreated, tableCsvDir, "", ""))
 			if err != nil {
 				return err
 			}
@@ -307,7 +303,7 @@ func toRunText(
 
 			logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nT, ": ", tblLt.Name, " all accumulators")
 
-			err = toCellCsvFile(dbConn, modelDef, tblLt, cvtAll, fileCreated, tableCsvDir, "", "")
+			err = pool.Go(toCellCsvJob(dbConn, modelDef, tblLt, cvtAll, fileCreated, tableCsvDir, "", ""))
 			if err != nil {
 				return err
 			}
@@ -346,15 +342,20 @@ func toRunText(
 
 			logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nMd, ": ", microLt.Name)
 
-			err = toCellCsvFile(dbConn, modelDef, microLt, cvtMicro, fileCreated, microCsvDir, "", "")
+			err = pool.Go(toCellCsvJob(dbConn, modelDef, microLt, cvtMicro, fileCreated, microCsvDir, "", ""))
 			if err != nil {
 				return err
 			}
 		}
 	}
 
+	// wait until all csv files are written
+	if err = pool.Wait(); err != nil {
+		return err
+	}
+
 	// save model run metadata into json
-	if err := helper.ToJsonFile(filepath.Join(outDir, modelDef.Model.Name+"."+csvName+".json"), pub); err != nil {
+	if err := toJsonOut(filepath.Join(outDir, modelDef.Model.Name+"."+csvName+".json"), pub); err != nil {
 		return err
 	}
 	return nil
@@ -6,7 +6,6 @@ package main
 import (
 	"database/sql"
 	"errors"
-	"os"
 	"path/filepath"
 	"strconv"
 
@@ -136,15 +135,11 @@ func dbToTextTask(modelName string, modelDigest string, runOpts *config.RunOptio
 		}
 	}
 
-	// create new output directory for task metadata
-	if !theCfg.isKeepOutputDir {
-		if ok := dirDeleteAndLog(outDir); !ok {
-			return errors.New("Error: unable to delete: " + outDir)
-		}
-	}
-	if err = os.MkdirAll(outDir, 0750); err != nil {
+	// create new output directory or zip archive for task metadata
+	if err = makeOutDir(outDir, runOpts.Bool(zipStreamArgKey)); err != nil {
 		return err
 	}
+	defer closeZipOut()
 	fileCreated := make(map[string]bool)
 
 	// write task metadata into json file
@@ -268,13 +263,9 @@ func dbToTextTask(modelName string, modelDigest string, runOpts *config.RunOptio
 		omppLog.Log("Warning: task ", meta.Task.Name, " model run(s) not completed, copy of task run history incomplete")
 	}
 
-	// pack worksets metadata json and csv files into zip
-	if runOpts.Bool(zipArgKey) {
-		zipPath, err := helper.PackZip(outDir, !theCfg.isKeepOutputDir, "")
-		if err != nil {
-			return err
-		}
-		omppLog.Log("Packed ", zipPath)
+	// close zip archive stream or pack into zip task metadata, model runs and worksets
+	if err = closeOutDir(outDir, runOpts.Bool(zipArgKey) || runOpts.Bool(zipStreamArgKey)); err != nil {
+		return err
 	}
 	return nil
 }
@@ -287,6 +278,7 @@ func toTaskListJson(dbConn *sql.DB, modelDef *db.ModelMeta, outDir string, isUse
 	if err != nil {
 		return err
 	}
+	tl = theCfg.filter.tasks(tl)
 
 	// read each task metadata and write into json files
 	for k := range tl {
@@ -316,5 +308,5 @@ func toTaskJson(dbConn *sql.DB, modelDef *db.ModelMeta, meta *db.TaskMeta, outDi
 		fname = modelDef.Model.Name + ".task." + strconv.Itoa(meta.Task.TaskId) + "." + helper.CleanFileName(meta.Task.Name) + ".json"
 	}
 
-	return helper.ToJsonFile(filepath.Join(outDir, fname), pub)
+	return toJsonOut(filepath.Join(outDir, fname), pub)
 }
@@ -6,7 +6,6 @@ package main
 import (
 	"database/sql"
 	"errors"
-	"os"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -101,15 +100,11 @@ func dbToTextWorkset(modelName string, modelDigest string, runOpts *config.RunOp
 		return errors.New("workset must be readonly: " + strconv.Itoa(wsRow.SetId) + " " + wsRow.Name)
 	}
 
-	// create new output directory for workset metadata
-	if !theCfg.isKeepOutputDir {
-		if ok := dirDeleteAndLog(outDir); !ok {
-			return errors.New("Error: unable to delete: " + outDir)
-		}
-	}
-	if err = os.MkdirAll(outDir, 0750); err != nil {
+	// create new output directory or zip archive for workset metadata
+	if err = makeOutDir(outDir, runOpts.Bool(zipStreamArgKey)); err != nil {
 		return err
 	}
+	defer closeZipOut()
 
 	// for single run or single workset output to text
 	// do not use of run and set id's in directory names by default
@@ -122,13 +117,9 @@ func dbToTextWorkset(modelName string, modelDigest string, runOpts *config.RunOp
 		return err
 	}
 
-	// pack worksets metadata json and csv files into zip
-	if runOpts.Bool(zipArgKey) {
-		zipPath, err := helper.PackZip(outDir, !theCfg.isKeepOutputDir, "")
-		if err != nil {
-			return err
-		}
-		omppLog.Log("Packed ", zipPath)
+	// close zip archive stream or pack into zip workset metadata json and csv files
+	if err = closeOutDir(outDir, runOpts.Bool(zipArgKey) || runOpts.Bool(zipStreamArgKey)); err != nil {
+		return err
 	}
 
 	return nil
@@ -147,6 +138,7 @@ func toWorksetListText(
 	if err != nil {
 		return err
 	}
+	wl = theCfg.filter.worksets(wl)
 
 	// read all workset parameters and dump it into csv files
 	for k := range wl {
@@ -188,12 +180,12 @@ func toWorksetText(
 	}
 	csvDir := filepath.Join(outDir, csvName)
 
-	if !theCfg.isKeepOutputDir {
+	if theZipOut == nil && !theCfg.isKeepOutputDir {
 		if ok := dirDeleteAndLog(csvDir); !ok {
 			return errors.New("Error: unable to delete: " + csvDir)
 		}
 	}
-	if err = os.MkdirAll(csvDir, 0750); err != nil {
+	if err = mkdirOut(csvDir); err != nil {
 		return err
 	}
 
@@ -227,7 +219,7 @@ func toWorksetText(
 	}
 
 	// save model workset metadata into json
-	if err := helper.ToJsonFile(filepath.Join(outDir, modelDef.Model.Name+"."+csvName+".json"), pub); err != nil {
+	if err := toJsonOut(filepath.Join(outDir, modelDef.Model.Name+"."+csvName+".json"), pub); err != nil {
 		return err
 	}
 	return nil
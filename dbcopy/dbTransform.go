@@ -0,0 +1,102 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// apply arithmetic operation to existing workset parameter values from parameter transform json file
+func dbTransformWorkset(modelName string, modelDigest string, runOpts *config.RunOptions) error {
+
+	// read parameter transform from json file
+	inpPath := runOpts.String(transformArgKey)
+	if inpPath == "" {
+		return errors.New("dbcopy invalid (empty or missing) argument of: " + transformArgKey)
+	}
+
+	var tf db.ParamTransform
+	isExist, err := helper.FromJsonFile(inpPath, &tf)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		return errors.New("parameter transform file not found or empty: " + inpPath)
+	}
+	if tf.Name == "" {
+		return errors.New("invalid (empty) parameter name in transform file: " + inpPath)
+	}
+
+	// get workset name and id
+	setName := runOpts.String(setNameArgKey)
+	setId := runOpts.Int(setIdArgKey, 0)
+
+	// conflicting options: use set id if positive else use set name
+	if runOpts.IsExist(setNameArgKey) && runOpts.IsExist(setIdArgKey) {
+		if setId > 0 {
+			omppLog.Log("dbcopy options conflict. Using set id: ", setId, " ignore set name: ", setName)
+			setName = ""
+		} else {
+			omppLog.Log("dbcopy options conflict. Using set name: ", setName, " ignore set id: ", setId)
+			setId = 0
+		}
+	}
+
+	if setId < 0 || setId == 0 && setName == "" {
+		return errors.New("dbcopy invalid argument(s) for set id: " + runOpts.String(setIdArgKey) + " and/or set name: " + runOpts.String(setNameArgKey))
+	}
+
+	// open source database connection and check is it valid
+	cs, dn := db.IfEmptyMakeDefault(modelName, runOpts.String(fromSqliteArgKey), runOpts.String(dbConnStrArgKey), runOpts.String(dbDriverArgKey))
+
+	srcDb, _, err := db.Open(cs, dn, false)
+	if err != nil {
+		return err
+	}
+	defer srcDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(srcDb); err != nil {
+		return err
+	}
+
+	// get model metadata
+	modelDef, err := db.GetModel(srcDb, modelName, modelDigest)
+	if err != nil {
+		return err
+	}
+
+	// get workset name by id, if required
+	if setId > 0 {
+		wsRow, err := db.GetWorkset(srcDb, setId)
+		if err != nil {
+			return err
+		}
+		if wsRow == nil {
+			return errors.New("workset not found, set id: " + strconv.Itoa(setId))
+		}
+		if wsRow.ModelId != modelDef.Model.ModelId {
+			return errors.New("workset " + strconv.Itoa(wsRow.SetId) + " " + wsRow.Name + " does not belong to model " + modelName + " " + modelDigest)
+		}
+		setName = wsRow.Name
+	}
+
+	// save current parameter values as new workset version and apply transform
+	omppLog.Log("Transform workset parameter ", setName, ": ", tf.Name)
+
+	rules, err := paramRules(srcDb, modelDef, true)
+	if err != nil {
+		return err
+	}
+	if err = db.TransformWorksetParameter(srcDb, modelDef, setName, &tf, rules, "", "Transform parameter "+tf.Name); err != nil {
+		return errors.New("failed to transform workset parameter " + setName + ": " + tf.Name + ": " + err.Error())
+	}
+
+	return nil
+}
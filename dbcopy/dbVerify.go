@@ -0,0 +1,431 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// verify integrity of model database or model text files (.json and .csv) and report all issues found.
+// If input directory specified then verify text files else verify model database.
+// It is an error if any issues found.
+func dbVerify(modelName string, modelDigest string, runOpts *config.RunOptions) error {
+
+	var viLst []db.VerifyIssue
+	var err error
+
+	if runOpts.IsExist(inputDirArgKey) {
+		viLst, err = textVerify(modelName, modelDigest, runOpts)
+	} else {
+		viLst, err = dbVerifyModel(modelName, modelDigest, runOpts)
+	}
+	if err != nil {
+		return err
+	}
+
+	for k := range viLst {
+		omppLog.Log(viLst[k].String())
+	}
+	if len(viLst) > 0 {
+		return errors.New("integrity verification failed, issues found: " + strconv.Itoa(len(viLst)))
+	}
+	omppLog.Log("No issues found")
+	return nil
+}
+
+// verify model database: recalculate model runs digests and check referential consistency of model runs, worksets and modeling tasks.
+func dbVerifyModel(modelName string, modelDigest string, runOpts *config.RunOptions) ([]db.VerifyIssue, error) {
+
+	// open source database connection and check is it valid
+	cs, dn := db.IfEmptyMakeDefaultReadOnly(modelName, runOpts.String(fromSqliteArgKey), runOpts.String(dbConnStrArgKey), runOpts.String(dbDriverArgKey))
+
+	srcDb, _, err := db.Open(cs, dn, false)
+	if err != nil {
+		return nil, err
+	}
+	defer srcDb.Close()
+
+	if err := db.CheckOpenmppSchemaVersion(srcDb); err != nil {
+		return nil, err
+	}
+
+	// get model metadata
+	modelDef, err := db.GetModel(srcDb, modelName, modelDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	// verify model runs digests
+	rl, err := db.GetRunList(srcDb, modelDef.Model.ModelId)
+	if err != nil {
+		return nil, err
+	}
+	omppLog.Log("Verify model runs: ", len(rl))
+	logT := time.Now().Unix()
+
+	viLst := []db.VerifyIssue{}
+
+	for k := range rl {
+
+		logT = omppLog.LogIfTime(logT, logPeriod, "    ", k, " of ", len(rl), ": ", rl[k].Name)
+
+		vl, err := db.VerifyRun(srcDb, modelDef, &rl[k], theCfg.doubleFmt)
+		if err != nil {
+			return nil, err
+		}
+		viLst = append(viLst, vl...)
+	}
+
+	// check referential consistency of model runs, worksets and tasks
+	omppLog.Log("Verify model runs, worksets and modeling tasks references")
+
+	vl, err := db.VerifyModelRefs(srcDb, modelDef.Model.ModelId)
+	if err != nil {
+		return nil, err
+	}
+	return append(viLst, vl...), nil
+}
+
+// verify model text files: model runs and worksets .json metadata files and .csv files of parameters and output tables.
+// If input directory is a .zip file then files are read directly from zip archive.
+// Model runs parameters and output tables values digests recalculated from .csv files and compared with digests from .json files.
+// If model metadata .json file not found in input directory then model metadata selected from database.
+func textVerify(modelName string, modelDigest string, runOpts *config.RunOptions) ([]db.VerifyIssue, error) {
+
+	inpDir := filepath.Clean(runOpts.String(inputDirArgKey))
+
+	if strings.HasSuffix(strings.ToLower(inpDir), ".zip") {
+
+		if err := openZipInp(inpDir); err != nil {
+			return nil, err
+		}
+		defer closeZipInp()
+
+		inpDir = strings.TrimSuffix(inpDir, filepath.Ext(inpDir))
+	}
+	omppLog.Log("Verify ", inpDir)
+
+	// get model metadata from json file or from database
+	modelDef := &db.ModelMeta{}
+
+	js, err := utf8Inp(filepath.Join(inpDir, modelName+".model.json"), theCfg.encodingName)
+	if err == nil {
+		isExist, err := modelDef.FromJson([]byte(js))
+		if err != nil {
+			return nil, err
+		}
+		if !isExist {
+			return nil, errors.New("model not found: " + modelName)
+		}
+	} else {
+		cs, dn := db.IfEmptyMakeDefaultReadOnly(modelName, runOpts.String(fromSqliteArgKey), runOpts.String(dbConnStrArgKey), runOpts.String(dbDriverArgKey))
+
+		srcDb, _, err := db.Open(cs, dn, false)
+		if err != nil {
+			return nil, err
+		}
+		defer srcDb.Close()
+
+		if err := db.CheckOpenmppSchemaVersion(srcDb); err != nil {
+			return nil, err
+		}
+		if modelDef, err = db.GetModel(srcDb, modelName, modelDigest); err != nil {
+			return nil, err
+		}
+	}
+	modelName = modelDef.Model.Name
+
+	viLst := []db.VerifyIssue{}
+
+	// verify model runs
+	fl, err := globInp(inpDir + "/" + modelName + ".run.*.json")
+	if err != nil {
+		return nil, err
+	}
+	omppLog.Log("Verify model runs: ", len(fl))
+
+	for k := range fl {
+		vl, err := textVerifyRun(modelDef, fl[k])
+		if err != nil {
+			return nil, err
+		}
+		viLst = append(viLst, vl...)
+	}
+
+	// verify worksets
+	fl, err = globInp(inpDir + "/" + modelName + ".set.*.json")
+	if err != nil {
+		return nil, err
+	}
+	omppLog.Log("Verify worksets: ", len(fl))
+
+	for k := range fl {
+		viLst = append(viLst, textVerifyWorkset(modelDef, fl[k])...)
+	}
+
+	return viLst, nil
+}
+
+// verify model run .json metadata file and .csv files of parameters and output tables.
+func textVerifyRun(modelDef *db.ModelMeta, metaPath string) ([]db.VerifyIssue, error) {
+
+	_, fn := filepath.Split(metaPath)
+	viLst := []db.VerifyIssue{}
+
+	var pub db.RunPub
+	isExist, err := fromJsonInp(metaPath, &pub)
+	if err != nil || !isExist {
+		return append(viLst, db.VerifyIssue{Kind: db.VerifyInvalidFile, Name: fn, Msg: "invalid (or empty) model run .json file"}), nil
+	}
+	omppLog.Log("Model run ", pub.Name)
+
+	addIssue := func(kind, item, msg string) {
+		viLst = append(viLst, db.VerifyIssue{Kind: kind, Name: pub.Name, Item: item, Msg: msg})
+	}
+
+	if !theCfg.isNoDigestCheck && pub.ModelDigest != modelDef.Model.Digest {
+		addIssue(db.VerifyModelDigest, "", "model digest mismatch: "+pub.ModelDigest+" expected: "+modelDef.Model.Digest)
+	}
+	if !db.IsRunCompleted(pub.Status) {
+		addIssue(db.VerifyInvalidFile, "", "model run not completed, status: "+pub.Status)
+		return viLst, nil
+	}
+	isDigest := pub.Status == db.DoneRunStatus || pub.Status == db.ExitRunStatus
+
+	if isDigest && !theCfg.isNoDigestCheck {
+		if dg := db.RunMetaDigest(pub.ModelDigest, pub.Name, pub.SubCount, pub.CreateDateTime, pub.RunStamp); dg != pub.RunDigest {
+			addIssue(db.VerifyRunDigest, "", "run digest mismatch: "+pub.RunDigest+" expected: "+dg)
+		}
+	}
+
+	// csv directories of parameters and output tables: modelName.run.1234.Name.json => run.1234.Name/parameters
+	d := filepath.Dir(metaPath)
+	c := strings.TrimSuffix(strings.TrimPrefix(fn, pub.ModelName+"."), ".json")
+	paramCsvDir := filepath.Join(d, c, "parameters")
+	tableCsvDir := filepath.Join(d, c, "output-tables")
+
+	// all model parameters must be included in model run, recalculate parameter values digest from csv
+	pl := []string{}
+
+	for j := range modelDef.Param {
+
+		name := modelDef.Param[j].Name
+		np := -1
+		for i := range pub.Param {
+			if pub.Param[i].Name == name {
+				np = i
+				break
+			}
+		}
+		if np < 0 {
+			addIssue(db.VerifyMissingParam, name, "parameter not found in model run .json")
+			continue
+		}
+		pl = append(pl, pub.Param[np].ValueDigest)
+
+		cvt := db.CellParamConverter{ModelDef: modelDef, Name: name, DoubleFmt: theCfg.doubleFmt}
+
+		dg, isOk, msg := textDigestParam(modelDef, cvt, paramCsvDir)
+		switch {
+		case msg != "":
+			addIssue(db.VerifyMissingFile, name, msg)
+		case !isOk:
+			addIssue(db.VerifyInvalidFile, name, "parameter .csv rows are not ordered by dimensions, digest cannot be verified")
+		case pub.Param[np].ValueDigest != "" && dg != pub.Param[np].ValueDigest:
+			addIssue(db.VerifyParamDigest, name, "value digest mismatch: "+pub.Param[np].ValueDigest+" expected: "+dg)
+		}
+	}
+
+	// recalculate output tables values digest from csv, if accumulators csv file exist
+	tl := []string{}
+
+	for j := range modelDef.Table {
+
+		name := modelDef.Table[j].Name
+		nt := -1
+		for i := range pub.Table {
+			if pub.Table[i].Name == name {
+				nt = i
+				break
+			}
+		}
+		if nt < 0 {
+			continue // skip table: it is suppressed and not in run results
+		}
+		tl = append(tl, pub.Table[nt].ValueDigest)
+
+		ctc := db.CellTableConverter{ModelDef: modelDef, Name: name, DoubleFmt: theCfg.doubleFmt}
+
+		dg, isOk, msg := textDigestTable(modelDef, db.CellExprConverter{CellTableConverter: ctc}, db.CellAccConverter{CellTableConverter: ctc}, tableCsvDir)
+		switch {
+		case msg != "":
+			addIssue(db.VerifyMissingFile, name, msg)
+		case !isOk:
+			addIssue(db.VerifyInvalidFile, name, "output table .csv rows are not ordered by dimensions, digest cannot be verified")
+		case dg != "" && pub.Table[nt].ValueDigest != "" && dg != pub.Table[nt].ValueDigest:
+			addIssue(db.VerifyTableDigest, name, "value digest mismatch: "+pub.Table[nt].ValueDigest+" expected: "+dg)
+		}
+	}
+
+	// verify run value digest
+	if isDigest && pub.ValueDigest != "" {
+		if dg := db.RunValueDigest(pub.SubCount, pub.SubCompleted, pub.Status, pl, tl); dg != pub.ValueDigest {
+			addIssue(db.VerifyValueDigest, "", "value digest mismatch: "+pub.ValueDigest+" expected: "+dg)
+		}
+	}
+
+	// microdata csv directory must exist if model run contains microdata
+	if len(pub.Entity) > 0 {
+		if _, err := statInp(filepath.Join(d, c, "microdata")); err != nil {
+			addIssue(db.VerifyMissingFile, "", "microdata directory not found: "+filepath.Join(c, "microdata"))
+		}
+	}
+	return viLst, nil
+}
+
+// recalculate parameter value digest from csv file.
+// Return digest, false if csv rows are not in dimensions order and error message if csv file not found or invalid.
+func textDigestParam(modelDef *db.ModelMeta, csvCvt db.CellParamConverter, csvDir string) (string, bool, string) {
+
+	cvt, err := csvCvt.ToCell()
+	if err != nil {
+		return "", false, "invalid converter from csv row: " + err.Error()
+	}
+	fn, err := csvCvt.CsvFileName()
+	if err != nil {
+		return "", false, "invalid csv file name: " + err.Error()
+	}
+	chs, err := csvCvt.CsvHeader()
+	if err != nil {
+		return "", false, "invalid csv header: " + err.Error()
+	}
+
+	f, fn, err := openCsvInp(csvDir, fn)
+	if err != nil {
+		return "", false, "csv file open error: " + err.Error()
+	}
+	defer f.Close()
+
+	from, err := makeFromCsvReader(fn, f, strings.Join(chs, ","), cvt)
+	if err != nil {
+		return "", false, err.Error()
+	}
+
+	dg, isOk, err := db.DigestParameterFrom(modelDef, csvCvt.Name, theCfg.doubleFmt, from)
+	if err != nil {
+		return "", false, err.Error()
+	}
+	return dg, isOk, ""
+}
+
+// recalculate output table value digest from accumulators and expressions csv files.
+// Return digest, false if csv rows are not in dimensions order and error message if csv file not found or invalid.
+// If accumulators csv file not exist then return empty "" digest: output table digest cannot be verified.
+func textDigestTable(modelDef *db.ModelMeta, cvtExpr db.CellExprConverter, cvtAcc db.CellAccConverter, csvDir string) (string, bool, string) {
+
+	// open expressions csv file, it must exist
+	eToCell, err := cvtExpr.ToCell()
+	if err != nil {
+		return "", false, "invalid converter from expressions csv row: " + err.Error()
+	}
+	eFn, err := cvtExpr.CsvFileName()
+	if err != nil {
+		return "", false, "invalid expressions csv file name: " + err.Error()
+	}
+	ehs, err := cvtExpr.CsvHeader()
+	if err != nil {
+		return "", false, "invalid expressions csv header: " + err.Error()
+	}
+
+	exprFile, eFn, err := openCsvInp(csvDir, eFn)
+	if err != nil {
+		return "", false, "expressions csv file open error: " + err.Error()
+	}
+	defer exprFile.Close()
+
+	exprFrom, err := makeFromCsvReader(eFn, exprFile, strings.Join(ehs, ","), eToCell)
+	if err != nil {
+		return "", false, err.Error()
+	}
+
+	// open accumulators csv file, if it is not exist then digest cannot be verified
+	aToCell, err := cvtAcc.ToCell()
+	if err != nil {
+		return "", false, "invalid converter from accumulators csv row: " + err.Error()
+	}
+	aFn, err := cvtAcc.CsvFileName()
+	if err != nil {
+		return "", false, "invalid accumulators csv file name: " + err.Error()
+	}
+	ahs, err := cvtAcc.CsvHeader()
+	if err != nil {
+		return "", false, "invalid accumulators csv header: " + err.Error()
+	}
+
+	accFile, aFn, err := openCsvInp(csvDir, aFn)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", true, "" // no accumulators csv: output table digest cannot be verified
+		}
+		return "", false, "accumulators csv file open error: " + err.Error()
+	}
+	defer accFile.Close()
+
+	accFrom, err := makeFromCsvReader(aFn, accFile, strings.Join(ahs, ","), aToCell)
+	if err != nil {
+		return "", false, err.Error()
+	}
+
+	dg, isOk, err := db.DigestOutputTableFrom(modelDef, cvtExpr.Name, theCfg.doubleFmt, accFrom, exprFrom)
+	if err != nil {
+		return "", false, err.Error()
+	}
+	return dg, isOk, ""
+}
+
+// verify workset .json metadata file: model digest must be the same and csv file must exist for each workset parameter.
+func textVerifyWorkset(modelDef *db.ModelMeta, metaPath string) []db.VerifyIssue {
+
+	_, fn := filepath.Split(metaPath)
+	viLst := []db.VerifyIssue{}
+
+	var pub db.WorksetPub
+	isExist, err := fromJsonInp(metaPath, &pub)
+	if err != nil || !isExist {
+		return append(viLst, db.VerifyIssue{Kind: db.VerifyInvalidFile, Name: fn, Msg: "invalid (or empty) workset .json file"})
+	}
+	omppLog.Log("Workset ", pub.Name)
+
+	addIssue := func(kind, item, msg string) {
+		viLst = append(viLst, db.VerifyIssue{Kind: kind, Name: pub.Name, Item: item, Msg: msg})
+	}
+
+	if !theCfg.isNoDigestCheck && pub.ModelDigest != modelDef.Model.Digest {
+		addIssue(db.VerifyModelDigest, "", "model digest mismatch: "+pub.ModelDigest+" expected: "+modelDef.Model.Digest)
+	}
+
+	// workset csv directory: modelName.set.Name.json => set.Name
+	csvDir := filepath.Join(filepath.Dir(metaPath), strings.TrimSuffix(strings.TrimPrefix(fn, modelDef.Model.Name+"."), ".json"))
+
+	for j := range pub.Param {
+
+		if _, ok := modelDef.ParamByName(pub.Param[j].Name); !ok {
+			addIssue(db.VerifyMissingParam, pub.Param[j].Name, "parameter not found in model")
+			continue
+		}
+		if _, err := findCsvInp(csvDir, pub.Param[j].Name+".csv"); err != nil {
+			addIssue(db.VerifyMissingFile, pub.Param[j].Name, "parameter csv file not found")
+		}
+	}
+	return viLst
+}
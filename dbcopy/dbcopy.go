@@ -49,6 +49,16 @@ Copy to "db2db": direct copy between two databases:
 
 	dbcopy -m modelOne -dbcopy.To db2db -dbcopy.ToSqlite modelOne.sqlite
 
+To synchronize model between two databases and copy only missing or changed objects use -dbcopy.Sync:
+
+	dbcopy -m modelOne -dbcopy.To db2db -dbcopy.Sync -dbcopy.ToSqlite archive/modelOne.sqlite
+
+Sync compares source and destination by model digest, model run digest and value digest,
+workset update date-time, modeling task metadata and task run history.
+Model runs, worksets or tasks which are up to date in destination database are skipped.
+If previous sync or copy was interrupted then incomplete model runs and worksets are copied again.
+At the end dbcopy reports how many model runs, worksets and tasks were copied, replaced or skipped.
+
 Copy to "csv": read entire model from database and save into .csv or .tsv files:
 
 	dbcopy -m modelOne -dbcopy.To csv
@@ -96,12 +106,43 @@ To copy only one modeling task metadata and run history:
 	dbcopy -m modelOne -dbcopy.TaskId 1
 	dbcopy -m modelOne -dbcopy.TaskName taskOne
 
+To copy entire model with selected model runs, worksets and modeling tasks use any of filter options:
+
+	dbcopy -m modelOne -dbcopy.Select run -dbcopy.FromDate 2024-07-01 -dbcopy.ToDate 2024-09-30
+	dbcopy -m modelOne -dbcopy.To db2db -dbcopy.ToSqlite archive.sqlite -dbcopy.Select run -dbcopy.FromDate 2024-07-01 -dbcopy.ToDate 2024-09-30
+	dbcopy -m modelOne -dbcopy.To csv -dbcopy.LastRuns 5
+	dbcopy -m modelOne -dbcopy.To csv -dbcopy.RunStatus success,error -dbcopy.NameMatch "Q3*"
+	dbcopy -m modelOne -dbcopy.Select run,set -dbcopy.NameRegex "^(Default|Base)"
+
+Filter options can be used to copy entire model into "text", "csv", "csv-all" or "db2db":
+
+	-dbcopy.Select    comma separated list of objects to copy: run, set, task, default: all
+	-dbcopy.FromDate  model run created or workset updated at or after this date or date-time
+	-dbcopy.ToDate    model run created or workset updated at or before this date or date-time, it is inclusive
+	-dbcopy.RunStatus model run status: success, error, exit or completed, default: success
+	-dbcopy.NameMatch model run, workset or task name pattern, ex.: Q3* or ?-run
+	-dbcopy.NameRegex model run, workset or task name regular expression
+	-dbcopy.LastRuns  copy only last N model runs selected by other filters
+
+Modeling tasks are selected by task name. If model runs or worksets are also copied then task is skipped
+if any of task worksets or task run model runs are not selected.
+Only readonly worksets are copied.
+
 It is convenient to pack (unpack) text files into .zip archive:
 
 	dbcopy -m modelOne -dbcopy.Zip=true
 	dbcopy -m modelOne -dbcopy.Zip
 	dbcopy -m modelOne -dbcopy.SetName Default -dbcopy.Zip
 
+Output json and csv files are written into output directory and packed into .zip archive after all files created,
+output directory is not deleted. Input .zip archive is read directly, dbcopy does not create temporary directory to unpack .zip.
+
+To write json and csv files directly into .zip archive stream, without creating output directory, use -dbcopy.ZipStream:
+
+	dbcopy -m modelOne -dbcopy.ZipStream
+
+The only exception is "all-in-one" csv output, -dbcopy.To csv-all, which is always packed into .zip after all csv files created.
+
 By default model name is used to create output directory for text files or as input directory to import from.
 It may be a problem on Linux if current directory already contains executable "modelName".
 
@@ -187,6 +228,44 @@ To delete from database entire model, model run results, set of input parameters
 	dbcopy -m modelOne -dbcopy.Delete -dbcopy.TaskId 1
 	dbcopy -m modelOne -dbcopy.Delete -dbcopy.TaskName taskOne
 
+To delete old model runs by retention policy and archive it into .zip file use -dbcopy.Purge with -dbcopy.KeepLast number of runs:
+
+	dbcopy -m modelOne -dbcopy.Purge -dbcopy.KeepLast 20 -dbcopy.DryRun
+	dbcopy -m modelOne -dbcopy.Purge -dbcopy.KeepLast 20
+	dbcopy -m modelOne -dbcopy.Purge -dbcopy.KeepLast 20 -dbcopy.OutputDir archive
+
+Purge keeps last 20 model runs of each run name, it always keeps model runs which are not completed yet
+and model runs referenced by any workset as a base run or by modeling task run history.
+All other model runs are written into modelOne.purge.2024_10_21_16_04_59_148.zip archive and deleted from database.
+At the end SQLite database file is compacted by VACUUM.
+Use -dbcopy.DryRun to display list of model runs to delete without actual archive and delete.
+To restore model runs from archive unpack it and use:
+
+	dbcopy -m modelOne -dbcopy.To db -dbcopy.InputDir modelOne.purge.2024_10_21_16_04_59_148
+
+To verify integrity of model database use -dbcopy.Verify:
+
+	dbcopy -m modelOne -dbcopy.Verify
+	dbcopy -m modelOne -dbcopy.Verify -dbcopy.FromSqlite archive/modelOne.sqlite
+
+It does recalculate model run digest and value digest, parameters and output tables value digests
+and compare it with digests stored in database. It also does check references between model runs, worksets and modeling tasks:
+model runs without parameters, parameters or output tables values which refer to missing base run,
+workset parameters without workset, worksets and modeling task runs which refer to deleted model runs.
+
+To verify integrity of model text files, for example, created by dbcopy -dbcopy.Zip, use -dbcopy.InputDir:
+
+	dbcopy -m modelOne -dbcopy.Verify -dbcopy.InputDir modelOne
+	dbcopy -m modelOne -dbcopy.Verify -dbcopy.InputDir modelOne.zip
+	dbcopy -m modelOne -dbcopy.Verify -dbcopy.InputDir modelOne.run.101.zip
+
+It does check model runs and worksets .json metadata files and .csv files, recalculate parameters and output tables
+value digests from .csv files and compare it with digests from .json files.
+If .zip file specified then files are read directly from .zip archive.
+Output tables digest verified only if accumulators .csv files exist, it is not possible if -dbcopy.NoAccumulatorsCsv was used.
+If model metadata modelOne.model.json file not exist in input directory then model metadata is selected from database.
+Dbcopy report all issues found and return an error exit code if there are any issues.
+
 To rename model run results, input set of parameters or modeling task:
 
 	dbcopy -m modelOne -dbcopy.Rename -dbcopy.RunId 101 -dbcopy.ToRunName New_Run_Name
@@ -200,6 +279,90 @@ To rename model run results, input set of parameters or modeling task:
 	dbcopy -m modelOne -dbcopy.Rename -dbcopy.TaskName taskOne -dbcopy.ToTaskName "New Task Name"
 	dbcopy -m modelOne -dbcopy.Rename -dbcopy.TaskId 1 -dbcopy.ToTaskName "New Task Name"
 
+To create parameter sweep: one input set of parameters (workset) for each combination of parameter values
+and modeling task which include all those worksets:
+
+	dbcopy -m modelOne -dbcopy.Sweep my-sweep.json
+	dbcopy -m modelOne -dbcopy.Sweep my-sweep.json -dbcopy.TaskName mySweepTask
+
+Parameter sweep json file contains base model run or base workset, list or range of values or factors for each parameter, for example:
+
+	{
+	  "Name": "mySweepTask",
+	  "BaseRun": "First run",
+	  "IsOneAtTime": false,
+	  "Param": [
+	    {"Name": "StartingSeed", "Value": [1, 2, 3]},
+	    {"Name": "ageSex", "IsFactor": true, "From": 0.5, "To": 1.5, "Count": 5}
+	  ],
+	  "Txt": [{"LangCode": "EN", "Descr": "Parameter sweep"}]
+	}
+
+Worksets are named as task name with combination number: mySweepTask_1, mySweepTask_2,...
+If IsOneAtTime is true then only one parameter changed in each workset else it is full factorial sweep: all combinations of values.
+
+To create input sets of parameters (worksets) from samples of uncertain parameters and modeling task which include all those worksets:
+
+	dbcopy -m modelOne -dbcopy.Sample my-sample.json
+	dbcopy -m modelOne -dbcopy.Sample my-sample.json -dbcopy.TaskName mySampleTask
+
+Sampling plan json file contains base model run or base workset, sampling method, number of samples
+and distribution of each uncertain parameter value for all parameter cells or for one parameter cell, for example:
+
+	{
+	  "Name": "mySampleTask",
+	  "BaseRun": "First run",
+	  "Method": "lhs",
+	  "Count": 100,
+	  "Seed": 12345,
+	  "Param": [
+	    {"Name": "StartingSeed", "Distr": {"Kind": "discrete", "Value": [1, 2, 3], "Weight": [0.5, 0.25, 0.25]}},
+	    {"Name": "ageSex", "IsFactor": true, "Distr": {"Kind": "normal", "Mean": 1.0, "StdDev": 0.1}},
+	    {"Name": "ageSex", "Dims": ["10-20", "M"], "Distr": {"Kind": "triangular", "Min": 0.5, "Mode": 1.0, "Max": 2.0}},
+	    {"Name": "ageSex", "Dims": ["20-30", "F"], "Distr": {"Kind": "uniform", "Min": 0.5, "Max": 1.5}}
+	  ],
+	  "Txt": [{"LangCode": "EN", "Descr": "Uncertainty analysis"}]
+	}
+
+Sampling method can be: "random" plain random sampling (default), "lhs" Latin hypercube or "sobol" Sobol sequence.
+Worksets are named as task name with sample number: mySampleTask_1, mySampleTask_2,...
+If seed is zero then it is generated, random seed and sampling plan are stored in modeling task notes.
+
+To update values of input set parameter (workset parameter) by arithmetic expression, e.g. to create "+5% growth" scenario:
+
+	dbcopy -m modelOne -s MyScenario -dbcopy.Transform growth.json
+	dbcopy -m modelOne -dbcopy.SetId 2 -dbcopy.Transform growth.json
+
+Parameter transform json file contains parameter name, expression and optional dimension filters, for example:
+
+	{
+	  "Name": "ageSex",
+	  "Expr": "value * 1.05",
+	  "Filter": [{"Name": "dim0", "Op": ">=", "Values": ["10-20"]}]
+	}
+
+Expression can be: "value * 1.05", "value / 2", "value + other_param", "value - 10" or "= 0" to replace values.
+If other parameter used in expression then it must be in the same workset and its dimensions must be a subset of parameter dimensions.
+Workset must be read-write, current parameter values saved as workset version in the same transaction.
+Workset versions require database schema version 105 or later, see sql/upgrade_v105.sql.
+
+Parameter values are checked by validation rules of the model after transform, when sweep or sample worksets created
+and before input set of parameters (workset) imported as read-only, for example:
+
+	dbcopy -m modelOne -dbcopy.To db -dbcopy.RulesDir models/bin
+
+Validation rules are from modelName.validation.json file in dbcopy.RulesDir directory, by default in current directory,
+and from Validate.ParamName.RuleName options of model profile, profile name is a model name.
+If parameter values do not satisfy validation rules then transform or import of read-only workset failed.
+
+To create new input set of parameters (workset) from model run parameters, including sub-values and parameter value notes:
+
+	dbcopy -m modelOne -dbcopy.RunName "My Model Run" -dbcopy.RunToSet MyScenario
+	dbcopy -m modelOne -dbcopy.LastRun -dbcopy.RunToSet MyScenario -dbcopy.ParamList ageSex,salaryAge
+
+Model run becomes the base run of new workset. By default all model run parameters copied into new workset.
+If dbcopy.ParamList specified then only those parameters copied and all other parameters are inherited from the base run.
+
 By default float and double values converted into csv text with "%.15g" format.
 It is possible to specify other format for float values values:
 
@@ -223,6 +386,54 @@ If you want to write utf-8 BOM into output csv file then:
 	dbcopy -m modelOne -dbcopy.Utf8BomIntoCsv
 	dbcopy -m modelOne -dbcopy.Utf8BomIntoCsv -dbcopy.To csv
 
+To write compressed .csv.gz or .csv.zst files of parameters, output tables, accumulators and microdata:
+
+	dbcopy -m modelOne -dbcopy.CsvCompress gzip
+	dbcopy -m modelOne -dbcopy.CsvCompress zstd -dbcopy.To csv
+	dbcopy -m modelOne -dbcopy.CsvCompress zstd -dbcopy.IntoTsv
+
+Compressed files are written directly, without creating uncompressed copy of .csv file.
+Model metadata .csv files are not compressed.
+Dbcopy does read compressed .csv.gz or .csv.zst files if .csv file not exist, for example:
+
+	dbcopy -m modelOne -dbcopy.To db
+
+To export or import model run parameters, output tables and microdata concurrently use Threads option:
+
+	dbcopy -m modelOne -dbcopy.Threads 8
+	dbcopy -m modelOne -dbcopy.Threads 8 -dbcopy.To csv
+	dbcopy -m modelOne -dbcopy.Threads 8 -dbcopy.To db -dbcopy.ToDatabase "DSN=bigSql" -dbcopy.ToDatabaseDriver odbc
+
+Each thread reads parameter, output table or microdata from database and writes it into .csv file,
+or reads .csv file and writes it into database in a separate transaction.
+If any of parameters or output tables import failed then model run is deleted from database.
+By default there is only one thread and model run data copied sequentially.
+It is also always sequential if output is a .zip archive stream (-dbcopy.ZipStream option),
+if output database is SQLite and for microdata import into database.
+
+To copy model run into the model with different digest, for example, from previous version of the model into the new build, use MapByName option:
+
+	dbcopy -m modelOne -dbcopy.MapByName -dbcopy.RunName Default -dbcopy.To db
+	dbcopy -m modelOne -dbcopy.MapByName -dbcopy.LastRun -dbcopy.To db2db -dbcopy.ToSqlite new/modelOne.sqlite
+	dbcopy -m modelOne -dbcopy.ModelDigest 649f17f26d67c37b78dde94f79772445 -dbcopy.MapByName -dbcopy.LastRun -dbcopy.To db2db -dbcopy.ToModelDigest 9cc4a8bf4a7d3c5d5f1e1bd2a7d0f2e4
+
+Parameters, output tables and entities are matched by name, dimensions and attributes by name,
+dimension items and enum-based values are mapped through enum codes.
+Parameter is compatible if it has the same dimension names and the same enum codes for each dimension,
+model run cannot be copied if any of destination model parameters is not found or not compatible.
+Use MapFillDefault option to copy such parameters from destination model default workset, each filled parameter is logged:
+
+	dbcopy -m modelOne -dbcopy.MapByName -dbcopy.MapFillDefault -dbcopy.RunName Default -dbcopy.To db
+
+Output table is compatible if it has the same dimensions, expressions and accumulators and destination has all source enum codes,
+output tables and entities which are not compatible are not copied.
+Dbcopy reports all structural mismatches: added or removed parameters, output tables, dimensions, changed enum lists and value types.
+
+Copy to "db" is using source model metadata from modelOne.model.json file in model run directory or in input directory,
+if there is no such file then items are matched by name and enum codes validated against destination model.
+Copy to "db2db" source and destination models can be in the same database if model digests are different,
+use ToModelDigest option to select destination model, by default it is a first model with the same name.
+
 By default dbcopy using SQLite database connection:
 
 	dbcopy -m modelOne
@@ -271,6 +482,7 @@ import (
 
 	"github.com/openmpp/go/ompp/config"
 	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
 	"github.com/openmpp/go/ompp/omppLog"
 )
 
@@ -279,6 +491,23 @@ const (
 	copyToArgKey        = "dbcopy.To"                // copy to: text=db-to-text, db=text-to-db, db2db=db-to-db, csv=db-to-csv, csv-all=db-to-csv-all-in-one
 	deleteArgKey        = "dbcopy.Delete"            // delete model or workset or model run or modeling task from database
 	renameArgKey        = "dbcopy.Rename"            // rename workset or model run or modeling task
+	sweepArgKey         = "dbcopy.Sweep"             // path to parameter sweep json file to create sweep worksets and modeling task
+	sampleArgKey        = "dbcopy.Sample"            // path to sampling plan json file to create sample worksets and modeling task
+	transformArgKey     = "dbcopy.Transform"         // path to parameter transform json file to update workset parameter values
+	runToSetArgKey      = "dbcopy.RunToSet"          // new workset name to create workset from model run parameters
+	paramListArgKey     = "dbcopy.ParamList"         // comma separated list of parameters to copy from model run into new workset
+	syncArgKey          = "dbcopy.Sync"              // if true then copy db2db only missing or changed model runs, worksets and tasks
+	selectArgKey        = "dbcopy.Select"            // comma separated list of objects to copy: run, set, task
+	fromDateArgKey      = "dbcopy.FromDate"          // select model runs created or worksets updated at or after this date-time
+	toDateArgKey        = "dbcopy.ToDate"            // select model runs created or worksets updated at or before this date-time
+	runStatusArgKey     = "dbcopy.RunStatus"         // select model runs by status: success, error, exit, completed
+	nameMatchArgKey     = "dbcopy.NameMatch"         // select model runs, worksets and tasks by name glob pattern, ex.: Q3*
+	nameRegexArgKey     = "dbcopy.NameRegex"         // select model runs, worksets and tasks by name regular expression
+	lastRunsArgKey      = "dbcopy.LastRuns"          // select only last N model runs
+	purgeArgKey         = "dbcopy.Purge"             // delete old model runs by retention policy and archive it into .zip
+	keepLastArgKey      = "dbcopy.KeepLast"          // purge: number of last model runs to keep for each run name
+	dryRunArgKey        = "dbcopy.DryRun"            // purge: if true then only display model runs to delete
+	verifyArgKey        = "dbcopy.Verify"            // verify integrity of model database or model text files
 	modelNameArgKey     = "dbcopy.ModelName"         // model name
 	modelNameShortKey   = "m"                        // model name (short form)
 	modelDigestArgKey   = "dbcopy.ModelDigest"       // model hash digest
@@ -308,6 +537,7 @@ const (
 	paramDirArgKey      = "dbcopy.ParamDir"          // path to workset parameters directory
 	paramDirShortKey    = "p"                        // path to workset parameters directory (short form)
 	zipArgKey           = "dbcopy.Zip"               // create output or use as input model.zip
+	zipStreamArgKey     = "dbcopy.ZipStream"         // write output directly into model.zip stream, do not create output directory
 	intoTsvArgKey       = "dbcopy.IntoTsv"           // if true then create .tsv output files instead of .csv by default
 	useIdCsvArgKey      = "dbcopy.IdCsv"             // if true then create csv files with enum id's default: enum code
 	useIdNamesArgKey    = "dbcopy.IdOutputNames"     // if true then always use id's in output directory and file names, false never use it
@@ -319,6 +549,12 @@ const (
 	doubleFormatArgKey  = "dbcopy.DoubleFormat"      // convert to string format for float and double
 	encodingArgKey      = "dbcopy.CodePage"          // code page for converting source files, e.g. windows-1252
 	useUtf8CsvArgKey    = "dbcopy.Utf8BomIntoCsv"    // if true then write utf-8 BOM into csv file
+	csvCompressArgKey   = "dbcopy.CsvCompress"       // compress parameters, output tables and microdata csv files: gzip or zstd
+	threadsArgKey       = "dbcopy.Threads"           // number of threads to export or import model run parameters, output tables and microdata
+	mapByNameArgKey     = "dbcopy.MapByName"         // if true then copy model run into model with different digest: match items by name and enum codes
+	toModelDigestArgKey = "dbcopy.ToModelDigest"     // destination model digest to copy model run db2db by name mapping
+	mapFillDefArgKey    = "dbcopy.MapFillDefault"    // if true then missing or not compatible parameters copied from destination default workset
+	rulesDirArgKey      = "dbcopy.RulesDir"          // directory of parameter validation rules file: modelName.validation.json
 )
 
 // useIdNames is type to define how to make run and set directory and file names
@@ -332,19 +568,26 @@ const (
 
 // run options
 var theCfg = struct {
-	isKeepOutputDir bool   // if true then keep existing output directory
-	isNoDigestCheck bool   // if true then ignore input model digest, use model name only to load values from csv
-	isTsv           bool   // if true then create .tsv output files instead of .csv by default
-	isIdCsv         bool   // if true then create csv files with enum id's default: enum code
-	isNoAccCsv      bool   // if true then do not create accumulators .csv files
-	isNoMicrodata   bool   // if true then suppress microdata output
-	isNoZeroCsv     bool   // if true then do not write zero values into output tables .csv files
-	isNoNullCsv     bool   // if true then do not write NULL values into output tables .csv files
-	doubleFmt       string // format to convert float or double value to string
-	encodingName    string // code page for converting source files, e.g. windows-1252
-	isWriteUtf8Bom  bool   // if true then write utf-8 BOM into csv file
+	isKeepOutputDir bool       // if true then keep existing output directory
+	isNoDigestCheck bool       // if true then ignore input model digest, use model name only to load values from csv
+	isTsv           bool       // if true then create .tsv output files instead of .csv by default
+	isIdCsv         bool       // if true then create csv files with enum id's default: enum code
+	isNoAccCsv      bool       // if true then do not create accumulators .csv files
+	isNoMicrodata   bool       // if true then suppress microdata output
+	isNoZeroCsv     bool       // if true then do not write zero values into output tables .csv files
+	isNoNullCsv     bool       // if true then do not write NULL values into output tables .csv files
+	doubleFmt       string     // format to convert float or double value to string
+	encodingName    string     // code page for converting source files, e.g. windows-1252
+	isWriteUtf8Bom  bool       // if true then write utf-8 BOM into csv file
+	csvCompressExt  string     // if not empty then compressed csv file extension: .gz or .zst
+	threads         int        // number of threads to export or import model run parameters, output tables and microdata
+	isMapByName     bool       // if true then copy model run into model with different digest: match items by name and enum codes
+	isMapFillDef    bool       // if true then missing or not compatible parameters copied from destination default workset
+	rulesDir        string     // directory of parameter validation rules file: modelName.validation.json
+	filter          copyFilter // selection of model runs, worksets and tasks to copy entire model
 }{
 	doubleFmt:    "%.15g", // default format to convert float or double values to string
+	threads:      1,       // by default export and import model run data sequentially
 	encodingName: "",      // by default detect utf-8 encoding or use OS-specific default: windows-1252 on Windowds and utf-8 outside
 }
 
@@ -365,6 +608,23 @@ func mainBody(args []string) error {
 	_ = flag.String(copyToArgKey, "text", "copy to: `text`=db-to-text, db=text-to-db, db2db=db-to-db, csv=db-to-csv, csv-all=db-to-csv-all-in-one")
 	_ = flag.Bool(deleteArgKey, false, "delete from database: model, set of input parameters, model run or modeling task")
 	_ = flag.Bool(renameArgKey, false, "rename set of input parameters, model run or modeling task")
+	_ = flag.String(sweepArgKey, "", "path to parameter sweep json file to create sweep input sets and modeling task")
+	_ = flag.String(sampleArgKey, "", "path to sampling plan json file to create input sets from samples of uncertain parameters and modeling task")
+	_ = flag.String(transformArgKey, "", "path to parameter transform json file to update values of input set parameter, ex.: value * 1.05")
+	_ = flag.String(runToSetArgKey, "", "name of new input set of parameters to create from model run parameters")
+	_ = flag.String(paramListArgKey, "", "comma separated list of parameters to copy from model run into new input set, by default all parameters")
+	_ = flag.Bool(syncArgKey, false, "if true then copy db2db only missing or changed model runs, input sets and modeling tasks")
+	_ = flag.String(selectArgKey, "", "comma separated list of objects to copy: run, set, task, default: all")
+	_ = flag.String(fromDateArgKey, "", "select model runs created or input sets updated at or after this date, ex.: 2024-07-01")
+	_ = flag.String(toDateArgKey, "", "select model runs created or input sets updated at or before this date, ex.: 2024-09-30")
+	_ = flag.String(runStatusArgKey, "", "select model runs by status: success, error, exit, completed, default: success")
+	_ = flag.String(nameMatchArgKey, "", "select model runs, input sets and modeling tasks by name pattern, ex.: Q3*")
+	_ = flag.String(nameRegexArgKey, "", "select model runs, input sets and modeling tasks by name regular expression")
+	_ = flag.Int(lastRunsArgKey, 0, "select only last N model runs")
+	_ = flag.Bool(purgeArgKey, false, "if true then delete old model runs by retention policy and archive it into .zip")
+	_ = flag.Int(keepLastArgKey, 0, "purge: number of last model runs to keep for each run name")
+	_ = flag.Bool(dryRunArgKey, false, "purge: if true then only display model runs to delete")
+	_ = flag.Bool(verifyArgKey, false, "if true then verify integrity of model database or model text files in input directory")
 	_ = flag.String(modelNameArgKey, "", "model name")
 	_ = flag.String(modelNameShortKey, "", "model name (short of "+modelNameArgKey+")")
 	_ = flag.String(modelDigestArgKey, "", "model hash digest")
@@ -394,6 +654,7 @@ func mainBody(args []string) error {
 	_ = flag.String(paramDirArgKey, "", "path to parameters directory (input parameters set directory)")
 	_ = flag.String(paramDirShortKey, "", "path to parameters directory (short of "+paramDirArgKey+")")
 	_ = flag.Bool(zipArgKey, false, "create output model.zip or use model.zip as input")
+	_ = flag.Bool(zipStreamArgKey, false, "write output directly into model.zip stream, do not create output directory")
 	_ = flag.Bool(intoTsvArgKey, theCfg.isTsv, "if true then create .tsv output files instead of .csv by default")
 	_ = flag.Bool(useIdNamesArgKey, false, "if true then always use id's in output directory names, false never use. Default for csv: only if name conflict")
 	_ = flag.Bool(useIdCsvArgKey, false, "if true then create csv files with enum id's default: enum code")
@@ -405,6 +666,12 @@ func mainBody(args []string) error {
 	_ = flag.String(doubleFormatArgKey, theCfg.doubleFmt, "convert to string format for float and double")
 	_ = flag.String(encodingArgKey, theCfg.encodingName, "code page to convert source file into utf-8, e.g.: windows-1252")
 	_ = flag.Bool(useUtf8CsvArgKey, theCfg.isWriteUtf8Bom, "if true then write utf-8 BOM into csv file")
+	_ = flag.String(csvCompressArgKey, "", "compress parameters, output tables and microdata csv files: gzip or zstd")
+	_ = flag.Int(threadsArgKey, theCfg.threads, "number of threads to export or import model run parameters, output tables and microdata")
+	_ = flag.Bool(mapByNameArgKey, theCfg.isMapByName, "if true then copy model run into model with different digest: match items by name and enum codes")
+	_ = flag.String(toModelDigestArgKey, "", "destination model digest to copy model run db2db by name mapping")
+	_ = flag.Bool(mapFillDefArgKey, theCfg.isMapFillDef, "if true then missing or not compatible parameters copied from destination default workset")
+	_ = flag.String(rulesDirArgKey, "", "directory of parameter validation rules file: modelName.validation.json, default: current directory")
 
 	// pairs of full and short argument names to map short name to full name
 	var optFs = []config.FullShort{
@@ -451,16 +718,112 @@ func mainBody(args []string) error {
 	theCfg.doubleFmt = runOpts.String(doubleFormatArgKey)
 	theCfg.encodingName = runOpts.String(encodingArgKey)
 	theCfg.isWriteUtf8Bom = runOpts.Bool(useUtf8CsvArgKey)
+	theCfg.isMapByName = runOpts.Bool(mapByNameArgKey)
+	theCfg.isMapFillDef = runOpts.Bool(mapFillDefArgKey)
+	theCfg.rulesDir = runOpts.String(rulesDirArgKey)
+
+	if theCfg.csvCompressExt, err = helper.CompressExt(runOpts.String(csvCompressArgKey)); err != nil {
+		return errors.New("dbcopy invalid argument: " + csvCompressArgKey + ": " + err.Error())
+	}
+	theCfg.threads = runOpts.Int(threadsArgKey, theCfg.threads)
+	if theCfg.threads < 1 {
+		return errors.New("dbcopy invalid argument: " + threadsArgKey + " must be a positive number: " + runOpts.String(threadsArgKey))
+	}
 
 	// minimal validation of run options
 	//
 	copyToArg := strings.ToLower(runOpts.String(copyToArgKey))
 	isDel := runOpts.Bool(deleteArgKey)
 	isRename := runOpts.Bool(renameArgKey)
+	isSweep := runOpts.IsExist(sweepArgKey)
+	isSample := runOpts.IsExist(sampleArgKey)
+	isTransform := runOpts.IsExist(transformArgKey)
+	isRunToSet := runOpts.IsExist(runToSetArgKey)
+	isSync := runOpts.Bool(syncArgKey)
+	isPurge := runOpts.Bool(purgeArgKey)
+	isVerify := runOpts.Bool(verifyArgKey)
 
 	if (isDel || isRename) && runOpts.IsExist(copyToArgKey) {
 		return errors.New("dbcopy invalid arguments: " + deleteArgKey + " or " + renameArgKey + " cannot be used with " + copyToArgKey)
 	}
+	if isSweep && (isDel || isRename || runOpts.IsExist(copyToArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + sweepArgKey + " cannot be used with " + deleteArgKey + " or " + renameArgKey + " or " + copyToArgKey)
+	}
+	if isSample && (isSweep || isDel || isRename || runOpts.IsExist(copyToArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + sampleArgKey + " cannot be used with " + sweepArgKey + " or " + deleteArgKey + " or " + renameArgKey + " or " + copyToArgKey)
+	}
+	if isTransform && (isSample || isSweep || isDel || isRename || runOpts.IsExist(copyToArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + transformArgKey + " cannot be used with " + sampleArgKey + " or " + sweepArgKey + " or " + deleteArgKey + " or " + renameArgKey + " or " + copyToArgKey)
+	}
+	if isTransform && !runOpts.IsExist(setNameArgKey) && !runOpts.IsExist(setIdArgKey) {
+		return errors.New("dbcopy invalid arguments: " + transformArgKey + " must be used with any of: " + setNameArgKey + ", " + setIdArgKey)
+	}
+	if isRunToSet && (isTransform || isSample || isSweep || isDel || isRename || runOpts.IsExist(copyToArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + runToSetArgKey + " cannot be used with " + transformArgKey + " or " + sampleArgKey + " or " + sweepArgKey + " or " + deleteArgKey + " or " + renameArgKey + " or " + copyToArgKey)
+	}
+	if runOpts.IsExist(paramListArgKey) && !isRunToSet {
+		return errors.New("dbcopy invalid arguments: " + paramListArgKey + " can be used only with " + runToSetArgKey)
+	}
+	if isSync && copyToArg != "db2db" {
+		return errors.New("dbcopy invalid arguments: " + syncArgKey + " can be used only if " + copyToArgKey + "=db2db")
+	}
+	if isSync &&
+		(runOpts.IsExist(runNameArgKey) || runOpts.IsExist(runIdArgKey) || runOpts.IsExist(runDigestArgKey) || runOpts.IsExist(runFirstArgKey) || runOpts.IsExist(runLastArgKey) ||
+			runOpts.IsExist(setNameArgKey) || runOpts.IsExist(setIdArgKey) || runOpts.IsExist(taskNameArgKey) || runOpts.IsExist(taskIdArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + syncArgKey + " can be used only to copy entire model, it cannot be used with model run, workset or task arguments")
+	}
+	if isPurge &&
+		(isSync || isDel || isRename || isSweep || isSample || isTransform || isRunToSet || runOpts.IsExist(copyToArgKey) ||
+			runOpts.IsExist(runNameArgKey) || runOpts.IsExist(runIdArgKey) || runOpts.IsExist(runDigestArgKey) || runOpts.IsExist(runFirstArgKey) || runOpts.IsExist(runLastArgKey) ||
+			runOpts.IsExist(setNameArgKey) || runOpts.IsExist(setIdArgKey) || runOpts.IsExist(taskNameArgKey) || runOpts.IsExist(taskIdArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + purgeArgKey + " cannot be used with " + copyToArgKey + " or any other operation or model run, workset or task arguments")
+	}
+	if isVerify &&
+		(isPurge || isSync || isDel || isRename || isSweep || isSample || isTransform || isRunToSet || runOpts.IsExist(copyToArgKey) ||
+			runOpts.IsExist(runNameArgKey) || runOpts.IsExist(runIdArgKey) || runOpts.IsExist(runDigestArgKey) || runOpts.IsExist(runFirstArgKey) || runOpts.IsExist(runLastArgKey) ||
+			runOpts.IsExist(setNameArgKey) || runOpts.IsExist(setIdArgKey) || runOpts.IsExist(taskNameArgKey) || runOpts.IsExist(taskIdArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + verifyArgKey + " cannot be used with " + copyToArgKey + " or any other operation or model run, workset or task arguments")
+	}
+	if isPurge && !runOpts.IsExist(keepLastArgKey) {
+		return errors.New("dbcopy invalid arguments: " + purgeArgKey + " must be used with " + keepLastArgKey)
+	}
+	if !isPurge && (runOpts.IsExist(keepLastArgKey) || runOpts.IsExist(dryRunArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + keepLastArgKey + " or " + dryRunArgKey + " can be used only with " + purgeArgKey)
+	}
+	// selection of model runs, worksets and tasks can be used only to copy entire model from database
+	if isCopyFilterOptions(runOpts) {
+
+		if copyToArg != "text" && copyToArg != "csv" && copyToArg != "csv-all" && copyToArg != "db2db" ||
+			isSync || isPurge || isVerify || isDel || isRename || isSweep || isSample || isTransform || isRunToSet {
+			return errors.New("dbcopy invalid arguments: " + selectArgKey + ", " + fromDateArgKey + ", " + toDateArgKey + ", " + runStatusArgKey + ", " +
+				nameMatchArgKey + ", " + nameRegexArgKey + ", " + lastRunsArgKey + " can be used only if " + copyToArgKey + "=text or =csv or =csv-all or =db2db")
+		}
+		if runOpts.IsExist(runNameArgKey) || runOpts.IsExist(runIdArgKey) || runOpts.IsExist(runDigestArgKey) || runOpts.IsExist(runFirstArgKey) || runOpts.IsExist(runLastArgKey) ||
+			runOpts.IsExist(setNameArgKey) || runOpts.IsExist(setIdArgKey) || runOpts.IsExist(taskNameArgKey) || runOpts.IsExist(taskIdArgKey) {
+			return errors.New("dbcopy invalid arguments: selection of model runs, worksets or tasks cannot be used with model run, workset or task arguments")
+		}
+	}
+	if theCfg.filter, err = parseCopyFilter(runOpts); err != nil {
+		return err
+	}
+	if theCfg.filter.isActive {
+		omppLog.Log("Select:", theCfg.filter.String())
+	}
+	// model run mapping by name can be used only to copy model run into database
+	if theCfg.isMapByName &&
+		(copyToArg != "db" && copyToArg != "db2db" ||
+			isSync || isPurge || isVerify || isDel || isRename || isSweep || isSample || isTransform || isRunToSet ||
+			!runOpts.IsExist(runNameArgKey) && !runOpts.IsExist(runIdArgKey) && !runOpts.IsExist(runDigestArgKey) &&
+				!runOpts.IsExist(runFirstArgKey) && !runOpts.IsExist(runLastArgKey)) {
+		return errors.New("dbcopy invalid arguments: " + mapByNameArgKey + " can be used only to copy model run if " + copyToArgKey + "=db or =db2db, with any of: " +
+			runNameArgKey + ", " + runIdArgKey + ", " + runDigestArgKey + ", " + runFirstArgKey + ", " + runLastArgKey)
+	}
+	if runOpts.IsExist(toModelDigestArgKey) && (!theCfg.isMapByName || copyToArg != "db2db") {
+		return errors.New("dbcopy invalid arguments: " + toModelDigestArgKey + " can be used only with " + mapByNameArgKey + " and if " + copyToArgKey + "=db2db")
+	}
+	if theCfg.isMapFillDef && !theCfg.isMapByName {
+		return errors.New("dbcopy invalid arguments: " + mapFillDefArgKey + " can be used only with " + mapByNameArgKey)
+	}
 	// to-database can be used only with "db" or "db2db"
 	if copyToArg != "db" && copyToArg != "db2db" &&
 		(runOpts.IsExist(toDbConnStrArgKey) || runOpts.IsExist(toDbDriverArgKey) || runOpts.IsExist(toSqliteArgKey)) {
@@ -506,6 +869,30 @@ func mainBody(args []string) error {
 	//
 	switch {
 
+	// create parameter sweep worksets and modeling task
+	case isSweep:
+		err = dbSweepTask(modelName, modelDigest, runOpts)
+
+	// create worksets from samples of uncertain parameters and modeling task
+	case isSample:
+		err = dbSampleTask(modelName, modelDigest, runOpts)
+
+	// apply arithmetic operation to workset parameter values
+	case isTransform:
+		err = dbTransformWorkset(modelName, modelDigest, runOpts)
+
+	// create new workset from model run parameters
+	case isRunToSet:
+		err = dbRunToWorkset(modelName, modelDigest, runOpts)
+
+	// delete old model runs by retention policy
+	case isPurge:
+		err = dbPurgeRuns(modelName, modelDigest, runOpts)
+
+	// verify integrity of model database or model text files
+	case isVerify:
+		err = dbVerify(modelName, modelDigest, runOpts)
+
 	// do delete
 	case isDel:
 
@@ -548,7 +935,11 @@ func mainBody(args []string) error {
 		case "db":
 			err = textToDbRun(modelName, modelDigest, runOpts)
 		case "db2db":
-			err = dbToDbRun(modelName, modelDigest, runOpts)
+			if theCfg.isMapByName {
+				err = dbToDbRunMap(modelName, modelDigest, runOpts)
+			} else {
+				err = dbToDbRun(modelName, modelDigest, runOpts)
+			}
 		default:
 			return errors.New("dbcopy invalid argument for copy-to: " + copyToArg)
 		}
@@ -593,7 +984,11 @@ func mainBody(args []string) error {
 		case "db":
 			err = textToDb(modelName, runOpts)
 		case "db2db":
-			err = dbToDb(modelName, modelDigest, runOpts)
+			if isSync {
+				err = dbSyncDbToDb(modelName, modelDigest, runOpts)
+			} else {
+				err = dbToDb(modelName, modelDigest, runOpts)
+			}
 		default:
 			return errors.New("dbcopy invalid argument for copy-to: " + copyToArg)
 		}
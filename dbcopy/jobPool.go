@@ -0,0 +1,118 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/openmpp/go/ompp/db"
+)
+
+// jobPool run jobs concurrently: read parameters, output tables or microdata from database and write into csv files
+// or read csv files and write it into database.
+// Number of concurrent jobs is limited by number of threads, if it is 1 then each job is done sequentially by caller.
+type jobPool struct {
+	sem chan struct{}  // semaphore to limit number of running jobs
+	wg  sync.WaitGroup // wait for all jobs to be completed
+	mx  sync.Mutex     // lock to update first error
+	err error          // first error returned by any job
+}
+
+// create new pool of nThreads concurrent jobs, if nThreads <= 1 then jobs done sequentially.
+func newJobPool(nThreads int) *jobPool {
+	if nThreads < 1 {
+		nThreads = 1
+	}
+	return &jobPool{sem: make(chan struct{}, nThreads)}
+}
+
+// create new pool of concurrent jobs to write csv output files.
+// Output zip archive is a stream where only one file can be written at a time, it is always done sequentially.
+func newCsvOutPool() *jobPool {
+	if theZipOut != nil {
+		return newJobPool(1)
+	}
+	return newJobPool(theCfg.threads)
+}
+
+// create new pool of concurrent jobs to write into database.
+// SQLite database allow only one write transaction at a time, it is always done sequentially.
+func newDbInpPool(dbFacet db.Facet) *jobPool {
+	if dbFacet == db.SqliteFacet {
+		return newJobPool(1)
+	}
+	return newJobPool(theCfg.threads)
+}
+
+// Go wait until number of running jobs is less than number of threads and start the job.
+// Return first error of any previous job, caller should stop to start new jobs on error.
+// If pool has only one thread then job is done by caller and job error is returned.
+func (p *jobPool) Go(job func() error) error {
+
+	if err := p.firstError(); err != nil {
+		return err
+	}
+
+	if cap(p.sem) <= 1 {
+		err := job()
+		p.setError(err)
+		return err
+	}
+
+	p.sem <- struct{}{}
+	p.wg.Add(1)
+
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		p.setError(job())
+	}()
+
+	return p.firstError()
+}
+
+// Wait for all jobs to be completed and return first error of any job.
+func (p *jobPool) Wait() error {
+	p.wg.Wait()
+	return p.firstError()
+}
+
+// return first error of any job
+func (p *jobPool) firstError() error {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.err
+}
+
+// store job error if it is a first error
+func (p *jobPool) setError(err error) {
+	if err == nil {
+		return
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// return job to convert parameter, output table values or microdata and write into csv file.
+// Read layout is copied into the job and can be changed by caller to create next job.
+func toCellCsvJob(
+	dbConn *sql.DB,
+	modelDef *db.ModelMeta,
+	readLayout interface{},
+	csvCvt db.CsvConverter,
+	fileCreated map[string]bool,
+	csvDir string,
+	extraFirstName string,
+	extraFirstValue string) func() error {
+
+	return func() error {
+		return toCellCsvFile(dbConn, modelDef, readLayout, csvCvt, fileCreated, csvDir, extraFirstName, extraFirstValue)
+	}
+}
@@ -10,8 +10,6 @@ import (
 
 	"github.com/openmpp/go/ompp/config"
 	"github.com/openmpp/go/ompp/db"
-	"github.com/openmpp/go/ompp/helper"
-	"github.com/openmpp/go/ompp/omppLog"
 )
 
 // copy model from text json and csv files into database
@@ -42,20 +40,14 @@ func textToDb(modelName string, runOpts *config.RunOptions) error {
 	// use modelName as subdirectory inside of input and output directories or as name of model.zip file
 	inpDir := runOpts.String(inputDirArgKey)
 
-	if !runOpts.Bool(zipArgKey) {
-		inpDir = filepath.Join(inpDir, modelName) // json and csv files located in modelName subdir
-	} else {
-		omppLog.Log("Unpack ", modelName, ".zip")
-
-		outDir := runOpts.String(outputDirArgKey)
-		if outDir == "" {
-			outDir = filepath.Join(inpDir, modelName)
-		}
-		if err = helper.UnpackZip(filepath.Join(inpDir, modelName+".zip"), !theCfg.isKeepOutputDir, outDir); err != nil {
+	// if this is model.zip then read json and csv files directly from zip archive, without unpacking it
+	if runOpts.Bool(zipArgKey) {
+		if err = openZipInp(filepath.Join(inpDir, modelName+".zip")); err != nil {
 			return err
 		}
-		inpDir = filepath.Join(outDir, modelName)
+		defer closeZipInp()
 	}
+	inpDir = filepath.Join(inpDir, modelName) // json and csv files located in modelName subdir
 
 	// insert model metadata from json file into database
 	modelDef, err := fromModelJsonToDb(dstDb, dbFacet, inpDir, modelName)
@@ -91,7 +83,7 @@ func textToDb(modelName string, runOpts *config.RunOptions) error {
 func fromModelJsonToDb(dbConn *sql.DB, dbFacet db.Facet, inpDir string, modelName string) (*db.ModelMeta, error) {
 
 	// restore  model metadta from json
-	js, err := helper.FileToUtf8(filepath.Join(inpDir, modelName+".model.json"), "")
+	js, err := utf8Inp(filepath.Join(inpDir, modelName+".model.json"), "")
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +107,7 @@ func fromModelJsonToDb(dbConn *sql.DB, dbFacet db.Facet, inpDir string, modelNam
 
 	// insert, update or delete model default profile
 	var modelProfile db.ProfileMeta
-	isExist, err = helper.FromJsonFile(filepath.Join(inpDir, modelName+".profile.json"), &modelProfile)
+	isExist, err = fromJsonInp(filepath.Join(inpDir, modelName+".profile.json"), &modelProfile)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +124,7 @@ func fromModelJsonToDb(dbConn *sql.DB, dbFacet db.Facet, inpDir string, modelNam
 func fromLangTextJsonToDb(dbConn *sql.DB, modelDef *db.ModelMeta, inpDir string) (*db.LangMeta, error) {
 
 	// restore language list from json and if exist then update db tables
-	js, err := helper.FileToUtf8(filepath.Join(inpDir, modelDef.Model.Name+".lang.json"), "")
+	js, err := utf8Inp(filepath.Join(inpDir, modelDef.Model.Name+".lang.json"), "")
 	if err != nil {
 		return nil, err
 	}
@@ -156,7 +148,7 @@ func fromLangTextJsonToDb(dbConn *sql.DB, modelDef *db.ModelMeta, inpDir string)
 
 	// restore text data from json and if exist then update db tables
 	var modelTxt db.ModelTxtMeta
-	isExist, err = helper.FromJsonFile(filepath.Join(inpDir, modelDef.Model.Name+".text.json"), &modelTxt)
+	isExist, err = fromJsonInp(filepath.Join(inpDir, modelDef.Model.Name+".text.json"), &modelTxt)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +160,7 @@ func fromLangTextJsonToDb(dbConn *sql.DB, modelDef *db.ModelMeta, inpDir string)
 
 	// restore model language-specific strings from json and if exist then update db table
 	var mwDef db.ModelWordMeta
-	isExist, err = helper.FromJsonFile(filepath.Join(inpDir, modelDef.Model.Name+".word.json"), &mwDef)
+	isExist, err = fromJsonInp(filepath.Join(inpDir, modelDef.Model.Name+".word.json"), &mwDef)
 	if err != nil {
 		return nil, err
 	}
@@ -8,6 +8,7 @@ import (
 	"encoding/csv"
 	"errors"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -43,7 +44,7 @@ func writeParamFromCsvFile(
 	}
 	ch := strings.Join(chs, ",")
 
-	f, err := os.Open(filepath.Join(csvDir, fn))
+	f, fn, err := openCsvInp(csvDir, fn)
 	if err != nil {
 		return errors.New("csv file open error: " + err.Error())
 	}
@@ -89,7 +90,7 @@ func writeTableFromCsvFiles(
 	}
 	ah := strings.Join(ahs, ",")
 
-	accFile, err := os.Open(filepath.Join(csvDir, aFn))
+	accFile, aFn, err := openCsvInp(csvDir, aFn)
 	if err != nil {
 		return errors.New("accumulators csv file open error: " + err.Error())
 	}
@@ -117,7 +118,7 @@ func writeTableFromCsvFiles(
 	}
 	eh := strings.Join(ehs, ",")
 
-	exprFile, err := os.Open(filepath.Join(csvDir, eFn))
+	exprFile, eFn, err := openCsvInp(csvDir, eFn)
 	if err != nil {
 		return errors.New("expressions csv file open error: " + err.Error())
 	}
@@ -167,7 +168,7 @@ func writeMicroFromCsvFile(
 	}
 	ch := strings.Join(chs, ",")
 
-	f, err := os.Open(filepath.Join(csvDir, fn))
+	f, fn, err := openCsvInp(csvDir, fn)
 	if err != nil {
 		return errors.New("csv file open error: " + err.Error())
 	}
@@ -187,13 +188,61 @@ func writeMicroFromCsvFile(
 	return nil
 }
 
+// find input csv file: fileName.csv or compressed fileName.csv.gz or fileName.csv.zst and return actual file name.
+// Return error which satisfy os.IsNotExist() if none of csv files exist.
+func findCsvInp(csvDir string, fileName string) (string, error) {
+
+	for _, ext := range []string{"", helper.GzipExt, helper.ZstdExt} {
+
+		fi, err := statInp(filepath.Join(csvDir, fileName+ext))
+		if err == nil && !fi.IsDir() {
+			return fileName + ext, nil
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", &fs.PathError{Op: "open", Path: filepath.Join(csvDir, fileName), Err: fs.ErrNotExist}
+}
+
+// open input csv file: fileName.csv or compressed fileName.csv.gz or fileName.csv.zst.
+// Return reader of decompressed csv content and actual file name.
+func openCsvInp(csvDir string, fileName string) (io.ReadCloser, string, error) {
+
+	fn, err := findCsvInp(csvDir, fileName)
+	if err != nil {
+		return nil, fileName, err
+	}
+	f, err := openInp(filepath.Join(csvDir, fn))
+	if err != nil {
+		return nil, fn, err
+	}
+	rd, err := helper.NewDecompressReader(f, fn)
+	if err != nil {
+		f.Close()
+		return nil, fn, err
+	}
+	return csvFileReader{ReadCloser: rd, f: f}, fn, nil
+}
+
+// decompressed csv file reader: Close() release decompressor and close source file
+type csvFileReader struct {
+	io.ReadCloser           // decompressed content reader
+	f             io.Closer // source csv file
+}
+
+func (r csvFileReader) Close() error {
+	r.ReadCloser.Close()
+	return r.f.Close()
+}
+
 // return closure to iterate over csv file rows
 func makeFromCsvReader(
-	fileName string, csvFile *os.File, csvHeader string, csvToCell func(row []string) (interface{}, error),
+	fileName string, csvFile io.Reader, csvHeader string, csvToCell func(row []string) (interface{}, error),
 ) (func() (interface{}, error), error) {
 
 	// create csv reader from utf-8 line
-	uRd, err := helper.Utf8Reader(csvFile, theCfg.encodingName)
+	uRd, err := helper.Utf8ReaderFrom(csvFile, theCfg.encodingName)
 	if err != nil {
 		return nil, errors.New("fail to create utf-8 converter: " + err.Error())
 	}
@@ -6,8 +6,8 @@ package main
 import (
 	"database/sql"
 	"errors"
-	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -50,19 +50,12 @@ func textToDbRun(modelName string, modelDigest string, runOpts *config.RunOption
 		inpDir = filepath.Join(runOpts.String(inputDirArgKey), modelName+".run."+helper.CleanFileName(runName))
 	}
 
-	// unzip if required and use unzipped directory as "root" input directory
+	// if required then read json and csv files directly from zip archive, without unpacking it
 	if runOpts.Bool(zipArgKey) {
-		base := filepath.Base(inpDir)
-		omppLog.Log("Unpack ", base, ".zip")
-
-		outDir := runOpts.String(outputDirArgKey)
-		if outDir == "" {
-			outDir = inpDir
-		}
-		if err := helper.UnpackZip(inpDir+".zip", !theCfg.isKeepOutputDir, outDir); err != nil {
+		if err := openZipInp(inpDir + ".zip"); err != nil {
 			return err
 		}
-		inpDir = filepath.Join(outDir, base)
+		defer closeZipInp()
 	}
 
 	// get model run metadata json path and csv directory by run id or run name or both
@@ -88,7 +81,7 @@ func textToDbRun(modelName string, modelDigest string, runOpts *config.RunOption
 		}
 
 		// find path to metadata json by pattern
-		fl, err := filepath.Glob(inpDir + "/" + mp)
+		fl, err := globInp(inpDir + "/" + mp)
 		if err != nil {
 			return err
 		}
@@ -105,7 +98,7 @@ func textToDbRun(modelName string, modelDigest string, runOpts *config.RunOption
 	if metaPath == "" {
 		return errors.New("no metadata json file found for model run: " + strconv.Itoa(runId) + " " + runName + " " + runDigest)
 	}
-	if _, err := os.Stat(metaPath); err != nil {
+	if _, err := statInp(metaPath); err != nil {
 		return errors.New("no metadata json file found for model run: " + strconv.Itoa(runId) + " " + runName + " " + runDigest)
 	}
 
@@ -138,8 +131,19 @@ func textToDbRun(modelName string, modelDigest string, runOpts *config.RunOption
 		return err
 	}
 
+	// if required then map model run from source model into destination model: match items by name and enum codes
+	var rm *db.RunMapping
+	if theCfg.isMapByName {
+		if rm, err = runMappingFromText(modelDef, inpDir, runOpts.String(inputDirArgKey)); err != nil {
+			return err
+		}
+		if err = mapFillDefault(dstDb, rm); err != nil {
+			return err
+		}
+	}
+
 	// read from metadata json and csv files and update target database
-	dstId, err := fromRunTextToDb(dstDb, dbFacet, modelDef, langDef, runName, metaPath)
+	dstId, err := fromRunTextToDb(dstDb, dbFacet, modelDef, langDef, runName, metaPath, rm)
 	if err != nil {
 		return err
 	}
@@ -150,6 +154,47 @@ func textToDbRun(modelName string, modelDigest string, runOpts *config.RunOption
 	return nil
 }
 
+// runMappingFromText return mapping of model run from source model into destination model.
+// Source model metadata is read from modelName.model.json file in model run directory or in input directory.
+// If source model .json file not exist then destination model is used as source and items matched by name only,
+// parameters, output tables and microdata enum codes are validated by destination model on .csv read.
+func runMappingFromText(modelDef *db.ModelMeta, inpDirs ...string) (*db.RunMapping, error) {
+
+	srcModel := modelDef
+
+	for _, d := range inpDirs {
+
+		p := filepath.Join(d, modelDef.Model.Name+".model.json")
+		if _, err := statInp(p); err != nil {
+			continue
+		}
+		js, err := utf8Inp(p, theCfg.encodingName)
+		if err != nil {
+			return nil, err
+		}
+		src := &db.ModelMeta{}
+
+		isExist, err := src.FromJson([]byte(js))
+		if err != nil {
+			return nil, err
+		}
+		if !isExist || src.Model.Name != modelDef.Model.Name {
+			return nil, errors.New("model name: " + modelDef.Model.Name + " not found in .json file: " + p)
+		}
+		srcModel = src
+		break
+	}
+	omppLog.Log("Map model run from ", srcModel.Model.Name, " ", srcModel.Model.Digest, " into ", modelDef.Model.Name, " ", modelDef.Model.Digest)
+
+	rm, err := db.NewRunMapping(srcModel, modelDef)
+	if err != nil {
+		return nil, err
+	}
+	logMapIssues(rm)
+
+	return rm, nil
+}
+
 // fromRunTextListToDb read all model runs (metadata, parameters, output tables)
 // from csv and json files, convert it to db cells and insert into database.
 // Double format is used for float model types digest calculation, if non-empty format supplied
@@ -163,7 +208,7 @@ func fromRunTextListToDb(
 ) error {
 
 	// get list of model run json files
-	fl, err := filepath.Glob(inpDir + "/" + modelDef.Model.Name + ".run.*.json")
+	fl, err := globInp(inpDir + "/" + modelDef.Model.Name + ".run.*.json")
 	if err != nil {
 		return err
 	}
@@ -177,7 +222,7 @@ func fromRunTextListToDb(
 	// update model run digest
 	for k := range fl {
 
-		_, err := fromRunTextToDb(dbConn, dbFacet, modelDef, langDef, "", fl[k])
+		_, err := fromRunTextToDb(dbConn, dbFacet, modelDef, langDef, "", fl[k], nil)
 		if err != nil {
 			return err
 		}
@@ -191,6 +236,8 @@ func fromRunTextListToDb(
 // convert it to db cells and insert into database,
 // and finally update model run digest.
 // Double format is used for float model types digest calculation, if non-empty format supplied
+// If run mapping is not nil then model run is mapped from source model into destination model:
+// parameters, output tables and entities matched by name, output tables and entities which are not compatible are skipped.
 // it return source run id (run id from metadata json file) and destination run id
 func fromRunTextToDb(
 	dbConn *sql.DB,
@@ -199,6 +246,7 @@ func fromRunTextToDb(
 	langDef *db.LangMeta,
 	srcName string,
 	metaPath string,
+	rm *db.RunMapping,
 ) (int, error) {
 
 	// if no metadata file then exit: nothing to do
@@ -209,7 +257,7 @@ func fromRunTextToDb(
 	// get model run metadata
 	// model name and set name must be specified as parameter or inside of metadata json
 	var pub db.RunPub
-	isExist, err := helper.FromJsonFile(metaPath, &pub)
+	isExist, err := fromJsonInp(metaPath, &pub)
 	if err != nil {
 		return 0, err
 	}
@@ -217,7 +265,7 @@ func fromRunTextToDb(
 		return 0, nil // no model run
 	}
 
-	// check if run subdir exist
+	// check if run subdir exist, source model name is used in subdirectory name
 	d, f := filepath.Split(metaPath)
 	c := strings.TrimSuffix(strings.TrimPrefix(f, pub.ModelName+"."), ".json")
 	pDir := filepath.Join(c, "parameters")
@@ -226,16 +274,16 @@ func fromRunTextToDb(
 	nMd := len(pub.Entity)
 
 	paramCsvDir := filepath.Join(d, pDir)
-	if _, err := os.Stat(paramCsvDir); err != nil {
+	if _, err := statInp(paramCsvDir); err != nil {
 		return 0, errors.New("csv parameters directory not found: " + pDir)
 	}
 	tableCsvDir := filepath.Join(d, tDir)
-	if _, err := os.Stat(tableCsvDir); err != nil {
+	if _, err := statInp(tableCsvDir); err != nil {
 		return 0, errors.New("csv output tables directory not found: " + tDir)
 	}
 	microCsvDir := filepath.Join(d, mDir)
 	if nMd > 0 {
-		if _, err := os.Stat(microCsvDir); err != nil {
+		if _, err := statInp(microCsvDir); err != nil {
 			return 0, errors.New("csv microdata directory not found: " + mDir)
 		}
 	}
@@ -249,6 +297,19 @@ func fromRunTextToDb(
 		pub.ModelDigest = "" // model digest validation disabled
 	}
 
+	// map source model run into destination model: skip output tables and entities which are not compatible
+	fLst := []string{}
+	if rm != nil {
+		p, fl, err := rm.RunPub(&pub)
+		if err != nil {
+			return 0, err
+		}
+		logMapFilled(fl)
+		pub = *p
+		fLst = fl
+		nMd = len(pub.Entity)
+	}
+
 	// destination: convert from "public" format into destination db rows
 	meta, err := pub.FromPublic(dbConn, modelDef)
 	if err != nil {
@@ -268,6 +329,23 @@ func fromRunTextToDb(
 
 	omppLog.Log("Model run from ", srcName, " into id: ", dstId)
 
+	// restore run parameters and output tables concurrently, each parameter or output table is written in separate transaction
+	// on error delete model run to rollback results of UpdateRun() call above and all completed parameters or tables
+	pool := newDbInpPool(dbFacet)
+
+	cleanupOnError := func(err error) (int, error) {
+
+		pool.Wait() // wait until all running jobs completed
+
+		omppLog.Log("Cleanup on error: delete model run ", srcName, " ", dstId)
+
+		e := db.DeleteRun(dbConn, dstId)
+		if e != nil {
+			omppLog.Log("Failed to delete model run: ", srcName, " id: ", dstId, ": ", e.Error())
+		}
+		return 0, err // return original error
+	}
+
 	// restore run parameters: all model parameters must be included in the run
 	nP := len(modelDef.Param)
 	omppLog.Log("  Parameters: ", nP)
@@ -294,18 +372,22 @@ func fromRunTextToDb(
 			IsIdCsv:   false,
 			DoubleFmt: theCfg.doubleFmt,
 		}
-
-		err = writeParamFromCsvFile(dbConn, modelDef, paramLt, paramCsvDir, cvtParam)
-		if err != nil {
-			omppLog.Log("Error at: ", paramLt.Name, ": ", err.Error())
-			omppLog.Log("Cleanup on error: delete model run ", srcName, " ", dstId)
-
-			// delete model run on error to rollback results of UpdateRun() call above
-			e := db.DeleteRun(dbConn, dstId)
+		isFill := slices.Contains(fLst, paramLt.Name) // parameter missing in source model run or not compatible
+
+		err = pool.Go(func() error {
+			var e error
+			if isFill {
+				e = writeParamFromWorkset(dbConn, modelDef, rm.FillSetId(), paramLt)
+			} else {
+				e = writeParamFromCsvFile(dbConn, modelDef, paramLt, paramCsvDir, cvtParam)
+			}
 			if e != nil {
-				omppLog.Log("Failed to delete model run: ", srcName, " id: ", dstId, ": ", e.Error())
+				omppLog.Log("Error at: ", paramLt.Name, ": ", e.Error())
 			}
-			return 0, err // return original error
+			return e
+		})
+		if err != nil {
+			return cleanupOnError(err)
 		}
 	}
 
@@ -347,22 +429,23 @@ func fromRunTextToDb(
 
 		logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nT, ": ", tblLt.Name)
 
-		err := writeTableFromCsvFiles(dbConn, modelDef, tblLt, tableCsvDir, cvtExpr, cvtAcc)
-		if err != nil {
-			if err != nil {
-				omppLog.Log("Error at: ", tblLt.Name, ": ", err.Error())
-				omppLog.Log("Cleanup on error: delete model run ", srcName, " ", dstId)
-
-				// delete model run on error to rollback results of UpdateRun() call above
-				e := db.DeleteRun(dbConn, dstId)
-				if e != nil {
-					omppLog.Log("Failed to delete model run: ", srcName, " id: ", dstId, ": ", e.Error())
-				}
-				return 0, err // return original error
+		err = pool.Go(func() error {
+			e := writeTableFromCsvFiles(dbConn, modelDef, tblLt, tableCsvDir, cvtExpr, cvtAcc)
+			if e != nil {
+				omppLog.Log("Error at: ", tblLt.Name, ": ", e.Error())
 			}
+			return e
+		})
+		if err != nil {
+			return cleanupOnError(err)
 		}
 	}
 
+	// wait until all parameters and output tables are written
+	if err = pool.Wait(); err != nil {
+		return cleanupOnError(err)
+	}
+
 	// update model run digest
 	if meta.Run.ValueDigest == "" {
 
@@ -398,17 +481,11 @@ func fromRunTextToDb(
 
 			logT = omppLog.LogIfTime(logT, logPeriod, "    ", j, " of ", nMd, ": ", microLt.Name)
 
+			// microdata written sequentially: each write updates model run entity rows
 			err := writeMicroFromCsvFile(dbConn, dbFacet, modelDef, meta, microLt, microCsvDir, cvtMicro)
 			if err != nil {
 				omppLog.Log("Error at: ", pub.Entity[j].Name, ": ", err.Error())
-				omppLog.Log("Cleanup on error: delete model run ", srcName, " ", dstId)
-
-				// delete model run on error to rollback results of UpdateRun() call above
-				e := db.DeleteRun(dbConn, dstId)
-				if e != nil {
-					omppLog.Log("Failed to delete model run: ", srcName, " id: ", dstId, ": ", e.Error())
-				}
-				return 0, err // return original error
+				return cleanupOnError(err)
 			}
 		}
 	}
@@ -6,7 +6,6 @@ package main
 import (
 	"database/sql"
 	"errors"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -61,7 +60,7 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 		}
 
 		// find path to metadata json by pattern
-		fl, err := filepath.Glob(inpDir + "/" + mp)
+		fl, err := globInp(inpDir + "/" + mp)
 		if err != nil {
 			return err
 		}
@@ -78,7 +77,7 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 	if metaPath == "" {
 		return errors.New("no metadata json file found for modeling task: " + strconv.Itoa(taskId) + " " + taskName)
 	}
-	if _, err := os.Stat(metaPath); err != nil {
+	if _, err := statInp(metaPath); err != nil {
 		return errors.New("no metadata json file found for modeling task: " + strconv.Itoa(taskId) + " " + taskName)
 	}
 
@@ -113,7 +112,7 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 
 	// read task metadata from json
 	var pub db.TaskPub
-	isExist, err := helper.FromJsonFile(metaPath, &pub)
+	isExist, err := fromJsonInp(metaPath, &pub)
 	if err != nil {
 		return err
 	}
@@ -162,7 +161,7 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 			var jsonPath, csvDir string
 
 			// find path to metadata json by pattern
-			fl, err := filepath.Glob(inpDir + "/" + mp)
+			fl, err := globInp(inpDir + "/" + mp)
 			if err != nil {
 				return err
 			}
@@ -183,7 +182,7 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 				csvDir = ""
 			} else {
 				csvDir = filepath.Join(d, c)
-				if _, err := os.Stat(csvDir); err != nil {
+				if _, err := statInp(csvDir); err != nil {
 					csvDir = ""
 				}
 			}
@@ -193,17 +192,17 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 				isRunNotFound = true // skip: no run metadata json file or csv directory
 				continue
 			}
-			if _, err := os.Stat(jsonPath); err != nil {
+			if _, err := statInp(jsonPath); err != nil {
 				isRunNotFound = true // skip: no run metadata json file
 				continue
 			}
-			if _, err := os.Stat(csvDir); err != nil {
+			if _, err := statInp(csvDir); err != nil {
 				isRunNotFound = true // skip: no run csv directory
 				continue
 			}
 
 			// read from metadata json and csv files and update target database
-			dstId, err := fromRunTextToDb(dstDb, dbFacet, modelDef, langDef, runName, jsonPath)
+			dstId, err := fromRunTextToDb(dstDb, dbFacet, modelDef, langDef, runName, jsonPath, nil)
 			if err != nil {
 				return err
 			}
@@ -233,7 +232,7 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 		var jsonPath, csvDir string
 
 		// find path to metadata json by pattern
-		fl, err := filepath.Glob(inpDir + "/" + mp)
+		fl, err := globInp(inpDir + "/" + mp)
 		if err != nil {
 			return err
 		}
@@ -255,14 +254,14 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 				csvDir = ""
 			} else {
 				csvDir = filepath.Join(d, c)
-				if _, err := os.Stat(csvDir); err != nil {
+				if _, err := statInp(csvDir); err != nil {
 					csvDir = ""
 				}
 			}
 
 		} else { // metadata json file not exist: search for csv directory by pattern
 
-			fl, err := filepath.Glob(inpDir + "/" + cp)
+			fl, err := globInp(inpDir + "/" + cp)
 			if err != nil {
 				return err
 			}
@@ -340,7 +339,7 @@ func textToDbTask(modelName string, modelDigest string, runOpts *config.RunOptio
 func fromTaskListJsonToDb(dbConn *sql.DB, modelDef *db.ModelMeta, langDef *db.LangMeta, inpDir string) error {
 
 	// get list of task json files
-	fl, err := filepath.Glob(inpDir + "/" + modelDef.Model.Name + ".task.*.json")
+	fl, err := globInp(inpDir + "/" + modelDef.Model.Name + ".task.*.json")
 	if err != nil {
 		return err
 	}
@@ -353,7 +352,7 @@ func fromTaskListJsonToDb(dbConn *sql.DB, modelDef *db.ModelMeta, langDef *db.La
 
 		// read task metadata from json
 		var pub db.TaskPub
-		isExist, err := helper.FromJsonFile(fl[k], &pub)
+		isExist, err := fromJsonInp(fl[k], &pub)
 		if err != nil {
 			return err
 		}
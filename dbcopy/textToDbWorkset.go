@@ -6,7 +6,6 @@ package main
 import (
 	"database/sql"
 	"errors"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -48,19 +47,12 @@ func textToDbWorkset(modelName string, modelDigest string, runOpts *config.RunOp
 		}
 	}
 
-	// unzip if required and use unzipped directory as "root" input diretory
+	// if required then read json and csv files directly from zip archive, without unpacking it
 	if runOpts.Bool(zipArgKey) {
-		base := filepath.Base(inpDir)
-		omppLog.Log("Unpack ", base, ".zip")
-
-		outDir := runOpts.String(outputDirArgKey)
-		if outDir == "" {
-			outDir = inpDir
-		}
-		if err := helper.UnpackZip(inpDir+".zip", !theCfg.isKeepOutputDir, outDir); err != nil {
+		if err := openZipInp(inpDir + ".zip"); err != nil {
 			return err
 		}
-		inpDir = filepath.Join(outDir, base)
+		defer closeZipInp()
 	}
 
 	// get workset metadata json path and csv directory by set id or set name or both
@@ -72,14 +64,14 @@ func textToDbWorkset(modelName string, modelDigest string, runOpts *config.RunOp
 		metaPath = filepath.Join(inpDir,
 			modelName+".set."+strconv.Itoa(setId)+"."+helper.CleanFileName(setName)+".json")
 
-		if _, err := os.Stat(metaPath); err != nil { // clear path to indicate metadata json file does not exist
+		if _, err := statInp(metaPath); err != nil { // clear path to indicate metadata json file does not exist
 			metaPath = ""
 		}
 
 		csvDir = filepath.Join(inpDir,
 			"set."+strconv.Itoa(setId)+"."+helper.CleanFileName(setName))
 
-		if _, err := os.Stat(csvDir); err != nil { // clear path to indicate csv directory does not exist
+		if _, err := statInp(csvDir); err != nil { // clear path to indicate csv directory does not exist
 			csvDir = ""
 		}
 
@@ -96,7 +88,7 @@ func textToDbWorkset(modelName string, modelDigest string, runOpts *config.RunOp
 		mp := modelName + "." + cp + ".json"
 
 		// find path to metadata json by pattern
-		fl, err := filepath.Glob(inpDir + "/" + mp)
+		fl, err := globInp(inpDir + "/" + mp)
 		if err != nil {
 			return err
 		}
@@ -118,14 +110,14 @@ func textToDbWorkset(modelName string, modelDigest string, runOpts *config.RunOp
 				csvDir = ""
 			} else {
 				csvDir = filepath.Join(d, c)
-				if _, err := os.Stat(csvDir); err != nil {
+				if _, err := statInp(csvDir); err != nil {
 					csvDir = ""
 				}
 			}
 
 		} else { // metadata json file not exist: search for csv directory by pattern
 
-			fl, err := filepath.Glob(inpDir + "/" + cp)
+			fl, err := globInp(inpDir + "/" + cp)
 			if err != nil {
 				return err
 			}
@@ -194,7 +186,7 @@ func fromWorksetTextListToDb(
 ) error {
 
 	// get list of workset json files
-	fl, err := filepath.Glob(inpDir + "/" + modelDef.Model.Name + ".set.*.json")
+	fl, err := globInp(inpDir + "/" + modelDef.Model.Name + ".set.*.json")
 	if err != nil {
 		return err
 	}
@@ -215,7 +207,7 @@ func fromWorksetTextListToDb(
 			csvDir = ""
 		} else {
 			csvDir = filepath.Join(d, csvDir)
-			if _, err := os.Stat(csvDir); err != nil {
+			if _, err := statInp(csvDir); err != nil {
 				csvDir = ""
 			}
 		}
@@ -260,7 +252,7 @@ func fromWorksetTextToDb(
 
 	if metaPath != "" { // read metadata json file
 
-		isExist, err := helper.FromJsonFile(metaPath, &pub)
+		isExist, err := fromJsonInp(metaPath, &pub)
 		if err != nil {
 			return 0, err
 		}
@@ -289,16 +281,20 @@ func fromWorksetTextToDb(
 	//   assume only one parameter sub-value in csv file
 	if metaPath == "" && csvDir != "" {
 
-		fl, err := filepath.Glob(csvDir + "/*.csv")
-		if err != nil {
-			return 0, err
+		fl := []string{}
+		for _, ext := range []string{".csv", ".csv" + helper.GzipExt, ".csv" + helper.ZstdExt} {
+			gl, err := globInp(csvDir + "/*" + ext)
+			if err != nil {
+				return 0, err
+			}
+			for j := range gl {
+				fl = append(fl, strings.TrimSuffix(filepath.Base(gl[j]), ext)) // remove .csv or .csv.gz or .csv.zst extension
+			}
 		}
 		pub.Param = make([]db.ParamRunSetPub, len(fl))
 
 		for j := range fl {
-			fn := filepath.Base(fl[j])
-			fn = fn[:len(fn)-4] // remove .csv extension
-			pub.Param[j].Name = fn
+			pub.Param[j].Name = fl[j]
 			pub.Param[j].SubCount = 1 // only one sub-value
 		}
 	}
@@ -331,7 +327,7 @@ func fromWorksetTextToDb(
 		return 0, err
 	}
 	if wsRow != nil {
-		err = db.UpdateWorksetReadonly(dbConn, wsRow.SetId, false) // make destination workset read-write
+		err = db.UpdateWorksetReadonly(dbConn, modelDef, wsRow.SetId, false, nil, "") // make destination workset read-write
 		if err != nil {
 			return 0, errors.New("failed to clear workset read-only status: " + strconv.Itoa(wsRow.SetId) + " " + wsRow.Name + " " + err.Error())
 		}
@@ -373,8 +369,12 @@ func fromWorksetTextToDb(
 		}
 	}
 
-	// update workset readonly status with actual value
-	err = db.UpdateWorksetReadonly(dbConn, dstId, isReadonly)
+	// update workset readonly status with actual value, read-only workset parameters must satisfy validation rules
+	rules, err := paramRules(dbConn, modelDef, isReadonly)
+	if err != nil {
+		return 0, err
+	}
+	err = db.UpdateWorksetReadonly(dbConn, modelDef, dstId, isReadonly, rules, "")
 	if err != nil {
 		return 0, err
 	}
@@ -411,7 +411,7 @@ func updateWorksetParamFromCsvFile(
 	}
 	ch := strings.Join(chs, ",")
 
-	f, err := os.Open(filepath.Join(csvDir, fn))
+	f, fn, err := openCsvInp(csvDir, fn)
 	if err != nil {
 		return errors.New("csv file open error: " + fn + ": " + err.Error())
 	}
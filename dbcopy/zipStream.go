@@ -0,0 +1,241 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// zip archive streams of json and csv files:
+// if output zip is not nil then files written directly into zip archive without creating output directory (-dbcopy.ZipStream),
+// if input zip is not nil then files read directly from zip archive without unpacking it into temporary directory.
+var (
+	theZipOut     *helper.ZipWriter // output zip archive stream
+	theZipOutPath string            // output zip archive path
+	theZipInp     *helper.ZipReader // input zip archive
+)
+
+// create new output directory or, if isZipStream is true, create outDir.zip archive stream instead of output directory.
+// Existing output directory is deleted unless keep output directory option specified.
+func makeOutDir(outDir string, isZipStream bool) error {
+
+	if isZipStream {
+		_, err := createZipOut(outDir)
+		return err
+	}
+
+	if !theCfg.isKeepOutputDir {
+		if ok := dirDeleteAndLog(outDir); !ok {
+			return errors.New("Error: unable to delete: " + outDir)
+		}
+	}
+	return os.MkdirAll(outDir, 0750)
+}
+
+// create outDir.zip archive and write all output files into that archive stream, return zip archive path.
+// Archive names are relative to the parent of outDir, it is the same layout as helper.PackZip(outDir).
+func createZipOut(outDir string) (string, error) {
+
+	outDir = filepath.Clean(outDir)
+	baseDir := filepath.Dir(outDir)
+	zipPath := outDir + ".zip"
+
+	if err := os.MkdirAll(baseDir, 0750); err != nil {
+		return "", err
+	}
+	omppLog.Log("Pack into ", zipPath)
+
+	zw, err := helper.CreateZipWriter(zipPath, baseDir)
+	if err != nil {
+		return "", err
+	}
+	theZipOut = zw
+	theZipOutPath = zipPath
+	return zipPath, nil
+}
+
+// close output zip archive stream, if it was created, or, if isZip is true, pack output directory into outDir.zip.
+// Output directory is not deleted after packing it into .zip archive.
+func closeOutDir(outDir string, isZip bool) error {
+
+	if theZipOut != nil {
+		return closeZipOut()
+	}
+	if !isZip {
+		return nil
+	}
+	zipPath, err := helper.PackZip(outDir, !theCfg.isKeepOutputDir, "")
+	if err != nil {
+		return err
+	}
+	omppLog.Log("Packed ", zipPath)
+	return nil
+}
+
+// close output zip archive stream, if it was created.
+func closeZipOut() error {
+	if theZipOut == nil {
+		return nil
+	}
+	err := theZipOut.Close()
+	theZipOut = nil
+
+	if err == nil {
+		omppLog.Log("Packed ", theZipOutPath)
+	}
+	return err
+}
+
+// open zip archive and read all input files from that archive.
+// Archive names are relative to zip archive directory, it is the same layout as helper.UnpackZip(zipPath) result.
+func openZipInp(zipPath string) error {
+
+	omppLog.Log("Read from ", zipPath)
+
+	zr, err := helper.OpenZipReader(zipPath, filepath.Dir(filepath.Clean(zipPath)))
+	if err != nil {
+		return err
+	}
+	theZipInp = zr
+	return nil
+}
+
+// close input zip archive, if it was opened.
+func closeZipInp() {
+	if theZipInp != nil {
+		theZipInp.Close()
+		theZipInp = nil
+	}
+}
+
+// create output directory or store directory in output zip archive.
+func mkdirOut(dirPath string) error {
+	if theZipOut != nil {
+		return theZipOut.MkdirAll(dirPath)
+	}
+	return os.MkdirAll(dirPath, 0750)
+}
+
+// create new output file or open existing file for append, it is a file on disk or a file in output zip archive.
+// Zip archive is a stream and only the last created file can be appended.
+func createOut(filePath string, isAppend bool) (io.WriteCloser, error) {
+
+	if theZipOut != nil {
+
+		var w io.Writer
+		var err error
+		if !isAppend {
+			w, err = theZipOut.Create(filePath)
+		} else {
+			w, err = theZipOut.Append(filePath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nopWriteCloser{w}, nil
+	}
+
+	flag := os.O_CREATE | os.O_TRUNC | os.O_WRONLY
+	if isAppend {
+		flag = os.O_APPEND | os.O_WRONLY
+	}
+	return os.OpenFile(filePath, flag, 0644)
+}
+
+// writer with no-op Close(), file inside of zip archive stream closed when next file created
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// convert source to json and write into output file or into output zip archive.
+func toJsonOut(jsonPath string, src interface{}) error {
+
+	if theZipOut == nil {
+		return helper.ToJsonFile(jsonPath, src)
+	}
+
+	w, err := theZipOut.Create(jsonPath)
+	if err != nil {
+		return errors.New("json file create error: " + err.Error())
+	}
+	return helper.ToJsonWriter(w, src)
+}
+
+// open input file from disk or from input zip archive.
+func openInp(filePath string) (io.ReadCloser, error) {
+	if theZipInp != nil {
+		return theZipInp.Open(filePath)
+	}
+	return os.Open(filePath)
+}
+
+// return input file or directory info from disk or from input zip archive.
+func statInp(filePath string) (fs.FileInfo, error) {
+	if theZipInp != nil {
+		return theZipInp.Stat(filePath)
+	}
+	return os.Stat(filePath)
+}
+
+// return input file paths matching the pattern from disk or from input zip archive.
+func globInp(pattern string) ([]string, error) {
+	if theZipInp != nil {
+		return theZipInp.Glob(pattern)
+	}
+	return filepath.Glob(pattern)
+}
+
+// read input json file from disk or from input zip archive and convert to destination pointer.
+// Return false if file not exist or empty.
+func fromJsonInp(jsonPath string, dst interface{}) (bool, error) {
+
+	if theZipInp == nil {
+		return helper.FromJsonFile(jsonPath, dst)
+	}
+
+	f, err := theZipInp.Open(jsonPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil // return: json file not exist
+		}
+		return false, errors.New("json file open error: " + err.Error())
+	}
+	defer f.Close()
+
+	return helper.FromJsonReader(f, dst)
+}
+
+// read input file content from disk or from input zip archive and convert it to UTF-8 string.
+func utf8Inp(filePath string, encodingName string) (string, error) {
+
+	if theZipInp == nil {
+		return helper.FileToUtf8(filePath, encodingName)
+	}
+
+	f, err := theZipInp.Open(filePath)
+	if err != nil {
+		return "", errors.New("file open error: " + err.Error())
+	}
+	defer f.Close()
+
+	rd, err := helper.Utf8ReaderFrom(f, encodingName)
+	if err != nil {
+		return "", errors.New("failed to create utf-8 reader " + encodingName + " : " + err.Error())
+	}
+
+	bt, err := io.ReadAll(rd)
+	if err != nil {
+		return "", errors.New("read to utf-8 error: " + err.Error())
+	}
+	return string(bt), nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -73,15 +74,27 @@ func toCsvOutput(csvPath string, columnNames []string, lineCvt rowConverter) err
 
 	// flush and return error, if any
 	wr.Flush()
-	return wr.Error()
+	if err = wr.Error(); err != nil {
+		return err
+	}
+
+	// close output file: write the rest of compressed data, if any
+	if isFile {
+		isFile = false
+		return f.Close()
+	}
+	return nil
 }
 
-// create csv or tsv output writer
-func createCsvWriter(csvPath string) (*os.File, *csv.Writer, error) {
+// create csv or tsv output writer.
+// If output is a file then return file writer, if compression required then Close() of file writer flush compressed data.
+// If output is console then return nil file writer.
+func createCsvWriter(csvPath string) (io.WriteCloser, *csv.Writer, error) {
 
 	// create csv file
 	isFile := csvPath != ""
 	var f *os.File
+	var cw io.WriteCloser
 	var err error
 	isClose := false
 
@@ -91,15 +104,22 @@ func createCsvWriter(csvPath string) (*os.File, *csv.Writer, error) {
 			return nil, nil, err
 		}
 		isClose = true
+
+		// if required then compress file content
+		if cw, err = helper.NewCompressWriter(f, helper.CompressExtOf(csvPath)); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
 	}
 	defer func() {
 		if isClose {
+			cw.Close()
 			f.Close()
 		}
 	}()
 
 	if isFile && theCfg.isWriteUtf8Bom { // if required then write utf-8 bom
-		if _, err = f.Write(helper.Utf8bom); err != nil {
+		if _, err = cw.Write(helper.Utf8bom); err != nil {
 			return nil, nil, err
 		}
 	}
@@ -107,7 +127,7 @@ func createCsvWriter(csvPath string) (*os.File, *csv.Writer, error) {
 	// create csv writes to file and/or to console
 	var csvWr *csv.Writer
 	if isFile {
-		csvWr = csv.NewWriter(f)
+		csvWr = csv.NewWriter(cw)
 	} else {
 		csvWr = csv.NewWriter(os.Stdout)
 		if runtime.GOOS == "windows" {
@@ -120,7 +140,24 @@ func createCsvWriter(csvPath string) (*os.File, *csv.Writer, error) {
 
 	isClose = false // return open file to upper level
 
-	return f, csvWr, nil
+	if !isFile {
+		return nil, csvWr, nil
+	}
+	return compressFile{WriteCloser: cw, f: f}, csvWr, nil
+}
+
+// output file with optional compression: Close() flush compressed data and close the file
+type compressFile struct {
+	io.WriteCloser          // compressor or file itself if no compression
+	f              *os.File // output file
+}
+
+func (cf compressFile) Close() error {
+	err := cf.WriteCloser.Close()
+	if e := cf.f.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
 }
 
 // if directory path not empty then create output directory if not already exists, remove existing directory if required
@@ -160,20 +197,24 @@ func dirDeleteAndLog(path string) bool {
 }
 
 // return file extension by output kind: .csv .tsv or .json
+// if compression required then it is .csv.gz .csv.zst or .tsv.gz .tsv.zst
 func extByKind() string {
 	switch theCfg.kind {
 	case asTsv:
-		return ".tsv"
+		return ".tsv" + theCfg.compressExt
 	case asJson:
 		return ".json"
 	}
-	return ".csv" // by default
+	return ".csv" + theCfg.compressExt // by default
 }
 
-// return kind of by file extension: .csv .tsv or .json,
+// return kind of by file extension: .csv .tsv or .json, compression extension .gz or .zst is ignored
 // if file path is empty or extension is unknown then return csv by default
 func kindByExt(path string) outputAs {
 	if path != "" {
+		if ce := helper.CompressExtOf(path); ce != "" {
+			path = path[:len(path)-len(ce)]
+		}
 		switch strings.ToLower(filepath.Ext(path)) {
 		case ".tsv":
 			return asTsv
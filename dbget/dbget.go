@@ -60,6 +60,16 @@ By default dbget write results into the file and user can redirect it to console
 It is convenient to use -pipe as a short form of: -dbget.ToConsole -OpenM.LogToConsole=false
 to produce output suitable for command pipes.
 
+Output .csv or .tsv files can be compressed by gzip or zstd, it is useful for large output tables and microdata:
+
+	dbget -m modelOne -do all-runs -dbget.CsvCompress gzip
+	dbget -m modelOne -r Default -table ageSexIncome -dbget.CsvCompress zstd
+	dbget -m modelOne -r Default -table ageSexIncome -dbget.File ageSexIncome.csv.gz
+
+Compressed output written into .csv.gz or .csv.zst files (.tsv.gz or .tsv.zst) without creating uncompressed copy.
+If output file name ends with .gz or .zst then it is compressed by gzip or zstd.
+Console output is never compressed.
+
 **Important:**
 By using -pipe you are suppressing any console error message output and therefore you must check dbget exit code
 or redirect log output to file by using -OpenM.LogToFile option.
@@ -121,6 +131,25 @@ Get list of the models from database:
 	  -dbget.Database "Database=modelName.sqlite; Timeout=86400; OpenMode=ReadWrite;"
 	  -dbget.DatabaseDriver SQLite
 
+Compare two versions of model metadata, for example, previous version of the model and new version of the model:
+
+	dbget -m modelOne -do model-diff -dbget.WithDigest 649f17f26d67c37b78dde94f79772445
+	dbget -m modelOne -do model-diff -dbget.WithSqlite new/modelOne.sqlite
+	dbget -m modelOne -do model-diff -dbget.WithSqlite new/modelOne.sqlite -json
+	dbget -m modelOne -do model-diff -dbget.WithSqlite new/modelOne.sqlite -lang fr-CA
+	dbget -m modelOne -do model-diff -dbget.WithSqlite new/modelOne.sqlite -dbget.NoLanguage
+
+	dbget -db old/modelOne.sqlite -m modelOne -do model-diff
+	  -dbget.WithDatabase "Database=new/modelOne.sqlite; Timeout=86400; OpenMode=ReadOnly;"
+	  -dbget.WithDriver SQLite
+
+Other model can be in the same database, specified by -dbget.WithDigest or -dbget.WithModel,
+or in other database, by default it is the model with the same name.
+Output contains added, removed and changed types and enum items, parameters and parameter dimensions,
+output tables dimensions, expressions and accumulators, entity attributes, groups and group members.
+Description and notes are compared in all languages, or only in the language specified by -lang,
+text is not compared if -dbget.NoLanguage option used.
+
 Get all model runs parameters and output table values:
 
 	dbget -m modelOne -do all-runs
@@ -178,6 +207,26 @@ Get output table values:
 
 	dbget -dbget.Sqlite modelOne.sqlite -dbget.Do table -dbget.Run Default -dbget.Table ageSexIncome
 
+Calculate output table values and compare model runs:
+
+	dbget -m modelOne -do table-calc -r Default -dbget.Table ageSexIncome -dbget.Calc "Expr0 * 100,Expr1 / Expr0"
+	dbget -m modelOne -do table-calc -r Default -dbget.WithRuns "Default-4" -dbget.Table ageSexIncome
+	dbget -m modelOne -do table-calc -r Default -dbget.Table ageSexIncome -dbget.Calc Expr0 -lang fr-CA
+	dbget -m modelOne -do table-calc -r Default -dbget.Table ageSexIncome -dbget.NoLanguage -json
+
+	dbget -m modelOne -do table-compare -r Default -dbget.WithRuns "Default-4" -dbget.Table ageSexIncome
+	dbget -m modelOne -do table-compare -r Default -dbget.WithRuns "Default-4" -dbget.Table ageSexIncome -dbget.Calc ratio
+	dbget -m modelOne -do table-compare
+	  -dbget.FirstRun
+	  -dbget.WithLastRun
+	  -dbget.Table ageSexIncome
+	  -dbget.Calc "Expr0[variant] - Expr0[base],Expr1[variant] / Expr1[base]"
+
+If calculation is not specified then table-calc output all table expressions for base run and variant runs.
+Calculation can be comma separated list of expressions or, to apply to each table expression, one of: diff, ratio, percent.
+If calculation is not specified then table-compare is using diff: Expr0[variant] - Expr0[base] for each table expression.
+Output can be .csv, .tsv or .json, json output contains enum codes and calculation names, it is not language-specific.
+
 Aggregate and compare microdata run values:
 
 	dbget -m modelOne -do microdata-aggregate
@@ -200,6 +249,40 @@ Aggregate and compare microdata run values:
 	  -dbget.GroupBy AgeGroup
 	  -dbget.Calc OM_AVG(Income[base]-Income[variant])
 
+Sensitivity analysis of modeling task run results:
+
+	dbget -m modelOne -do task-sensitivity -dbget.Task mySweepTask
+	dbget -m modelOne -do task-sensitivity -dbget.Task mySweepTask -dbget.TaskRun myTaskRun
+	dbget -m modelOne -do task-sensitivity -dbget.Task mySweepTask -dbget.Table ageSexIncome,salarySex
+	dbget -m modelOne -do task-sensitivity -dbget.Task mySweepTask -pipe
+
+	dbget -m modelOne -do task-tornado -dbget.Task mySweepTask
+	dbget -m modelOne -do task-tornado -dbget.Task mySweepTask -dbget.TaskRun myTaskRun -tsv
+
+If task run is not specified then last completed task run is used.
+Input parameters are parameters of task worksets which values are different in task model runs,
+parameter value of each model run is an average of all parameter cells
+and output expression value of each model run is an average of all expression cells.
+Output of task-sensitivity is Pearson correlation, simple regression slope, standardized regression coefficient (src)
+and R squared of multiple linear regression for each output table expression and each input parameter.
+Output of task-tornado is tornado chart data: output expression values at low and high parameter values
+when all other parameters are at reference value, for example, for one-at-a-time parameter sweep.
+Reference values are base run values, if all task worksets are based on the same model run, or most frequent parameter values.
+
+Compare output table values of all scenarios of modeling task run:
+
+	dbget -m modelOne -do task-compare -dbget.Task myTask -dbget.Table salarySex
+	dbget -m modelOne -do task-compare -dbget.Task myTask -dbget.TaskRun myTaskRun -dbget.Table salarySex -dbget.Calc expr1
+	dbget -m modelOne -do task-compare -dbget.Task myTask -dbget.Table salarySex -dbget.Calc "expr0[variant] - expr0[base]"
+	dbget -m modelOne -do task-compare -dbget.Task myTask -dbget.Table salarySex -dbget.Calc "expr0[variant] / expr0[base]" -r "Default"
+
+Output contains one row for each scenario and each output table cell: workset name, model run,
+values of input parameters which are different across scenarios, dimension items and calculated value.
+Parameter value is a value of scalar parameter, average of all cells of other numeric parameter or parameter value digest.
+If calculation not specified then first output table expression is used.
+Comparison of [variant] and [base] is done with baseline run specified by -dbget.Run,
+by default it is the base run of all task worksets or, if worksets have different base runs, first scenario run.
+
 Get model metadata from compatibility (Modgen) views:
 
 	dbget -m modelOne -do old-model
@@ -286,6 +369,7 @@ const (
 	noLangArgKey        = "dbget.NoLanguage"     // if true then do language-neutral output: enum codes and "C" formats
 	encodingArgKey      = "dbget.CodePage"       // code page for converting source files, e.g. windows-1252
 	useUtf8ArgKey       = "dbget.Utf8Bom"        // if true then write utf-8 BOM into output
+	compressArgKey      = "dbget.CsvCompress"    // compress output csv or tsv files: gzip or zstd
 	noteArgKey          = "dbget.Notes"          // if true then output notes into .md files
 	doubleFormatArgKey  = "dbget.DoubleFormat"   // convert to string format for float and double
 	noZeroArgKey        = "dbget.NoZeroCsv"      // if true then do not write zero values into output tables or microdata csv
@@ -313,6 +397,13 @@ const (
 	entityArgKey        = "dbget.Entity"         // microdata entity name
 	groupByArgKey       = "dbget.GroupBy"        // microdata group by attributes
 	calcArgKey          = "dbget.Calc"           // calculation(s) expressions to compare or aggregate
+	taskArgKey          = "dbget.Task"           // modeling task name
+	taskRunArgKey       = "dbget.TaskRun"        // modeling task run stamp or name
+	withModelArgKey     = "dbget.WithModel"      // other model name to compare model metadata
+	withDigestArgKey    = "dbget.WithDigest"     // other model digest to compare model metadata
+	withSqliteArgKey    = "dbget.WithSqlite"     // other model db SQLite path
+	withDbArgKey        = "dbget.WithDatabase"   // other model db connection string
+	withDriverArgKey    = "dbget.WithDriver"     // other model db driver name, ie: SQLite, odbc, sqlite3
 )
 
 // output format: csv by default, or tsv or json
@@ -340,6 +431,7 @@ var theCfg = struct {
 	isNoLang        bool     // if true then do language-neutral output: enum codes and "C" formats
 	encodingName    string   // "code page" to convert source file into utf-8, for example: windows-1252
 	isWriteUtf8Bom  bool     // if true then write utf-8 BOM into csv file
+	compressExt     string   // if not empty then compressed output file extension: .gz or .zst
 	isNote          bool     // if true then output notes into .md files
 }{
 	kind:           asCsv,   // by default output as as .csv
@@ -386,6 +478,7 @@ func mainBody(args []string) error {
 	_ = flag.Bool(noLangArgKey, theCfg.isNoLang, "if true then do language-neutral output: enum codes and 'C' formats")
 	_ = flag.String(encodingArgKey, theCfg.encodingName, "code page to convert source file into utf-8, e.g.: windows-1252")
 	_ = flag.Bool(useUtf8ArgKey, theCfg.isWriteUtf8Bom, "if true then write utf-8 BOM into output")
+	_ = flag.String(compressArgKey, "", "compress output csv or tsv files: gzip or zstd")
 	_ = flag.Bool(noteArgKey, theCfg.isNote, "if true then write notes into .md files")
 	_ = flag.String(doubleFormatArgKey, theCfg.doubleFmt, "convert to string format for float and double")
 	_ = flag.Bool(noZeroArgKey, false, "if true then do not write zero values into output tables .csv files")
@@ -413,6 +506,13 @@ func mainBody(args []string) error {
 	_ = flag.String(entityArgKey, "", "microdata entity name")
 	_ = flag.String(groupByArgKey, "", "list of microdata group by attributes")
 	_ = flag.String(calcArgKey, "", "list of calculation(s) expressions to compare or aggregate")
+	_ = flag.String(taskArgKey, "", "modeling task name")
+	_ = flag.String(taskRunArgKey, "", "modeling task run stamp or name")
+	_ = flag.String(withModelArgKey, "", "other model name to compare model metadata")
+	_ = flag.String(withDigestArgKey, "", "other model digest to compare model metadata")
+	_ = flag.String(withSqliteArgKey, "", "other model database SQLite file path")
+	_ = flag.String(withDbArgKey, "", "other model database connection string")
+	_ = flag.String(withDriverArgKey, db.SQLiteDbDriver, "other model database driver name: SQLite, odbc, sqlite3")
 
 	// pairs of full and short argument names to map short name to full name
 	var optFs = []config.FullShort{
@@ -454,6 +554,18 @@ func mainBody(args []string) error {
 	theCfg.isNote = runOpts.Bool(noteArgKey)
 	theCfg.doubleFmt = runOpts.String(doubleFormatArgKey)
 
+	// get output compression: by option or by output file name extension: .gz or .zst
+	if runOpts.IsExist(compressArgKey) {
+		if theCfg.compressExt, err = helper.CompressExt(runOpts.String(compressArgKey)); err != nil {
+			return errors.New("invalid arguments: " + compressArgKey + " " + err.Error())
+		}
+	} else {
+		theCfg.compressExt = helper.CompressExtOf(theCfg.fileName)
+	}
+	if theCfg.compressExt != "" && theCfg.fileName != "" && helper.CompressExtOf(theCfg.fileName) == "" {
+		theCfg.fileName = theCfg.fileName + theCfg.compressExt
+	}
+
 	// get output format: cv, tsv or json
 	if f := runOpts.String(asArgKey); f != "" {
 
@@ -493,9 +605,12 @@ func mainBody(args []string) error {
 		}
 	}
 
-	// output to json supported only for model metadata
+	// output to json supported only for model metadata and output table calculation
+	if theCfg.kind == asJson && theCfg.compressExt != "" {
+		return errors.New("invalid arguments: " + compressArgKey + " can be used only with csv or tsv output")
+	}
 	if theCfg.kind == asJson {
-		if theCfg.action != "model-list" && theCfg.action != "old-model" {
+		if theCfg.action != "model-list" && theCfg.action != "model-diff" && theCfg.action != "old-model" && theCfg.action != "table-calc" && theCfg.action != "table-compare" {
 			return errors.New("JSON output not allowed for: " + theCfg.action)
 		}
 	}
@@ -595,6 +710,8 @@ func mainBody(args []string) error {
 	switch theCfg.action {
 	case "model-list":
 		return modelList(srcDb)
+	case "model-diff":
+		return modelDiff(srcDb, modelId, runOpts)
 	case "run":
 		return runValue(srcDb, modelId, runOpts)
 	case "all-runs":
@@ -611,6 +728,16 @@ func mainBody(args []string) error {
 		return microdataAggregate(srcDb, modelId, false, runOpts)
 	case "microdata-compare":
 		return microdataAggregate(srcDb, modelId, true, runOpts)
+	case "table-calc":
+		return tableCalc(srcDb, modelId, false, runOpts)
+	case "table-compare":
+		return tableCalc(srcDb, modelId, true, runOpts)
+	case "task-sensitivity":
+		return taskSensitivity(srcDb, modelId, false, runOpts)
+	case "task-tornado":
+		return taskSensitivity(srcDb, modelId, true, runOpts)
+	case "task-compare":
+		return taskCompare(srcDb, modelId, runOpts)
 	case "old-model":
 		return modelOldMeta(srcDb, modelId)
 	case "old-run":
@@ -10,7 +10,10 @@ import (
 
 	"golang.org/x/text/language"
 
+	"github.com/openmpp/go/ompp/config"
 	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
 )
 
 // match user language to the list of model languages, if no match then return empty "" model language code
@@ -75,3 +78,125 @@ func findRun(srcDb *sql.DB, modelId int, rdsn string, runId int, isFirst, isLast
 	r, e := db.GetLastRun(srcDb, modelId)
 	return "last model run", r, e
 }
+
+// find base model run and list of variant model runs.
+// Base run is specified by run digest, stamp, name, run id or first or last run options.
+// Variant runs are specified by comma separated list of run digests, stamps, names or run id's or first or last run options.
+// If base run not specified then first of variant runs is used as base run.
+// All runs must be completed successfully.
+func findBaseVariantRuns(srcDb *sql.DB, modelId int, runOpts *config.RunOptions) (*db.RunRow, []*db.RunRow, error) {
+
+	// find base model run
+	msg, baseRun, err := findRun(srcDb, modelId, runOpts.String(runArgKey), runOpts.Int(runIdArgKey, 0), runOpts.Bool(runFirstArgKey), runOpts.Bool(runLastArgKey))
+	if err != nil {
+		return nil, nil, errors.New("Error at get base model run: " + msg + " " + err.Error())
+	}
+	if baseRun != nil {
+		if baseRun.Status != db.DoneRunStatus {
+			return nil, nil, errors.New("Error: base model run not completed successfully: " + msg)
+		}
+	} else {
+		if runOpts.String(runArgKey) != "" || runOpts.Int(runIdArgKey, 0) != 0 || runOpts.Bool(runFirstArgKey) || runOpts.Bool(runLastArgKey) {
+			return nil, nil, errors.New("Error: base model run not found")
+		}
+	}
+
+	// make list of variant model runs
+	varRunLst := []*db.RunRow{}
+
+	// check variant run search results and push to vrarints list
+	pushToVar := func(src string, m string, r *db.RunRow) error {
+
+		if src != "" && r == nil {
+			return errors.New("Error: model run not found: " + src)
+		}
+		if r.Status != db.DoneRunStatus {
+			return errors.New("Error: model run not completed successfully: " + m)
+		}
+		if baseRun == nil { // if base run not specified then use first run as base run
+			baseRun = r
+			return nil
+		}
+		// else: add to the list of variant runs
+		if r.RunDigest == baseRun.RunDigest {
+			omppLog.Log("Warning: skip this model run, it is the same as base run: ", src)
+			return nil
+
+		}
+
+		// check if variant not already exist in the list of variants
+		isFound := false
+		for j := 0; !isFound && j < len(varRunLst); j++ {
+			isFound = varRunLst[j].RunDigest == r.RunDigest
+		}
+		if !isFound {
+			varRunLst = append(varRunLst, r)
+		}
+		return nil
+	}
+
+	// get variant runs from comma separarted list of digest, stamp or name
+	if rdsnLst := helper.ParseCsvLine(runOpts.String(withRunsArgKey), ','); len(rdsnLst) > 0 {
+
+		for _, rdsn := range rdsnLst {
+
+			m, r, e := findRun(srcDb, modelId, rdsn, 0, false, false)
+			if e != nil {
+				return nil, nil, errors.New("Error at get model run: " + m + " " + e.Error())
+			}
+			if e = pushToVar(rdsn, m, r); e != nil {
+				return nil, nil, e
+			}
+		}
+	}
+	// get variant runs from comma separarted list of run id's
+	if idLst := helper.ParseCsvLine(runOpts.String(withRunIdsArgKey), ','); len(idLst) > 0 {
+
+		for _, sId := range idLst {
+
+			if sId == "" {
+				continue
+			}
+			rId, e := strconv.Atoi(sId)
+			if e != nil || rId <= 0 {
+				return nil, nil, errors.New("Invalid model run id: " + sId)
+			}
+
+			m, r, e := findRun(srcDb, modelId, "", rId, false, false)
+			if e != nil {
+				return nil, nil, errors.New("Error at get model run: " + m + " " + e.Error())
+			}
+			if e = pushToVar(sId, m, r); e != nil {
+				return nil, nil, e
+			}
+		}
+	}
+	// check if first run must be used as variant run
+	if runOpts.Bool(withRunFirstArgKey) {
+
+		m, r, e := findRun(srcDb, modelId, "", 0, true, false)
+		if e != nil {
+			return nil, nil, errors.New("Error at get first model run: " + m + " " + e.Error())
+		}
+		if e = pushToVar(m, m, r); e != nil {
+			return nil, nil, e
+		}
+	}
+	// check if last run must be used as variant run
+	if runOpts.Bool(withRunLastArgKey) {
+
+		m, r, e := findRun(srcDb, modelId, "", 0, false, true)
+		if e != nil {
+			return nil, nil, errors.New("Error at get last model run: " + m + " " + e.Error())
+		}
+		if e = pushToVar(m, m, r); e != nil {
+			return nil, nil, e
+		}
+	}
+
+	// check: base model run must exist
+	if baseRun == nil {
+		return nil, nil, errors.New("Error: base model run not found")
+	}
+	return baseRun, varRunLst, nil
+}
@@ -18,117 +18,10 @@ import (
 // compare model runs microdata or aggregate microdata and write run results into csv or json files.
 func microdataAggregate(srcDb *sql.DB, modelId int, isCompare bool, runOpts *config.RunOptions) error {
 
-	// find base model run
-	msg, baseRun, err := findRun(srcDb, modelId, runOpts.String(runArgKey), runOpts.Int(runIdArgKey, 0), runOpts.Bool(runFirstArgKey), runOpts.Bool(runLastArgKey))
+	// find base model run and variant runs
+	baseRun, varRunLst, err := findBaseVariantRuns(srcDb, modelId, runOpts)
 	if err != nil {
-		return errors.New("Error at get base model run: " + msg + " " + err.Error())
-	}
-	if baseRun != nil {
-		if baseRun.Status != db.DoneRunStatus {
-			return errors.New("Error: base model run not completed successfully: " + msg)
-		}
-	} else {
-		if runOpts.String(runArgKey) != "" || runOpts.Int(runIdArgKey, 0) != 0 || runOpts.Bool(runFirstArgKey) || runOpts.Bool(runLastArgKey) {
-			return errors.New("Error: base model run not found")
-		}
-	}
-
-	// make list of variant model runs
-	varRunLst := []*db.RunRow{}
-
-	// check variant run search results and push to vrarints list
-	pushToVar := func(src string, m string, r *db.RunRow) error {
-
-		if src != "" && r == nil {
-			return errors.New("Error: model run not found: " + src)
-		}
-		if r.Status != db.DoneRunStatus {
-			return errors.New("Error: model run not completed successfully: " + m)
-		}
-		if baseRun == nil { // if base run not specified then use first run as base run
-			baseRun = r
-			return nil
-		}
-		// else: add to the list of variant runs
-		if r.RunDigest == baseRun.RunDigest {
-			omppLog.Log("Warning: skip this model run, it is the same as base run: ", src)
-			return nil
-
-		}
-
-		// check if variant not already exist in the list of variants
-		isFound := false
-		for j := 0; !isFound && j < len(varRunLst); j++ {
-			isFound = varRunLst[j].RunDigest == r.RunDigest
-		}
-		if !isFound {
-			varRunLst = append(varRunLst, r)
-		}
-		return nil
-	}
-
-	// get variant runs from comma separarted list of digest, stamp or name
-	if rdsnLst := helper.ParseCsvLine(runOpts.String(withRunsArgKey), ','); len(rdsnLst) > 0 {
-
-		for _, rdsn := range rdsnLst {
-
-			m, r, e := findRun(srcDb, modelId, rdsn, 0, false, false)
-			if e != nil {
-				return errors.New("Error at get model run: " + m + " " + e.Error())
-			}
-			if e = pushToVar(rdsn, m, r); e != nil {
-				return e
-			}
-		}
-	}
-	// get variant runs from comma separarted list of run id's
-	if idLst := helper.ParseCsvLine(runOpts.String(withRunIdsArgKey), ','); len(idLst) > 0 {
-
-		for _, sId := range idLst {
-
-			if sId == "" {
-				continue
-			}
-			rId, e := strconv.Atoi(sId)
-			if e != nil || rId <= 0 {
-				return errors.New("Invalid model run id: " + sId)
-			}
-
-			m, r, e := findRun(srcDb, modelId, "", rId, false, false)
-			if e != nil {
-				return errors.New("Error at get model run: " + m + " " + e.Error())
-			}
-			if e = pushToVar(sId, m, r); e != nil {
-				return e
-			}
-		}
-	}
-	// check if first run must be used as variant run
-	if runOpts.Bool(withRunFirstArgKey) {
-
-		m, r, e := findRun(srcDb, modelId, "", 0, true, false)
-		if e != nil {
-			return errors.New("Error at get first model run: " + m + " " + e.Error())
-		}
-		if e = pushToVar(m, m, r); e != nil {
-			return e
-		}
-	}
-	// check if last run must be used as variant run
-	if runOpts.Bool(withRunLastArgKey) {
-
-		m, r, e := findRun(srcDb, modelId, "", 0, false, true)
-		if e != nil {
-			return errors.New("Error at get last model run: " + m + " " + e.Error())
-		}
-		if e = pushToVar(m, m, r); e != nil {
-			return e
-		}
-	}
-
-	// check: base model run must exist
-	if baseRun == nil {
-		return errors.New("Error: base model run not found")
+		return err
 	}
 	if isCompare && len(varRunLst) <= 0 {
 		return errors.New("Error: at least one variant model run is required")
@@ -363,6 +256,16 @@ func microdataAggregate(srcDb *sql.DB, modelId int, isCompare bool, runOpts *con
 	}
 
 	csvWr.Flush() // flush csv to response
+	if err = csvWr.Error(); err != nil {
+		return errors.New("Error at microdata run calculation output: " + entityName + ": " + err.Error())
+	}
 
+	// close output file: write the rest of compressed data, if any
+	if isFile {
+		isFile = false
+		if err = f.Close(); err != nil {
+			return errors.New("Error at microdata run calculation output: " + entityName + ": " + err.Error())
+		}
+	}
 	return nil
 }
@@ -0,0 +1,135 @@
+// Copyright OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// write differences between two versions of model metadata into csv, tsv or json file.
+// Other model can be in the same database or in other database, by default it is a model with the same name.
+func modelDiff(srcDb *sql.DB, modelId int, runOpts *config.RunOptions) error {
+
+	// get model metadata
+	fromModel, err := db.GetModelById(srcDb, modelId)
+	if err != nil {
+		return err
+	}
+
+	// other model name and digest: by default use the same model name
+	withName := runOpts.String(withModelArgKey)
+	withDigest := runOpts.String(withDigestArgKey)
+	isWithDb := runOpts.IsExist(withSqliteArgKey) || runOpts.IsExist(withDbArgKey)
+
+	if withName == "" && withDigest == "" && !isWithDb {
+		return errors.New("invalid (empty) model name and model digest to compare with: " + withModelArgKey + " " + withDigestArgKey)
+	}
+	if withName == "" && withDigest == "" {
+		withName = fromModel.Model.Name
+	}
+
+	// open other model database, if required, by default it is the same database
+	withDb := srcDb
+
+	if isWithDb {
+		cs, dn := db.IfEmptyMakeDefaultReadOnly(withName, runOpts.String(withSqliteArgKey), runOpts.String(withDbArgKey), runOpts.String(withDriverArgKey))
+
+		wDb, _, err := db.Open(cs, dn, false)
+		if err != nil {
+			return err
+		}
+		defer wDb.Close()
+
+		if err := db.CheckOpenmppSchemaVersion(wDb); err != nil {
+			return err
+		}
+		withDb = wDb
+	}
+
+	// get other model metadata
+	toModel, err := db.GetModel(withDb, withName, withDigest)
+	if err != nil {
+		return err
+	}
+	if !isWithDb && fromModel.Model.ModelId == toModel.Model.ModelId {
+		return errors.New("model compared to itself: " + fromModel.Model.Name + " " + fromModel.Model.Digest)
+	}
+
+	// get description and notes: in all languages or in specified language, if language-neutral output then do not compare text
+	var fromTxt, toTxt *db.ModelTxtMeta
+
+	if !theCfg.isNoLang {
+
+		lc := ""
+		if runOpts.IsExist(langArgKey) {
+			lc = theCfg.lang
+		}
+		if fromTxt, err = db.GetModelText(srcDb, fromModel.Model.ModelId, lc, true); err != nil {
+			return err
+		}
+		if toTxt, err = db.GetModelText(withDb, toModel.Model.ModelId, lc, true); err != nil {
+			return err
+		}
+	}
+
+	md, err := db.CompareModel(fromModel, toModel, fromTxt, toTxt)
+	if err != nil {
+		return err
+	}
+
+	// use specified file name or make default
+	fp := ""
+
+	if theCfg.isConsole {
+		omppLog.Log("Do model-diff ", toModel.Model.Name, " ", toModel.Model.Digest)
+	} else {
+
+		fp = theCfg.fileName
+		if fp == "" {
+			fp = "model-diff" + extByKind()
+		}
+		fp = filepath.Join(theCfg.dir, fp)
+
+		omppLog.Log("Do model-diff ", toModel.Model.Name, " ", toModel.Model.Digest, ": "+fp)
+	}
+	omppLog.Log("Model differences: ", len(md.Item))
+
+	// write json output into file or console
+	if theCfg.kind == asJson {
+		return toJsonOutput(fp, md) // save results
+	}
+	// else write csv or tsv output into file or console
+
+	row := make([]string, 8)
+	idx := 0
+
+	err = toCsvOutput(
+		fp,
+		[]string{"kind", "change", "name", "item", "property", "lang_code", "from_value", "to_value"},
+		func() (bool, []string, error) {
+			if 0 <= idx && idx < len(md.Item) {
+				row[0] = md.Item[idx].Kind
+				row[1] = md.Item[idx].Change
+				row[2] = md.Item[idx].Name
+				row[3] = md.Item[idx].Item
+				row[4] = md.Item[idx].Prop
+				row[5] = md.Item[idx].LangCode
+				row[6] = md.Item[idx].From
+				row[7] = md.Item[idx].To
+				idx++
+				return false, row, nil
+			}
+			return true, row, nil // end of model differences
+		})
+	if err != nil {
+		return errors.New("failed to write model differences into csv " + err.Error())
+	}
+	return nil
+}
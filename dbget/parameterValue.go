@@ -179,6 +179,16 @@ func parameterRunValue(srcDb *sql.DB, meta *db.ModelMeta, name string, run *db.R
 	}
 
 	csvWr.Flush() // flush csv to response
+	if err = csvWr.Error(); err != nil {
+		return errors.New("Error at parameter output: " + name + ": " + err.Error())
+	}
 
+	// close output file: write the rest of compressed data, if any
+	if isFile {
+		isFile = false
+		if err = f.Close(); err != nil {
+			return errors.New("Error at parameter output: " + name + ": " + err.Error())
+		}
+	}
 	return nil
 }
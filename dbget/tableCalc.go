@@ -0,0 +1,322 @@
+// Copyright OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strconv"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// calculate output table expressions or compare model runs output table values and write results into csv, tsv or json file.
+func tableCalc(srcDb *sql.DB, modelId int, isCompare bool, runOpts *config.RunOptions) error {
+
+	// find base model run and variant runs
+	baseRun, varRunLst, err := findBaseVariantRuns(srcDb, modelId, runOpts)
+	if err != nil {
+		return err
+	}
+	if isCompare && len(varRunLst) <= 0 {
+		return errors.New("Error: at least one variant model run is required")
+	}
+
+	// get model metadata and find output table
+	name := runOpts.String(tableArgKey)
+	if name == "" {
+		return errors.New("Invalid (empty) output table name")
+	}
+	meta, err := db.GetModelById(srcDb, modelId)
+	if err != nil {
+		return errors.New("Error at get model metadata by id: " + strconv.Itoa(modelId) + ": " + err.Error())
+	}
+	idx, ok := meta.OutTableByName(name)
+	if !ok {
+		return errors.New("Error: model output table not found: " + name)
+	}
+
+	// make calculation expressions: output table expressions, comparison of expressions or list of calculations
+	calcLt, err := tableCalcLayout(&meta.Table[idx], runOpts.String(calcArgKey), isCompare)
+	if err != nil {
+		return errors.New("Error at output table calculation: " + name + ": " + err.Error())
+	}
+
+	// create cell conveter to csv
+	ctc := db.CellTableCalcConverter{
+		CellTableConverter: db.CellTableConverter{
+			ModelDef:    meta,
+			Name:        name,
+			IsIdCsv:     false, // use code, not id's
+			DoubleFmt:   theCfg.doubleFmt,
+			IsNoZeroCsv: runOpts.Bool(noZeroArgKey),
+			IsNoNullCsv: runOpts.Bool(noNullArgKey),
+		},
+		CalcMaps: db.EmptyCalcMaps(),
+	}
+	if e := ctc.SetCalcIdNameMap(calcLt); e != nil {
+		return errors.New("Failed to create output table converter to csv: " + name + ": " + e.Error())
+	}
+
+	// set run digests and run id's maps in the convereter
+	ctc.CalcMaps.IdToDigest[baseRun.RunId] = baseRun.RunDigest // add base run digest to converter
+	ctc.CalcMaps.DigestToId[baseRun.RunDigest] = baseRun.RunId
+
+	runIds := make([]int, len(varRunLst))
+	for k := 0; k < len(varRunLst); k++ {
+		ctc.CalcMaps.IdToDigest[varRunLst[k].RunId] = varRunLst[k].RunDigest // add run digest to converter
+		ctc.CalcMaps.DigestToId[varRunLst[k].RunDigest] = varRunLst[k].RunId
+		runIds[k] = varRunLst[k].RunId
+	}
+
+	// read all output table calculated values
+	tblLt := db.ReadTableLayout{
+		ReadLayout: db.ReadLayout{
+			Name:           name,
+			FromId:         baseRun.RunId,
+			ReadPageLayout: db.ReadPageLayout{Offset: 0, Size: 0},
+		},
+	}
+
+	// use specified file name or make default
+	fp := ""
+	if theCfg.isConsole {
+		omppLog.Log("Do ", theCfg.action, " ", name)
+	} else {
+
+		fp = theCfg.fileName
+		if fp == "" {
+			if isCompare {
+				fp = name + ".compare" + extByKind()
+			} else {
+				fp = name + ".calc" + extByKind()
+			}
+		}
+		fp = filepath.Join(theCfg.dir, fp)
+
+		omppLog.Log("Do ", theCfg.action, ": "+fp)
+	}
+
+	// json output: list of cells with enum codes, calculation names and run digests
+	if theCfg.kind == asJson {
+
+		cvtCell, err := ctc.IdToCodeCell(meta, name)
+		if err != nil {
+			return errors.New("Failed to create output table cell id's to code converter: " + name + ": " + err.Error())
+		}
+		cLst := []interface{}{}
+
+		cvtWr := func(c interface{}) (bool, error) {
+
+			cc, e := cvtCell(c)
+			if e != nil {
+				return false, e
+			}
+			cLst = append(cLst, cc)
+			return true, nil
+		}
+
+		_, err = db.ReadOutputTableCalculteTo(srcDb, meta, &tblLt, calcLt, runIds, cvtWr)
+		if err != nil {
+			return errors.New("Error at output table calculation output: " + name + ": " + err.Error())
+		}
+		return toJsonOutput(fp, cLst)
+	}
+
+	// make csv header
+	// create converter from db cell into csv row []string
+	hdr, err := ctc.CsvHeader()
+	if err != nil {
+		return errors.New("Failed to make output table csv header: " + name + ": " + err.Error())
+	}
+	var cvtRow func(interface{}, []string) (bool, error)
+
+	if theCfg.isNoLang {
+
+		cvtRow, err = ctc.ToCsvRow()
+		if err != nil {
+			return errors.New("Failed to create output table converter to csv: " + name + ": " + err.Error())
+		}
+
+	} else { // get language-specific metadata
+
+		langDef, err := db.GetLanguages(srcDb)
+		if err != nil {
+			return errors.New("Error at get language-specific metadata: " + err.Error())
+		}
+		txt, err := db.GetModelText(srcDb, meta.Model.ModelId, theCfg.lang, true)
+		if err != nil {
+			return errors.New("Error at get model text metadata: " + err.Error())
+		}
+
+		cvtLoc := &db.CellTableCalcLocaleConverter{
+			CellTableCalcConverter: ctc,
+			Lang:                   theCfg.lang,
+			LangDef:                langDef,
+			EnumTxt:                txt.TypeEnumTxt,
+			ExprTxt:                txt.TableExprTxt,
+		}
+
+		cvtRow, err = cvtLoc.ToCsvRow()
+		if err != nil {
+			return errors.New("Failed to create output table converter to csv: " + name + ": " + err.Error())
+		}
+	}
+
+	// start csv output to file or console
+	f, csvWr, err := createCsvWriter(fp)
+	if err != nil {
+		return err
+	}
+	isFile := f != nil
+
+	defer func() {
+		if isFile {
+			f.Close()
+		}
+	}()
+
+	// write csv header
+	if err := csvWr.Write(hdr); err != nil {
+		return errors.New("Error at csv write: " + name + ": " + err.Error())
+	}
+
+	// convert calculated cell into []string and write line into csv file
+	cs := make([]string, len(hdr))
+
+	cvtWr := func(c interface{}) (bool, error) {
+
+		// if converter return empty line then skip it
+		isNotEmpty := true
+		var e2 error = nil
+
+		if isNotEmpty, e2 = cvtRow(c, cs); e2 != nil {
+			return false, e2
+		}
+		if isNotEmpty {
+			if e2 = csvWr.Write(cs); e2 != nil {
+				return false, e2
+			}
+		}
+		return true, nil
+	}
+
+	_, err = db.ReadOutputTableCalculteTo(srcDb, meta, &tblLt, calcLt, runIds, cvtWr)
+	if err != nil {
+		return errors.New("Error at output table calculation output: " + name + ": " + err.Error())
+	}
+
+	csvWr.Flush() // flush csv to response
+	if err = csvWr.Error(); err != nil {
+		return errors.New("Error at output table calculation output: " + name + ": " + err.Error())
+	}
+
+	// close output file: write the rest of compressed data, if any
+	if isFile {
+		isFile = false
+		if err = f.Close(); err != nil {
+			return errors.New("Error at output table calculation output: " + name + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// return output table calculation layout.
+//
+// If calculation is empty then all output table expressions are used, for table-compare it is the same as "diff".
+// Comparison can be one of: diff, ratio or percent, it is applied to each output table expression,
+// for example: if table has Expr0 and Expr1 and comparison is diff then calculation is:
+// Expr0, Expr0[variant] - Expr0[base], Expr1, Expr1[variant] - Expr1[base].
+// Otherwise calculation is comma separated list of expressions, ex.: Expr0[variant] / Expr0[base], Expr1 * 100.
+func tableCalcLayout(table *db.TableMeta, calc string, isCompare bool) ([]db.CalculateTableLayout, error) {
+
+	if calc == "" && isCompare {
+		calc = "diff"
+	}
+
+	// check if it is comparison of all expressions
+	var fnc func(expr string) string
+	switch calc {
+	case "diff":
+		fnc = func(expr string) string {
+			return expr + "[variant] - " + expr + "[base]"
+		}
+	case "ratio":
+		fnc = func(expr string) string {
+			return expr + "[variant] / " + expr + "[base]"
+		}
+	case "percent":
+		fnc = func(expr string) string {
+			return "100 * (" + expr + "[variant] - " + expr + "[base]" + ") / " + expr + "[base]"
+		}
+	}
+
+	calcLt := []db.CalculateTableLayout{}
+
+	// comma separated list of calculations
+	// if calculation is the output table expression name then use expression id and name
+	if fnc == nil && calc != "" {
+
+		ce := helper.ParseCsvLine(calc, ',')
+		for j := range ce {
+
+			if ce[j] == "" {
+				continue
+			}
+			cId := j + db.CALCULATED_ID_OFFSET
+			cName := "ex_" + strconv.Itoa(cId)
+
+			for k := range table.Expr {
+				if table.Expr[k].Name == ce[j] {
+					cId = table.Expr[k].ExprId
+					cName = table.Expr[k].Name
+					break
+				}
+			}
+
+			calcLt = append(calcLt, db.CalculateTableLayout{
+				CalculateLayout: db.CalculateLayout{
+					Calculate: ce[j],
+					CalcId:    cId,
+					Name:      cName,
+				},
+				IsAggr: false,
+			})
+		}
+		if len(calcLt) <= 0 {
+			return []db.CalculateTableLayout{}, errors.New("invalid (empty) calculation expression")
+		}
+		return calcLt, nil
+	}
+
+	// all output table expressions and optional comparison of each expression
+	for _, ex := range table.Expr {
+
+		calcLt = append(calcLt, db.CalculateTableLayout{
+			CalculateLayout: db.CalculateLayout{
+				Calculate: ex.Name,
+				CalcId:    ex.ExprId,
+				Name:      ex.Name,
+			},
+			IsAggr: false,
+		})
+
+		if fnc != nil {
+			calcLt = append(calcLt, db.CalculateTableLayout{
+				CalculateLayout: db.CalculateLayout{
+					Calculate: fnc(ex.Name),
+					CalcId:    ex.ExprId + db.CALCULATED_ID_OFFSET,
+					Name:      calc + "_" + ex.Name,
+				},
+				IsAggr: false,
+			})
+		}
+	}
+
+	return calcLt, nil
+}
@@ -198,6 +198,16 @@ func tableRunValue(srcDb *sql.DB, meta *db.ModelMeta, name string, run *db.RunRo
 	}
 
 	csvWr.Flush() // flush csv to response
+	if err = csvWr.Error(); err != nil {
+		return errors.New("Error at output table output: " + name + ": " + err.Error())
+	}
 
+	// close output file: write the rest of compressed data, if any
+	if isFile {
+		isFile = false
+		if err = f.Close(); err != nil {
+			return errors.New("Error at output table output: " + name + ": " + err.Error())
+		}
+	}
 	return nil
 }
@@ -0,0 +1,113 @@
+// Copyright OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// write modeling task scenario matrix into csv or tsv file:
+// output table expression or calculation value of each scenario model run
+// together with values of input parameters which are different across scenarios.
+func taskCompare(srcDb *sql.DB, modelId int, runOpts *config.RunOptions) error {
+
+	// get modeling task name, output table name and optional task run stamp or name
+	taskName := runOpts.String(taskArgKey)
+	if taskName == "" {
+		return errors.New("Invalid (empty) modeling task name")
+	}
+	tableName := runOpts.String(tableArgKey)
+	if tableName == "" {
+		return errors.New("Invalid (empty) output table name")
+	}
+	trsn := runOpts.String(taskRunArgKey)
+
+	// get model metadata
+	meta, err := db.GetModelById(srcDb, modelId)
+	if err != nil {
+		return errors.New("Error at get model metadata by id: " + strconv.Itoa(modelId) + ": " + err.Error())
+	}
+
+	// make scenario matrix of task run results, optional baseline run is run digest, stamp or name
+	tc, err := db.GetTaskCompare(srcDb, meta, taskName, trsn, tableName, runOpts.String(calcArgKey), runOpts.String(runArgKey))
+	if err != nil {
+		return errors.New("Error at modeling task comparison: " + taskName + " " + trsn + ": " + err.Error())
+	}
+	omppLog.Log("Task run: ", tc.TaskRunName, " ", tc.TaskRunStamp, " scenarios: ", len(tc.Scenario), " parameters: ", len(tc.Param))
+	if tc.BaseRunDigest != "" {
+		omppLog.Log("Baseline run: ", tc.BaseRunDigest)
+	}
+
+	// use specified file name or make default
+	fp := ""
+
+	if theCfg.isConsole {
+		omppLog.Log("Do ", theCfg.action, " ", taskName)
+	} else {
+
+		fp = theCfg.fileName
+		if fp == "" {
+			fp = taskName + "." + tableName + ".compare" + extByKind()
+		}
+		fp = filepath.Join(theCfg.dir, fp)
+
+		omppLog.Log("Do ", theCfg.action, ": "+fp)
+	}
+
+	// csv header: scenario columns, parameters, dimensions and calculated value
+	nP := len(tc.Param)
+	nD := len(tc.Dim)
+
+	hdr := make([]string, 3+nP+nD+1)
+	hdr[0] = "set_name"
+	hdr[1] = "run_name"
+	hdr[2] = "run_digest"
+	copy(hdr[3:], tc.Param)
+	copy(hdr[3+nP:], tc.Dim)
+	hdr[len(hdr)-1] = "calc_value"
+
+	// write each output table cell for each scenario
+	row := make([]string, len(hdr))
+	nRow := 0
+	nSc := 0
+
+	return toCsvOutput(
+		fp,
+		hdr,
+		func() (bool, []string, error) {
+
+			if nSc >= len(tc.Scenario) { // move to the next cell
+				nSc = 0
+				nRow++
+			}
+			if nRow < 0 || nRow >= len(tc.Row) { // end of output rows
+				return true, row, nil
+			}
+			sc := tc.Scenario[nSc]
+			c := tc.Row[nRow]
+
+			row[0] = sc.SetName
+			row[1] = sc.RunName
+			row[2] = sc.RunDigest
+			copy(row[3:], sc.ParamValue)
+			copy(row[3+nP:], c.Dims)
+
+			if c.IsNull[nSc] {
+				row[len(row)-1] = "null"
+			} else {
+				row[len(row)-1] = fmt.Sprintf(theCfg.doubleFmt, c.Value[nSc])
+			}
+
+			nSc++
+			return false, row, nil
+		})
+}
@@ -0,0 +1,137 @@
+// Copyright OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/openmpp/go/ompp/config"
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// write modeling task sensitivity analysis into csv or tsv file:
+// sensitivity indices of output expressions versus varied input parameters or tornado chart data.
+func taskSensitivity(srcDb *sql.DB, modelId int, isTornado bool, runOpts *config.RunOptions) error {
+
+	// get modeling task name and optional task run stamp or name
+	taskName := runOpts.String(taskArgKey)
+	if taskName == "" {
+		return errors.New("Invalid (empty) modeling task name")
+	}
+	trsn := runOpts.String(taskRunArgKey)
+
+	// get model metadata
+	meta, err := db.GetModelById(srcDb, modelId)
+	if err != nil {
+		return errors.New("Error at get model metadata by id: " + strconv.Itoa(modelId) + ": " + err.Error())
+	}
+
+	// do sensitivity analysis of task run results
+	sp, err := db.GetTaskSensitivity(srcDb, meta, taskName, trsn, helper.ParseCsvLine(runOpts.String(tableArgKey), ','))
+	if err != nil {
+		return errors.New("Error at modeling task sensitivity analysis: " + taskName + " " + trsn + ": " + err.Error())
+	}
+	omppLog.Log("Task run: ", sp.TaskRunName, " ", sp.TaskRunStamp, " model runs: ", sp.RunCount, " parameters: ", len(sp.Param))
+
+	// use specified file name or make default
+	fp := ""
+
+	if theCfg.isConsole {
+		omppLog.Log("Do ", theCfg.action, " ", taskName)
+	} else {
+
+		fp = theCfg.fileName
+		if fp == "" {
+			if isTornado {
+				fp = taskName + ".tornado" + extByKind()
+			} else {
+				fp = taskName + ".sensitivity" + extByKind()
+			}
+		}
+		fp = filepath.Join(theCfg.dir, fp)
+
+		omppLog.Log("Do ", theCfg.action, ": "+fp)
+	}
+
+	fmtVal := func(v float64) string {
+		return fmt.Sprintf(theCfg.doubleFmt, v)
+	}
+
+	// write tornado chart data
+	if isTornado {
+
+		row := make([]string, 10)
+		idx := 0
+
+		return toCsvOutput(
+			fp,
+			[]string{"table_name", "expr_name", "parameter_name", "input_low", "input_high", "output_low", "output_high", "output_base", "swing", "rank"},
+			func() (bool, []string, error) {
+
+				if idx < 0 || idx >= len(sp.Tornado) { // end of tornado rows
+					return true, row, nil
+				}
+				t := sp.Tornado[idx]
+
+				// rank of parameter for that output expression: tornado rows are sorted by swing
+				rank := 1
+				for k := idx - 1; k >= 0 && sp.Tornado[k].TableName == t.TableName && sp.Tornado[k].ExprName == t.ExprName; k-- {
+					rank++
+				}
+
+				row[0] = t.TableName
+				row[1] = t.ExprName
+				row[2] = t.ParamName
+				row[3] = fmtVal(t.InputLow)
+				row[4] = fmtVal(t.InputHigh)
+				row[5] = fmtVal(t.OutputLow)
+				row[6] = fmtVal(t.OutputHigh)
+				row[7] = "null"
+				if t.IsBase {
+					row[7] = fmtVal(t.OutputBase)
+				}
+				row[8] = fmtVal(t.Swing)
+				row[9] = strconv.Itoa(rank)
+
+				idx++
+				return false, row, nil
+			})
+	}
+	// else write sensitivity indices
+
+	row := make([]string, 7)
+	idx := 0
+
+	return toCsvOutput(
+		fp,
+		[]string{"table_name", "expr_name", "parameter_name", "correlation", "slope", "src", "r_squared"},
+		func() (bool, []string, error) {
+
+			if idx < 0 || idx >= len(sp.Index) { // end of sensitivity indices
+				return true, row, nil
+			}
+			si := sp.Index[idx]
+
+			row[0] = si.TableName
+			row[1] = si.ExprName
+			row[2] = si.ParamName
+			row[3] = fmtVal(si.Correlation)
+			row[4] = fmtVal(si.Slope)
+			row[5] = "null"
+			row[6] = "null"
+			if si.IsSrc {
+				row[5] = fmtVal(si.Src)
+				row[6] = fmtVal(si.RSquared)
+			}
+
+			idx++
+			return false, row, nil
+		})
+}
@@ -7,6 +7,7 @@ require (
 	github.com/husobee/vestigo v1.1.1
 	github.com/jeandeaual/go-locale v0.0.0-20240223122105-ce5225dcaa49
 	github.com/keybase/go-ps v0.0.0-20190827175125-91aafc93ba19
+	github.com/klauspost/compress v1.17.11
 	github.com/mattn/go-sqlite3 v1.14.22
 	golang.org/x/text v0.14.0
 )
@@ -7,6 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
 // CellTableCalc is value of output table calculated expression.
@@ -30,6 +33,15 @@ type CellTableCalcConverter struct {
 	CalcMaps           // map between runs digest and id and calculations name and id
 }
 
+// Converter for output table calculated cell to implement CsvLocaleConverter interface.
+type CellTableCalcLocaleConverter struct {
+	CellTableCalcConverter
+	Lang    string            // language code, expected to compatible with BCP 47 language tag
+	LangDef *LangMeta         // language metadata to find translations
+	EnumTxt []TypeEnumTxtRow  // type enum text rows: type_enum_txt join to model_type_dic
+	ExprTxt []TableExprTxtRow // output table expression text rows: table_expr_txt join to model_table_dic
+}
+
 // Set calculation name to Id and Id to name maps
 func (cellCvt *CellTableCalcConverter) SetCalcIdNameMap(calcLt []CalculateTableLayout) error {
 
@@ -156,16 +168,25 @@ func (cellCvt *CellTableCalcConverter) ToCsvIdRow() (func(interface{}, []string)
 		}
 
 		// use "null" string for db NULL values and format for model float types
+		isNotEmpty := true
+
 		if cell.IsNull {
 			row[n+2] = "null"
+			isNotEmpty = !cellCvt.IsNoNullCsv
 		} else {
+
+			if cellCvt.IsNoZeroCsv {
+				fv, ok := cell.Value.(float64)
+				isNotEmpty = ok && fv != 0
+			}
+
 			if cellCvt.DoubleFmt != "" {
 				row[n+2] = fmt.Sprintf(cellCvt.DoubleFmt, cell.Value)
 			} else {
 				row[n+2] = fmt.Sprint(cell.Value)
 			}
 		}
-		return true, nil
+		return isNotEmpty, nil
 	}
 
 	return cvt, nil
@@ -229,16 +250,161 @@ func (cellCvt *CellTableCalcConverter) ToCsvRow() (func(interface{}, []string) (
 		}
 
 		// use "null" string for db NULL values and format for model float types
+		isNotEmpty := true
+
 		if cell.IsNull {
 			row[n+2] = "null"
+			isNotEmpty = !cellCvt.IsNoNullCsv
 		} else {
+
+			if cellCvt.IsNoZeroCsv {
+				fv, ok := cell.Value.(float64)
+				isNotEmpty = ok && fv != 0
+			}
+
 			if cellCvt.DoubleFmt != "" {
 				row[n+2] = fmt.Sprintf(cellCvt.DoubleFmt, cell.Value)
 			} else {
 				row[n+2] = fmt.Sprint(cell.Value)
 			}
 		}
-		return true, nil
+		return isNotEmpty, nil
+	}
+
+	return cvt, nil
+}
+
+// Return converter from output table calculated cell (run_id, calc_id, dimensions, calc_value)
+// to language-specific csv []string row of run digest, calculation label, enum labels and value.
+//
+// Converter return isNotEmpty flag, it return false if IsNoZero or IsNoNull is set and cell value is empty or zero.
+// Converter return error if len(row) not equal to number of fields in csv record.
+// If dimension type is enum based then csv row is enum label.
+// Value and dimesions of built-in types converted to locale-specific strings, e.g.: 1234.56 => 1 234,56
+func (cellCvt *CellTableCalcLocaleConverter) ToCsvRow() (func(interface{}, []string) (bool, error), error) {
+
+	// find output table by name
+	table, err := cellCvt.tableByName()
+	if err != nil {
+		return nil, err
+	}
+
+	// for each dimension create converter from item id to label
+	fd := make([]func(itemId int) (string, error), table.Rank)
+
+	for k := 0; k < table.Rank; k++ {
+		f, err := table.Dim[k].typeOf.itemIdToLabel(cellCvt.Lang, cellCvt.EnumTxt, cellCvt.LangDef, cellCvt.Name+"."+table.Dim[k].Name, table.Dim[k].IsTotal)
+		if err != nil {
+			return nil, err
+		}
+		fd[k] = f
+	}
+
+	cvtCalcId, err := cellCvt.calcIdToLabel() // converter from calculation id to language-specific label
+	if err != nil {
+		return nil, err
+	}
+
+	// format value locale-specific strings, e.g.: 1234.56 => 1 234,56
+	prt := message.NewPrinter(language.Make(cellCvt.Lang))
+
+	cvt := func(src interface{}, row []string) (bool, error) {
+
+		cell, ok := src.(CellTableCalc)
+		if !ok {
+			return false, errors.New("invalid type, expected: output table calculated cell (internal error): " + cellCvt.Name)
+		}
+
+		n := len(cell.DimIds)
+		if len(row) != n+3 {
+			return false, errors.New("invalid size of csv row buffer, expected: " + strconv.Itoa(n+3) + ": " + cellCvt.Name)
+		}
+
+		row[0] = cellCvt.IdToDigest[cell.RunId]
+		if row[0] == "" {
+			return false, errors.New("invalid (missing) run id: " + strconv.Itoa(cell.RunId) + " output table: " + cellCvt.Name)
+		}
+		row[1], err = cvtCalcId(cell.CalcId)
+		if err != nil {
+			return false, err
+		}
+
+		// convert dimension item id to label
+		for k, e := range cell.DimIds {
+			v, err := fd[k](e)
+			if err != nil {
+				return false, err
+			}
+			row[k+2] = v
+		}
+
+		// use "null" string for db NULL values and format for model float types
+		isNotEmpty := true
+
+		if cell.IsNull {
+			row[n+2] = "null"
+			isNotEmpty = !cellCvt.IsNoNullCsv
+		} else {
+
+			if cellCvt.IsNoZeroCsv {
+				fv, ok := cell.Value.(float64)
+				isNotEmpty = ok && fv != 0
+			}
+
+			if cellCvt.DoubleFmt != "" {
+				row[n+2] = prt.Sprintf(cellCvt.DoubleFmt, cell.Value)
+			} else {
+				row[n+2] = prt.Sprint(cell.Value)
+			}
+		}
+		return isNotEmpty, nil
+	}
+
+	return cvt, nil
+}
+
+// Return converter from calculation id to language-specific label.
+// If calculation is output table expression then converter return expression description by expression id and language.
+// If language code or description is empty or calculation is not an output table expression then converter return calculation name.
+func (cellCvt *CellTableCalcLocaleConverter) calcIdToLabel() (func(calcId int) (string, error), error) {
+
+	// find output table by name
+	table, err := cellCvt.tableByName()
+	if err != nil {
+		return nil, err
+	}
+	labelMap := make(map[int]string, len(cellCvt.CalcIdToName))
+
+	// add calculation name into map as default label
+	for cId, name := range cellCvt.CalcIdToName {
+		labelMap[cId] = name
+	}
+
+	// replace labels: if calculation is output table expression then use description where exists for specified language
+	if cellCvt.Lang != "" {
+		for j := range cellCvt.ExprTxt {
+
+			if cellCvt.ExprTxt[j].ModelId != table.ModelId || cellCvt.ExprTxt[j].TableId != table.TableId ||
+				cellCvt.ExprTxt[j].LangCode != cellCvt.Lang || cellCvt.ExprTxt[j].Descr == "" {
+				continue
+			}
+			eId := cellCvt.ExprTxt[j].ExprId
+
+			for k := range table.Expr {
+				if table.Expr[k].ExprId == eId && cellCvt.CalcIdToName[eId] == table.Expr[k].Name {
+					labelMap[eId] = cellCvt.ExprTxt[j].Descr
+					break
+				}
+			}
+		}
+	}
+
+	cvt := func(calcId int) (string, error) {
+
+		if lbl, ok := labelMap[calcId]; ok {
+			return lbl, nil
+		}
+		return "", errors.New("invalid (missing) calculation id: " + strconv.Itoa(calcId) + " output table: " + cellCvt.Name)
 	}
 
 	return cvt, nil
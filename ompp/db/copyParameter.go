@@ -7,9 +7,6 @@ import (
 	"database/sql"
 	"errors"
 	"strconv"
-	"time"
-
-	"github.com/openmpp/go/ompp/helper"
 )
 
 // CopyParameterFromRun copy parameter metadata and parameter values into workset from model run.
@@ -18,7 +15,13 @@ import (
 // If isReplace is false then delete existing metadata and new insert new from model run.
 // Destination workset must be in read-write state.
 // Source model run must be completed, run status one of: s=success, x=exit, e=error.
-func CopyParameterFromRun(dbConn *sql.DB, modelDef *ModelMeta, ws *WorksetRow, paramName string, isReplace bool, rs *RunRow) error {
+// If ifUpdateDt is not empty then it is conditional update:
+// workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version in the same transaction.
+// If validation rules not empty then parameter values must satisfy the rules, otherwise ParamRuleError returned.
+func CopyParameterFromRun(
+	dbConn *sql.DB, modelDef *ModelMeta, ws *WorksetRow, paramName string, isReplace bool, rs *RunRow, rules []ParamRule, ifUpdateDt string, versionNote string,
+) error {
 
 	// validate parameters
 	if modelDef == nil {
@@ -49,12 +52,23 @@ func CopyParameterFromRun(dbConn *sql.DB, modelDef *ModelMeta, ws *WorksetRow, p
 	if err != nil {
 		return err
 	}
+	if err = trxCheckWorksetUpdateDt(trx, ws.SetId, ifUpdateDt); err != nil {
+		trx.Rollback()
+		return err
+	}
+	if _, err = trxSaveWorksetVersion(trx, modelDef, ws.SetId, false, []string{paramName}, versionNote); err != nil {
+		trx.Rollback()
+		return err
+	}
 	if err = dbCopyParameterFromRun(trx, ws, &pm, isReplace, rs); err != nil {
 		trx.Rollback()
 		return err
 	}
-	trx.Commit()
-	return nil
+	if err = trxCheckWorksetParamRules(trx, modelDef, ws.SetId, rules, []string{paramName}); err != nil {
+		trx.Rollback()
+		return err
+	}
+	return trx.Commit()
 }
 
 // CopyParameterFromWorkset copy parameter metadata and parameter values from one workset to another.
@@ -62,7 +76,13 @@ func CopyParameterFromRun(dbConn *sql.DB, modelDef *ModelMeta, ws *WorksetRow, p
 // If isReplace is true and parameter already exist in destination workset then error returned.
 // If isReplace is false then delete existing metadata and new insert new from source workset.
 // Destination workset must be in read-write state, source workset must be read-only.
-func CopyParameterFromWorkset(dbConn *sql.DB, modelDef *ModelMeta, dstWs *WorksetRow, paramName string, isReplace bool, srcWs *WorksetRow) error {
+// If ifUpdateDt is not empty then it is conditional update:
+// destination workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version in the same transaction.
+// If validation rules not empty then parameter values must satisfy the rules, otherwise ParamRuleError returned.
+func CopyParameterFromWorkset(
+	dbConn *sql.DB, modelDef *ModelMeta, dstWs *WorksetRow, paramName string, isReplace bool, srcWs *WorksetRow, rules []ParamRule, ifUpdateDt string, versionNote string,
+) error {
 
 	// validate parameters
 	if modelDef == nil {
@@ -90,11 +110,98 @@ func CopyParameterFromWorkset(dbConn *sql.DB, modelDef *ModelMeta, dstWs *Workse
 	if err != nil {
 		return err
 	}
+	if err = trxCheckWorksetUpdateDt(trx, dstWs.SetId, ifUpdateDt); err != nil {
+		trx.Rollback()
+		return err
+	}
+	if _, err = trxSaveWorksetVersion(trx, modelDef, dstWs.SetId, false, []string{paramName}, versionNote); err != nil {
+		trx.Rollback()
+		return err
+	}
 	if err = dbCopyParameterFromWorkset(trx, dstWs, &pm, isReplace, srcWs); err != nil {
 		trx.Rollback()
 		return err
 	}
-	trx.Commit()
+	if err = trxCheckWorksetParamRules(trx, modelDef, dstWs.SetId, rules, []string{paramName}); err != nil {
+		trx.Rollback()
+		return err
+	}
+	return trx.Commit()
+}
+
+// CreateWorksetFromRun create new workset from model run parameters: parameter values, sub-values and value notes.
+//
+// If list of parameter names is empty then all model run parameters copied into new workset.
+// Otherwise only listed parameters copied and the rest is inherited from the base run.
+// In both cases model run is the base run of new workset.
+// New workset must not exist, it is created as read-write and set to read-only at the end if meta.Set.IsReadonly is true.
+// Read-only workset parameters values must satisfy meta.ParamRules validation rules, otherwise ParamRuleError returned.
+// Source model run must be completed, run status one of: s=success, x=exit, e=error.
+// Workset text (description and notes) is taken from meta.Txt, meta.Param must be empty.
+// On success meta.Set updated with new workset id.
+func CreateWorksetFromRun(dbConn *sql.DB, modelDef *ModelMeta, langDef *LangMeta, rs *RunRow, meta *WorksetMeta, paramNames []string) error {
+
+	// validate parameters
+	if modelDef == nil {
+		return errors.New("invalid (empty) model metadata")
+	}
+	if langDef == nil {
+		return errors.New("invalid (empty) language list")
+	}
+	if meta == nil || meta.Set.Name == "" {
+		return errors.New("invalid (empty) workset name")
+	}
+	if len(meta.Param) > 0 {
+		return errors.New("error: cannot create new workset from model run with non-empty list of parameters " + meta.Set.Name)
+	}
+	if rs == nil || rs.RunId <= 0 || rs.ModelId != modelDef.Model.ModelId {
+		return errors.New("invalid (empty) model run")
+	}
+	if !IsRunCompleted(rs.Status) {
+		return errors.New("error: model run is not completed: " + modelDef.Model.Name + ": " + rs.Name + ": " + rs.Status)
+	}
+
+	// make list of parameters to copy: all model parameters or only listed parameters
+	hLst := make([]int, 0, len(modelDef.Param))
+	if len(paramNames) <= 0 {
+		for k := range modelDef.Param {
+			hLst = append(hLst, modelDef.Param[k].ParamHid)
+		}
+	} else {
+		for _, name := range paramNames {
+			i, ok := modelDef.ParamByName(name)
+			if !ok {
+				return errors.New("model: " + modelDef.Model.Name + " parameter " + name + " not found")
+			}
+			for _, h := range hLst {
+				if h == modelDef.Param[i].ParamHid {
+					return errors.New("error: duplicate parameter name: " + name)
+				}
+			}
+			hLst = append(hLst, modelDef.Param[i].ParamHid)
+		}
+	}
+
+	// new workset is based on source model run
+	isReadonly := meta.Set.IsReadonly
+	meta.Set.ModelId = modelDef.Model.ModelId
+	meta.Set.BaseRunId = rs.RunId
+	meta.Set.IsReadonly = false
+	meta.Set.UpdateDateTime = ""
+
+	// create workset and copy parameters inside of transaction scope
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	if err = doCreateWorksetFromBase(trx, modelDef, langDef, meta, hLst, rs, nil, nil, isReadonly); err != nil {
+		trx.Rollback()
+		return err
+	}
+	if err = trx.Commit(); err != nil {
+		return err
+	}
+	meta.Set.IsReadonly = isReadonly
 	return nil
 }
 
@@ -202,10 +309,14 @@ func dbCopyParameterFromRun(trx *sql.Tx, ws *WorksetRow, pm *ParamMeta, isReplac
 	}
 
 	// "unlock" workset before commit: restore original value of is_readonly=0
+	dt, err := trxWorksetUpdateDt(trx, dstSetId)
+	if err != nil {
+		return err
+	}
 	err = TrxUpdate(trx,
 		"UPDATE workset_lst"+
 			" SET is_readonly = 0,"+
-			" update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
+			" update_dt = "+ToQuoted(dt)+
 			" WHERE set_id = "+sDstId)
 	if err != nil {
 		return err
@@ -389,10 +500,14 @@ func dbCopyParameterFromWorkset(trx *sql.Tx, dstWs *WorksetRow, pm *ParamMeta, i
 	}
 
 	// "unlock" destination workset before commit: restore original value of is_readonly=0
+	dt, err := trxWorksetUpdateDt(trx, dstSetId)
+	if err != nil {
+		return err
+	}
 	err = TrxUpdate(trx,
 		"UPDATE workset_lst"+
 			" SET is_readonly = 0,"+
-			" update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
+			" update_dt = "+ToQuoted(dt)+
 			" WHERE set_id = "+sDstId)
 
 	return err // return last error, if any
@@ -43,7 +43,7 @@ const (
 const MinSchemaVersion = 104
 
 // MaxSchemaVersion is a maximum compatible db schema version
-const MaxSchemaVersion = 104
+const MaxSchemaVersion = 105
 
 // Open database connection.
 //
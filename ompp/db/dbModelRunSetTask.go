@@ -216,9 +216,13 @@ type RunEntityRow struct {
 //
 // WorksetMeta is workset metadata db rows: workset_lst, workset_txt, workset_parameter, workset_parameter_txt
 type WorksetMeta struct {
-	Set   WorksetRow      // workset master row: workset_lst
-	Txt   []WorksetTxtRow // workset text rows: workset_txt
-	Param []worksetParam  // workset parameter: parameter_hid, sub-value count and workset_parameter_txt rows
+	Set              WorksetRow      // workset master row: workset_lst
+	Txt              []WorksetTxtRow // workset text rows: workset_txt
+	Param            []worksetParam  // workset parameter: parameter_hid, sub-value count and workset_parameter_txt rows
+	IfUpdateDateTime string          // if not empty then conditional update: workset must not be changed since that update date-time
+	VersionNote      string          // if not empty then save current parameters values as new workset version before update
+	VersionParam     []string        // parameters to save in workset version, on replace all workset parameters also saved
+	ParamRules       []ParamRule     // if not empty then parameters values must satisfy validation rules on update
 }
 
 // WorksetHdrPub is "public" workset metadata for json import-export
@@ -7,28 +7,39 @@ import (
 	"database/sql"
 	"errors"
 	"strconv"
-	"time"
-
-	"github.com/openmpp/go/ompp/helper"
 )
 
 // DeleteWorkset delete workset metadata and workset parameter values from database.
-func DeleteWorkset(dbConn *sql.DB, setId int) error {
+//
+// If ifUpdateDt is not empty then it is conditional delete:
+// workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+func DeleteWorkset(dbConn *sql.DB, setId int, ifUpdateDt string) error {
 
 	// validate parameters
 	if setId <= 0 {
 		return errors.New("invalid workset id: " + strconv.Itoa(setId))
 	}
+	isVer := isWorksetVersionExist(dbConn)
 
 	// delete inside of transaction scope
 	trx, err := dbConn.Begin()
 	if err != nil {
 		return err
 	}
+	if err := trxCheckWorksetUpdateDt(trx, setId, ifUpdateDt); err != nil {
+		trx.Rollback()
+		return err
+	}
 	if err := dbDeleteWorkset(trx, setId); err != nil {
 		trx.Rollback()
 		return err
 	}
+	if isVer {
+		if err := trxDeleteWorksetVersions(trx, setId); err != nil {
+			trx.Rollback()
+			return err
+		}
+	}
 	trx.Commit()
 	return nil
 }
@@ -163,10 +174,14 @@ func dbDeleteWorksetAllParameters(trx *sql.Tx, setId int) error {
 	}
 
 	// "unlock" workset before commit: restore original value of is_readonly=0
+	dt, err := trxWorksetUpdateDt(trx, setId)
+	if err != nil {
+		return err
+	}
 	err = TrxUpdate(trx,
 		"UPDATE workset_lst"+
 			" SET is_readonly = 0,"+
-			" update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
+			" update_dt = "+ToQuoted(dt)+
 			" WHERE set_id = "+sId)
 	if err != nil {
 		return err
@@ -179,11 +194,14 @@ func dbDeleteWorksetAllParameters(trx *sql.Tx, setId int) error {
 // If parameter not exist in workset then nothing deleted.
 // Workset must be read-write in order to delete parameter.
 // It is return parameter Hid = 0 if nothing deleted.
-func DeleteWorksetParameter(dbConn *sql.DB, modelId int, setName, paramName string) (int, error) {
+// If ifUpdateDt is not empty then it is conditional delete:
+// workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version in the same transaction.
+func DeleteWorksetParameter(dbConn *sql.DB, modelDef *ModelMeta, setName, paramName string, ifUpdateDt string, versionNote string) (int, error) {
 
 	// validate parameters
-	if modelId <= 0 {
-		return 0, errors.New("invalid model id: " + strconv.Itoa(modelId))
+	if modelDef == nil {
+		return 0, errors.New("invalid (empty) model metadata")
 	}
 	if setName == "" {
 		return 0, errors.New("invalid (empty) workset name")
@@ -197,24 +215,30 @@ func DeleteWorksetParameter(dbConn *sql.DB, modelId int, setName, paramName stri
 	if err != nil {
 		return 0, err
 	}
-	paramHid, err := dbDeleteWorksetParameter(trx, modelId, setName, paramName)
+	paramHid, err := dbDeleteWorksetParameter(trx, modelDef, setName, paramName, ifUpdateDt, versionNote)
 	if err != nil {
 		trx.Rollback()
 		return 0, err
 	}
-	trx.Commit()
+	if err = trx.Commit(); err != nil {
+		return 0, err
+	}
 	return paramHid, nil
 }
 
 // dbDeleteWorksetParameter delete workset parameter metadata and values from database.
 // It does update as part of transaction.
-func dbDeleteWorksetParameter(trx *sql.Tx, modelId int, setName, paramName string) (int, error) {
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time.
+// If versionNote is not empty then current parameter values saved as new workset version before delete.
+func dbDeleteWorksetParameter(trx *sql.Tx, modelDef *ModelMeta, setName, paramName string, ifUpdateDt string, versionNote string) (int, error) {
+
+	smId := strconv.Itoa(modelDef.Model.ModelId)
 
 	// "lock" workset to prevent update or use by the model
 	err := TrxUpdate(trx,
 		"UPDATE workset_lst"+
 			" SET is_readonly = is_readonly + 1"+
-			" WHERE model_id = "+strconv.Itoa(modelId)+" AND set_name = "+ToQuoted(setName))
+			" WHERE model_id = "+smId+" AND set_name = "+ToQuoted(setName))
 	if err != nil {
 		return 0, err
 	}
@@ -224,7 +248,7 @@ func dbDeleteWorksetParameter(trx *sql.Tx, modelId int, setName, paramName strin
 	nRd := 0
 	err = TrxSelectFirst(trx,
 		"SELECT set_id, is_readonly FROM workset_lst"+
-			" WHERE model_id = "+strconv.Itoa(modelId)+" AND set_name = "+ToQuoted(setName),
+			" WHERE model_id = "+smId+" AND set_name = "+ToQuoted(setName),
 		func(row *sql.Row) error {
 			if err := row.Scan(&setId, &nRd); err != nil {
 				return err
@@ -233,12 +257,18 @@ func dbDeleteWorksetParameter(trx *sql.Tx, modelId int, setName, paramName strin
 		})
 	switch {
 	case err == sql.ErrNoRows:
+		if ifUpdateDt != "" {
+			return 0, ErrWorksetChanged // workset deleted
+		}
 		return 0, nil // workset not found: nothing to do
 	case err != nil:
 		return 0, err
 	case nRd != 1:
 		return 0, errors.New("failed to update: workset is read-only: " + setName)
 	}
+	if err = trxCheckWorksetUpdateDt(trx, setId, ifUpdateDt); err != nil {
+		return 0, err
+	}
 	sId := strconv.Itoa(setId)
 
 	// build a list of workset parameters db-tables
@@ -269,6 +299,11 @@ func dbDeleteWorksetParameter(trx *sql.Tx, modelId int, setName, paramName strin
 	}
 	spHid := strconv.Itoa(paramHid)
 
+	// save current parameter values as new workset version
+	if _, err = trxSaveWorksetVersion(trx, modelDef, setId, false, []string{paramName}, versionNote); err != nil {
+		return 0, err
+	}
+
 	// delete workset parameter values
 	err = TrxUpdate(trx, "DELETE FROM "+tblName+" WHERE set_id = "+sId)
 	if err != nil {
@@ -287,10 +322,14 @@ func dbDeleteWorksetParameter(trx *sql.Tx, modelId int, setName, paramName strin
 	}
 
 	// "unlock" workset before commit: restore original value of is_readonly=0
+	dt, err := trxWorksetUpdateDt(trx, setId)
+	if err != nil {
+		return 0, err
+	}
 	err = TrxUpdate(trx,
 		"UPDATE workset_lst"+
 			" SET is_readonly = 0,"+
-			" update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
+			" update_dt = "+ToQuoted(dt)+
 			" WHERE set_id = "+strconv.Itoa(setId))
 	if err != nil {
 		return 0, err
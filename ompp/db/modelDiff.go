@@ -0,0 +1,643 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ModelDiff is a difference between two versions of model metadata,
+// for example, between previous version of the model and new version of the model.
+//
+// Types and enum items, parameters, output tables, entities and groups are matched by name,
+// dimensions, expressions, accumulators, entity attributes and group members are matched by name.
+// Model item can be added, removed or changed, for example, parameter type changed from int to double.
+// Description and notes are compared for each language, only for the items which exist in both models.
+type ModelDiff struct {
+	FromModel ModelDicRow     // model to compare from, for example: previous version of the model
+	ToModel   ModelDicRow     // model to compare to, for example: new version of the model
+	Item      []ModelDiffItem // list of model differences
+}
+
+// ModelDiffItem is a difference of model type, parameter, output table, entity or group between two versions of the model.
+type ModelDiffItem struct {
+	Kind     string // kind of model item, for example: type, enum, parameter, table-dim, entity-attr
+	Change   string // change: added, removed, changed
+	Name     string // model, type, parameter, output table, entity or group name
+	Item     string // enum code, dimension, expression, accumulator, attribute or group member name, if applicable
+	Prop     string // changed property, for example: type, rank, size, descr, note; empty if item added or removed
+	LangCode string // language code of description or notes change
+	From     string // property value in the model compared from
+	To       string // property value in the model compared to
+}
+
+// kind of model item of model difference
+const (
+	DiffModel       = "model"         // model
+	DiffType        = "type"          // model type
+	DiffEnum        = "enum"          // enum item of model type
+	DiffParam       = "parameter"     // parameter
+	DiffParamDim    = "parameter-dim" // parameter dimension
+	DiffTable       = "table"         // output table
+	DiffTableDim    = "table-dim"     // output table dimension
+	DiffTableExpr   = "table-expr"    // output table expression
+	DiffTableAcc    = "table-acc"     // output table accumulator
+	DiffEntity      = "entity"        // entity
+	DiffEntityAttr  = "entity-attr"   // entity attribute
+	DiffGroup       = "group"         // group of parameters or output tables
+	DiffGroupMember = "group-member"  // group member: parameter, output table or child group
+)
+
+// kind of change of model difference
+const (
+	DiffAdded   = "added"   // item exist only in the model compared to
+	DiffRemoved = "removed" // item exist only in the model compared from
+	DiffChanged = "changed" // item property is different
+)
+
+// String return model difference as text message, for example: table-dim changed: ageSexIncome: dim0: size: 4 => 5
+func (di *ModelDiffItem) String() string {
+
+	s := di.Kind + " " + di.Change + ":"
+	if di.Name != "" {
+		s += " " + di.Name
+	}
+	if di.Item != "" {
+		s += ": " + di.Item
+	}
+	if di.Prop != "" {
+		s += ": " + di.Prop
+		if di.LangCode != "" {
+			s += " [" + di.LangCode + "]"
+		}
+		s += ": " + di.From + " => " + di.To
+	}
+	return s
+}
+
+// CompareModel return differences between two versions of model metadata.
+// If fromTxt or toTxt is nil then description and notes are not compared.
+func CompareModel(fromModel, toModel *ModelMeta, fromTxt, toTxt *ModelTxtMeta) (*ModelDiff, error) {
+
+	if fromModel == nil || toModel == nil {
+		return nil, errors.New("invalid (empty) model metadata")
+	}
+	md := ModelDiff{
+		FromModel: fromModel.Model,
+		ToModel:   toModel.Model,
+		Item:      []ModelDiffItem{},
+	}
+
+	// model
+	md.addProp(DiffModel, fromModel.Model.Name, "", "digest", fromModel.Model.Digest, toModel.Model.Digest)
+	md.addProp(DiffModel, fromModel.Model.Name, "", "version", fromModel.Model.Version, toModel.Model.Version)
+
+	md.compareTypes(fromModel, toModel)
+	md.compareParams(fromModel, toModel)
+	md.compareTables(fromModel, toModel)
+	md.compareEntities(fromModel, toModel)
+	md.compareGroups(fromModel, toModel)
+
+	if fromTxt != nil && toTxt != nil {
+		md.compareText(fromModel, toModel, fromTxt, toTxt)
+	}
+	return &md, nil
+}
+
+// append added or removed item
+func (md *ModelDiff) add(kind, change, name, item string) {
+	md.Item = append(md.Item, ModelDiffItem{Kind: kind, Change: change, Name: name, Item: item})
+}
+
+// append property change if values are different
+func (md *ModelDiff) addProp(kind, name, item, prop, from, to string) {
+	if from != to {
+		md.Item = append(md.Item, ModelDiffItem{Kind: kind, Change: DiffChanged, Name: name, Item: item, Prop: prop, From: from, To: to})
+	}
+}
+
+// compare lists of item names, for example: dimension names, and append removed and added items.
+// If onMatch not nil then it is called with source and destination index of each item which exist in both lists.
+func (md *ModelDiff) compareNames(kind, name string, fromNames, toNames []string, onMatch func(fromIdx, toIdx int)) {
+	idx, _, added := matchNames(fromNames, toNames)
+	md.compareItems(kind, name, idx, fromNames, added, onMatch)
+}
+
+// append removed and added items, call onMatch for each item which exist in both models:
+// idx is index of matched item in the model compared to for each item of the model compared from or -1 if item removed.
+func (md *ModelDiff) compareItems(kind, name string, idx []int, fromNames, added []string, onMatch func(fromIdx, toIdx int)) {
+
+	for j := range idx {
+		if idx[j] < 0 {
+			md.add(kind, DiffRemoved, name, fromNames[j])
+			continue
+		}
+		if onMatch != nil {
+			onMatch(j, idx[j])
+		}
+	}
+	for _, s := range added {
+		md.add(kind, DiffAdded, name, s)
+	}
+}
+
+// compare types and enum items: enum items are matched by code
+func (md *ModelDiff) compareTypes(fromModel, toModel *ModelMeta) {
+
+	toIdx := make(map[string]int, len(toModel.Type))
+	for k := range toModel.Type {
+		toIdx[toModel.Type[k].Name] = k
+	}
+
+	for k := range fromModel.Type {
+
+		ft := &fromModel.Type[k]
+		n, ok := toIdx[ft.Name]
+		if !ok {
+			md.add(DiffType, DiffRemoved, ft.Name, "")
+			continue
+		}
+		tt := &toModel.Type[n]
+
+		md.addProp(DiffType, ft.Name, "", "dictionary", dicIdName(ft.DicId), dicIdName(tt.DicId))
+
+		if ft.IsBuiltIn() || tt.IsBuiltIn() {
+			continue
+		}
+
+		// compare enum items by code
+		fIds, fCodes := typeEnums(ft)
+		tIds, _ := typeEnums(tt)
+		idx, _, added := matchTypeEnums(ft, tt)
+
+		md.compareItems(DiffEnum, ft.Name, idx, fCodes, added, func(j, m int) {
+			md.addProp(DiffEnum, ft.Name, fCodes[j], "id", strconv.Itoa(fIds[j]), strconv.Itoa(tIds[m]))
+		})
+	}
+
+	for k := range toModel.Type {
+		if _, ok := findTypeByName(fromModel, toModel.Type[k].Name); !ok {
+			md.add(DiffType, DiffAdded, toModel.Type[k].Name, "")
+		}
+	}
+}
+
+// compare parameters: value type, rank and dimensions
+func (md *ModelDiff) compareParams(fromModel, toModel *ModelMeta) {
+
+	for k := range fromModel.Param {
+
+		fp := &fromModel.Param[k]
+		n, ok := toModel.ParamByName(fp.Name)
+		if !ok {
+			md.add(DiffParam, DiffRemoved, fp.Name, "")
+			continue
+		}
+		tp := &toModel.Param[n]
+
+		md.addProp(DiffParam, fp.Name, "", "type", typeNameOf(fromModel, fp.TypeId), typeNameOf(toModel, tp.TypeId))
+		md.addProp(DiffParam, fp.Name, "", "rank", strconv.Itoa(fp.Rank), strconv.Itoa(tp.Rank))
+		md.addProp(DiffParam, fp.Name, "", "hidden", strconv.FormatBool(fp.IsHidden), strconv.FormatBool(tp.IsHidden))
+		md.addProp(DiffParam, fp.Name, "", "extendable", strconv.FormatBool(fp.IsExtendable), strconv.FormatBool(tp.IsExtendable))
+
+		// dimensions: match by name
+		fn := make([]string, len(fp.Dim))
+		for j := range fp.Dim {
+			fn[j] = fp.Dim[j].Name
+		}
+		tn := make([]string, len(tp.Dim))
+		for j := range tp.Dim {
+			tn[j] = tp.Dim[j].Name
+		}
+		md.compareNames(DiffParamDim, fp.Name, fn, tn, func(j, m int) {
+			fd := &fp.Dim[j]
+			td := &tp.Dim[m]
+			md.addProp(DiffParamDim, fp.Name, fd.Name, "type", typeNameOf(fromModel, fd.TypeId), typeNameOf(toModel, td.TypeId))
+			md.addProp(DiffParamDim, fp.Name, fd.Name, "position", strconv.Itoa(fd.DimId), strconv.Itoa(td.DimId))
+		})
+	}
+
+	for k := range toModel.Param {
+		if _, ok := fromModel.ParamByName(toModel.Param[k].Name); !ok {
+			md.add(DiffParam, DiffAdded, toModel.Param[k].Name, "")
+		}
+	}
+}
+
+// compare output tables: dimensions, expressions and accumulators
+func (md *ModelDiff) compareTables(fromModel, toModel *ModelMeta) {
+
+	for k := range fromModel.Table {
+
+		ft := &fromModel.Table[k]
+		n, ok := toModel.OutTableByName(ft.Name)
+		if !ok {
+			md.add(DiffTable, DiffRemoved, ft.Name, "")
+			continue
+		}
+		tt := &toModel.Table[n]
+
+		md.addProp(DiffTable, ft.Name, "", "rank", strconv.Itoa(ft.Rank), strconv.Itoa(tt.Rank))
+		md.addProp(DiffTable, ft.Name, "", "hidden", strconv.FormatBool(ft.IsHidden), strconv.FormatBool(tt.IsHidden))
+
+		// dimensions: match by name
+		fn := make([]string, len(ft.Dim))
+		for j := range ft.Dim {
+			fn[j] = ft.Dim[j].Name
+		}
+		tn := make([]string, len(tt.Dim))
+		for j := range tt.Dim {
+			tn[j] = tt.Dim[j].Name
+		}
+		md.compareNames(DiffTableDim, ft.Name, fn, tn, func(j, m int) {
+			fd := &ft.Dim[j]
+			td := &tt.Dim[m]
+			md.addProp(DiffTableDim, ft.Name, fd.Name, "type", typeNameOf(fromModel, fd.TypeId), typeNameOf(toModel, td.TypeId))
+			md.addProp(DiffTableDim, ft.Name, fd.Name, "position", strconv.Itoa(fd.DimId), strconv.Itoa(td.DimId))
+			md.addProp(DiffTableDim, ft.Name, fd.Name, "total", strconv.FormatBool(fd.IsTotal), strconv.FormatBool(td.IsTotal))
+			md.addProp(DiffTableDim, ft.Name, fd.Name, "size", strconv.Itoa(fd.DimSize), strconv.Itoa(td.DimSize))
+		})
+
+		// expressions: match by name
+		fn = make([]string, len(ft.Expr))
+		for j := range ft.Expr {
+			fn[j] = ft.Expr[j].Name
+		}
+		tn = make([]string, len(tt.Expr))
+		for j := range tt.Expr {
+			tn[j] = tt.Expr[j].Name
+		}
+		md.compareNames(DiffTableExpr, ft.Name, fn, tn, func(j, m int) {
+			fe := &ft.Expr[j]
+			te := &tt.Expr[m]
+			md.addProp(DiffTableExpr, ft.Name, fe.Name, "expression", fe.SrcExpr, te.SrcExpr)
+			md.addProp(DiffTableExpr, ft.Name, fe.Name, "position", strconv.Itoa(fe.ExprId), strconv.Itoa(te.ExprId))
+			md.addProp(DiffTableExpr, ft.Name, fe.Name, "decimals", strconv.Itoa(fe.Decimals), strconv.Itoa(te.Decimals))
+		})
+
+		// accumulators: match by name
+		fn = make([]string, len(ft.Acc))
+		for j := range ft.Acc {
+			fn[j] = ft.Acc[j].Name
+		}
+		tn = make([]string, len(tt.Acc))
+		for j := range tt.Acc {
+			tn[j] = tt.Acc[j].Name
+		}
+		md.compareNames(DiffTableAcc, ft.Name, fn, tn, func(j, m int) {
+			fa := &ft.Acc[j]
+			ta := &tt.Acc[m]
+			md.addProp(DiffTableAcc, ft.Name, fa.Name, "accumulator", fa.SrcAcc, ta.SrcAcc)
+			md.addProp(DiffTableAcc, ft.Name, fa.Name, "position", strconv.Itoa(fa.AccId), strconv.Itoa(ta.AccId))
+			md.addProp(DiffTableAcc, ft.Name, fa.Name, "derived", strconv.FormatBool(fa.IsDerived), strconv.FormatBool(ta.IsDerived))
+		})
+	}
+
+	for k := range toModel.Table {
+		if _, ok := fromModel.OutTableByName(toModel.Table[k].Name); !ok {
+			md.add(DiffTable, DiffAdded, toModel.Table[k].Name, "")
+		}
+	}
+}
+
+// compare entities and entity attributes
+func (md *ModelDiff) compareEntities(fromModel, toModel *ModelMeta) {
+
+	for k := range fromModel.Entity {
+
+		fe := &fromModel.Entity[k]
+		n, ok := toModel.EntityByName(fe.Name)
+		if !ok {
+			md.add(DiffEntity, DiffRemoved, fe.Name, "")
+			continue
+		}
+		te := &toModel.Entity[n]
+
+		// attributes: match by name
+		fn := make([]string, len(fe.Attr))
+		for j := range fe.Attr {
+			fn[j] = fe.Attr[j].Name
+		}
+		tn := make([]string, len(te.Attr))
+		for j := range te.Attr {
+			tn[j] = te.Attr[j].Name
+		}
+		md.compareNames(DiffEntityAttr, fe.Name, fn, tn, func(j, m int) {
+			fa := &fe.Attr[j]
+			ta := &te.Attr[m]
+			md.addProp(DiffEntityAttr, fe.Name, fa.Name, "type", typeNameOf(fromModel, fa.TypeId), typeNameOf(toModel, ta.TypeId))
+			md.addProp(DiffEntityAttr, fe.Name, fa.Name, "internal", strconv.FormatBool(fa.IsInternal), strconv.FormatBool(ta.IsInternal))
+		})
+	}
+
+	for k := range toModel.Entity {
+		if _, ok := fromModel.EntityByName(toModel.Entity[k].Name); !ok {
+			md.add(DiffEntity, DiffAdded, toModel.Entity[k].Name, "")
+		}
+	}
+}
+
+// compare groups of parameters or output tables: group members are matched by name
+func (md *ModelDiff) compareGroups(fromModel, toModel *ModelMeta) {
+
+	for k := range fromModel.Group {
+
+		fg := &fromModel.Group[k]
+		n, ok := findGroupByName(toModel, fg.Name)
+		if !ok {
+			md.add(DiffGroup, DiffRemoved, fg.Name, "")
+			continue
+		}
+		tg := &toModel.Group[n]
+
+		md.addProp(DiffGroup, fg.Name, "", "parameter", strconv.FormatBool(fg.IsParam), strconv.FormatBool(tg.IsParam))
+		md.addProp(DiffGroup, fg.Name, "", "hidden", strconv.FormatBool(fg.IsHidden), strconv.FormatBool(tg.IsHidden))
+
+		fm := groupMembers(fromModel, fg)
+		tm := groupMembers(toModel, tg)
+
+		md.compareNames(DiffGroupMember, fg.Name, fm, tm, nil)
+	}
+
+	for k := range toModel.Group {
+		if _, ok := findGroupByName(fromModel, toModel.Group[k].Name); !ok {
+			md.add(DiffGroup, DiffAdded, toModel.Group[k].Name, "")
+		}
+	}
+}
+
+// key of model item: kind, name and item name, for example: table-expr ageSexIncome expr0
+type diffKey struct {
+	kind string
+	name string
+	item string
+}
+
+// key of model item text: item, text property (descr or note) and language
+type diffTxtKey struct {
+	diffKey
+	prop     string
+	langCode string
+}
+
+// compare description and notes of model items which exist in both models
+func (md *ModelDiff) compareText(fromModel, toModel *ModelMeta, fromTxt, toTxt *ModelTxtMeta) {
+
+	fromItems := modelItemKeys(fromModel)
+	toItems := modelItemKeys(toModel)
+
+	fromKeys, fromVals := modelTextKeys(fromModel, fromTxt)
+	toKeys, toVals := modelTextKeys(toModel, toTxt)
+
+	// compare text in order of the model compared to, append text which exist only in the model compared from
+	keys := append([]diffTxtKey{}, toKeys...)
+	for _, k := range fromKeys {
+		if _, ok := toVals[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range keys {
+
+		if !fromItems[k.diffKey] || !toItems[k.diffKey] {
+			continue // skip text of added or removed items
+		}
+		fv := fromVals[k]
+		tv := toVals[k]
+		if fv != tv {
+			md.Item = append(md.Item,
+				ModelDiffItem{Kind: k.kind, Change: DiffChanged, Name: k.name, Item: k.item, Prop: k.prop, LangCode: k.langCode, From: fv, To: tv})
+		}
+	}
+}
+
+// return set of model item keys: model, types, enums, parameters, tables, entities, groups and its dimensions, expressions, attributes
+func modelItemKeys(m *ModelMeta) map[diffKey]bool {
+
+	ks := map[diffKey]bool{{kind: DiffModel, name: m.Model.Name}: true}
+
+	for k := range m.Type {
+		ks[diffKey{kind: DiffType, name: m.Type[k].Name}] = true
+		for j := range m.Type[k].Enum {
+			ks[diffKey{kind: DiffEnum, name: m.Type[k].Name, item: m.Type[k].Enum[j].Name}] = true
+		}
+	}
+	for k := range m.Param {
+		ks[diffKey{kind: DiffParam, name: m.Param[k].Name}] = true
+		for j := range m.Param[k].Dim {
+			ks[diffKey{kind: DiffParamDim, name: m.Param[k].Name, item: m.Param[k].Dim[j].Name}] = true
+		}
+	}
+	for k := range m.Table {
+		t := &m.Table[k]
+		ks[diffKey{kind: DiffTable, name: t.Name}] = true
+		for j := range t.Dim {
+			ks[diffKey{kind: DiffTableDim, name: t.Name, item: t.Dim[j].Name}] = true
+		}
+		for j := range t.Expr {
+			ks[diffKey{kind: DiffTableExpr, name: t.Name, item: t.Expr[j].Name}] = true
+		}
+		for j := range t.Acc {
+			ks[diffKey{kind: DiffTableAcc, name: t.Name, item: t.Acc[j].Name}] = true
+		}
+	}
+	for k := range m.Entity {
+		ks[diffKey{kind: DiffEntity, name: m.Entity[k].Name}] = true
+		for j := range m.Entity[k].Attr {
+			ks[diffKey{kind: DiffEntityAttr, name: m.Entity[k].Name, item: m.Entity[k].Attr[j].Name}] = true
+		}
+	}
+	for k := range m.Group {
+		ks[diffKey{kind: DiffGroup, name: m.Group[k].Name}] = true
+	}
+	return ks
+}
+
+// return ordered list of model text keys and text values by key: description and notes in each language
+func modelTextKeys(m *ModelMeta, txt *ModelTxtMeta) ([]diffTxtKey, map[diffTxtKey]string) {
+
+	keys := []diffTxtKey{}
+	vals := map[diffTxtKey]string{}
+
+	// append description and notes, skip text of item if item not found in the model
+	add := func(kind, name, item, langCode, descr, note string) {
+		for _, p := range []struct{ prop, val string }{{"descr", descr}, {"note", note}} {
+			k := diffTxtKey{diffKey: diffKey{kind: kind, name: name, item: item}, prop: p.prop, langCode: langCode}
+			if _, ok := vals[k]; !ok {
+				keys = append(keys, k)
+			}
+			vals[k] = p.val
+		}
+	}
+
+	for _, r := range txt.ModelTxt {
+		add(DiffModel, m.Model.Name, "", r.LangCode, r.Descr, r.Note)
+	}
+	for _, r := range txt.TypeTxt {
+		if n, ok := m.TypeByKey(r.TypeId); ok {
+			add(DiffType, m.Type[n].Name, "", r.LangCode, r.Descr, r.Note)
+		}
+	}
+	for _, r := range txt.TypeEnumTxt {
+		if n, ok := m.TypeByKey(r.TypeId); ok {
+			for j := range m.Type[n].Enum {
+				if m.Type[n].Enum[j].EnumId == r.EnumId {
+					add(DiffEnum, m.Type[n].Name, m.Type[n].Enum[j].Name, r.LangCode, r.Descr, r.Note)
+					break
+				}
+			}
+		}
+	}
+	for _, r := range txt.ParamTxt {
+		if n, ok := m.ParamByKey(r.ParamId); ok {
+			add(DiffParam, m.Param[n].Name, "", r.LangCode, r.Descr, r.Note)
+		}
+	}
+	for _, r := range txt.ParamDimsTxt {
+		if n, ok := m.ParamByKey(r.ParamId); ok {
+			for j := range m.Param[n].Dim {
+				if m.Param[n].Dim[j].DimId == r.DimId {
+					add(DiffParamDim, m.Param[n].Name, m.Param[n].Dim[j].Name, r.LangCode, r.Descr, r.Note)
+					break
+				}
+			}
+		}
+	}
+	for _, r := range txt.TableTxt {
+		if n, ok := m.OutTableByKey(r.TableId); ok {
+			add(DiffTable, m.Table[n].Name, "", r.LangCode, r.Descr, r.Note)
+		}
+	}
+	for _, r := range txt.TableDimsTxt {
+		if n, ok := m.OutTableByKey(r.TableId); ok {
+			for j := range m.Table[n].Dim {
+				if m.Table[n].Dim[j].DimId == r.DimId {
+					add(DiffTableDim, m.Table[n].Name, m.Table[n].Dim[j].Name, r.LangCode, r.Descr, r.Note)
+					break
+				}
+			}
+		}
+	}
+	for _, r := range txt.TableExprTxt {
+		if n, ok := m.OutTableByKey(r.TableId); ok {
+			for j := range m.Table[n].Expr {
+				if m.Table[n].Expr[j].ExprId == r.ExprId {
+					add(DiffTableExpr, m.Table[n].Name, m.Table[n].Expr[j].Name, r.LangCode, r.Descr, r.Note)
+					break
+				}
+			}
+		}
+	}
+	for _, r := range txt.TableAccTxt {
+		if n, ok := m.OutTableByKey(r.TableId); ok {
+			for j := range m.Table[n].Acc {
+				if m.Table[n].Acc[j].AccId == r.AccId {
+					add(DiffTableAcc, m.Table[n].Name, m.Table[n].Acc[j].Name, r.LangCode, r.Descr, r.Note)
+					break
+				}
+			}
+		}
+	}
+	for _, r := range txt.EntityTxt {
+		if n, ok := m.EntityByKey(r.EntityId); ok {
+			add(DiffEntity, m.Entity[n].Name, "", r.LangCode, r.Descr, r.Note)
+		}
+	}
+	for _, r := range txt.EntityAttrTxt {
+		if n, ok := m.EntityByKey(r.EntityId); ok {
+			if j, ok := m.Entity[n].AttrByKey(r.AttrId); ok {
+				add(DiffEntityAttr, m.Entity[n].Name, m.Entity[n].Attr[j].Name, r.LangCode, r.Descr, r.Note)
+			}
+		}
+	}
+	for _, r := range txt.GroupTxt {
+		if n, ok := findGroupById(m, r.GroupId); ok {
+			add(DiffGroup, m.Group[n].Name, "", r.LangCode, r.Descr, r.Note)
+		}
+	}
+
+	return keys, vals
+}
+
+// return names of group members: parameters, output tables or child groups
+func groupMembers(m *ModelMeta, g *GroupMeta) []string {
+
+	ms := make([]string, 0, len(g.GroupPc))
+
+	for _, pc := range g.GroupPc {
+		switch {
+		case pc.ChildGroupId >= 0:
+			if n, ok := findGroupById(m, pc.ChildGroupId); ok {
+				ms = append(ms, m.Group[n].Name)
+			}
+		case pc.ChildLeafId >= 0 && g.IsParam:
+			if n, ok := m.ParamByKey(pc.ChildLeafId); ok {
+				ms = append(ms, m.Param[n].Name)
+			}
+		case pc.ChildLeafId >= 0:
+			if n, ok := m.OutTableByKey(pc.ChildLeafId); ok {
+				ms = append(ms, m.Table[n].Name)
+			}
+		}
+	}
+	return ms
+}
+
+// return index of type by name
+func findTypeByName(m *ModelMeta, name string) (int, bool) {
+	for k := range m.Type {
+		if m.Type[k].Name == name {
+			return k, true
+		}
+	}
+	return len(m.Type), false
+}
+
+// return index of group by name
+func findGroupByName(m *ModelMeta, name string) (int, bool) {
+	for k := range m.Group {
+		if m.Group[k].Name == name {
+			return k, true
+		}
+	}
+	return len(m.Group), false
+}
+
+// return index of group by group id
+func findGroupById(m *ModelMeta, groupId int) (int, bool) {
+	for k := range m.Group {
+		if m.Group[k].GroupId == groupId {
+			return k, true
+		}
+	}
+	return len(m.Group), false
+}
+
+// return type name by type id or type id as string if type not found
+func typeNameOf(m *ModelMeta, typeId int) string {
+	if n, ok := m.TypeByKey(typeId); ok {
+		return m.Type[n].Name
+	}
+	return strconv.Itoa(typeId)
+}
+
+// return type dictionary name by dictionary id: simple, logical, classification, range, partition, link
+func dicIdName(dicId int) string {
+	switch dicId {
+	case 0:
+		return "simple"
+	case 1:
+		return "logical"
+	case 2:
+		return "classification"
+	case rangeDicId:
+		return "range"
+	case 4:
+		return "partition"
+	case 5:
+		return "link"
+	}
+	return strconv.Itoa(dicId)
+}
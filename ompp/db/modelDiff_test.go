@@ -0,0 +1,179 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"testing"
+)
+
+func TestCompareModel(t *testing.T) {
+
+	// previous model version:
+	// entity Person attributes age int, sex SEX, oldAttr int and entity Household,
+	// groups: params (rate, count), tables (income) and oldGroup (rate)
+	fromModel := &ModelMeta{
+		Model: ModelDicRow{ModelId: 1, Name: "modelOne", Digest: "d1", Version: "1.0"},
+		Type: []TypeMeta{
+			{TypeDicRow: TypeDicRow{ModelId: 1, TypeId: 4, Name: "int"}},
+			{TypeDicRow: TypeDicRow{ModelId: 1, TypeId: 14, Name: "double"}},
+			{TypeDicRow: TypeDicRow{ModelId: 1, TypeId: 101, Name: "SEX", DicId: 2, TotalEnumId: 2},
+				Enum: []TypeEnumRow{{ModelId: 1, TypeId: 101, EnumId: 0, Name: "M"}, {ModelId: 1, TypeId: 101, EnumId: 1, Name: "F"}}},
+		},
+		Param: []ParamMeta{
+			{ParamDicRow: ParamDicRow{ModelId: 1, ParamId: 0, Name: "rate", Rank: 0, TypeId: 14}},
+			{ParamDicRow: ParamDicRow{ModelId: 1, ParamId: 1, Name: "count", Rank: 0, TypeId: 4}},
+		},
+		Table: []TableMeta{
+			{TableDicRow: TableDicRow{ModelId: 1, TableId: 0, Name: "income", Rank: 0},
+				Acc:  []TableAccRow{{ModelId: 1, TableId: 0, AccId: 0, Name: "acc0", SrcAcc: "value_sum()"}},
+				Expr: []TableExprRow{{ModelId: 1, TableId: 0, ExprId: 0, Name: "expr0", SrcExpr: "OM_AVG(acc0)", Decimals: 2}}},
+		},
+		Entity: []EntityMeta{
+			{EntityDicRow: EntityDicRow{ModelId: 1, EntityId: 0, Name: "Person"},
+				Attr: []EntityAttrRow{
+					{ModelId: 1, EntityId: 0, AttrId: 0, Name: "age", TypeId: 4},
+					{ModelId: 1, EntityId: 0, AttrId: 1, Name: "sex", TypeId: 101},
+					{ModelId: 1, EntityId: 0, AttrId: 2, Name: "oldAttr", TypeId: 4},
+				}},
+			{EntityDicRow: EntityDicRow{ModelId: 1, EntityId: 1, Name: "Household"},
+				Attr: []EntityAttrRow{{ModelId: 1, EntityId: 1, AttrId: 0, Name: "size", TypeId: 4}}},
+		},
+		Group: []GroupMeta{
+			{GroupLstRow: GroupLstRow{ModelId: 1, GroupId: 10, IsParam: true, Name: "params"},
+				GroupPc: []GroupPcRow{{ModelId: 1, GroupId: 10, ChildPos: 0, ChildGroupId: -1, ChildLeafId: 0}, {ModelId: 1, GroupId: 10, ChildPos: 1, ChildGroupId: -1, ChildLeafId: 1}}},
+			{GroupLstRow: GroupLstRow{ModelId: 1, GroupId: 20, IsParam: false, Name: "tables"},
+				GroupPc: []GroupPcRow{{ModelId: 1, GroupId: 20, ChildPos: 0, ChildGroupId: -1, ChildLeafId: 0}}},
+			{GroupLstRow: GroupLstRow{ModelId: 1, GroupId: 30, IsParam: true, Name: "oldGroup"},
+				GroupPc: []GroupPcRow{{ModelId: 1, GroupId: 30, ChildPos: 0, ChildGroupId: -1, ChildLeafId: 0}}},
+		},
+	}
+
+	// new model version: parameter newParam added, income expression changed,
+	// entity Person: age type changed to double, sex is internal, oldAttr removed and income added,
+	// entity Household removed and entity Firm added,
+	// group params is hidden and includes newParam and child group subParams, oldGroup removed and subParams added
+	toModel := &ModelMeta{
+		Model: ModelDicRow{ModelId: 2, Name: "modelOne", Digest: "d2", Version: "1.0"},
+		Type: []TypeMeta{
+			{TypeDicRow: TypeDicRow{ModelId: 2, TypeId: 4, Name: "int"}},
+			{TypeDicRow: TypeDicRow{ModelId: 2, TypeId: 14, Name: "double"}},
+			{TypeDicRow: TypeDicRow{ModelId: 2, TypeId: 101, Name: "SEX", DicId: 2, TotalEnumId: 2},
+				Enum: []TypeEnumRow{{ModelId: 2, TypeId: 101, EnumId: 0, Name: "M"}, {ModelId: 2, TypeId: 101, EnumId: 1, Name: "F"}}},
+		},
+		Param: []ParamMeta{
+			{ParamDicRow: ParamDicRow{ModelId: 2, ParamId: 0, Name: "rate", Rank: 0, TypeId: 14}},
+			{ParamDicRow: ParamDicRow{ModelId: 2, ParamId: 1, Name: "count", Rank: 0, TypeId: 4}},
+			{ParamDicRow: ParamDicRow{ModelId: 2, ParamId: 2, Name: "newParam", Rank: 0, TypeId: 14}},
+		},
+		Table: []TableMeta{
+			{TableDicRow: TableDicRow{ModelId: 2, TableId: 0, Name: "income", Rank: 0},
+				Acc:  []TableAccRow{{ModelId: 2, TableId: 0, AccId: 0, Name: "acc0", SrcAcc: "value_sum()"}},
+				Expr: []TableExprRow{{ModelId: 2, TableId: 0, ExprId: 0, Name: "expr0", SrcExpr: "OM_SUM(acc0)", Decimals: 2}}},
+		},
+		Entity: []EntityMeta{
+			{EntityDicRow: EntityDicRow{ModelId: 2, EntityId: 0, Name: "Person"},
+				Attr: []EntityAttrRow{
+					{ModelId: 2, EntityId: 0, AttrId: 0, Name: "age", TypeId: 14},
+					{ModelId: 2, EntityId: 0, AttrId: 1, Name: "sex", TypeId: 101, IsInternal: true},
+					{ModelId: 2, EntityId: 0, AttrId: 2, Name: "income", TypeId: 14},
+				}},
+			{EntityDicRow: EntityDicRow{ModelId: 2, EntityId: 1, Name: "Firm"},
+				Attr: []EntityAttrRow{{ModelId: 2, EntityId: 1, AttrId: 0, Name: "size", TypeId: 4}}},
+		},
+		Group: []GroupMeta{
+			{GroupLstRow: GroupLstRow{ModelId: 2, GroupId: 10, IsParam: true, Name: "params", IsHidden: true},
+				GroupPc: []GroupPcRow{
+					{ModelId: 2, GroupId: 10, ChildPos: 0, ChildGroupId: -1, ChildLeafId: 0},
+					{ModelId: 2, GroupId: 10, ChildPos: 1, ChildGroupId: -1, ChildLeafId: 1},
+					{ModelId: 2, GroupId: 10, ChildPos: 2, ChildGroupId: -1, ChildLeafId: 2},
+					{ModelId: 2, GroupId: 10, ChildPos: 3, ChildGroupId: 40, ChildLeafId: -1},
+				}},
+			{GroupLstRow: GroupLstRow{ModelId: 2, GroupId: 20, IsParam: false, Name: "tables"},
+				GroupPc: []GroupPcRow{{ModelId: 2, GroupId: 20, ChildPos: 0, ChildGroupId: -1, ChildLeafId: 0}}},
+			{GroupLstRow: GroupLstRow{ModelId: 2, GroupId: 40, IsParam: true, Name: "subParams"},
+				GroupPc: []GroupPcRow{{ModelId: 2, GroupId: 40, ChildPos: 0, ChildGroupId: -1, ChildLeafId: 2}}},
+		},
+	}
+	if err := fromModel.updateInternals(); err != nil {
+		t.Fatal(err)
+	}
+	if err := toModel.updateInternals(); err != nil {
+		t.Fatal(err)
+	}
+
+	// model FR description changed, EN is the same,
+	// Person age EN note changed, params FR description exist only in previous version,
+	// text of removed or added items is skipped: Household, oldAttr, Firm, income
+	fromTxt := &ModelTxtMeta{
+		ModelTxt: []ModelTxtRow{{ModelId: 1, LangCode: "EN", Descr: "Model One"}, {ModelId: 1, LangCode: "FR", Descr: "Modèle un"}},
+		EntityTxt: []EntityTxtRow{
+			{ModelId: 1, EntityId: 0, LangCode: "EN", Descr: "Person"},
+			{ModelId: 1, EntityId: 1, LangCode: "EN", Descr: "Household"},
+		},
+		EntityAttrTxt: []EntityAttrTxtRow{
+			{ModelId: 1, EntityId: 0, AttrId: 0, LangCode: "EN", Descr: "Age", Note: "in years"},
+			{ModelId: 1, EntityId: 0, AttrId: 2, LangCode: "EN", Descr: "Old attribute"},
+		},
+		GroupTxt: []GroupTxtRow{
+			{ModelId: 1, GroupId: 10, DescrNote: DescrNote{LangCode: "EN", Descr: "Parameters"}},
+			{ModelId: 1, GroupId: 10, DescrNote: DescrNote{LangCode: "FR", Descr: "Paramètres"}},
+		},
+	}
+	toTxt := &ModelTxtMeta{
+		ModelTxt: []ModelTxtRow{{ModelId: 2, LangCode: "EN", Descr: "Model One"}, {ModelId: 2, LangCode: "FR", Descr: "Modèle 1"}},
+		EntityTxt: []EntityTxtRow{
+			{ModelId: 2, EntityId: 0, LangCode: "EN", Descr: "Person"},
+			{ModelId: 2, EntityId: 1, LangCode: "EN", Descr: "Firm"},
+		},
+		EntityAttrTxt: []EntityAttrTxtRow{
+			{ModelId: 2, EntityId: 0, AttrId: 0, LangCode: "EN", Descr: "Age", Note: "in full years"},
+			{ModelId: 2, EntityId: 0, AttrId: 2, LangCode: "EN", Descr: "Income"},
+		},
+		GroupTxt: []GroupTxtRow{
+			{ModelId: 2, GroupId: 10, DescrNote: DescrNote{LangCode: "EN", Descr: "Parameters"}},
+		},
+	}
+
+	md, err := CompareModel(fromModel, toModel, fromTxt, toTxt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type diffCheck struct {
+		kind, change, name, item, prop, langCode, from, to string
+	}
+	expected := []diffCheck{
+		{DiffModel, DiffChanged, "modelOne", "", "digest", "", fromModel.Model.Digest, toModel.Model.Digest},
+		{DiffParam, DiffAdded, "newParam", "", "", "", "", ""},
+		{DiffTableExpr, DiffChanged, "income", "expr0", "expression", "", "OM_AVG(acc0)", "OM_SUM(acc0)"},
+		{DiffEntityAttr, DiffChanged, "Person", "age", "type", "", "int", "double"},
+		{DiffEntityAttr, DiffChanged, "Person", "sex", "internal", "", "false", "true"},
+		{DiffEntityAttr, DiffRemoved, "Person", "oldAttr", "", "", "", ""},
+		{DiffEntityAttr, DiffAdded, "Person", "income", "", "", "", ""},
+		{DiffEntity, DiffRemoved, "Household", "", "", "", "", ""},
+		{DiffEntity, DiffAdded, "Firm", "", "", "", "", ""},
+		{DiffGroup, DiffChanged, "params", "", "hidden", "", "false", "true"},
+		{DiffGroupMember, DiffAdded, "params", "newParam", "", "", "", ""},
+		{DiffGroupMember, DiffAdded, "params", "subParams", "", "", "", ""},
+		{DiffGroup, DiffRemoved, "oldGroup", "", "", "", "", ""},
+		{DiffGroup, DiffAdded, "subParams", "", "", "", "", ""},
+		{DiffModel, DiffChanged, "modelOne", "", "descr", "FR", "Modèle un", "Modèle 1"},
+		{DiffEntityAttr, DiffChanged, "Person", "age", "note", "EN", "in years", "in full years"},
+		{DiffGroup, DiffChanged, "params", "", "descr", "FR", "Paramètres", ""},
+	}
+
+	for k := range md.Item {
+		t.Log(md.Item[k].String())
+	}
+	if len(md.Item) != len(expected) {
+		t.Fatalf("invalid number of model differences: %d, expected: %d", len(md.Item), len(expected))
+	}
+	for k, e := range expected {
+		di := md.Item[k]
+		if di.Kind != e.kind || di.Change != e.change || di.Name != e.name || di.Item != e.item || di.Prop != e.prop ||
+			di.LangCode != e.langCode || di.From != e.from || di.To != e.to {
+			t.Errorf("[%d] invalid model difference: %s, expected: %v", k, di.String(), e)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+)
+
+// RunPurgePolicy is a retention policy for model runs: which runs to keep and which can be deleted.
+//
+// Model run is always kept if it is not completed or if run is referenced by workset as a base run or by modeling task run.
+// For each run name only KeepLast most recent runs are kept and all older runs with the same name can be deleted.
+type RunPurgePolicy struct {
+	KeepLast int // number of most recent runs to keep for each run name, if zero then only referenced runs are kept
+}
+
+// SelectRunsToPurge return list of model runs which can be deleted by retention policy.
+//
+// Only completed model runs (success, exit or error) can be deleted.
+// Runs referenced by workset base_run_id or by modeling task run (task_run_set) are always kept.
+// For each run name KeepLast most recent runs are kept.
+// Result is ordered by run id.
+func SelectRunsToPurge(dbConn *sql.DB, modelId int, policy RunPurgePolicy) ([]RunRow, error) {
+
+	// validate parameters
+	if modelId <= 0 {
+		return nil, errors.New("invalid model id: " + strconv.Itoa(modelId))
+	}
+	if policy.KeepLast < 0 {
+		return nil, errors.New("invalid number of runs to keep: " + strconv.Itoa(policy.KeepLast))
+	}
+
+	rl, err := GetRunList(dbConn, modelId)
+	if err != nil {
+		return nil, err
+	}
+	if len(rl) <= 0 {
+		return []RunRow{}, nil // no model runs
+	}
+
+	// find run id's referenced by worksets as base run or by modeling task runs
+	smId := strconv.Itoa(modelId)
+	refIds := map[int]bool{}
+
+	err = SelectRows(dbConn,
+		"SELECT W.base_run_id FROM workset_lst W"+
+			" WHERE W.model_id = "+smId+
+			" AND W.base_run_id IS NOT NULL"+
+			" UNION"+
+			" SELECT TRS.run_id FROM task_run_set TRS"+
+			" INNER JOIN task_lst T ON (T.task_id = TRS.task_id)"+
+			" WHERE T.model_id = "+smId+
+			" AND TRS.run_id > 0",
+		func(rows *sql.Rows) error {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			refIds[id] = true
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return purgeRunSelect(rl, policy, refIds), nil
+}
+
+// purgeRunSelect return model runs which can be deleted by retention policy.
+// Source list of runs must be ordered by run id, refIds is a set of run id's which must be kept.
+func purgeRunSelect(runRs []RunRow, policy RunPurgePolicy, refIds map[int]bool) []RunRow {
+
+	// count runs with the same name starting from most recent run
+	isKeep := make([]bool, len(runRs))
+	nameCount := map[string]int{}
+
+	for k := len(runRs) - 1; k >= 0; k-- {
+
+		if !IsRunCompleted(runRs[k].Status) || refIds[runRs[k].RunId] {
+			isKeep[k] = true
+			continue
+		}
+		n := nameCount[runRs[k].Name]
+		if n < policy.KeepLast {
+			isKeep[k] = true
+			nameCount[runRs[k].Name] = n + 1
+		}
+	}
+
+	rl := []RunRow{}
+	for k := range runRs {
+		if !isKeep[k] {
+			rl = append(rl, runRs[k])
+		}
+	}
+	return rl
+}
+
+// Vacuum does compact database file: it is SQLite VACUUM and it is no-op for any other database facet.
+func Vacuum(dbConn *sql.DB, dbFacet Facet) error {
+	if dbFacet != SqliteFacet {
+		return nil
+	}
+	return Update(dbConn, "VACUUM")
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"testing"
+)
+
+func TestPurgeRunSelect(t *testing.T) {
+
+	runRs := []RunRow{
+		{RunId: 101, Name: "a", Status: DoneRunStatus},
+		{RunId: 102, Name: "b", Status: DoneRunStatus},
+		{RunId: 103, Name: "a", Status: ErrorRunStatus},
+		{RunId: 104, Name: "a", Status: ExitRunStatus},
+		{RunId: 105, Name: "b", Status: ProgressRunStatus},
+		{RunId: 106, Name: "a", Status: DoneRunStatus},
+		{RunId: 107, Name: "b", Status: DoneRunStatus},
+	}
+	ids := func(rl []RunRow) []int {
+		r := []int{}
+		for k := range rl {
+			r = append(r, rl[k].RunId)
+		}
+		return r
+	}
+	isEqual := func(a, b []int) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for k := range a {
+			if a[k] != b[k] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// keep last run of each name, run in progress always kept
+	rl := purgeRunSelect(runRs, RunPurgePolicy{KeepLast: 1}, map[int]bool{})
+	if e := []int{101, 102, 103, 104}; !isEqual(ids(rl), e) {
+		t.Errorf("keep last 1: expected %v, got %v", e, ids(rl))
+	}
+
+	// runs referenced by worksets or task runs are always kept and not counted as last runs
+	rl = purgeRunSelect(runRs, RunPurgePolicy{KeepLast: 1}, map[int]bool{101: true, 106: true})
+	if e := []int{102, 103}; !isEqual(ids(rl), e) {
+		t.Errorf("keep last 1 and referenced: expected %v, got %v", e, ids(rl))
+	}
+
+	// keep only referenced runs
+	rl = purgeRunSelect(runRs, RunPurgePolicy{KeepLast: 0}, map[int]bool{102: true})
+	if e := []int{101, 103, 104, 106, 107}; !isEqual(ids(rl), e) {
+		t.Errorf("keep referenced only: expected %v, got %v", e, ids(rl))
+	}
+
+	// nothing to delete if number of runs to keep is large enough
+	rl = purgeRunSelect(runRs, RunPurgePolicy{KeepLast: 3}, map[int]bool{})
+	if e := []int{101}; !isEqual(ids(rl), e) {
+		t.Errorf("keep last 3: expected %v, got %v", e, ids(rl))
+	}
+	rl = purgeRunSelect(runRs, RunPurgePolicy{KeepLast: 4}, map[int]bool{})
+	if len(rl) != 0 {
+		t.Errorf("keep last 4: expected empty list, got %v", ids(rl))
+	}
+}
@@ -22,11 +22,14 @@ type WriteLayout struct {
 //
 // Double format string is used for digest calculation if value type if float or double.
 type WriteParamLayout struct {
-	WriteLayout        // common write layout: parameter name, run or set id
-	SubCount    int    // sub-values count
-	IsToRun     bool   // if true then write into into model run else into workset
-	IsPage      bool   // if true then write only page of data else all parameter values
-	DoubleFmt   string // used for float model types digest calculation
+	WriteLayout                  // common write layout: parameter name, run or set id
+	SubCount         int         // sub-values count
+	IsToRun          bool        // if true then write into into model run else into workset
+	IsPage           bool        // if true then write only page of data else all parameter values
+	DoubleFmt        string      // used for float model types digest calculation
+	IfUpdateDateTime string      // if not empty then conditional workset update: workset must not be changed since that update date-time
+	VersionNote      string      // if not empty then save current workset parameter values as new workset version before update
+	ParamRules       []ParamRule // if not empty then workset parameter values must satisfy validation rules after update
 }
 
 // WriteTableLayout describes output table values for insert or update.
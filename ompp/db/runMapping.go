@@ -0,0 +1,832 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/openmpp/go/ompp/helper"
+)
+
+// RunMapping is a mapping of model run from source model into destination model with different digest,
+// for example, to copy model run from previous version of the model into new version of the model.
+//
+// Parameters, output tables and entities are matched by name, dimensions and attributes are matched by name,
+// dimension items and enum-based values are mapped from source enum id to destination enum id through enum code.
+// Source and destination items are compatible if:
+//   - parameter: rank and dimension names are the same, dimension types have the same enum codes
+//     and parameter value type is the same built-in kind or source enum codes exist in destination type;
+//   - output table: rank, dimension names and total items are the same, source dimension enum codes exist in destination
+//     and output table expressions and accumulators names are the same;
+//   - entity: all source entity attributes exist in destination and source attribute enum codes exist in destination.
+//
+// Model run must include all model parameters, run cannot be mapped if any of destination parameters is missing or not compatible,
+// unless destination model workset is used to fill such parameters, see FillFromWorkset.
+// Output tables and entities which are not compatible are excluded from model run.
+type RunMapping struct {
+	SrcModel  *ModelMeta                // source model metadata
+	DstModel  *ModelMeta                // destination model metadata
+	Issue     []MapIssue                // structural mismatches between source and destination model
+	param     map[string]*itemMap       // compatible parameters by name
+	table     map[string]*itemMap       // compatible output tables by name
+	entity    map[string]*itemMap       // compatible entities by name
+	fillSetId int                       // if positive then destination workset id to fill missing or not compatible parameters
+	fill      map[string]ParamRunSetPub // parameters of destination workset to fill missing or not compatible parameters
+}
+
+// MapIssue is a structural mismatch between source and destination model parameter, output table or entity
+type MapIssue struct {
+	Kind string // issue kind, for example: missing-parameter, rank, enum-list
+	Name string // parameter, output table or entity name
+	Item string // dimension, expression, accumulator or attribute name, if applicable
+	Msg  string // issue description
+}
+
+// model mapping issue kinds
+const (
+	MapMissingParam  = "missing-parameter" // destination model parameter not found in source model
+	MapDeletedParam  = "deleted-parameter" // source model parameter not found in destination model
+	MapMissingTable  = "missing-table"     // destination model output table not found in source model
+	MapDeletedTable  = "deleted-table"     // source model output table not found in destination model
+	MapDeletedEntity = "deleted-entity"    // source model entity not found in destination model
+	MapRank          = "rank"              // number of dimensions changed
+	MapDimension     = "dimension"         // dimension name, type or total item changed
+	MapEnumList      = "enum-list"         // enum items of dimension or value type changed
+	MapValueType     = "value-type"        // parameter value type changed
+	MapExpression    = "expression"        // output table expressions changed
+	MapAccumulator   = "accumulator"       // output table accumulators changed
+	MapAttribute     = "attribute"         // entity attribute not found or attribute type changed
+)
+
+// String return issue as text message, for example: enum-list: ageSex: age: enum items removed: 60+
+func (mi *MapIssue) String() string {
+
+	s := mi.Kind + ":"
+	if mi.Name != "" {
+		s += " " + mi.Name + ":"
+	}
+	if mi.Item != "" {
+		s += " " + mi.Item + ":"
+	}
+	return s + " " + mi.Msg
+}
+
+// mapping of compatible parameter, output table or entity
+type itemMap struct {
+	srcIdx   int                               // index in source model parameters, output tables or entities
+	dstIdx   int                               // index in destination model parameters, output tables or entities
+	dimCvt   []func(itemId int) (int, error)   // dimension item id converters, nil if item id is the same
+	valueCvt func(itemId int) (int, error)     // parameter enum value id converter, nil if value is the same
+	exprId   map[int]int                       // output table expression id map from source to destination
+	accId    map[int]int                       // output table accumulator id map from source to destination
+	attrCvt  map[string]func(int) (int, error) // entity attribute enum id converters by attribute name
+}
+
+// NewRunMapping compare source and destination model metadata and return model run mapping.
+// List of mismatches between source and destination model is returned as mapping issues.
+func NewRunMapping(srcModel, dstModel *ModelMeta) (*RunMapping, error) {
+
+	if srcModel == nil || dstModel == nil {
+		return nil, errors.New("invalid (empty) source or destination model metadata")
+	}
+	rm := RunMapping{
+		SrcModel: srcModel,
+		DstModel: dstModel,
+		Issue:    []MapIssue{},
+		param:    map[string]*itemMap{},
+		table:    map[string]*itemMap{},
+		entity:   map[string]*itemMap{},
+	}
+
+	// parameters: match by name, check rank, dimensions and value type
+	for k := range dstModel.Param {
+		if _, ok := srcModel.ParamByName(dstModel.Param[k].Name); !ok {
+			rm.addIssue(MapMissingParam, dstModel.Param[k].Name, "", "parameter not found in source model")
+		}
+	}
+	for k := range srcModel.Param {
+
+		sp := &srcModel.Param[k]
+		n, ok := dstModel.ParamByName(sp.Name)
+		if !ok {
+			rm.addIssue(MapDeletedParam, sp.Name, "", "parameter not found in destination model")
+			continue
+		}
+		if im, ok := rm.mapParam(k, n); ok {
+			rm.param[sp.Name] = im
+		}
+	}
+
+	// output tables: match by name, check rank, dimensions, expressions and accumulators
+	for k := range dstModel.Table {
+		if _, ok := srcModel.OutTableByName(dstModel.Table[k].Name); !ok {
+			rm.addIssue(MapMissingTable, dstModel.Table[k].Name, "", "output table not found in source model")
+		}
+	}
+	for k := range srcModel.Table {
+
+		st := &srcModel.Table[k]
+		n, ok := dstModel.OutTableByName(st.Name)
+		if !ok {
+			rm.addIssue(MapDeletedTable, st.Name, "", "output table not found in destination model")
+			continue
+		}
+		if im, ok := rm.mapTable(k, n); ok {
+			rm.table[st.Name] = im
+		}
+	}
+
+	// entities: match by name, check attributes
+	for k := range srcModel.Entity {
+
+		se := &srcModel.Entity[k]
+		n, ok := dstModel.EntityByName(se.Name)
+		if !ok {
+			rm.addIssue(MapDeletedEntity, se.Name, "", "entity not found in destination model")
+			continue
+		}
+		if im, ok := rm.mapEntity(k, n); ok {
+			rm.entity[se.Name] = im
+		}
+	}
+
+	return &rm, nil
+}
+
+// FillFromWorkset use destination model workset, for example, default workset,
+// to fill destination model parameters which are missing in source model run or not compatible.
+func (rm *RunMapping) FillFromWorkset(dbConn *sql.DB, setId int) error {
+
+	hLst, nSub, defIds, err := GetWorksetParamList(dbConn, setId)
+	if err != nil {
+		return err
+	}
+	fill := map[string]ParamRunSetPub{}
+
+	for k, h := range hLst {
+
+		i, ok := rm.DstModel.ParamByHid(h)
+		if !ok {
+			return errors.New("parameter not found, id: " + strconv.Itoa(h))
+		}
+		name := rm.DstModel.Param[i].Name
+		fill[name] = ParamRunSetPub{ParamRunSetTxtPub: ParamRunSetTxtPub{Name: name}, SubCount: nSub[k], DefaultSubId: defIds[k]}
+	}
+	rm.fillSetId = setId
+	rm.fill = fill
+	return nil
+}
+
+// FillSetId return id of destination model workset to fill missing or not compatible parameters, it is zero if not used.
+func (rm *RunMapping) FillSetId() int {
+	return rm.fillSetId
+}
+
+// IsParam return true if source parameter is compatible with destination parameter.
+func (rm *RunMapping) IsParam(name string) bool {
+	_, ok := rm.param[name]
+	return ok
+}
+
+// IsTable return true if source output table is compatible with destination output table.
+func (rm *RunMapping) IsTable(name string) bool {
+	_, ok := rm.table[name]
+	return ok
+}
+
+// IsEntity return true if source entity is compatible with destination entity.
+func (rm *RunMapping) IsEntity(name string) bool {
+	_, ok := rm.entity[name]
+	return ok
+}
+
+// RunPub return copy of source model run "public" metadata mapped into destination model.
+//
+// Parameters are ordered as in destination model, output tables and entities which are not compatible are excluded.
+// Model name and digest are set to destination model, value digests are cleared and must be recalculated.
+// If destination workset to fill parameters is used then missing or not compatible parameters are taken from that workset
+// and names of such parameters are returned.
+// Return error if any of destination model parameters is missing in model run or not compatible and cannot be filled.
+func (rm *RunMapping) RunPub(pub *RunPub) (*RunPub, []string, error) {
+
+	if pub == nil {
+		return nil, nil, errors.New("invalid (empty) model run metadata")
+	}
+	dst := *pub
+	dst.ModelName = rm.DstModel.Model.Name
+	dst.ModelDigest = rm.DstModel.Model.Digest
+	dst.ValueDigest = ""
+
+	// parameters: all destination model parameters must be in model run and must be compatible or filled from destination workset
+	dst.Param = make([]ParamRunSetPub, 0, len(rm.DstModel.Param))
+	missing := []string{}
+	filled := []string{}
+
+	for k := range rm.DstModel.Param {
+
+		name := rm.DstModel.Param[k].Name
+		n := -1
+		for j := range pub.Param {
+			if pub.Param[j].Name == name {
+				n = j
+				break
+			}
+		}
+		if n < 0 || !rm.IsParam(name) {
+			if p, ok := rm.fill[name]; ok {
+				dst.Param = append(dst.Param, p)
+				filled = append(filled, name)
+			} else {
+				missing = append(missing, name)
+			}
+			continue
+		}
+		p := pub.Param[n]
+		p.ValueDigest = ""
+		dst.Param = append(dst.Param, p)
+	}
+	if len(missing) > 0 {
+		return nil, nil, errors.New("model run " + pub.Name + " cannot be mapped into model " + rm.DstModel.Model.Name + " " + rm.DstModel.Model.Digest +
+			", missing or not compatible parameters: " + strings.Join(missing, ", "))
+	}
+
+	// output tables and entities: exclude tables and entities which are not compatible
+	dst.Table = make([]TableRunPub, 0, len(pub.Table))
+
+	for k := range pub.Table {
+		if rm.IsTable(pub.Table[k].Name) {
+			dst.Table = append(dst.Table, TableRunPub{Name: pub.Table[k].Name})
+		}
+	}
+
+	dst.Entity = make([]EntityRunPub, 0, len(pub.Entity))
+
+	for k := range pub.Entity {
+		if rm.IsEntity(pub.Entity[k].Name) {
+			e := pub.Entity[k]
+			e.GenDigest = ""
+			e.ValueDigest = ""
+			dst.Entity = append(dst.Entity, e)
+		}
+	}
+
+	return &dst, filled, nil
+}
+
+// ParamCell return converter of source parameter cell into destination parameter cell: map dimension items and enum value id.
+func (rm *RunMapping) ParamCell(name string) (func(interface{}) (interface{}, error), error) {
+
+	im, ok := rm.param[name]
+	if !ok {
+		return nil, errors.New("parameter not found or not compatible: " + name)
+	}
+
+	cvt := func(src interface{}) (interface{}, error) {
+
+		srcCell, ok := src.(CellParam)
+		if !ok {
+			return nil, errors.New("invalid type, expected: parameter cell (internal error): " + name)
+		}
+		dimIds, err := im.mapDimIds(srcCell.DimIds, name)
+		if err != nil {
+			return nil, err
+		}
+		dstCell := CellParam{
+			cellIdValue: cellIdValue{DimIds: dimIds, IsNull: srcCell.IsNull, Value: srcCell.Value},
+			SubId:       srcCell.SubId,
+		}
+
+		// if parameter value type is enum-based then convert enum id
+		if im.valueCvt != nil && !srcCell.IsNull {
+			iv, ok := helper.ToIntValue(srcCell.Value)
+			if !ok {
+				return nil, errors.New("invalid parameter value type, expected: integer enum id: " + name)
+			}
+			if dstCell.Value, err = im.valueCvt(iv); err != nil {
+				return nil, err
+			}
+		}
+		return dstCell, nil
+	}
+
+	return cvt, nil
+}
+
+// TableAccCell return converter of source output table accumulator cell into destination cell: map dimension items and accumulator id.
+func (rm *RunMapping) TableAccCell(name string) (func(interface{}) (interface{}, error), error) {
+
+	im, ok := rm.table[name]
+	if !ok {
+		return nil, errors.New("output table not found or not compatible: " + name)
+	}
+
+	cvt := func(src interface{}) (interface{}, error) {
+
+		srcCell, ok := src.(CellAcc)
+		if !ok {
+			return nil, errors.New("invalid type, expected: output table accumulator cell (internal error): " + name)
+		}
+		dimIds, err := im.mapDimIds(srcCell.DimIds, name)
+		if err != nil {
+			return nil, err
+		}
+		accId, ok := im.accId[srcCell.AccId]
+		if !ok {
+			return nil, errors.New("invalid accumulator id: " + strconv.Itoa(srcCell.AccId) + " of: " + name)
+		}
+		return CellAcc{
+			cellIdValue: cellIdValue{DimIds: dimIds, IsNull: srcCell.IsNull, Value: srcCell.Value},
+			AccId:       accId,
+			SubId:       srcCell.SubId,
+		}, nil
+	}
+
+	return cvt, nil
+}
+
+// TableExprCell return converter of source output table expression cell into destination cell: map dimension items and expression id.
+func (rm *RunMapping) TableExprCell(name string) (func(interface{}) (interface{}, error), error) {
+
+	im, ok := rm.table[name]
+	if !ok {
+		return nil, errors.New("output table not found or not compatible: " + name)
+	}
+
+	cvt := func(src interface{}) (interface{}, error) {
+
+		srcCell, ok := src.(CellExpr)
+		if !ok {
+			return nil, errors.New("invalid type, expected: output table expression cell (internal error): " + name)
+		}
+		dimIds, err := im.mapDimIds(srcCell.DimIds, name)
+		if err != nil {
+			return nil, err
+		}
+		exprId, ok := im.exprId[srcCell.ExprId]
+		if !ok {
+			return nil, errors.New("invalid expression id: " + strconv.Itoa(srcCell.ExprId) + " of: " + name)
+		}
+		return CellExpr{
+			cellIdValue: cellIdValue{DimIds: dimIds, IsNull: srcCell.IsNull, Value: srcCell.Value},
+			ExprId:      exprId,
+		}, nil
+	}
+
+	return cvt, nil
+}
+
+// MicroCell return converter of source microdata cell into destination cell:
+// reorder attributes from source entity generation into destination entity generation order and map enum id's.
+func (rm *RunMapping) MicroCell(name string, srcGen, dstGen *EntityGenMeta) (func(interface{}) (interface{}, error), error) {
+
+	im, ok := rm.entity[name]
+	if !ok {
+		return nil, errors.New("entity not found or not compatible: " + name)
+	}
+	if srcGen == nil || dstGen == nil {
+		return nil, errors.New("invalid (empty) entity generation metadata: " + name)
+	}
+	se := &rm.SrcModel.Entity[im.srcIdx]
+	de := &rm.DstModel.Entity[im.dstIdx]
+
+	nAttr := len(dstGen.GenAttr)
+	if len(srcGen.GenAttr) != nAttr {
+		return nil, errors.New("invalid number of entity generation attributes: " + strconv.Itoa(len(srcGen.GenAttr)) + ", expected: " + strconv.Itoa(nAttr) + ": " + name)
+	}
+
+	// for each destination generation attribute find source generation attribute by name
+	pos := make([]int, nAttr)
+	fa := make([]func(int) (int, error), nAttr)
+
+	for j := range dstGen.GenAttr {
+
+		n, ok := de.AttrByKey(dstGen.GenAttr[j].AttrId)
+		if !ok {
+			return nil, errors.New("entity attribute not found by id: " + strconv.Itoa(dstGen.GenAttr[j].AttrId) + ": " + name)
+		}
+		aName := de.Attr[n].Name
+
+		pos[j] = -1
+		for i := range srcGen.GenAttr {
+			if m, ok := se.AttrByKey(srcGen.GenAttr[i].AttrId); ok && se.Attr[m].Name == aName {
+				pos[j] = i
+				break
+			}
+		}
+		if pos[j] < 0 {
+			return nil, errors.New("entity generation attribute not found: " + aName + ": " + name)
+		}
+		fa[j] = im.attrCvt[aName]
+	}
+
+	cvt := func(src interface{}) (interface{}, error) {
+
+		srcCell, ok := src.(CellMicro)
+		if !ok {
+			return nil, errors.New("invalid type, expected: microdata cell (internal error): " + name)
+		}
+		if len(srcCell.Attr) != nAttr {
+			return nil, errors.New("invalid number of attributes, expected: " + strconv.Itoa(nAttr) + ": " + name)
+		}
+
+		dstCell := CellMicro{
+			Key:  srcCell.Key,
+			Attr: make([]attrValue, nAttr),
+		}
+		for j := range dstCell.Attr {
+
+			a := srcCell.Attr[pos[j]]
+
+			if fa[j] != nil && !a.IsNull && a.Value != nil {
+				iv, ok := helper.ToIntValue(a.Value)
+				if !ok {
+					return nil, errors.New("invalid attribute value, must be integer enum id: " + name)
+				}
+				v, err := fa[j](iv)
+				if err != nil {
+					return nil, err
+				}
+				a.Value = v
+			}
+			dstCell.Attr[j] = a
+		}
+		return dstCell, nil
+	}
+
+	return cvt, nil
+}
+
+// append mapping issue
+func (rm *RunMapping) addIssue(kind, name, item, msg string) {
+	rm.Issue = append(rm.Issue, MapIssue{Kind: kind, Name: name, Item: item, Msg: msg})
+}
+
+// compare source and destination parameter, return parameter mapping and true if parameters are compatible
+func (rm *RunMapping) mapParam(srcIdx, dstIdx int) (*itemMap, bool) {
+
+	sp := &rm.SrcModel.Param[srcIdx]
+	dp := &rm.DstModel.Param[dstIdx]
+	nIssue := len(rm.Issue)
+
+	if sp.Rank != dp.Rank || len(sp.Dim) != len(dp.Dim) {
+		rm.addIssue(MapRank, sp.Name, "", "rank changed from "+strconv.Itoa(sp.Rank)+" to "+strconv.Itoa(dp.Rank))
+		return nil, false
+	}
+	im := itemMap{srcIdx: srcIdx, dstIdx: dstIdx, dimCvt: make([]func(int) (int, error), len(dp.Dim))}
+
+	// dimensions must have the same names and the same enum codes, otherwise parameter values are incomplete
+	for k := range dp.Dim {
+
+		if sp.Dim[k].Name != dp.Dim[k].Name {
+			rm.addIssue(MapDimension, sp.Name, dp.Dim[k].Name, "dimension name changed from "+sp.Dim[k].Name+" to "+dp.Dim[k].Name)
+			continue
+		}
+		if kind, msg := compareTypeOf(sp.Dim[k].typeOf, dp.Dim[k].typeOf, true); kind != "" {
+			rm.addIssue(kind, sp.Name, dp.Dim[k].Name, msg)
+			continue
+		}
+		im.dimCvt[k] = enumIdMap(sp.Dim[k].typeOf, dp.Dim[k].typeOf, sp.Name+"."+dp.Dim[k].Name, false)
+	}
+
+	// parameter value type: source enum codes must exist in destination type
+	if kind, msg := compareTypeOf(sp.typeOf, dp.typeOf, false); kind != "" {
+		if kind == MapDimension {
+			kind = MapValueType
+		}
+		rm.addIssue(kind, sp.Name, "", msg)
+	} else {
+		im.valueCvt = enumIdMap(sp.typeOf, dp.typeOf, sp.Name, false)
+	}
+
+	return &im, len(rm.Issue) == nIssue
+}
+
+// compare source and destination output table, return output table mapping and true if output tables are compatible
+func (rm *RunMapping) mapTable(srcIdx, dstIdx int) (*itemMap, bool) {
+
+	st := &rm.SrcModel.Table[srcIdx]
+	dt := &rm.DstModel.Table[dstIdx]
+	nIssue := len(rm.Issue)
+
+	if st.Rank != dt.Rank || len(st.Dim) != len(dt.Dim) {
+		rm.addIssue(MapRank, st.Name, "", "rank changed from "+strconv.Itoa(st.Rank)+" to "+strconv.Itoa(dt.Rank))
+		return nil, false
+	}
+	im := itemMap{
+		srcIdx: srcIdx,
+		dstIdx: dstIdx,
+		dimCvt: make([]func(int) (int, error), len(dt.Dim)),
+		exprId: make(map[int]int, len(dt.Expr)),
+		accId:  make(map[int]int, len(dt.Acc)),
+	}
+
+	// dimensions must have the same names and total items, source enum codes must exist in destination
+	for k := range dt.Dim {
+
+		if st.Dim[k].Name != dt.Dim[k].Name {
+			rm.addIssue(MapDimension, st.Name, dt.Dim[k].Name, "dimension name changed from "+st.Dim[k].Name+" to "+dt.Dim[k].Name)
+			continue
+		}
+		if st.Dim[k].IsTotal != dt.Dim[k].IsTotal {
+			rm.addIssue(MapDimension, st.Name, dt.Dim[k].Name, "total item changed from "+strconv.FormatBool(st.Dim[k].IsTotal)+" to "+strconv.FormatBool(dt.Dim[k].IsTotal))
+			continue
+		}
+		if kind, msg := compareTypeOf(st.Dim[k].typeOf, dt.Dim[k].typeOf, false); kind != "" {
+			rm.addIssue(kind, st.Name, dt.Dim[k].Name, msg)
+			continue
+		}
+		im.dimCvt[k] = enumIdMap(st.Dim[k].typeOf, dt.Dim[k].typeOf, st.Name+"."+dt.Dim[k].Name, dt.Dim[k].IsTotal)
+	}
+
+	// expressions and accumulators must have the same names
+	sNames := make([]string, len(st.Expr))
+	dNames := make([]string, len(dt.Expr))
+	for k := range st.Expr {
+		sNames[k] = st.Expr[k].Name
+	}
+	for k := range dt.Expr {
+		dNames[k] = dt.Expr[k].Name
+	}
+	if msg := compareNames(sNames, dNames, "expressions"); msg != "" {
+		rm.addIssue(MapExpression, st.Name, "", msg)
+	} else {
+		for k := range st.Expr {
+			for j := range dt.Expr {
+				if st.Expr[k].Name == dt.Expr[j].Name {
+					im.exprId[st.Expr[k].ExprId] = dt.Expr[j].ExprId
+					break
+				}
+			}
+		}
+	}
+
+	sNames = make([]string, len(st.Acc))
+	dNames = make([]string, len(dt.Acc))
+	for k := range st.Acc {
+		sNames[k] = st.Acc[k].Name
+	}
+	for k := range dt.Acc {
+		dNames[k] = dt.Acc[k].Name
+	}
+	if msg := compareNames(sNames, dNames, "accumulators"); msg != "" {
+		rm.addIssue(MapAccumulator, st.Name, "", msg)
+	} else {
+		for k := range st.Acc {
+			for j := range dt.Acc {
+				if st.Acc[k].Name == dt.Acc[j].Name {
+					im.accId[st.Acc[k].AccId] = dt.Acc[j].AccId
+					break
+				}
+			}
+		}
+	}
+
+	return &im, len(rm.Issue) == nIssue
+}
+
+// compare source and destination entity, return entity mapping and true if entities are compatible
+func (rm *RunMapping) mapEntity(srcIdx, dstIdx int) (*itemMap, bool) {
+
+	se := &rm.SrcModel.Entity[srcIdx]
+	de := &rm.DstModel.Entity[dstIdx]
+	nIssue := len(rm.Issue)
+
+	im := itemMap{srcIdx: srcIdx, dstIdx: dstIdx, attrCvt: map[string]func(int) (int, error){}}
+
+	// all source attributes must exist in destination entity, source enum codes must exist in destination
+	for k := range se.Attr {
+
+		n, ok := de.AttrByName(se.Attr[k].Name)
+		if !ok {
+			rm.addIssue(MapAttribute, se.Name, se.Attr[k].Name, "attribute not found in destination model")
+			continue
+		}
+		if kind, msg := compareTypeOf(se.Attr[k].typeOf, de.Attr[n].typeOf, false); kind != "" {
+			if kind == MapDimension {
+				kind = MapAttribute
+			}
+			rm.addIssue(kind, se.Name, se.Attr[k].Name, msg)
+			continue
+		}
+		if f := enumIdMap(se.Attr[k].typeOf, de.Attr[n].typeOf, se.Name+"."+se.Attr[k].Name, false); f != nil {
+			im.attrCvt[se.Attr[k].Name] = f
+		}
+	}
+
+	return &im, len(rm.Issue) == nIssue
+}
+
+// map source dimension items id's into destination id's
+func (im *itemMap) mapDimIds(srcIds []int, name string) ([]int, error) {
+
+	if len(srcIds) != len(im.dimCvt) {
+		return nil, errors.New("invalid cell rank: " + strconv.Itoa(len(srcIds)) + ", expected: " + strconv.Itoa(len(im.dimCvt)) + ": " + name)
+	}
+	dstIds := make([]int, len(srcIds))
+
+	for k := range srcIds {
+		if im.dimCvt[k] == nil {
+			dstIds[k] = srcIds[k]
+			continue
+		}
+		v, err := im.dimCvt[k](srcIds[k])
+		if err != nil {
+			return nil, err
+		}
+		dstIds[k] = v
+	}
+	return dstIds, nil
+}
+
+// compare source and destination types and return empty "" issue kind if types are compatible
+// or return issue kind and mismatch description.
+// Built-in types are compatible if it is the same kind of type: bool, string, integer or float.
+// Enum-based types are compatible if source enum codes exist in destination type,
+// if isSameEnums is true then source and destination must have the same enum codes.
+func compareTypeOf(src, dst *TypeMeta, isSameEnums bool) (string, string) {
+
+	if src == nil || dst == nil {
+		return MapDimension, "type not found"
+	}
+	if src.IsBuiltIn() != dst.IsBuiltIn() {
+		return MapDimension, "type changed from " + src.Name + " to " + dst.Name
+	}
+
+	// built-in types: must be the same kind of type
+	if src.IsBuiltIn() {
+
+		kindOf := func(t *TypeMeta) string {
+			switch {
+			case t.IsBool():
+				return "bool"
+			case t.IsString():
+				return "string"
+			case t.IsFloat():
+				return "float"
+			}
+			return "int"
+		}
+		if kindOf(src) != kindOf(dst) {
+			return MapDimension, "type changed from " + src.Name + " to " + dst.Name
+		}
+		return "", ""
+	}
+
+	// enum-based types: compare enum codes
+	_, removed, added := matchTypeEnums(src, dst)
+
+	msg := ""
+	if len(removed) > 0 {
+		msg = "enum items removed from " + dst.Name + ": " + joinNames(removed)
+	}
+	if isSameEnums && len(added) > 0 {
+		if msg != "" {
+			msg += ", "
+		}
+		msg += "enum items added into " + dst.Name + ": " + joinNames(added)
+	}
+	if msg != "" {
+		return MapEnumList, msg
+	}
+	return "", ""
+}
+
+// max number of enum codes or names to include into mapping issue message
+const maxMapIssueItems = 8
+
+// return comma separated list of names, limited by max number of names in mapping issue message
+func joinNames(names []string) string {
+	if len(names) <= maxMapIssueItems {
+		return strings.Join(names, ", ")
+	}
+	return strings.Join(names[:maxMapIssueItems], ", ") + ", ... (" + strconv.Itoa(len(names)) + ")"
+}
+
+// compare source and destination names, return empty "" if names are the same or return mismatch description
+func compareNames(src, dst []string, what string) string {
+
+	_, removed, added := matchNames(src, dst)
+
+	msg := ""
+	if len(removed) > 0 {
+		msg = what + " removed: " + joinNames(removed)
+	}
+	if len(added) > 0 {
+		if msg != "" {
+			msg += ", "
+		}
+		msg += what + " added: " + joinNames(added)
+	}
+	return msg
+}
+
+// match source names to destination names,
+// return index of destination name for each source name or -1 if source name not found,
+// names which exist only in source list and names which exist only in destination list.
+func matchNames(src, dst []string) ([]int, []string, []string) {
+
+	dm := make(map[string]int, len(dst))
+	for k, n := range dst {
+		dm[n] = k
+	}
+	sm := make(map[string]bool, len(src))
+
+	idx := make([]int, len(src))
+	removed := []string{}
+	for k, n := range src {
+		sm[n] = true
+		if j, ok := dm[n]; ok {
+			idx[k] = j
+		} else {
+			idx[k] = -1
+			removed = append(removed, n)
+		}
+	}
+
+	added := []string{}
+	for _, n := range dst {
+		if !sm[n] {
+			added = append(added, n)
+		}
+	}
+	return idx, removed, added
+}
+
+// match enum codes of source and destination enum-based types,
+// return index of destination enum for each source enum or -1 if source enum code not found,
+// enum codes which exist only in source type and enum codes which exist only in destination type.
+func matchTypeEnums(src, dst *TypeMeta) ([]int, []string, []string) {
+	_, srcCodes := typeEnums(src)
+	_, dstCodes := typeEnums(dst)
+	return matchNames(srcCodes, dstCodes)
+}
+
+// return enum id's and enum codes of enum-based type, for range type enum code is the same as enum id
+func typeEnums(t *TypeMeta) ([]int, []string) {
+
+	if t.IsRange {
+		n := 1 + t.MaxEnumId - t.MinEnumId
+		if n < 0 {
+			n = 0
+		}
+		ids := make([]int, n)
+		codes := make([]string, n)
+		for k := 0; k < n; k++ {
+			ids[k] = t.MinEnumId + k
+			codes[k] = strconv.Itoa(t.MinEnumId + k)
+		}
+		return ids, codes
+	}
+
+	ids := make([]int, len(t.Enum))
+	codes := make([]string, len(t.Enum))
+	for k := range t.Enum {
+		ids[k] = t.Enum[k].EnumId
+		codes[k] = t.Enum[k].Name
+	}
+	return ids, codes
+}
+
+// return converter from source enum id to destination enum id through enum code,
+// return nil if type is built-in or all enum id's are the same in source and destination.
+// If isTotal is true then source total enum id converted to destination total enum id.
+func enumIdMap(src, dst *TypeMeta, msgName string, isTotal bool) func(itemId int) (int, error) {
+
+	if src == nil || dst == nil || src.IsBuiltIn() || dst.IsBuiltIn() {
+		return nil
+	}
+
+	srcIds, srcCodes := typeEnums(src)
+	dstIds, dstCodes := typeEnums(dst)
+
+	dc := make(map[string]int, len(dstCodes))
+	for k := range dstCodes {
+		dc[dstCodes[k]] = dstIds[k]
+	}
+
+	m := make(map[int]int, len(srcIds)+1)
+	isSame := true
+
+	for k := range srcIds {
+		if id, ok := dc[srcCodes[k]]; ok {
+			m[srcIds[k]] = id
+			isSame = isSame && id == srcIds[k]
+		}
+	}
+	if isSame && (!isTotal || src.TotalEnumId == dst.TotalEnumId) && len(m) == len(srcIds) {
+		return nil // all source enum id's are the same in destination
+	}
+	if isTotal {
+		m[src.TotalEnumId] = dst.TotalEnumId
+	}
+	return func(itemId int) (int, error) {
+		if id, ok := m[itemId]; ok {
+			return id, nil
+		}
+		return 0, errors.New("invalid value: " + strconv.Itoa(itemId) + " of: " + msgName)
+	}
+}
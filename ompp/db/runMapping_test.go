@@ -0,0 +1,171 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"testing"
+)
+
+func TestRunMapping(t *testing.T) {
+
+	// source model: SEX type M=0 F=1, AGE type 10-20, 20-30
+	// destination model: SEX type F=0 M=1, AGE type 10-20, 20-30, 30+
+	srcModel := &ModelMeta{
+		Model: ModelDicRow{ModelId: 1, Name: "modelOne", Digest: "d1"},
+		Type: []TypeMeta{
+			{TypeDicRow: TypeDicRow{ModelId: 1, TypeId: 4, Name: "int"}},
+			{TypeDicRow: TypeDicRow{ModelId: 1, TypeId: 14, Name: "double"}},
+			{TypeDicRow: TypeDicRow{ModelId: 1, TypeId: 101, Name: "SEX", TotalEnumId: 2},
+				Enum: []TypeEnumRow{{ModelId: 1, TypeId: 101, EnumId: 0, Name: "M"}, {ModelId: 1, TypeId: 101, EnumId: 1, Name: "F"}}},
+			{TypeDicRow: TypeDicRow{ModelId: 1, TypeId: 102, Name: "AGE", TotalEnumId: 2},
+				Enum: []TypeEnumRow{{ModelId: 1, TypeId: 102, EnumId: 0, Name: "10-20"}, {ModelId: 1, TypeId: 102, EnumId: 1, Name: "20-30"}}},
+		},
+		Param: []ParamMeta{
+			{ParamDicRow: ParamDicRow{ModelId: 1, ParamId: 0, Name: "ageSex", Rank: 2, TypeId: 14},
+				Dim: []ParamDimsRow{{ModelId: 1, ParamId: 0, DimId: 0, Name: "dim0", TypeId: 102}, {ModelId: 1, ParamId: 0, DimId: 1, Name: "dim1", TypeId: 101}}},
+			{ParamDicRow: ParamDicRow{ModelId: 1, ParamId: 1, Name: "salary", Rank: 1, TypeId: 4},
+				Dim: []ParamDimsRow{{ModelId: 1, ParamId: 1, DimId: 0, Name: "dim0", TypeId: 101}}},
+			{ParamDicRow: ParamDicRow{ModelId: 1, ParamId: 2, Name: "oldParam", Rank: 0, TypeId: 4}},
+		},
+		Table: []TableMeta{
+			{TableDicRow: TableDicRow{ModelId: 1, TableId: 0, Name: "bySex", Rank: 1},
+				Dim:  []TableDimsRow{{ModelId: 1, TableId: 0, DimId: 0, Name: "dim0", TypeId: 101, IsTotal: true, DimSize: 3}},
+				Acc:  []TableAccRow{{ModelId: 1, TableId: 0, AccId: 0, Name: "acc0"}},
+				Expr: []TableExprRow{{ModelId: 1, TableId: 0, ExprId: 0, Name: "expr0"}}},
+			{TableDicRow: TableDicRow{ModelId: 1, TableId: 1, Name: "byAge", Rank: 1},
+				Dim:  []TableDimsRow{{ModelId: 1, TableId: 1, DimId: 0, Name: "dim0", TypeId: 102, DimSize: 2}},
+				Acc:  []TableAccRow{{ModelId: 1, TableId: 1, AccId: 0, Name: "acc0"}},
+				Expr: []TableExprRow{{ModelId: 1, TableId: 1, ExprId: 0, Name: "expr0"}}},
+		},
+	}
+	dstModel := &ModelMeta{
+		Model: ModelDicRow{ModelId: 2, Name: "modelOne", Digest: "d2"},
+		Type: []TypeMeta{
+			{TypeDicRow: TypeDicRow{ModelId: 2, TypeId: 4, Name: "int"}},
+			{TypeDicRow: TypeDicRow{ModelId: 2, TypeId: 14, Name: "double"}},
+			{TypeDicRow: TypeDicRow{ModelId: 2, TypeId: 101, Name: "SEX", TotalEnumId: 2},
+				Enum: []TypeEnumRow{{ModelId: 2, TypeId: 101, EnumId: 0, Name: "F"}, {ModelId: 2, TypeId: 101, EnumId: 1, Name: "M"}}},
+			{TypeDicRow: TypeDicRow{ModelId: 2, TypeId: 102, Name: "AGE", TotalEnumId: 3},
+				Enum: []TypeEnumRow{{ModelId: 2, TypeId: 102, EnumId: 0, Name: "10-20"}, {ModelId: 2, TypeId: 102, EnumId: 1, Name: "20-30"}, {ModelId: 2, TypeId: 102, EnumId: 2, Name: "30+"}}},
+		},
+		Param: []ParamMeta{
+			{ParamDicRow: ParamDicRow{ModelId: 2, ParamId: 0, Name: "salary", Rank: 1, TypeId: 4},
+				Dim: []ParamDimsRow{{ModelId: 2, ParamId: 0, DimId: 0, Name: "dim0", TypeId: 101}}},
+			{ParamDicRow: ParamDicRow{ModelId: 2, ParamId: 1, Name: "ageSex", Rank: 2, TypeId: 14},
+				Dim: []ParamDimsRow{{ModelId: 2, ParamId: 1, DimId: 0, Name: "dim0", TypeId: 102}, {ModelId: 2, ParamId: 1, DimId: 1, Name: "dim1", TypeId: 101}}},
+		},
+		Table: []TableMeta{
+			{TableDicRow: TableDicRow{ModelId: 2, TableId: 0, Name: "byAge", Rank: 1},
+				Dim:  []TableDimsRow{{ModelId: 2, TableId: 0, DimId: 0, Name: "dim0", TypeId: 102, DimSize: 3}},
+				Acc:  []TableAccRow{{ModelId: 2, TableId: 0, AccId: 0, Name: "acc0"}},
+				Expr: []TableExprRow{{ModelId: 2, TableId: 0, ExprId: 0, Name: "expr0"}}},
+			{TableDicRow: TableDicRow{ModelId: 2, TableId: 1, Name: "bySex", Rank: 1},
+				Dim:  []TableDimsRow{{ModelId: 2, TableId: 1, DimId: 0, Name: "dim0", TypeId: 101, IsTotal: true, DimSize: 3}},
+				Acc:  []TableAccRow{{ModelId: 2, TableId: 1, AccId: 0, Name: "acc0"}},
+				Expr: []TableExprRow{{ModelId: 2, TableId: 1, ExprId: 0, Name: "expr1"}, {ModelId: 2, TableId: 1, ExprId: 1, Name: "expr0"}}},
+		},
+	}
+	if err := srcModel.updateInternals(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dstModel.updateInternals(); err != nil {
+		t.Fatal(err)
+	}
+
+	rm, err := NewRunMapping(srcModel, dstModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ageSex: AGE enum item added, salary compatible, oldParam deleted
+	// byAge: compatible, new AGE item only in destination, bySex: expression added
+	if !rm.IsParam("salary") || rm.IsParam("ageSex") || rm.IsParam("oldParam") {
+		t.Errorf("invalid parameters mapping: salary: %v ageSex: %v oldParam: %v", rm.IsParam("salary"), rm.IsParam("ageSex"), rm.IsParam("oldParam"))
+	}
+	if !rm.IsTable("byAge") || rm.IsTable("bySex") {
+		t.Errorf("invalid output tables mapping: byAge: %v bySex: %v", rm.IsTable("byAge"), rm.IsTable("bySex"))
+	}
+
+	nk := map[string]int{}
+	for _, mi := range rm.Issue {
+		nk[mi.Kind]++
+		t.Log(mi.String())
+	}
+	if nk[MapEnumList] != 1 || nk[MapDeletedParam] != 1 || nk[MapExpression] != 1 || len(rm.Issue) != 3 {
+		t.Errorf("invalid mapping issues: %v", rm.Issue)
+	}
+
+	// salary[SEX]: M=0 => M=1
+	cvt, err := rm.ParamCell("salary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := cvt(CellParam{cellIdValue: cellIdValue{DimIds: []int{0}, Value: int64(100)}, SubId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc, ok := c.(CellParam); !ok || pc.DimIds[0] != 1 || pc.SubId != 1 || pc.Value != int64(100) {
+		t.Errorf("invalid parameter cell: %v", c)
+	}
+
+	// byAge[AGE]: AGE id's are the same
+	cvtAcc, err := rm.TableAccCell("byAge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = cvtAcc(CellAcc{cellIdValue: cellIdValue{DimIds: []int{1}, Value: 2.5}, AccId: 0, SubId: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ac, ok := c.(CellAcc); !ok || ac.DimIds[0] != 1 || ac.AccId != 0 || ac.SubId != 3 || ac.Value != 2.5 {
+		t.Errorf("invalid accumulator cell: %v", c)
+	}
+
+	// run metadata: ageSex is not compatible
+	pub := &RunPub{
+		ModelName:   "modelOne",
+		ModelDigest: "d1",
+		Name:        "Default",
+		ValueDigest: "v1",
+		Param: []ParamRunSetPub{
+			{ParamRunSetTxtPub: ParamRunSetTxtPub{Name: "ageSex"}, SubCount: 1},
+			{ParamRunSetTxtPub: ParamRunSetTxtPub{Name: "salary"}, SubCount: 2},
+			{ParamRunSetTxtPub: ParamRunSetTxtPub{Name: "oldParam"}, SubCount: 1},
+		},
+		Table: []TableRunPub{{Name: "byAge"}, {Name: "bySex"}},
+	}
+	if _, _, err = rm.RunPub(pub); err == nil {
+		t.Error("expected error: parameter ageSex is not compatible")
+	}
+
+	// fill not compatible ageSex from destination workset
+	rm.fillSetId = 1
+	rm.fill = map[string]ParamRunSetPub{"ageSex": {ParamRunSetTxtPub: ParamRunSetTxtPub{Name: "ageSex"}, SubCount: 1}}
+
+	fd, fLst, err := rm.RunPub(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fLst) != 1 || fLst[0] != "ageSex" || len(fd.Param) != 2 || fd.Param[1].Name != "ageSex" || fd.Param[1].SubCount != 1 {
+		t.Errorf("invalid filled parameters: %v: %v", fLst, fd.Param)
+	}
+
+	// make ageSex compatible: remove 30+ from destination AGE type, byAge still compatible
+	dstModel.Type[3].Enum = dstModel.Type[3].Enum[:2]
+
+	if rm, err = NewRunMapping(srcModel, dstModel); err != nil {
+		t.Fatal(err)
+	}
+	dst, fLst, err := rm.RunPub(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fLst) != 0 {
+		t.Errorf("invalid filled parameters: %v", fLst)
+	}
+	if dst.ModelDigest != dstModel.Model.Digest || dst.ValueDigest != "" || len(dst.Param) != 2 || dst.Param[0].Name != "salary" || dst.Param[0].SubCount != 2 ||
+		dst.Param[1].Name != "ageSex" || len(dst.Table) != 1 || dst.Table[0].Name != "byAge" {
+		t.Errorf("invalid mapped model run: %v", dst)
+	}
+}
@@ -0,0 +1,531 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SamplePub is "public" sampling plan of uncertain parameters for json import-export:
+// base model run or workset, sampling method, number of samples and distribution of each uncertain parameter value.
+//
+// One workset created for each sample and modeling task created from all sample worksets.
+// Workset names are: TaskName_1, TaskName_2,... TaskName_N.
+// Random seed and sampling plan are stored in task notes to reproduce the samples.
+type SamplePub struct {
+	ModelName   string        // model name
+	ModelDigest string        // model digest
+	Name        string        // modeling task name, also used as prefix of workset names
+	BaseRun     string        // if not empty then base model run digest, stamp or name
+	BaseSet     string        // if not empty then base workset name, workset must be read-only
+	Method      string        // sampling method: "random" (default), "lhs" Latin hypercube or "sobol" sequence
+	Count       int           // number of samples: number of worksets to create
+	Seed        int64         // random seed, if zero then it is generated, not used by Sobol sequence
+	Param       []SampleParam // uncertain parameters
+	Txt         []DescrNote   // task description and notes by language
+}
+
+// SampleParam is uncertain parameter value: distribution of the value for all parameter cells or for one parameter cell.
+// Only float or integer parameters can be sampled.
+type SampleParam struct {
+	Name     string      // parameter name
+	Dims     []string    // if not empty then dimension items codes of parameter cell else sample applied to all cells
+	IsFactor bool        // if true then sample is a multiplicative factor else it is parameter value
+	Distr    SampleDistr // distribution of parameter value or factor
+}
+
+// SampleDistr is a distribution of uncertain parameter value: uniform, normal, triangular or discrete.
+type SampleDistr struct {
+	Kind   string    // distribution: "uniform", "normal", "triangular" or "discrete"
+	Min    float64   // uniform and triangular: minimum value
+	Max    float64   // uniform and triangular: maximum value
+	Mode   float64   // triangular: most likely value
+	Mean   float64   // normal: mean
+	StdDev float64   // normal: standard deviation
+	Value  []float64 // discrete: list of values
+	Weight []float64 // discrete: weights of values, if empty then all values are equally likely
+}
+
+// sampling methods
+const (
+	SampleRandom = "random" // plain random (Monte Carlo) sampling
+	SampleLhs    = "lhs"    // Latin hypercube sampling
+	SampleSobol  = "sobol"  // Sobol quasi-random sequence
+)
+
+// distribution kinds
+const (
+	DistrUniform    = "uniform"    // uniform distribution between Min and Max
+	DistrNormal     = "normal"     // normal distribution with Mean and StdDev
+	DistrTriangular = "triangular" // triangular distribution between Min and Max with Mode
+	DistrDiscrete   = "discrete"   // discrete distribution of Value with Weight
+)
+
+// CreateSampleWorksets create one workset for each sample of uncertain parameters and return task definition with list of worksets.
+//
+// Workset parameters are copied from base workset or from base model run and sampled values or factors applied to parameter cells.
+// If base is a model run then workset contains only sampled parameters and all other parameters are from the base run.
+// If base is a workset then all parameters of base workset are copied and base run of new workset is the same.
+// If neither base run nor base set specified then default workset of the model used as base.
+// All created worksets are read-only, ready to run the model.
+// It is an error if any of sample worksets already exist.
+// If sample.Seed is zero then it is updated with generated seed value.
+// If validation rules not empty then worksets parameters values must satisfy the rules, otherwise ParamRuleError returned.
+func CreateSampleWorksets(dbConn *sql.DB, modelDef *ModelMeta, langDef *LangMeta, sample *SamplePub, rules []ParamRule) (*TaskDefPub, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata")
+	}
+	if langDef == nil {
+		return nil, errors.New("invalid (empty) language list")
+	}
+	if sample == nil || sample.Name == "" {
+		return nil, errors.New("invalid (empty) sampling plan name")
+	}
+	if len(sample.Param) <= 0 {
+		return nil, errors.New("invalid (empty) list of sample parameters: " + sample.Name)
+	}
+	if sample.Count <= 0 {
+		return nil, errors.New("invalid number of samples: " + strconv.Itoa(sample.Count) + ": " + sample.Name)
+	}
+	if sample.Method == "" {
+		sample.Method = SampleRandom
+	}
+	if sample.Method != SampleSobol && sample.Seed == 0 {
+		sample.Seed = time.Now().UnixNano()
+	}
+
+	// find sample parameters and make parameter cell filters
+	pmLst := make([]*ParamMeta, len(sample.Param))
+	whereLst := make([]string, len(sample.Param))
+
+	for k := range sample.Param {
+
+		i, ok := modelDef.ParamByName(sample.Param[k].Name)
+		if !ok {
+			return nil, errors.New("model: " + modelDef.Model.Name + " parameter " + sample.Param[k].Name + " not found")
+		}
+		pmLst[k] = &modelDef.Param[i]
+
+		if pmLst[k].typeOf == nil || !pmLst[k].typeOf.IsFloat() && !pmLst[k].typeOf.IsInt() {
+			return nil, errors.New("error: sample parameter must be float or integer type: " + pmLst[k].Name)
+		}
+		if err := sample.Param[k].Distr.check(); err != nil {
+			return nil, errors.New("invalid distribution of parameter: " + pmLst[k].Name + ": " + err.Error())
+		}
+
+		w, err := paramCellWhere(pmLst[k], sample.Param[k].Dims)
+		if err != nil {
+			return nil, err
+		}
+		whereLst[k] = w
+
+		for j := 0; j < k; j++ {
+			if pmLst[j].ParamHid == pmLst[k].ParamHid && whereLst[j] == whereLst[k] {
+				return nil, errors.New("error: sample parameter is not unique: " + sample.Param[k].Name + sampleCellLabel(sample.Param[k].Dims))
+			}
+		}
+	}
+
+	// draw samples and convert into parameter values or factors
+	uLst, err := SampleUnitPoints(sample.Method, sample.Count, len(sample.Param), sample.Seed)
+	if err != nil {
+		return nil, err
+	}
+	vLst := make([][]float64, sample.Count)
+
+	for n := range uLst {
+		vLst[n] = make([]float64, len(sample.Param))
+
+		for k := range sample.Param {
+			v := sample.Param[k].Distr.Quantile(uLst[n][k])
+			if !sample.Param[k].IsFactor && pmLst[k].typeOf.IsInt() {
+				v = math.Round(v)
+			}
+			vLst[n][k] = v
+		}
+	}
+
+	// find base model run or base workset, list of parameters to copy and check: sample worksets must not exist
+	baseRun, baseSet, err := findWorksetBase(dbConn, modelDef, sample.BaseRun, sample.BaseSet)
+	if err != nil {
+		return nil, err
+	}
+	hLst, err := worksetCopyParamList(dbConn, pmLst, baseSet)
+	if err != nil {
+		return nil, err
+	}
+	nameLst, err := makeNewWorksetNames(dbConn, modelDef, sample.Name, sample.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	// task description and notes: append sampling plan to the notes
+	plan, err := samplePlanNote(sample)
+	if err != nil {
+		return nil, err
+	}
+	txt := append([]DescrNote{}, sample.Txt...)
+	if len(txt) <= 0 {
+		txt = append(txt, DescrNote{LangCode: modelDef.Model.DefaultLangCode, Descr: sample.Name})
+	}
+	for k := range txt {
+		if txt[k].Note != "" {
+			txt[k].Note += "\n\n"
+		}
+		txt[k].Note += plan
+	}
+
+	// create all sample worksets in one transaction: if any of worksets failed then none created
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	// create workset for each sample
+	for n := range vLst {
+
+		// workset notes: list of sampled parameter values, for example: Age = 10, Ratio[Low,Male] x 1.5
+		nl := []string{}
+		uc := make([]paramCellUpdate, len(sample.Param))
+
+		for k := range sample.Param {
+			op := " = "
+			if sample.Param[k].IsFactor {
+				op = " x "
+			}
+			nl = append(nl, pmLst[k].Name+sampleCellLabel(sample.Param[k].Dims)+op+strconv.FormatFloat(vLst[n][k], 'g', -1, 64))
+
+			uc[k] = paramCellUpdate{pm: pmLst[k], where: whereLst[k], value: vLst[n][k], isFactor: sample.Param[k].IsFactor}
+		}
+		descr := sample.Name + ": sample " + strconv.Itoa(n+1) + " of " + strconv.Itoa(sample.Count)
+		note := strings.Join(nl, "\n")
+
+		wm := WorksetMeta{
+			Set: WorksetRow{
+				ModelId:    modelDef.Model.ModelId,
+				Name:       nameLst[n],
+				IsReadonly: false,
+			},
+			Txt:        []WorksetTxtRow{},
+			Param:      []worksetParam{},
+			ParamRules: rules,
+		}
+		if baseRun != nil {
+			wm.Set.BaseRunId = baseRun.RunId
+		}
+		if baseSet != nil {
+			wm.Set.BaseRunId = baseSet.BaseRunId
+		}
+		for _, dn := range txt {
+			wm.Txt = append(wm.Txt, WorksetTxtRow{LangCode: dn.LangCode, Descr: descr, Note: note})
+		}
+
+		if err = doCreateWorksetFromBase(trx, modelDef, langDef, &wm, hLst, baseRun, baseSet, uc, true); err != nil {
+			trx.Rollback()
+			return nil, errors.New("failed to create sample workset: " + nameLst[n] + ": " + err.Error())
+		}
+	}
+	if err = trx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// return task definition: list of sample worksets
+	return &TaskDefPub{
+		ModelName:   modelDef.Model.Name,
+		ModelDigest: modelDef.Model.Digest,
+		Name:        sample.Name,
+		Txt:         txt,
+		Set:         nameLst,
+	}, nil
+}
+
+// return sampling plan as task notes: method, number of samples, seed, base and parameters distributions as json
+func samplePlanNote(sample *SamplePub) (string, error) {
+
+	bt, err := json.Marshal(struct {
+		Method  string
+		Count   int
+		Seed    int64
+		BaseRun string
+		BaseSet string
+		Param   []SampleParam
+	}{
+		Method:  sample.Method,
+		Count:   sample.Count,
+		Seed:    sample.Seed,
+		BaseRun: sample.BaseRun,
+		BaseSet: sample.BaseSet,
+		Param:   sample.Param,
+	})
+	if err != nil {
+		return "", err
+	}
+	return "Sampling plan: " + string(bt), nil
+}
+
+// return parameter cell label, for example: [Low,Male] or empty string if it is all parameter cells
+func sampleCellLabel(dims []string) string {
+	if len(dims) <= 0 {
+		return ""
+	}
+	return "[" + strings.Join(dims, ",") + "]"
+}
+
+// return sql filter of parameter cell by dimension items codes, for example: dim0 = 1 AND dim1 = 2.
+// Return empty string if list of dimension items is empty: all parameter cells.
+func paramCellWhere(pm *ParamMeta, dims []string) (string, error) {
+
+	if len(dims) <= 0 {
+		return "", nil
+	}
+	if len(dims) != pm.Rank || len(dims) != len(pm.Dim) {
+		return "", errors.New("invalid number of dimensions of parameter cell: " + pm.Name + sampleCellLabel(dims))
+	}
+
+	wl := make([]string, len(dims))
+
+	for k := range dims {
+
+		cvt, err := pm.Dim[k].typeOf.itemCodeToId(pm.Name+"."+pm.Dim[k].Name, false)
+		if err != nil {
+			return "", err
+		}
+		id, err := cvt(dims[k])
+		if err != nil {
+			return "", err
+		}
+		wl[k] = pm.Dim[k].colName + " = " + strconv.Itoa(id)
+	}
+	return strings.Join(wl, " AND "), nil
+}
+
+// check distribution kind and arguments
+func (d *SampleDistr) check() error {
+
+	isFinite := func(v ...float64) bool {
+		for _, x := range v {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch d.Kind {
+	case DistrUniform:
+		if !isFinite(d.Min, d.Max) || d.Min > d.Max {
+			return errors.New("invalid uniform distribution, expected: Min <= Max")
+		}
+	case DistrNormal:
+		if !isFinite(d.Mean, d.StdDev) || d.StdDev < 0 {
+			return errors.New("invalid normal distribution, expected: StdDev >= 0")
+		}
+	case DistrTriangular:
+		if !isFinite(d.Min, d.Max, d.Mode) || d.Min > d.Mode || d.Mode > d.Max {
+			return errors.New("invalid triangular distribution, expected: Min <= Mode <= Max")
+		}
+	case DistrDiscrete:
+		if len(d.Value) <= 0 || !isFinite(d.Value...) {
+			return errors.New("invalid (empty) list of discrete distribution values")
+		}
+		if len(d.Weight) > 0 {
+			if len(d.Weight) != len(d.Value) {
+				return errors.New("invalid discrete distribution, number of weights must be the same as number of values")
+			}
+			sum := 0.0
+			for _, w := range d.Weight {
+				if !isFinite(w) || w < 0 {
+					return errors.New("invalid discrete distribution weight: " + strconv.FormatFloat(w, 'g', -1, 64))
+				}
+				sum += w
+			}
+			if sum <= 0 {
+				return errors.New("invalid discrete distribution, sum of weights must be positive")
+			}
+		}
+	default:
+		return errors.New("invalid distribution: " + d.Kind)
+	}
+	return nil
+}
+
+// Quantile return distribution value for probability u in [0, 1) interval: inverse of cumulative distribution function.
+func (d *SampleDistr) Quantile(u float64) float64 {
+
+	// keep probability away from 0 and 1 to avoid infinite values
+	const eps = 1.0e-12
+	if u < eps {
+		u = eps
+	}
+	if u > 1.0-eps {
+		u = 1.0 - eps
+	}
+
+	switch d.Kind {
+	case DistrUniform:
+		return d.Min + u*(d.Max-d.Min)
+
+	case DistrNormal:
+		return d.Mean + d.StdDev*math.Sqrt2*math.Erfinv(2.0*u-1.0)
+
+	case DistrTriangular:
+		w := d.Max - d.Min
+		if w <= 0 {
+			return d.Min
+		}
+		if u < (d.Mode-d.Min)/w {
+			return d.Min + math.Sqrt(u*w*(d.Mode-d.Min))
+		}
+		return d.Max - math.Sqrt((1.0-u)*w*(d.Max-d.Mode))
+
+	case DistrDiscrete:
+		if len(d.Value) <= 0 {
+			return math.NaN()
+		}
+		if len(d.Weight) != len(d.Value) {
+			return d.Value[int(u*float64(len(d.Value)))]
+		}
+		sum := 0.0
+		for _, w := range d.Weight {
+			sum += w
+		}
+		c := 0.0
+		for k, w := range d.Weight {
+			c += w
+			if u*sum < c {
+				return d.Value[k]
+			}
+		}
+		return d.Value[len(d.Value)-1]
+	}
+	return math.NaN()
+}
+
+// SampleUnitPoints return count points in [0, 1) unit hypercube of dimCount dimensions.
+// Method can be "random" for plain random sampling, "lhs" for Latin hypercube sampling or "sobol" for Sobol sequence.
+// Random seed is used by "random" and "lhs" methods, Sobol sequence starts from the first point after zero point.
+func SampleUnitPoints(method string, count, dimCount int, seed int64) ([][]float64, error) {
+
+	if count <= 0 || dimCount <= 0 {
+		return [][]float64{}, nil
+	}
+	pts := make([][]float64, count)
+	for n := range pts {
+		pts[n] = make([]float64, dimCount)
+	}
+
+	switch method {
+	case SampleRandom, "":
+		rnd := rand.New(rand.NewSource(seed))
+
+		for n := range pts {
+			for j := range pts[n] {
+				pts[n][j] = rnd.Float64()
+			}
+		}
+
+	case SampleLhs:
+		// each dimension divided into count strata and each stratum contains exactly one point
+		rnd := rand.New(rand.NewSource(seed))
+
+		for j := 0; j < dimCount; j++ {
+			perm := rnd.Perm(count)
+			for n := range pts {
+				pts[n][j] = (float64(perm[n]) + rnd.Float64()) / float64(count)
+			}
+		}
+
+	case SampleSobol:
+		if dimCount > 1+len(sobolDirInit) {
+			return nil, errors.New("too many parameters for Sobol sequence: " + strconv.Itoa(dimCount) + ", maximum: " + strconv.Itoa(1+len(sobolDirInit)))
+		}
+		sobolPoints(pts)
+
+	default:
+		return nil, errors.New("invalid sampling method: " + method)
+	}
+	return pts, nil
+}
+
+// Sobol sequence primitive polynomials and initial direction numbers for dimensions 2,3,...
+// from S. Joe and F. Y. Kuo: degree of polynomial s, polynomial coefficients a and initial direction numbers m.
+var sobolDirInit = []struct {
+	s int
+	a uint32
+	m []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+	{3, 2, []uint32{1, 1, 1}},
+	{4, 1, []uint32{1, 1, 3, 3}},
+	{4, 4, []uint32{1, 3, 5, 13}},
+	{5, 2, []uint32{1, 1, 5, 5, 17}},
+	{5, 4, []uint32{1, 1, 5, 5, 5}},
+	{5, 7, []uint32{1, 1, 7, 11, 19}},
+	{5, 11, []uint32{1, 1, 5, 1, 1}},
+	{5, 13, []uint32{1, 1, 1, 3, 11}},
+	{5, 14, []uint32{1, 3, 5, 5, 31}},
+	{6, 1, []uint32{1, 3, 3, 9, 7, 49}},
+	{6, 13, []uint32{1, 1, 1, 15, 21, 21}},
+	{6, 16, []uint32{1, 3, 1, 13, 27, 49}},
+	{6, 19, []uint32{1, 1, 1, 15, 7, 5}},
+	{6, 22, []uint32{1, 3, 1, 15, 13, 25}},
+	{6, 25, []uint32{1, 1, 5, 5, 19, 61}},
+	{7, 1, []uint32{1, 3, 7, 11, 23, 15, 103}},
+	{7, 4, []uint32{1, 3, 7, 13, 13, 15, 69}},
+}
+
+// fill points by Sobol sequence using Gray code order, zero point of the sequence is skipped
+func sobolPoints(pts [][]float64) {
+
+	const nBits = 32
+	dimCount := len(pts[0])
+
+	// direction numbers v[j][i], i = 1,...,nBits
+	v := make([][nBits + 1]uint32, dimCount)
+
+	for i := 1; i <= nBits; i++ {
+		v[0][i] = 1 << (nBits - i)
+	}
+	for j := 1; j < dimCount; j++ {
+
+		s, a, m := sobolDirInit[j-1].s, sobolDirInit[j-1].a, sobolDirInit[j-1].m
+
+		for i := 1; i <= nBits; i++ {
+			if i <= s {
+				v[j][i] = m[i-1] << (nBits - i)
+				continue
+			}
+			v[j][i] = v[j][i-s] ^ (v[j][i-s] >> s)
+			for k := 1; k < s; k++ {
+				v[j][i] ^= ((a >> (s - 1 - k)) & 1) * v[j][i-k]
+			}
+		}
+	}
+
+	// next point: x[n+1] = x[n] xor v[c], where c is index of rightmost zero bit of n
+	x := make([]uint32, dimCount)
+
+	for n := range pts {
+
+		c := 1
+		for k := n; k&1 == 1; k >>= 1 {
+			c++
+		}
+		for j := 0; j < dimCount; j++ {
+			x[j] ^= v[j][c]
+			pts[n][j] = float64(x[j]) / (1 << nBits)
+		}
+	}
+}
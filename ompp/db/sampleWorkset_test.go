@@ -0,0 +1,110 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSampleUnitPoints(t *testing.T) {
+
+	// random and Latin hypercube samples must be the same for the same seed
+	for _, method := range []string{SampleRandom, SampleLhs} {
+
+		p1, err := SampleUnitPoints(method, 10, 3, 12345)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		p2, _ := SampleUnitPoints(method, 10, 3, 12345)
+		if !reflect.DeepEqual(p1, p2) {
+			t.Errorf("%s: expected the same points for the same seed", method)
+		}
+		for n := range p1 {
+			for j := range p1[n] {
+				if p1[n][j] < 0 || p1[n][j] >= 1 {
+					t.Errorf("%s: point out of [0, 1) interval: %v", method, p1[n][j])
+				}
+			}
+		}
+	}
+
+	// Latin hypercube: each stratum of each dimension contains exactly one point
+	pts, _ := SampleUnitPoints(SampleLhs, 8, 2, 7)
+	for j := 0; j < 2; j++ {
+		isUsed := make([]bool, 8)
+		for n := range pts {
+			s := int(pts[n][j] * 8)
+			if isUsed[s] {
+				t.Errorf("lhs: stratum %d of dimension %d contains more than one point", s, j)
+			}
+			isUsed[s] = true
+		}
+	}
+
+	// Sobol sequence: first points of first two dimensions
+	pts, err := SampleUnitPoints(SampleSobol, 4, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]float64{{0.5, 0.5}, {0.75, 0.25}, {0.25, 0.75}, {0.375, 0.375}}
+	if !reflect.DeepEqual(pts, expected) {
+		t.Errorf("sobol: expected: %v result: %v", expected, pts)
+	}
+
+	if _, err = SampleUnitPoints(SampleSobol, 4, 100, 0); err == nil {
+		t.Error("sobol: expected error for too many dimensions")
+	}
+	if _, err = SampleUnitPoints("unknown", 4, 2, 0); err == nil {
+		t.Error("expected error for invalid sampling method")
+	}
+}
+
+func TestSampleDistrQuantile(t *testing.T) {
+
+	d := SampleDistr{Kind: DistrUniform, Min: 10, Max: 20}
+	if v := d.Quantile(0.25); v != 12.5 {
+		t.Errorf("uniform: expected 12.5 result: %v", v)
+	}
+
+	d = SampleDistr{Kind: DistrNormal, Mean: 5, StdDev: 2}
+	if v := d.Quantile(0.5); math.Abs(v-5) > 1.0e-9 {
+		t.Errorf("normal: expected 5 result: %v", v)
+	}
+	if v := d.Quantile(0.975); math.Abs(v-(5+2*1.959964)) > 1.0e-5 {
+		t.Errorf("normal: expected 8.919928 result: %v", v)
+	}
+	if v := d.Quantile(0); math.IsInf(v, 0) || math.IsNaN(v) {
+		t.Errorf("normal: expected finite value result: %v", v)
+	}
+
+	d = SampleDistr{Kind: DistrTriangular, Min: 0, Mode: 1, Max: 2}
+	if v := d.Quantile(0.5); math.Abs(v-1) > 1.0e-9 {
+		t.Errorf("triangular: expected 1 result: %v", v)
+	}
+	if v := d.Quantile(0.125); math.Abs(v-0.5) > 1.0e-9 {
+		t.Errorf("triangular: expected 0.5 result: %v", v)
+	}
+
+	d = SampleDistr{Kind: DistrDiscrete, Value: []float64{1, 2, 3}, Weight: []float64{1, 2, 1}}
+	for _, c := range []struct{ u, v float64 }{{0.1, 1}, {0.3, 2}, {0.7, 2}, {0.9, 3}} {
+		if v := d.Quantile(c.u); v != c.v {
+			t.Errorf("discrete: expected %v result: %v at %v", c.v, v, c.u)
+		}
+	}
+
+	for _, bad := range []SampleDistr{
+		{Kind: DistrUniform, Min: 2, Max: 1},
+		{Kind: DistrNormal, StdDev: -1},
+		{Kind: DistrTriangular, Min: 0, Mode: 3, Max: 2},
+		{Kind: DistrDiscrete},
+		{Kind: DistrDiscrete, Value: []float64{1, 2}, Weight: []float64{1}},
+		{Kind: "unknown"},
+	} {
+		if err := bad.check(); err == nil {
+			t.Errorf("expected invalid distribution: %v", bad)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SweepPub is "public" parameter sweep for json import-export:
+// base model run or workset and list of parameters to vary.
+//
+// One workset created for each combination of parameter values and modeling task created from all sweep worksets.
+// Workset names are: TaskName_1, TaskName_2,... TaskName_N.
+// If IsOneAtTime is true then only one parameter changed in each workset and all other parameters are the same as base
+// else it is full factorial sweep and each workset is a combination of parameter values.
+type SweepPub struct {
+	ModelName   string       // model name
+	ModelDigest string       // model digest
+	Name        string       // modeling task name, also used as prefix of workset names
+	BaseRun     string       // if not empty then base model run digest, stamp or name
+	BaseSet     string       // if not empty then base workset name, workset must be read-only
+	IsOneAtTime bool         // if true then one-at-a-time sweep else full factorial
+	Param       []SweepParam // parameters to vary
+	Txt         []DescrNote  // task description and notes by language
+}
+
+// SweepParam is parameter to vary: list or range of parameter values or multiplicative factors.
+// Value is assigned to all parameter cells and factor is applied to all parameter cells.
+// Only float or integer parameters can be used in the sweep.
+type SweepParam struct {
+	Name     string    // parameter name
+	IsFactor bool      // if true then values are multiplicative factors else it is parameter values
+	Value    []float64 // list of values or factors
+	From     float64   // range start value, used only if Count is positive
+	To       float64   // range end value, used only if Count is positive
+	Count    int       // if positive then number of values in range from From to To, inclusive
+}
+
+// Levels return sweep parameter levels: list of values if not empty or range of values from From to To.
+func (sp *SweepParam) Levels() []float64 {
+
+	if len(sp.Value) > 0 {
+		return append([]float64{}, sp.Value...)
+	}
+	if sp.Count <= 0 {
+		return []float64{}
+	}
+	if sp.Count == 1 {
+		return []float64{sp.From}
+	}
+
+	lv := make([]float64, sp.Count)
+	for k := 0; k < sp.Count; k++ {
+		lv[k] = sp.From + (sp.To-sp.From)*float64(k)/float64(sp.Count-1)
+	}
+	return lv
+}
+
+// SweepCombinations return list of sweep combinations as index of level for each parameter.
+// If isOneAtTime is true then level index is -1 for each parameter which is not changed (same as base).
+// If isOneAtTime is false then it is full factorial: all combinations of all parameter levels.
+func SweepCombinations(levelCount []int, isOneAtTime bool) [][]int {
+
+	cLst := [][]int{}
+	if len(levelCount) <= 0 {
+		return cLst
+	}
+	for _, n := range levelCount {
+		if n <= 0 {
+			return cLst
+		}
+	}
+
+	// one-at-a-time: for each parameter for each level all other parameters are the same as base
+	if isOneAtTime {
+		for k, n := range levelCount {
+			for j := 0; j < n; j++ {
+				c := make([]int, len(levelCount))
+				for i := range c {
+					c[i] = -1
+				}
+				c[k] = j
+				cLst = append(cLst, c)
+			}
+		}
+		return cLst
+	}
+
+	// full factorial: iterate over all combinations, last parameter is changing first
+	c := make([]int, len(levelCount))
+	for {
+		cLst = append(cLst, append([]int{}, c...))
+
+		k := len(c) - 1
+		for ; k >= 0; k-- {
+			c[k]++
+			if c[k] < levelCount[k] {
+				break
+			}
+			c[k] = 0
+		}
+		if k < 0 {
+			break
+		}
+	}
+	return cLst
+}
+
+// CreateSweepWorksets create one workset for each combination of sweep parameters and return task definition with list of worksets.
+//
+// Workset parameters are copied from base workset or from base model run and sweep values or factors applied to parameter cells.
+// If base is a model run then workset contains only sweep parameters and all other parameters are from the base run.
+// If base is a workset then all parameters of base workset are copied and base run of new workset is the same.
+// If neither base run nor base set specified then default workset of the model used as base.
+// All created worksets are read-only, ready to run the model.
+// If validation rules not empty then worksets parameters values must satisfy the rules, otherwise ParamRuleError returned.
+// It is an error if any of sweep worksets already exist.
+func CreateSweepWorksets(dbConn *sql.DB, modelDef *ModelMeta, langDef *LangMeta, sweep *SweepPub, rules []ParamRule) (*TaskDefPub, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata")
+	}
+	if langDef == nil {
+		return nil, errors.New("invalid (empty) language list")
+	}
+	if sweep == nil || sweep.Name == "" {
+		return nil, errors.New("invalid (empty) parameter sweep name")
+	}
+	if len(sweep.Param) <= 0 {
+		return nil, errors.New("invalid (empty) list of sweep parameters: " + sweep.Name)
+	}
+	if sweep.BaseRun != "" && sweep.BaseSet != "" {
+		return nil, errors.New("invalid sweep base, it must be either model run or workset: " + sweep.Name)
+	}
+
+	// find sweep parameters and make levels: list of values or factors
+	pmLst := make([]*ParamMeta, len(sweep.Param))
+	levels := make([][]float64, len(sweep.Param))
+	nLevels := make([]int, len(sweep.Param))
+
+	for k := range sweep.Param {
+
+		i, ok := modelDef.ParamByName(sweep.Param[k].Name)
+		if !ok {
+			return nil, errors.New("model: " + modelDef.Model.Name + " parameter " + sweep.Param[k].Name + " not found")
+		}
+		pmLst[k] = &modelDef.Param[i]
+
+		for j := 0; j < k; j++ {
+			if pmLst[j].ParamHid == pmLst[k].ParamHid {
+				return nil, errors.New("error: sweep parameter is not unique: " + sweep.Param[k].Name)
+			}
+		}
+
+		if err := checkSweepParam(pmLst[k], &sweep.Param[k]); err != nil {
+			return nil, err
+		}
+		levels[k] = sweep.Param[k].Levels()
+		nLevels[k] = len(levels[k])
+	}
+
+	cLst := SweepCombinations(nLevels, sweep.IsOneAtTime)
+	if len(cLst) <= 0 {
+		return nil, errors.New("invalid (empty) parameter sweep: " + sweep.Name)
+	}
+
+	// find base model run or base workset, list of parameters to copy and check: sweep worksets must not exist
+	baseRun, baseSet, err := findWorksetBase(dbConn, modelDef, sweep.BaseRun, sweep.BaseSet)
+	if err != nil {
+		return nil, err
+	}
+	hLst, err := worksetCopyParamList(dbConn, pmLst, baseSet)
+	if err != nil {
+		return nil, err
+	}
+	nameLst, err := makeNewWorksetNames(dbConn, modelDef, sweep.Name, len(cLst))
+	if err != nil {
+		return nil, err
+	}
+
+	// create all sweep worksets in one transaction: if any of worksets failed then none created
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	// create workset for each combination of sweep parameter values
+	for k, c := range cLst {
+
+		// workset description: list of sweep parameter values, for example: Name: Age = 10, Ratio x 1.5
+		dl := []string{}
+		for j := range c {
+			if c[j] < 0 {
+				continue
+			}
+			op := " = "
+			if sweep.Param[j].IsFactor {
+				op = " x "
+			}
+			dl = append(dl, pmLst[j].Name+op+strconv.FormatFloat(levels[j][c[j]], 'g', -1, 64))
+		}
+		descr := sweep.Name + ": " + strings.Join(dl, ", ")
+
+		wm := WorksetMeta{
+			Set: WorksetRow{
+				ModelId:    modelDef.Model.ModelId,
+				Name:       nameLst[k],
+				IsReadonly: false,
+			},
+			Txt:        []WorksetTxtRow{},
+			Param:      []worksetParam{},
+			ParamRules: rules,
+		}
+		if baseRun != nil {
+			wm.Set.BaseRunId = baseRun.RunId
+		}
+		if baseSet != nil {
+			wm.Set.BaseRunId = baseSet.BaseRunId
+		}
+		for _, dn := range sweep.Txt {
+			wm.Txt = append(wm.Txt, WorksetTxtRow{LangCode: dn.LangCode, Descr: descr})
+		}
+
+		// sweep values or factors applied to all parameter cells
+		uLst := []paramCellUpdate{}
+		for j := range c {
+			if c[j] >= 0 {
+				uLst = append(uLst, paramCellUpdate{pm: pmLst[j], value: levels[j][c[j]], isFactor: sweep.Param[j].IsFactor})
+			}
+		}
+
+		if err = doCreateWorksetFromBase(trx, modelDef, langDef, &wm, hLst, baseRun, baseSet, uLst, true); err != nil {
+			trx.Rollback()
+			return nil, errors.New("failed to create sweep workset: " + nameLst[k] + ": " + err.Error())
+		}
+	}
+	if err = trx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// return task definition: list of sweep worksets
+	return &TaskDefPub{
+		ModelName:   modelDef.Model.Name,
+		ModelDigest: modelDef.Model.Digest,
+		Name:        sweep.Name,
+		Txt:         sweep.Txt,
+		Set:         nameLst,
+	}, nil
+}
+
+// check if parameter can be used in the sweep: it must be float or integer parameter with non-empty list of levels
+func checkSweepParam(pm *ParamMeta, sp *SweepParam) error {
+
+	if pm.typeOf == nil || !pm.typeOf.IsFloat() && !pm.typeOf.IsInt() {
+		return errors.New("error: sweep parameter must be float or integer type: " + pm.Name)
+	}
+
+	lv := sp.Levels()
+	if len(lv) <= 0 {
+		return errors.New("invalid (empty) list of sweep values: " + pm.Name)
+	}
+	for _, v := range lv {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("invalid sweep value: " + pm.Name + ": " + strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		if !sp.IsFactor && pm.typeOf.IsInt() && v != math.Trunc(v) {
+			return errors.New("invalid sweep value, integer expected: " + pm.Name + ": " + strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	}
+	return nil
+}
+
+// make list of new workset names: Name_1, Name_2,... Name_N and return error if any of worksets already exist
+func makeNewWorksetNames(dbConn *sql.DB, modelDef *ModelMeta, name string, count int) ([]string, error) {
+
+	nameLst := make([]string, count)
+
+	for k := 0; k < count; k++ {
+
+		nameLst[k] = name + "_" + strconv.Itoa(k+1)
+
+		ws, err := GetWorksetByName(dbConn, modelDef.Model.ModelId, nameLst[k])
+		if err != nil {
+			return nil, err
+		}
+		if ws != nil {
+			return nil, errors.New("error: workset already exist: " + nameLst[k])
+		}
+	}
+	return nameLst, nil
+}
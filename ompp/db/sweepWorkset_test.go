@@ -0,0 +1,59 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSweepParamLevels(t *testing.T) {
+
+	sp := SweepParam{Name: "p", Value: []float64{1, 2.5}}
+	if lv := sp.Levels(); !reflect.DeepEqual(lv, []float64{1, 2.5}) {
+		t.Errorf("fail to get list of values: %v", lv)
+	}
+
+	sp = SweepParam{Name: "p", From: 10, To: 20, Count: 5}
+	if lv := sp.Levels(); !reflect.DeepEqual(lv, []float64{10, 12.5, 15, 17.5, 20}) {
+		t.Errorf("fail to get range of values: %v", lv)
+	}
+
+	sp = SweepParam{Name: "p", From: 3, To: 7, Count: 1}
+	if lv := sp.Levels(); !reflect.DeepEqual(lv, []float64{3}) {
+		t.Errorf("fail to get range of one value: %v", lv)
+	}
+
+	sp = SweepParam{Name: "p"}
+	if lv := sp.Levels(); len(lv) != 0 {
+		t.Errorf("expected empty list of values: %v", lv)
+	}
+}
+
+func TestSweepCombinations(t *testing.T) {
+
+	c := SweepCombinations([]int{2, 3}, false)
+	expected := [][]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("full factorial: expected: %v result: %v", expected, c)
+	}
+
+	c = SweepCombinations([]int{2, 3}, true)
+	expected = [][]int{{0, -1}, {1, -1}, {-1, 0}, {-1, 1}, {-1, 2}}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("one-at-a-time: expected: %v result: %v", expected, c)
+	}
+
+	c = SweepCombinations([]int{3, 5, 5}, false)
+	if len(c) != 75 {
+		t.Errorf("full factorial: expected 75 combinations, result: %d", len(c))
+	}
+
+	if c = SweepCombinations([]int{2, 0}, false); len(c) != 0 {
+		t.Errorf("expected empty result if any parameter has no values: %v", c)
+	}
+	if c = SweepCombinations([]int{}, true); len(c) != 0 {
+		t.Errorf("expected empty result for empty list of parameters: %v", c)
+	}
+}
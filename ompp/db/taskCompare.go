@@ -0,0 +1,433 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// TaskComparePub is scenario matrix of modeling task run results:
+// output table expression or calculation value of each scenario model run lined up by output table cells,
+// and values of input parameters which are different across scenarios.
+//
+// Calculation can be output expression name, ex.: Expr0, or calculation of expressions, ex.: Expr0 / Expr1,
+// or comparison with baseline run, ex.: Expr0[variant] - Expr0[base].
+type TaskComparePub struct {
+	ModelName     string                // model name
+	ModelDigest   string                // model digest
+	TaskName      string                // modeling task name
+	TaskRunName   string                // task run name
+	TaskRunStamp  string                // task run stamp
+	TableName     string                // output table name
+	Calc          string                // output expression name or calculation, ex.: Expr0[variant] - Expr0[base]
+	BaseRunDigest string                // if not empty then digest of baseline run of comparison
+	Param         []string              // names of input parameters which are different across scenarios
+	Dim           []string              // output table dimension names
+	Scenario      []TaskCompareScenario // scenarios: successfully completed model runs of task run
+	Row           []TaskCompareRow      // output table cells: dimension items and value of each scenario
+}
+
+// TaskCompareScenario is a scenario of modeling task run: input workset, model run and values of different parameters.
+//
+// Parameter value is a value of scalar parameter, average of all cells of other numeric parameter
+// or parameter value digest if it is not scalar and not numeric parameter.
+type TaskCompareScenario struct {
+	SetName    string   // input workset name, empty if model run does not have input workset
+	RunName    string   // model run name
+	RunDigest  string   // model run digest
+	ParamValue []string // values of input parameters which are different across scenarios
+}
+
+// TaskCompareRow is output table cell: dimension items and value of each scenario
+type TaskCompareRow struct {
+	Dims   []string  // dimension item codes
+	IsNull []bool    // if true then scenario value is NULL or not found
+	Value  []float64 // value of each scenario
+}
+
+// GetTaskCompare return scenario matrix of modeling task run results.
+//
+// Task run selected by task run stamp or name, if trsn is empty then last completed task run is used.
+// Only successfully completed model runs of task run are included as scenarios.
+// If calculation is empty then first output table expression is used.
+// If calculation contains [base] or [variant] then it is comparison with baseline run:
+// baseline run selected by baseRdsn digest, stamp or name, if baseRdsn is empty
+// then it is the base run of all task worksets or, if worksets have different base runs, first scenario model run.
+// If baseline run is one of scenarios then its values are comparison of baseline run with itself.
+func GetTaskCompare(dbConn *sql.DB, modelDef *ModelMeta, taskName, trsn, tableName, calc, baseRdsn string) (*TaskComparePub, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata")
+	}
+	if taskName == "" {
+		return nil, errors.New("invalid (empty) modeling task name")
+	}
+	if tableName == "" {
+		return nil, errors.New("invalid (empty) output table name")
+	}
+
+	k, ok := modelDef.OutTableByName(tableName)
+	if !ok {
+		return nil, errors.New("output table not found: " + tableName)
+	}
+	table := &modelDef.Table[k]
+
+	if calc == "" {
+		if len(table.Expr) <= 0 {
+			return nil, errors.New("output table does not have expressions: " + tableName)
+		}
+		calc = table.Expr[0].Name
+	}
+	isCompare := strings.Contains(calc, "[base]") || strings.Contains(calc, "[variant]")
+
+	// find modeling task run and successfully completed model runs of task run
+	trr, err := getCompletedTaskRun(dbConn, modelDef, taskName, trsn)
+	if err != nil {
+		return nil, err
+	}
+	trsLst, err := GetTaskRunSetRows(dbConn, trr.TaskRunId)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &TaskComparePub{
+		ModelName:    modelDef.Model.Name,
+		ModelDigest:  modelDef.Model.Digest,
+		TaskName:     taskName,
+		TaskRunName:  trr.Name,
+		TaskRunStamp: trr.RunStamp,
+		TableName:    tableName,
+		Calc:         calc,
+		Param:        []string{},
+		Dim:          make([]string, table.Rank),
+		Scenario:     []TaskCompareScenario{},
+		Row:          []TaskCompareRow{},
+	}
+	for j := range table.Dim {
+		tc.Dim[j] = table.Dim[j].Name
+	}
+
+	runLst := []*RunRow{}
+	setIds := []int{}
+	baseRunId := -1
+
+	for _, trs := range trsLst {
+
+		if trs.RunId <= 0 {
+			continue
+		}
+		r, err := GetRun(dbConn, trs.RunId)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil || r.Status != DoneRunStatus {
+			continue
+		}
+		runLst = append(runLst, r)
+
+		sc := TaskCompareScenario{RunName: r.Name, RunDigest: r.RunDigest, ParamValue: []string{}}
+
+		var ws *WorksetRow
+		if trs.SetId > 0 {
+			if ws, err = GetWorkset(dbConn, trs.SetId); err != nil {
+				return nil, err
+			}
+		}
+		if ws != nil {
+			sc.SetName = ws.Name
+			setIds = append(setIds, ws.SetId)
+		}
+		tc.Scenario = append(tc.Scenario, sc)
+
+		switch {
+		case ws == nil || ws.BaseRunId <= 0:
+			baseRunId = 0
+		case baseRunId < 0:
+			baseRunId = ws.BaseRunId
+		case baseRunId != ws.BaseRunId:
+			baseRunId = 0
+		}
+	}
+	if len(runLst) <= 0 {
+		return nil, errors.New("there are no successfully completed model runs in modeling task run: " + taskName + ": " + trr.Name)
+	}
+
+	// baseline run of comparison: by digest, stamp or name or base run of all task worksets or first scenario run
+	fromId := runLst[0].RunId
+
+	if isCompare {
+
+		var baseRun *RunRow
+		switch {
+		case baseRdsn != "":
+			if baseRun, err = GetRunByDigestStampName(dbConn, modelDef.Model.ModelId, baseRdsn); err != nil {
+				return nil, err
+			}
+			if baseRun == nil {
+				return nil, errors.New("baseline model run not found: " + baseRdsn)
+			}
+		case baseRunId > 0:
+			if baseRun, err = GetRun(dbConn, baseRunId); err != nil {
+				return nil, err
+			}
+		}
+		if baseRun == nil || baseRun.Status != DoneRunStatus {
+			if baseRdsn != "" {
+				return nil, errors.New("baseline model run is not completed successfully: " + baseRdsn)
+			}
+			baseRun = runLst[0]
+		}
+		fromId = baseRun.RunId
+		tc.BaseRunDigest = baseRun.RunDigest
+	}
+
+	// find input parameters which are different across scenarios
+	if err = taskCompareParams(dbConn, modelDef, setIds, runLst, tc); err != nil {
+		return nil, err
+	}
+
+	// calculate output table values of all scenarios,
+	// if baseline run is one of scenarios then it is compared with itself, ex.: Expr0[base] - Expr0[base]
+	runIds := make([]int, len(runLst))
+	for k, r := range runLst {
+		runIds[k] = r.RunId
+	}
+	tableLt := ReadCalculteTableLayout{
+		ReadLayout: ReadLayout{Name: tableName, FromId: fromId},
+		Calculation: []CalculateTableLayout{{
+			CalculateLayout: CalculateLayout{Calculate: calc, CalcId: CALCULATED_ID_OFFSET, Name: "calc"},
+		}},
+	}
+
+	cLst, _, err := CalculateOutputTable(dbConn, modelDef, &tableLt, runIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// dimension items converters from id to code
+	fd := make([]func(itemId int) (string, error), table.Rank)
+
+	for j := 0; j < table.Rank; j++ {
+		f, err := table.Dim[j].typeOf.itemIdToCode(tableName+"."+table.Dim[j].Name, table.Dim[j].IsTotal)
+		if err != nil {
+			return nil, err
+		}
+		fd[j] = f
+	}
+
+	// line up scenario values by output table cells
+	scIdx := map[int]int{}
+	for n, r := range runLst {
+		scIdx[r.RunId] = n
+	}
+	rowIdx := map[string]int{}
+
+	for e := cLst.Front(); e != nil; e = e.Next() {
+
+		c, ok := e.Value.(CellTableCalc)
+		if !ok {
+			return nil, errors.New("invalid type, expected: output table calculated cell (internal error): " + tableName)
+		}
+		n, ok := scIdx[c.RunId]
+		if !ok || c.CalcId != CALCULATED_ID_OFFSET {
+			continue // it is not a scenario model run, ex.: baseline run
+		}
+
+		key := ""
+		for j := range c.DimIds {
+			key += strconv.Itoa(c.DimIds[j]) + ","
+		}
+
+		i, ok := rowIdx[key]
+		if !ok {
+
+			row := TaskCompareRow{
+				Dims:   make([]string, table.Rank),
+				IsNull: make([]bool, len(runLst)),
+				Value:  make([]float64, len(runLst)),
+			}
+			for j := range c.DimIds {
+				if row.Dims[j], err = fd[j](c.DimIds[j]); err != nil {
+					return nil, err
+				}
+			}
+			for j := range row.IsNull {
+				row.IsNull[j] = true
+			}
+
+			i = len(tc.Row)
+			rowIdx[key] = i
+			tc.Row = append(tc.Row, row)
+		}
+		tc.Row[i].IsNull[n] = c.IsNull
+		if fv, ok := c.Value.(float64); ok {
+			tc.Row[i].Value[n] = fv
+		}
+	}
+
+	return tc, nil
+}
+
+// find input parameters of task worksets which are different across scenario model runs
+// and append parameter names and scenario parameter values to the task comparison
+func taskCompareParams(dbConn *sql.DB, modelDef *ModelMeta, setIds []int, runLst []*RunRow, tc *TaskComparePub) error {
+
+	// list of parameters included in task worksets
+	pmLst := []*ParamMeta{}
+
+	for _, sId := range setIds {
+
+		hLst, _, _, err := GetWorksetParamList(dbConn, sId)
+		if err != nil {
+			return err
+		}
+		for _, h := range hLst {
+
+			i, ok := modelDef.ParamByHid(h)
+			if !ok {
+				return errors.New("parameter not found, id: " + strconv.Itoa(h))
+			}
+			isFound := false
+			for j := 0; !isFound && j < len(pmLst); j++ {
+				isFound = pmLst[j].ParamHid == h
+			}
+			if !isFound {
+				pmLst = append(pmLst, &modelDef.Param[i])
+			}
+		}
+	}
+
+	// parameter is different if value digest is different in scenario model runs
+	for _, pm := range pmLst {
+
+		dgst := make([]string, len(runLst))
+		isDiff := false
+
+		for k, r := range runLst {
+
+			var sd sql.NullString
+			err := SelectFirst(dbConn,
+				"SELECT value_digest FROM run_parameter WHERE run_id = "+strconv.Itoa(r.RunId)+" AND parameter_hid = "+strconv.Itoa(pm.ParamHid),
+				func(row *sql.Row) error {
+					return row.Scan(&sd)
+				})
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			dgst[k] = sd.String
+			isDiff = isDiff || dgst[k] != dgst[0]
+		}
+		if !isDiff {
+			continue // parameter is the same in all scenarios
+		}
+
+		// parameter value of each scenario
+		tc.Param = append(tc.Param, pm.Name)
+
+		for k, r := range runLst {
+
+			v := dgst[k]
+			switch {
+			case pm.Rank <= 0:
+				s, err := selectRunParamScalar(dbConn, pm, r.RunId)
+				if err != nil {
+					return err
+				}
+				v = s
+			case pm.typeOf.IsBuiltIn() && (pm.typeOf.IsFloat() || pm.typeOf.IsInt()):
+				avg, isOk, err := selectRunParamAvg(dbConn, pm, r.RunId)
+				if err != nil {
+					return err
+				}
+				if isOk {
+					v = strconv.FormatFloat(avg, 'g', -1, 64)
+				}
+			}
+			tc.Scenario[k].ParamValue = append(tc.Scenario[k].ParamValue, v)
+		}
+	}
+	return nil
+}
+
+// return value of scalar parameter in model run as string, enum-based values converted to enum codes.
+// If parameter has multiple sub-values then value of the first sub-value returned.
+// Empty "" string returned if value is NULL or not found.
+func selectRunParamScalar(dbConn *sql.DB, pm *ParamMeta, runId int) (string, error) {
+
+	q := "SELECT param_value FROM " + pm.DbRunTable +
+		" WHERE run_id =" +
+		" (SELECT base_run_id FROM run_parameter WHERE run_id = " + strconv.Itoa(runId) + " AND parameter_hid = " + strconv.Itoa(pm.ParamHid) + ")" +
+		" ORDER BY sub_id"
+
+	v := ""
+	var err error
+
+	switch {
+	case pm.typeOf.IsBool() || !pm.typeOf.IsBuiltIn():
+
+		cvt, e := pm.typeOf.itemIdToCode(pm.Name, false)
+		if e != nil {
+			return "", e
+		}
+		var iv sql.NullInt64
+		err = SelectFirst(dbConn, q, func(row *sql.Row) error {
+			if e := row.Scan(&iv); e != nil {
+				return e
+			}
+			if iv.Valid {
+				s, e := cvt(int(iv.Int64))
+				if e != nil {
+					return e
+				}
+				v = s
+			}
+			return nil
+		})
+
+	case pm.typeOf.IsFloat():
+
+		var fv sql.NullFloat64
+		err = SelectFirst(dbConn, q, func(row *sql.Row) error {
+			if e := row.Scan(&fv); e != nil {
+				return e
+			}
+			if fv.Valid {
+				v = strconv.FormatFloat(fv.Float64, 'g', -1, 64)
+			}
+			return nil
+		})
+
+	case pm.typeOf.IsInt():
+
+		var iv sql.NullInt64
+		err = SelectFirst(dbConn, q, func(row *sql.Row) error {
+			if e := row.Scan(&iv); e != nil {
+				return e
+			}
+			if iv.Valid {
+				v = strconv.FormatInt(iv.Int64, 10)
+			}
+			return nil
+		})
+
+	default:
+
+		var sv sql.NullString
+		err = SelectFirst(dbConn, q, func(row *sql.Row) error {
+			if e := row.Scan(&sv); e != nil {
+				return e
+			}
+			v = sv.String
+			return nil
+		})
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	return v, nil
+}
@@ -0,0 +1,604 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// TaskSensitivityPub is sensitivity analysis of modeling task run results:
+// sensitivity indices of each output table expression versus input parameters varied across task worksets
+// and tornado chart data for one-at-a-time changes of parameters.
+//
+// Input parameter value of each model run is an average of all parameter cells
+// and output expression value of each model run is an average of all expression cells.
+// Only float or integer parameters, which are included in task worksets and have different values in task model runs, are analyzed.
+type TaskSensitivityPub struct {
+	ModelName     string             // model name
+	ModelDigest   string             // model digest
+	TaskName      string             // modeling task name
+	TaskRunName   string             // task run name
+	TaskRunStamp  string             // task run stamp
+	BaseRunDigest string             // if not empty then digest of base run of task worksets, used as tornado reference
+	RunCount      int                // number of successfully completed model runs in task run
+	Param         []SensitivityParam // input parameters varied across task model runs
+	Index         []SensitivityIndex // sensitivity indices for each output expression and each input parameter
+	Tornado       []TornadoItem      // tornado chart data for each output expression, sorted by swing in descending order
+}
+
+// SensitivityParam is input parameter varied across task model runs
+type SensitivityParam struct {
+	Name      string  // parameter name
+	Min       float64 // minimum of parameter value
+	Max       float64 // maximum of parameter value
+	Reference float64 // reference value: value in base run or most frequent value
+}
+
+// SensitivityIndex is sensitivity indices of output expression versus input parameter
+type SensitivityIndex struct {
+	TableName   string  // output table name
+	ExprName    string  // output expression name
+	ParamName   string  // parameter name
+	Correlation float64 // Pearson correlation coefficient
+	Slope       float64 // simple linear regression coefficient: change of output by unit change of parameter
+	IsSrc       bool    // if true then multiple linear regression is defined: Src and RSquared are valid
+	Src         float64 // standardized regression coefficient of multiple linear regression
+	RSquared    float64 // coefficient of determination of multiple linear regression of output expression
+}
+
+// TornadoItem is a bar of tornado chart: output expression values at low and high parameter value
+// when all other parameters are at reference value.
+type TornadoItem struct {
+	TableName  string  // output table name
+	ExprName   string  // output expression name
+	ParamName  string  // parameter name
+	InputLow   float64 // low value of parameter
+	InputHigh  float64 // high value of parameter
+	OutputLow  float64 // output expression value at low parameter value
+	OutputHigh float64 // output expression value at high parameter value
+	IsBase     bool    // if true then output value of base run is defined
+	OutputBase float64 // output expression value in base run
+	Swing      float64 // absolute difference between output at high and low parameter values
+	paramIdx   int     // index of parameter in the list of varied parameters
+}
+
+// GetTaskSensitivity return sensitivity analysis of modeling task run results.
+//
+// Task run selected by task run stamp or name, if trsn is empty then last completed task run is used.
+// Only successfully completed model runs of task run are analyzed.
+// If list of table names is not empty then only those output tables are included else all output tables.
+// If all task worksets are based on the same successfully completed model run
+// then values of that base run are used as tornado reference, else most frequent parameter value is the reference.
+func GetTaskSensitivity(dbConn *sql.DB, modelDef *ModelMeta, taskName string, trsn string, tableNames []string) (*TaskSensitivityPub, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata")
+	}
+	if taskName == "" {
+		return nil, errors.New("invalid (empty) modeling task name")
+	}
+
+	// find modeling task and task run
+	trr, err := getCompletedTaskRun(dbConn, modelDef, taskName, trsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// get successfully completed model runs and input worksets of task run
+	trsLst, err := GetTaskRunSetRows(dbConn, trr.TaskRunId)
+	if err != nil {
+		return nil, err
+	}
+	runLst := []*RunRow{}
+	setIds := []int{}
+	baseRunId := -1
+
+	for _, trs := range trsLst {
+
+		if trs.RunId <= 0 {
+			continue
+		}
+		r, err := GetRun(dbConn, trs.RunId)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil || r.Status != DoneRunStatus {
+			continue
+		}
+		runLst = append(runLst, r)
+
+		if trs.SetId <= 0 {
+			continue
+		}
+		setIds = append(setIds, trs.SetId)
+
+		ws, err := GetWorkset(dbConn, trs.SetId)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case ws == nil || ws.BaseRunId <= 0:
+			baseRunId = 0
+		case baseRunId < 0:
+			baseRunId = ws.BaseRunId
+		case baseRunId != ws.BaseRunId:
+			baseRunId = 0
+		}
+	}
+	if len(runLst) < 2 {
+		return nil, errors.New("not enough successfully completed model runs in modeling task run: " + taskName + ": " + trr.Name)
+	}
+
+	// base run of all task worksets, if it is successfully completed
+	var baseRun *RunRow
+	if baseRunId > 0 {
+		if baseRun, err = GetRun(dbConn, baseRunId); err != nil {
+			return nil, err
+		}
+		if baseRun != nil && baseRun.Status != DoneRunStatus {
+			baseRun = nil
+		}
+	}
+
+	// list of parameters included in task worksets: only float or integer parameters
+	pmLst := []*ParamMeta{}
+
+	for _, sId := range setIds {
+
+		hLst, _, _, err := GetWorksetParamList(dbConn, sId)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hLst {
+
+			i, ok := modelDef.ParamByHid(h)
+			if !ok {
+				return nil, errors.New("parameter not found, id: " + strconv.Itoa(h))
+			}
+			pm := &modelDef.Param[i]
+			if pm.typeOf == nil || !pm.typeOf.IsFloat() && !pm.typeOf.IsInt() {
+				continue
+			}
+			isFound := false
+			for j := 0; !isFound && j < len(pmLst); j++ {
+				isFound = pmLst[j].ParamHid == h
+			}
+			if !isFound {
+				pmLst = append(pmLst, pm)
+			}
+		}
+	}
+
+	// read parameter values of each model run and select parameters which are varied across model runs
+	sp := &TaskSensitivityPub{
+		ModelName:    modelDef.Model.Name,
+		ModelDigest:  modelDef.Model.Digest,
+		TaskName:     taskName,
+		TaskRunName:  trr.Name,
+		TaskRunStamp: trr.RunStamp,
+		RunCount:     len(runLst),
+		Param:        []SensitivityParam{},
+		Index:        []SensitivityIndex{},
+		Tornado:      []TornadoItem{},
+	}
+	if baseRun != nil {
+		sp.BaseRunDigest = baseRun.RunDigest
+	}
+	xLst := [][]float64{}
+
+	for _, pm := range pmLst {
+
+		x := make([]float64, len(runLst))
+		for k, r := range runLst {
+			v, isOk, err := selectRunParamAvg(dbConn, pm, r.RunId)
+			if err != nil {
+				return nil, err
+			}
+			if !isOk {
+				return nil, errors.New("parameter value not found: " + pm.Name + " in model run: " + r.RunDigest)
+			}
+			x[k] = v
+		}
+
+		p := SensitivityParam{Name: pm.Name, Min: x[0], Max: x[0]}
+		for _, v := range x {
+			p.Min = math.Min(p.Min, v)
+			p.Max = math.Max(p.Max, v)
+		}
+		if p.Min == p.Max {
+			continue // parameter is the same in all model runs
+		}
+
+		// reference value: base run value or most frequent value
+		isRef := false
+		if baseRun != nil {
+			if p.Reference, isRef, err = selectRunParamAvg(dbConn, pm, baseRun.RunId); err != nil {
+				return nil, err
+			}
+		}
+		if !isRef {
+			p.Reference = mostFrequentValue(x)
+		}
+
+		sp.Param = append(sp.Param, p)
+		xLst = append(xLst, x)
+	}
+	if len(sp.Param) <= 0 {
+		return sp, nil // there are no varied parameters
+	}
+
+	// reference values of all varied parameters
+	xRef := make([]float64, len(sp.Param))
+	for j := range sp.Param {
+		xRef[j] = sp.Param[j].Reference
+	}
+
+	// for each output table expression calculate sensitivity indices and tornado chart data
+	for k := range modelDef.Table {
+
+		tm := &modelDef.Table[k]
+
+		if len(tableNames) > 0 {
+			isFound := false
+			for j := 0; !isFound && j < len(tableNames); j++ {
+				isFound = tableNames[j] == tm.Name
+			}
+			if !isFound {
+				continue
+			}
+		}
+
+		// read expression values of each model run, expression must exist in all model runs
+		yLst := make([][]float64, len(tm.Expr))
+		isY := make([]bool, len(tm.Expr))
+		for j := range yLst {
+			yLst[j] = make([]float64, len(runLst))
+			isY[j] = true
+		}
+
+		for n, r := range runLst {
+
+			ev, err := selectRunExprAvg(dbConn, tm, r.RunId)
+			if err != nil {
+				return nil, err
+			}
+			for j := range tm.Expr {
+				v, ok := ev[tm.Expr[j].ExprId]
+				yLst[j][n] = v
+				isY[j] = isY[j] && ok
+			}
+		}
+
+		var baseEv map[int]float64
+		if baseRun != nil {
+			if baseEv, err = selectRunExprAvg(dbConn, tm, baseRun.RunId); err != nil {
+				return nil, err
+			}
+		}
+
+		for j := range tm.Expr {
+
+			if !isY[j] {
+				continue // expression values not found in some of model runs
+			}
+			cLst, sLst, srcLst, rSq, isSrc := SensitivityIndices(xLst, yLst[j])
+			if cLst == nil {
+				continue // output expression is the same in all model runs
+			}
+
+			for i := range sp.Param {
+				si := SensitivityIndex{
+					TableName:   tm.Name,
+					ExprName:    tm.Expr[j].Name,
+					ParamName:   sp.Param[i].Name,
+					Correlation: cLst[i],
+					Slope:       sLst[i],
+					IsSrc:       isSrc,
+				}
+				if isSrc {
+					si.Src = srcLst[i]
+					si.RSquared = rSq
+				}
+				sp.Index = append(sp.Index, si)
+			}
+
+			// tornado chart data, sorted by swing
+			yBase, isBase := baseEv[tm.Expr[j].ExprId]
+
+			tLst := TornadoBars(xLst, yLst[j], xRef, isBase, yBase)
+			for i := range tLst {
+				tLst[i].TableName = tm.Name
+				tLst[i].ExprName = tm.Expr[j].Name
+				tLst[i].ParamName = sp.Param[tLst[i].paramIdx].Name
+			}
+			sp.Tornado = append(sp.Tornado, tLst...)
+		}
+	}
+
+	return sp, nil
+}
+
+// return completed modeling task run by task run stamp or name, if trsn is empty then return last completed task run
+func getCompletedTaskRun(dbConn *sql.DB, modelDef *ModelMeta, taskName string, trsn string) (*TaskRunRow, error) {
+
+	tr, err := GetTaskByName(dbConn, modelDef.Model.ModelId, taskName)
+	if err != nil {
+		return nil, err
+	}
+	if tr == nil {
+		return nil, errors.New("modeling task not found: " + taskName)
+	}
+
+	var trr *TaskRunRow
+	if trsn != "" {
+		trr, err = GetTaskRunByStampOrName(dbConn, tr.TaskId, trsn)
+	} else {
+		trr, err = GetTaskLastCompletedRun(dbConn, tr.TaskId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if trr == nil {
+		return nil, errors.New("modeling task run not found: " + taskName + ": " + trsn)
+	}
+	if !IsRunCompleted(trr.Status) {
+		return nil, errors.New("modeling task run is not completed: " + taskName + ": " + trr.Name)
+	}
+	return trr, nil
+}
+
+// return average of parameter values in model run and false if parameter values not found
+func selectRunParamAvg(dbConn *sql.DB, pm *ParamMeta, runId int) (float64, bool, error) {
+
+	var v sql.NullFloat64
+
+	err := SelectFirst(dbConn,
+		"SELECT AVG(param_value) FROM "+pm.DbRunTable+
+			" WHERE run_id ="+
+			" (SELECT base_run_id FROM run_parameter WHERE run_id = "+strconv.Itoa(runId)+" AND parameter_hid = "+strconv.Itoa(pm.ParamHid)+")",
+		func(row *sql.Row) error {
+			return row.Scan(&v)
+		})
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, false, nil
+	case err != nil:
+		return 0, false, err
+	}
+	return v.Float64, v.Valid, nil
+}
+
+// return average of output table expression values in model run as map of expression id to average value
+func selectRunExprAvg(dbConn *sql.DB, tm *TableMeta, runId int) (map[int]float64, error) {
+
+	ev := map[int]float64{}
+
+	err := SelectRows(dbConn,
+		"SELECT expr_id, AVG(expr_value) FROM "+tm.DbExprTable+
+			" WHERE run_id ="+
+			" (SELECT base_run_id FROM run_table WHERE run_id = "+strconv.Itoa(runId)+" AND table_hid = "+strconv.Itoa(tm.TableHid)+")"+
+			" GROUP BY expr_id",
+		func(rows *sql.Rows) error {
+			var eId int
+			var v sql.NullFloat64
+			if err := rows.Scan(&eId, &v); err != nil {
+				return err
+			}
+			if v.Valid {
+				ev[eId] = v.Float64
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// return most frequent value, if there are multiple such values then return first of it
+func mostFrequentValue(x []float64) float64 {
+
+	cm := map[float64]int{}
+	for _, v := range x {
+		cm[v]++
+	}
+	ref := x[0]
+	for _, v := range x {
+		if cm[v] > cm[ref] {
+			ref = v
+		}
+	}
+	return ref
+}
+
+// SensitivityIndices return sensitivity indices of output values y versus each input x[j].
+// Each x[j] and y contains values of each model run.
+// It returns for each x[j]: Pearson correlation coefficients, simple linear regression slopes,
+// standardized regression coefficients of multiple linear regression, R squared
+// and true if multiple linear regression is defined.
+// It returns nil indices if output y is the same in all model runs.
+func SensitivityIndices(x [][]float64, y []float64) ([]float64, []float64, []float64, float64, bool) {
+
+	n := len(y)
+	if n < 2 {
+		return nil, nil, nil, 0, false
+	}
+	yMean, ySd := meanStdDev(y)
+	if ySd == 0 {
+		return nil, nil, nil, 0, false
+	}
+
+	// standardized input values and correlation with output
+	p := len(x)
+	z := make([][]float64, p)
+	rxy := make([]float64, p)
+	slope := make([]float64, p)
+
+	for j := 0; j < p; j++ {
+
+		xMean, xSd := meanStdDev(x[j])
+		z[j] = make([]float64, n)
+
+		for k := 0; k < n; k++ {
+			if xSd > 0 {
+				z[j][k] = (x[j][k] - xMean) / xSd
+			}
+			rxy[j] += z[j][k] * (y[k] - yMean) / ySd
+		}
+		rxy[j] /= float64(n - 1)
+
+		if xSd > 0 {
+			slope[j] = rxy[j] * ySd / xSd
+		}
+	}
+
+	// standardized regression coefficients: solve Rxx * src = rxy, where Rxx is correlation matrix of inputs
+	if n <= p+1 {
+		return rxy, slope, nil, 0, false // not enough model runs for multiple linear regression
+	}
+	rxx := make([][]float64, p)
+	for i := 0; i < p; i++ {
+		rxx[i] = make([]float64, p)
+		for j := 0; j < p; j++ {
+			for k := 0; k < n; k++ {
+				rxx[i][j] += z[i][k] * z[j][k]
+			}
+			rxx[i][j] /= float64(n - 1)
+		}
+	}
+	src, ok := solveLinear(rxx, rxy)
+	if !ok {
+		return rxy, slope, nil, 0, false
+	}
+
+	rSq := 0.0
+	for j := 0; j < p; j++ {
+		rSq += src[j] * rxy[j]
+	}
+	return rxy, slope, src, rSq, true
+}
+
+// TornadoBars return tornado chart bars of output values y versus each input x[j], sorted by swing in descending order.
+// Each x[j] and y contains values of each model run, xRef[j] is a reference value of input x[j].
+// Bar of input j is created from model runs where all other inputs are at reference values,
+// if base output value is defined then reference point (xRef[j], yBase) is also included.
+// There is no bar for input if there are less than two different values of that input.
+func TornadoBars(x [][]float64, y []float64, xRef []float64, isBase bool, yBase float64) []TornadoItem {
+
+	tLst := []TornadoItem{}
+
+	for j := range x {
+
+		isPt := false
+		t := TornadoItem{paramIdx: j, IsBase: isBase}
+		if isBase {
+			isPt = true
+			t.InputLow, t.OutputLow, t.InputHigh, t.OutputHigh, t.OutputBase = xRef[j], yBase, xRef[j], yBase, yBase
+		}
+
+		for k := range y {
+
+			// skip model run if any other input is not at reference value
+			isOat := true
+			for i := 0; isOat && i < len(x); i++ {
+				isOat = i == j || x[i][k] == xRef[i]
+			}
+			if !isOat {
+				continue
+			}
+
+			if !isPt || x[j][k] < t.InputLow {
+				t.InputLow, t.OutputLow = x[j][k], y[k]
+			}
+			if !isPt || x[j][k] > t.InputHigh {
+				t.InputHigh, t.OutputHigh = x[j][k], y[k]
+			}
+			isPt = true
+		}
+
+		if isPt && t.InputLow < t.InputHigh {
+			t.Swing = math.Abs(t.OutputHigh - t.OutputLow)
+			tLst = append(tLst, t)
+		}
+	}
+
+	sort.SliceStable(tLst, func(i, j int) bool { return tLst[i].Swing > tLst[j].Swing })
+	return tLst
+}
+
+// return mean and sample standard deviation
+func meanStdDev(x []float64) (float64, float64) {
+
+	if len(x) <= 0 {
+		return 0, 0
+	}
+	m := 0.0
+	for _, v := range x {
+		m += v
+	}
+	m /= float64(len(x))
+
+	if len(x) < 2 {
+		return m, 0
+	}
+	s := 0.0
+	for _, v := range x {
+		s += (v - m) * (v - m)
+	}
+	return m, math.Sqrt(s / float64(len(x)-1))
+}
+
+// solve system of linear equations a * x = b by Gaussian elimination with partial pivoting.
+// Return false if matrix is singular.
+func solveLinear(a [][]float64, b []float64) ([]float64, bool) {
+
+	const eps = 1.0e-10
+	n := len(b)
+
+	// copy source matrix and right side into augmented matrix
+	m := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		m[i] = make([]float64, n+1)
+		copy(m[i], a[i])
+		m[i][n] = b[i]
+	}
+
+	for c := 0; c < n; c++ {
+
+		// find pivot row
+		p := c
+		for i := c + 1; i < n; i++ {
+			if math.Abs(m[i][c]) > math.Abs(m[p][c]) {
+				p = i
+			}
+		}
+		if math.Abs(m[p][c]) < eps {
+			return nil, false
+		}
+		m[c], m[p] = m[p], m[c]
+
+		for i := c + 1; i < n; i++ {
+			f := m[i][c] / m[c][c]
+			for j := c; j <= n; j++ {
+				m[i][j] -= f * m[c][j]
+			}
+		}
+	}
+
+	// back substitution
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		s := m[i][n]
+		for j := i + 1; j < n; j++ {
+			s -= m[i][j] * x[j]
+		}
+		x[i] = s / m[i][i]
+	}
+	return x, true
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSensitivityIndices(t *testing.T) {
+
+	// y = 2 * x0 - x1 + 10, full factorial of x0 = 1, 2, 3 and x1 = 5, 7
+	x := [][]float64{
+		{1, 1, 2, 2, 3, 3},
+		{5, 7, 5, 7, 5, 7},
+	}
+	y := make([]float64, len(x[0]))
+	for k := range y {
+		y[k] = 2*x[0][k] - x[1][k] + 10
+	}
+
+	cLst, sLst, srcLst, rSq, isSrc := SensitivityIndices(x, y)
+	if cLst == nil || !isSrc {
+		t.Fatalf("expected sensitivity indices: %v %v", cLst, isSrc)
+	}
+	if math.Abs(sLst[0]-2) > 1.0e-9 || math.Abs(sLst[1]+1) > 1.0e-9 {
+		t.Errorf("expected slopes: 2, -1 result: %v", sLst)
+	}
+	if cLst[0] <= 0 || cLst[1] >= 0 {
+		t.Errorf("expected positive and negative correlation: %v", cLst)
+	}
+	if math.Abs(rSq-1) > 1.0e-9 {
+		t.Errorf("expected R squared 1 result: %v", rSq)
+	}
+	// inputs are not correlated in full factorial design: standardized regression coefficients are equal to correlations
+	for j := range srcLst {
+		if math.Abs(srcLst[j]-cLst[j]) > 1.0e-9 {
+			t.Errorf("expected src equal to correlation: %v %v", srcLst, cLst)
+		}
+	}
+
+	// output is the same in all runs
+	if cLst, _, _, _, _ = SensitivityIndices(x, []float64{1, 1, 1, 1, 1, 1}); cLst != nil {
+		t.Errorf("expected empty result for constant output: %v", cLst)
+	}
+
+	// collinear inputs: multiple regression is not defined
+	x = [][]float64{{1, 2, 3, 4}, {2, 4, 6, 8}}
+	if _, _, _, _, isSrc = SensitivityIndices(x, []float64{1, 3, 2, 5}); isSrc {
+		t.Error("expected undefined regression for collinear inputs")
+	}
+}
+
+func TestTornadoBars(t *testing.T) {
+
+	// one-at-a-time: reference is x0 = 2, x1 = 5, runs are: x0 = 1, x0 = 3, x1 = 4, x1 = 6
+	x := [][]float64{
+		{1, 3, 2, 2},
+		{5, 5, 4, 6},
+	}
+	y := []float64{10, 30, 19, 21}
+
+	tLst := TornadoBars(x, y, []float64{2, 5}, true, 20)
+	if len(tLst) != 2 {
+		t.Fatalf("expected two tornado bars: %v", tLst)
+	}
+	if tLst[0].paramIdx != 0 || tLst[0].Swing != 20 || tLst[0].OutputLow != 10 || tLst[0].OutputHigh != 30 {
+		t.Errorf("invalid first tornado bar: %v", tLst[0])
+	}
+	if tLst[1].paramIdx != 1 || tLst[1].Swing != 2 || tLst[1].InputLow != 4 || tLst[1].InputHigh != 6 {
+		t.Errorf("invalid second tornado bar: %v", tLst[1])
+	}
+
+	// without base run: only one run for each parameter is not enough
+	tLst = TornadoBars([][]float64{{1, 2}, {5, 5}}, []float64{1, 2}, []float64{2, 5}, false, 0)
+	if len(tLst) != 1 || tLst[0].InputLow != 1 || tLst[0].InputHigh != 2 {
+		t.Errorf("invalid tornado bar: %v", tLst)
+	}
+}
+
+func TestSolveLinear(t *testing.T) {
+
+	x, ok := solveLinear([][]float64{{2, 1}, {1, 3}}, []float64{3, 5})
+	if !ok || math.Abs(x[0]-0.8) > 1.0e-9 || math.Abs(x[1]-1.4) > 1.0e-9 {
+		t.Errorf("expected: 0.8, 1.4 result: %v %v", x, ok)
+	}
+	if _, ok = solveLinear([][]float64{{1, 2}, {2, 4}}, []float64{1, 2}); ok {
+		t.Error("expected singular matrix")
+	}
+}
@@ -233,18 +233,8 @@ func doUpdateRunMetaDigest(trx *sql.Tx, runId int) (string, error) {
 		return "", err
 	}
 
-	// digest header: run metadata
-	hMd5 := md5.New()
-
-	_, err = hMd5.Write([]byte(
-		"model_digest,run_name,sub_count,create_dt,run_stamp\n" +
-			modelDigest + "," + runName + "," + strconv.Itoa(subCount) + "," + createDt + "," + runStamp + "\n"))
-	if err != nil {
-		return "", err
-	}
-
 	// update model run digest
-	dg := fmt.Sprintf("%x", hMd5.Sum(nil))
+	dg := RunMetaDigest(modelDigest, runName, subCount, createDt, runStamp)
 
 	err = TrxUpdate(trx,
 		"UPDATE run_lst SET run_digest = "+ToQuoted(dg)+" WHERE run_id = "+strconv.Itoa(runId))
@@ -282,23 +272,9 @@ func doUpdateRunValueDigest(trx *sql.Tx, runId int) (string, error) {
 		return "", err
 	}
 
-	// digest header: run metadata
-	hMd5 := md5.New()
+	// select run parameters values digest
+	pDgst := []string{}
 
-	_, err = hMd5.Write([]byte(
-		"sub_count,sub_completed,status\n" +
-			strconv.Itoa(subCount) + "," + strconv.Itoa(subCompleted) + "," + runStatus + "\n"))
-	if err != nil {
-		return "", err
-	}
-
-	// append run parameters value digest header
-	_, err = hMd5.Write([]byte("value_digest\n"))
-	if err != nil {
-		return "", err
-	}
-
-	// append run parameters values digest to run digest
 	err = TrxSelectRows(trx,
 		"SELECT M.model_parameter_id, R.value_digest"+
 			" FROM run_parameter R"+
@@ -311,31 +287,21 @@ func doUpdateRunValueDigest(trx *sql.Tx, runId int) (string, error) {
 			var i int
 			var sd sql.NullString
 
-			err := rows.Scan(&i, &sd)
-			if err != nil {
+			if err := rows.Scan(&i, &sd); err != nil {
 				return err
 			}
-			if sd.Valid {
-				_, err = hMd5.Write([]byte(sd.String + "\n"))
-			} else {
-				_, err = hMd5.Write([]byte("\n"))
-			}
-			return err
+			pDgst = append(pDgst, sd.String)
+			return nil
 		})
 	if err != nil && err != sql.ErrNoRows {
 		return "", err
 	}
 
-	// if run completed succesfully then append output tables value digest
-	if runStatus == DoneRunStatus {
+	// if run completed succesfully then select output tables value digest
+	tDgst := []string{}
 
-		// append output tables value digest header
-		_, err = hMd5.Write([]byte("value_digest\n"))
-		if err != nil {
-			return "", err
-		}
+	if runStatus == DoneRunStatus {
 
-		// append output tables values digest to run digest
 		err = TrxSelectRows(trx,
 			"SELECT M.model_table_id, R.value_digest"+
 				" FROM run_table R"+
@@ -348,16 +314,11 @@ func doUpdateRunValueDigest(trx *sql.Tx, runId int) (string, error) {
 				var i int
 				var sd sql.NullString
 
-				err := rows.Scan(&i, &sd)
-				if err != nil {
+				if err := rows.Scan(&i, &sd); err != nil {
 					return err
 				}
-				if sd.Valid {
-					_, err = hMd5.Write([]byte(sd.String + "\n"))
-				} else {
-					_, err = hMd5.Write([]byte("\n"))
-				}
-				return err
+				tDgst = append(tDgst, sd.String)
+				return nil
 			})
 		if err != nil && err != sql.ErrNoRows {
 			return "", err
@@ -365,7 +326,7 @@ func doUpdateRunValueDigest(trx *sql.Tx, runId int) (string, error) {
 	}
 
 	// update model run digest
-	dg := fmt.Sprintf("%x", hMd5.Sum(nil))
+	dg := RunValueDigest(subCount, subCompleted, runStatus, pDgst, tDgst)
 
 	err = TrxUpdate(trx,
 		"UPDATE run_lst SET value_digest = "+ToQuoted(dg)+" WHERE run_id = "+strconv.Itoa(runId))
@@ -376,6 +337,50 @@ func doUpdateRunValueDigest(trx *sql.Tx, runId int) (string, error) {
 	return dg, nil
 }
 
+// RunMetaDigest return model run metadata digest: digest of model digest, run name, sub count, created date-time and run stamp.
+func RunMetaDigest(modelDigest, runName string, subCount int, createDt, runStamp string) string {
+
+	hMd5 := md5.New()
+
+	hMd5.Write([]byte(
+		"model_digest,run_name,sub_count,create_dt,run_stamp\n" +
+			modelDigest + "," + runName + "," + strconv.Itoa(subCount) + "," + createDt + "," + runStamp + "\n"))
+
+	return fmt.Sprintf("%x", hMd5.Sum(nil))
+}
+
+// RunValueDigest return model run value digest: digest of run status, parameters value digests and output tables value digests.
+// Parameters digests must be ordered by model parameter id and output tables digests by model table id.
+// Output tables digests included only if run status is success.
+func RunValueDigest(subCount, subCompleted int, status string, paramDigest []string, tableDigest []string) string {
+
+	// digest header: run metadata
+	hMd5 := md5.New()
+
+	hMd5.Write([]byte(
+		"sub_count,sub_completed,status\n" +
+			strconv.Itoa(subCount) + "," + strconv.Itoa(subCompleted) + "," + status + "\n"))
+
+	// append run parameters values digest
+	hMd5.Write([]byte("value_digest\n"))
+
+	for _, d := range paramDigest {
+		hMd5.Write([]byte(d + "\n"))
+	}
+
+	// if run completed succesfully then append output tables value digest
+	if status == DoneRunStatus {
+
+		hMd5.Write([]byte("value_digest\n"))
+
+		for _, d := range tableDigest {
+			hMd5.Write([]byte(d + "\n"))
+		}
+	}
+
+	return fmt.Sprintf("%x", hMd5.Sum(nil))
+}
+
 // doInsertRun insert new model run metadata in database.
 // It does update as part of transaction.
 // Run status must be completed (success, exit or error) otherwise error returned.
@@ -12,26 +12,160 @@ import (
 	"github.com/openmpp/go/ompp/helper"
 )
 
+// ErrWorksetChanged is returned by conditional workset update if workset was changed since expected update date-time.
+var ErrWorksetChanged = errors.New("workset was changed by another update")
+
+// trxCheckWorksetUpdateDt return ErrWorksetChanged if workset update date-time is not equal to expected value.
+// It does "lock" workset row and select workset update date-time as part of transaction.
+// If expected update date-time is empty "" then workset is not checked.
+func trxCheckWorksetUpdateDt(trx *sql.Tx, setId int, ifUpdateDt string) error {
+
+	if ifUpdateDt == "" {
+		return nil // unconditional update
+	}
+	sId := strconv.Itoa(setId)
+
+	err := TrxUpdate(trx, "UPDATE workset_lst SET update_dt = update_dt WHERE set_id = "+sId)
+	if err != nil {
+		return err
+	}
+
+	dt := ""
+	err = TrxSelectFirst(trx,
+		"SELECT update_dt FROM workset_lst WHERE set_id = "+sId,
+		func(row *sql.Row) error {
+			return row.Scan(&dt)
+		})
+	switch {
+	case err == sql.ErrNoRows:
+		return ErrWorksetChanged // workset deleted
+	case err != nil:
+		return err
+	}
+	if dt != ifUpdateDt {
+		return ErrWorksetChanged
+	}
+	return nil
+}
+
+// trxWorksetUpdateDt return new workset update date-time: current date-time or,
+// if workset update date-time is the same or later, one millisecond after workset update date-time.
+// Workset update date-time is used as workset version (ETag), it must be different after each update,
+// even if two updates done within the same millisecond.
+func trxWorksetUpdateDt(trx *sql.Tx, setId int) (string, error) {
+
+	dt := ""
+	err := TrxSelectFirst(trx,
+		"SELECT update_dt FROM workset_lst WHERE set_id = "+strconv.Itoa(setId),
+		func(row *sql.Row) error {
+			return row.Scan(&dt)
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	return nextUpdateDt(dt, time.Now()), nil
+}
+
+// nextUpdateDt return current date-time or, if last update date-time is the same or later, one millisecond after last update.
+// If last update date-time is empty or invalid then return current date-time.
+func nextUpdateDt(lastDt string, now time.Time) string {
+
+	dt := helper.MakeDateTime(now)
+	if lastDt < dt {
+		return dt
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05.000", lastDt, time.Local)
+	if err != nil {
+		return dt // not a date-time: replace with current date-time
+	}
+	return helper.MakeDateTime(t.Add(time.Millisecond))
+}
+
 // UpdateWorksetReadonly update workset readonly status.
-func UpdateWorksetReadonly(dbConn *sql.DB, setId int, isReadonly bool) error {
+//
+// If workset is made read-only and validation rules not empty then workset parameters values must satisfy the rules,
+// otherwise ParamRuleError returned and read-only status not updated.
+// If ifUpdateDt is not empty then it is conditional update:
+// workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+func UpdateWorksetReadonly(dbConn *sql.DB, modelDef *ModelMeta, setId int, isReadonly bool, rules []ParamRule, ifUpdateDt string) error {
 
-	return Update(dbConn,
-		"UPDATE workset_lst"+
-			" SET is_readonly = "+toBoolSqlConst(isReadonly)+", "+" update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
-			" WHERE set_id ="+strconv.Itoa(setId))
+	// validate parameters
+	if modelDef == nil {
+		return errors.New("invalid (empty) model metadata")
+	}
+
+	// do update in transaction scope
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	if err = doUpdateWorksetReadonly(trx, modelDef, setId, isReadonly, rules, ifUpdateDt); err != nil {
+		trx.Rollback()
+		return err
+	}
+	return trx.Commit()
 }
 
 // UpdateWorksetReadonlyByName update workset readonly status by workset name.
 func UpdateWorksetReadonlyByName(dbConn *sql.DB, modelId int, name string, isReadonly bool) error {
 
-	return Update(dbConn,
-		"UPDATE workset_lst"+
-			" SET is_readonly = "+toBoolSqlConst(isReadonly)+", "+" update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
-			" WHERE set_id = ("+
-			" SELECT MIN(W.set_id) FROM workset_lst W"+
+	// do update in transaction scope
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return err
+	}
+
+	setId := 0
+	err = TrxSelectFirst(trx,
+		"SELECT MIN(W.set_id) FROM workset_lst W"+
 			" WHERE W.model_id = "+strconv.Itoa(modelId)+
-			" AND set_name = "+ToQuoted(name)+
-			")")
+			" AND W.set_name = "+ToQuoted(name),
+		func(row *sql.Row) error {
+			var n sql.NullInt64
+			if err := row.Scan(&n); err != nil {
+				return err
+			}
+			setId = int(n.Int64)
+			return nil
+		})
+	if err != nil && err != sql.ErrNoRows {
+		trx.Rollback()
+		return err
+	}
+	if setId <= 0 {
+		trx.Rollback()
+		return nil // workset not found: nothing to do
+	}
+
+	if err = doUpdateWorksetReadonly(trx, nil, setId, isReadonly, nil, ""); err != nil {
+		trx.Rollback()
+		return err
+	}
+	return trx.Commit()
+}
+
+// doUpdateWorksetReadonly update workset readonly status and workset update date-time.
+// It does update as part of transaction.
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+// If workset is made read-only then parameters values must satisfy validation rules, otherwise ParamRuleError returned.
+func doUpdateWorksetReadonly(trx *sql.Tx, modelDef *ModelMeta, setId int, isReadonly bool, rules []ParamRule, ifUpdateDt string) error {
+
+	if err := trxCheckWorksetUpdateDt(trx, setId, ifUpdateDt); err != nil {
+		return err
+	}
+	if isReadonly {
+		if err := trxCheckWorksetParamRules(trx, modelDef, setId, rules, nil); err != nil {
+			return err
+		}
+	}
+	dt, err := trxWorksetUpdateDt(trx, setId)
+	if err != nil {
+		return err
+	}
+	return TrxUpdate(trx,
+		"UPDATE workset_lst"+
+			" SET is_readonly = "+toBoolSqlConst(isReadonly)+", "+" update_dt = "+ToQuoted(dt)+
+			" WHERE set_id ="+strconv.Itoa(setId))
 }
 
 // RenameWorkset do rename workset if new name is not empty "" string.
@@ -120,11 +254,15 @@ func doRenameWorkset(trx *sql.Tx, setId int, newSetName string, isAnyReadonly bo
 
 	// rename workset and
 	// "unlock" workset before commit: restore original value of is_readonly
+	dt, err := trxWorksetUpdateDt(trx, setId)
+	if err != nil {
+		return false, err
+	}
 	err = TrxUpdate(trx,
 		"UPDATE workset_lst"+
 			" SET set_name = "+toQuotedMax(newSetName, nameDbMax)+", "+
 			" is_readonly = is_readonly - 1,"+
-			" update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
+			" update_dt = "+ToQuoted(dt)+
 			" WHERE set_id ="+sId)
 	if err != nil {
 		return false, err
@@ -150,6 +288,15 @@ func doRenameWorkset(trx *sql.Tx, setId int, newSetName string, isAnyReadonly bo
 //
 // Merge does merge of text metadata with existing workset or create empty new workset.
 // If workset exist then text is updated if such language already exist or inserted if no text in that language.
+//
+// If meta.IfUpdateDateTime is not empty then it is conditional update:
+// workset must exist and must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+//
+// If meta.VersionNote is not empty and workset exist then current values of meta.VersionParam parameters
+// saved as new workset version in the same transaction, on replace all workset parameters also saved.
+//
+// If workset is read-only after update then workset parameters values must satisfy meta.ParamRules validation rules,
+// otherwise ParamRuleError returned.
 func (meta *WorksetMeta) UpdateWorkset(dbConn *sql.DB, modelDef *ModelMeta, isReplace bool, langDef *LangMeta) error {
 
 	// validate parameters
@@ -172,13 +319,14 @@ func (meta *WorksetMeta) UpdateWorkset(dbConn *sql.DB, modelDef *ModelMeta, isRe
 		return err
 	}
 	err = doUpdateWorkset(trx, modelDef, meta, isReplace, langDef)
+	if err == nil && meta.Set.IsReadonly {
+		err = trxCheckWorksetParamRules(trx, modelDef, meta.Set.SetId, meta.ParamRules, nil)
+	}
 	if err != nil {
 		trx.Rollback()
 		return err
 	}
-	trx.Commit()
-
-	return nil
+	return trx.Commit()
 }
 
 // doUpdateWorkset insert new or update existing workset metadata in database.
@@ -240,6 +388,16 @@ func doUpdateWorkset(trx *sql.Tx, modelDef *ModelMeta, meta *WorksetMeta, isRepl
 		return errors.New("failed to update: workset already exists and it is read-only: " + strconv.Itoa(meta.Set.SetId) + ": " + meta.Set.Name)
 	}
 
+	// conditional update: workset must exist and must not be changed since expected update date-time
+	if meta.IfUpdateDateTime != "" {
+		if setId <= 0 {
+			return ErrWorksetChanged
+		}
+		if err = trxCheckWorksetUpdateDt(trx, setId, meta.IfUpdateDateTime); err != nil {
+			return err
+		}
+	}
+
 	// if workset not exist then create new empty workset
 	if setId <= 0 {
 
@@ -293,6 +451,11 @@ pLoop:
 		return errors.New("failed to update: workset parameter not exist in database, hId: " + strconv.Itoa(meta.Param[j].ParamHid) + " : " + meta.Set.Name)
 	}
 
+	// save current parameters values as new workset version
+	if _, err = trxSaveWorksetVersion(trx, modelDef, setId, isReplace, meta.VersionParam, meta.VersionNote); err != nil {
+		return err
+	}
+
 	// do replace of metadata or merge
 	if isReplace {
 		return doReplaceWorkset(trx, modelDef, meta, langDef)
@@ -366,8 +529,13 @@ func doReplaceWorkset(trx *sql.Tx, modelDef *ModelMeta, meta *WorksetMeta, langD
 	// SET is_readonly = 0, base_run_id = 1234, update_dt = '2012-08-17 16:05:59.123'
 	// WHERE set_id = 22
 	//
+	// if update date-time is not defined then use current time, it must be different after each update
 	if meta.Set.UpdateDateTime == "" {
-		meta.Set.UpdateDateTime = helper.MakeDateTime(time.Now())
+		dt, err := trxWorksetUpdateDt(trx, meta.Set.SetId)
+		if err != nil {
+			return err
+		}
+		meta.Set.UpdateDateTime = dt
 	}
 	err := TrxUpdate(trx,
 		"UPDATE workset_lst"+
@@ -472,10 +640,14 @@ func doMergeWorkset(trx *sql.Tx, modelDef *ModelMeta, meta *WorksetMeta, langDef
 			sl += " base_run_id = NULL, "
 		}
 	}
-	meta.Set.UpdateDateTime = helper.MakeDateTime(time.Now())
+	dt, err := trxWorksetUpdateDt(trx, meta.Set.SetId)
+	if err != nil {
+		return err
+	}
+	meta.Set.UpdateDateTime = dt
 	sl += " update_dt = " + ToQuoted(meta.Set.UpdateDateTime)
 
-	err := TrxUpdate(trx, "UPDATE workset_lst SET "+sl+" WHERE set_id ="+sId)
+	err = TrxUpdate(trx, "UPDATE workset_lst SET "+sl+" WHERE set_id ="+sId)
 	if err != nil {
 		return err
 	}
@@ -6,10 +6,9 @@ package db
 import (
 	"database/sql"
 	"errors"
+	"math"
 	"strconv"
-	"time"
-
-	"github.com/openmpp/go/ompp/helper"
+	"strings"
 )
 
 // UpdateWorksetParameterFrom add new or replace existing workset parameter.
@@ -21,6 +20,9 @@ import (
 //
 // Set name is used to find workset and set id updated with actual database value.
 // Workset must be read-write for replace or merge.
+// If meta.IfUpdateDateTime is not empty then workset must not be changed since that update date-time,
+// otherwise ErrWorksetChanged returned.
+// If meta.ParamRules is not empty then parameter values must satisfy validation rules, otherwise ParamRuleError returned.
 func (meta *WorksetMeta) UpdateWorksetParameterFrom(
 	dbConn *sql.DB, modelDef *ModelMeta, isReplaceMeta bool, param *ParamRunSetPub, langDef *LangMeta, from func() (interface{}, error),
 ) (int, error) {
@@ -73,6 +75,9 @@ func (meta *WorksetMeta) UpdateWorksetParameterFrom(
 		}
 
 		err = doWriteSetParameterFrom(trx, pm, meta.Set.SetId, param.SubCount, param.DefaultSubId, false, from, "")
+		if err == nil {
+			err = trxCheckWorksetParamRules(trx, modelDef, meta.Set.SetId, meta.ParamRules, []string{pm.Name})
+		}
 		if err != nil {
 			trx.Rollback()
 			return 0, err
@@ -92,7 +97,12 @@ func (meta *WorksetMeta) UpdateWorksetParameterFrom(
 // Parameter must exist exist in the model otherwise it is an error.
 // If parameter not exist in workset then function does nothing (it is empty operation).
 // If input array of ParamRunSetTxtPub is empty then it is empty operation and return is success.
-func UpdateWorksetParameterText(dbConn *sql.DB, modelDef *ModelMeta, setName string, paramTxtPub []ParamRunSetTxtPub, langDef *LangMeta) error {
+//
+// If ifUpdateDt is not empty then it is conditional update:
+// workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+func UpdateWorksetParameterText(
+	dbConn *sql.DB, modelDef *ModelMeta, setName string, paramTxtPub []ParamRunSetTxtPub, langDef *LangMeta, ifUpdateDt string,
+) error {
 
 	// validate parameters
 	if len(paramTxtPub) <= 0 {
@@ -131,7 +141,7 @@ func UpdateWorksetParameterText(dbConn *sql.DB, modelDef *ModelMeta, setName str
 	if err != nil {
 		return err
 	}
-	err = doUpdateWorksetParameterText(trx, modelDef, setName, paramLst, langDef)
+	err = doUpdateWorksetParameterText(trx, modelDef, setName, paramLst, langDef, ifUpdateDt)
 	if err != nil {
 		trx.Rollback()
 		return err
@@ -195,6 +205,11 @@ func doUpdateWorksetParameterMeta(
 	}
 	wm.Set.SetId = setId // workset exist, id may be different
 
+	// conditional update: workset must not be changed since expected update date-time
+	if err = trxCheckWorksetUpdateDt(trx, setId, wm.IfUpdateDateTime); err != nil {
+		return 0, err
+	}
+
 	// check if parameter exist in workset_parameter
 	sId := strconv.Itoa(wm.Set.SetId)
 
@@ -294,7 +309,9 @@ func doUpdateWorksetParameterMeta(
 	}
 
 	// "unlock" workset for parameter values write: restore original value of is_readonly=0
-	wm.Set.UpdateDateTime = helper.MakeDateTime(time.Now())
+	if wm.Set.UpdateDateTime, err = trxWorksetUpdateDt(trx, setId); err != nil {
+		return 0, err
+	}
 	err = TrxUpdate(trx,
 		"UPDATE workset_lst"+
 			" SET is_readonly = 0,"+
@@ -314,7 +331,10 @@ func doUpdateWorksetParameterMeta(
 // Workset must exist and must be read-write for replace or merge.
 //
 // If parameter not exist in workset then function does nothing (it is empty operation).
-func doUpdateWorksetParameterText(trx *sql.Tx, modelDef *ModelMeta, setName string, paramLst []worksetParam, langDef *LangMeta) error {
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time.
+func doUpdateWorksetParameterText(
+	trx *sql.Tx, modelDef *ModelMeta, setName string, paramLst []worksetParam, langDef *LangMeta, ifUpdateDt string,
+) error {
 
 	// "lock" workset to prevent update or use by the model
 	err := TrxUpdate(trx,
@@ -344,6 +364,9 @@ func doUpdateWorksetParameterText(trx *sql.Tx, modelDef *ModelMeta, setName stri
 	case nRd != 1:
 		return errors.New("failed to update: workset is read-only: " + setName)
 	}
+	if err = trxCheckWorksetUpdateDt(trx, setId, ifUpdateDt); err != nil {
+		return err
+	}
 	sId := strconv.Itoa(setId)
 
 	// merge parameter(s) value notes
@@ -380,10 +403,294 @@ func doUpdateWorksetParameterText(trx *sql.Tx, modelDef *ModelMeta, setName stri
 	}
 
 	// "unlock" workset for parameter values write: restore original value of is_readonly=0
+	dt, err := trxWorksetUpdateDt(trx, setId)
+	if err != nil {
+		return err
+	}
+	err = TrxUpdate(trx,
+		"UPDATE workset_lst"+
+			" SET is_readonly = 0,"+
+			" update_dt = "+ToQuoted(dt)+
+			" WHERE set_id = "+sId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ParamTransformOp is arithmetic operation to apply to existing workset parameter values
+type ParamTransformOp string
+
+// Workset parameter transform operations: param_value = param_value op argument
+const (
+	MulTransformOp ParamTransformOp = "*" // multiply: value * 1.05
+	DivTransformOp ParamTransformOp = "/" // divide: value / 2
+	AddTransformOp ParamTransformOp = "+" // add: value + other_param
+	SubTransformOp ParamTransformOp = "-" // subtract: value - 10
+	SetTransformOp ParamTransformOp = "=" // replace: value = 0
+)
+
+// ParamTransform define arithmetic operation on existing workset parameter values, ex.: value * 1.05 where Year >= 2030.
+//
+// Argument of operation is a constant Value or, if Param name is not empty, other workset parameter: value + other_param.
+// Other parameter dimensions must be a subset of transformed parameter dimensions, matched by dimension name and type.
+// Other parameter must have only one sub-value or the same number of sub-values as transformed parameter.
+// If Expr is not empty then it is parsed into Op, Value and Param, ex.: "value * 1.05" or "value + other_param".
+type ParamTransform struct {
+	Name    string           // parameter name
+	Expr    string           // if not empty then expression to apply, ex.: value * 1.05
+	Op      ParamTransformOp // operation: * / + - or = to replace value
+	Value   float64          // constant argument of operation
+	Param   string           // if not empty then name of other workset parameter to use as argument
+	IsSubId bool             // if true then transform only one sub-value
+	SubId   int              // sub-value id to transform
+	Filter  []FilterColumn   // dimension filters, ex.: Year >= 2030, it can also be a filter by param_value
+}
+
+// ParseParamTransformExpr parse transform expression into operation and argument, which is a constant or other parameter name.
+//
+// Expression is: "value * 1.05" or "value + other_param" or "= 0" or simply "0" to replace value.
+func ParseParamTransformExpr(expr string) (ParamTransformOp, float64, string, error) {
+
+	s := strings.TrimSpace(expr)
+	if s == "" {
+		return "", 0, "", errors.New("invalid (empty) transform expression")
+	}
+
+	// split expression into operation and argument
+	op := SetTransformOp
+	arg := s
+
+	isValue := len(s) >= 5 && strings.EqualFold(s[:5], "value")
+	if isValue && len(s) > 5 {
+		c := s[5]
+		isValue = c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9')
+	}
+
+	if isValue {
+
+		rest := strings.TrimSpace(s[5:])
+		if rest == "" {
+			return "", 0, "", errors.New("invalid transform expression, operation expected: " + expr)
+		}
+		switch o := ParamTransformOp(rest[:1]); o {
+		case MulTransformOp, DivTransformOp, AddTransformOp, SubTransformOp, SetTransformOp:
+			op = o
+		default:
+			return "", 0, "", errors.New("invalid transform expression, unknown operation: " + expr)
+		}
+		arg = strings.TrimSpace(rest[1:])
+	} else {
+		if strings.HasPrefix(s, string(SetTransformOp)) {
+			arg = strings.TrimSpace(s[1:])
+		}
+	}
+	if arg == "" {
+		return "", 0, "", errors.New("invalid transform expression, argument expected: " + expr)
+	}
+
+	// argument is a number or other parameter name
+	if v, err := strconv.ParseFloat(arg, 64); err == nil {
+		return op, v, "", nil
+	}
+	for k, r := range arg {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && (k == 0 || !(r >= '0' && r <= '9')) {
+			return "", 0, "", errors.New("invalid transform expression argument, it must be a number or parameter name: " + expr)
+		}
+	}
+	return op, 0, arg, nil
+}
+
+// TransformWorksetParameter apply arithmetic operation to existing workset parameter values, ex.: value * 1.05 where Year >= 2030.
+// Parameter values updated by single sql UPDATE inside of transaction and return error if operation failed.
+//
+// Workset must exist, must be read-write and must contain parameter and other parameter, if used as argument.
+// If ifUpdateDt is not empty then it is conditional update:
+// workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version in the same transaction.
+// If validation rules not empty then parameter values must satisfy the rules after transform,
+// otherwise ParamRuleError returned and parameter values not updated.
+func TransformWorksetParameter(
+	dbConn *sql.DB, modelDef *ModelMeta, setName string, tf *ParamTransform, rules []ParamRule, ifUpdateDt string, versionNote string,
+) error {
+
+	// validate parameters
+	if modelDef == nil {
+		return errors.New("invalid (empty) model metadata")
+	}
+	if tf == nil {
+		return errors.New("invalid (empty) parameter transform")
+	}
+	if setName == "" {
+		return errors.New("invalid (empty) workset name")
+	}
+
+	// parse expression, if specified
+	if tf.Expr != "" {
+		op, v, pn, err := ParseParamTransformExpr(tf.Expr)
+		if err != nil {
+			return err
+		}
+		tf.Op = op
+		tf.Value = v
+		tf.Param = pn
+	}
+
+	// find parameter and other parameter, if it is an argument
+	k, ok := modelDef.ParamByName(tf.Name)
+	if !ok {
+		return errors.New("model parameter not found: " + tf.Name)
+	}
+	param := &modelDef.Param[k]
+
+	var argParam *ParamMeta
+	if tf.Param != "" {
+		j, ok := modelDef.ParamByName(tf.Param)
+		if !ok {
+			return errors.New("model parameter not found: " + tf.Param)
+		}
+		argParam = &modelDef.Param[j]
+	}
+
+	// do update in transaction scope
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	err = doTransformWorksetParameter(trx, modelDef, setName, param, argParam, tf, rules, ifUpdateDt, versionNote)
+	if err != nil {
+		trx.Rollback()
+		return err
+	}
+	return trx.Commit()
+}
+
+// doTransformWorksetParameter apply arithmetic operation to existing workset parameter values.
+// It does update as part of transaction.
+// If versionNote is not empty then current parameter values saved as new workset version before update.
+// Parameter values must satisfy validation rules after update.
+func doTransformWorksetParameter(
+	trx *sql.Tx, modelDef *ModelMeta, setName string, param *ParamMeta, argParam *ParamMeta, tf *ParamTransform, rules []ParamRule, ifUpdateDt string, versionNote string,
+) error {
+
+	// "lock" workset to prevent update or use by the model
+	err := TrxUpdate(trx,
+		"UPDATE workset_lst"+
+			" SET is_readonly = is_readonly + 1"+
+			" WHERE model_id = "+strconv.Itoa(modelDef.Model.ModelId)+" AND set_name = "+ToQuoted(setName))
+	if err != nil {
+		return err
+	}
+
+	// check if workset exist and not readonly
+	var setId, nRd int
+	err = TrxSelectFirst(trx,
+		"SELECT set_id, is_readonly FROM workset_lst"+
+			" WHERE model_id = "+strconv.Itoa(modelDef.Model.ModelId)+" AND set_name = "+ToQuoted(setName),
+		func(row *sql.Row) error {
+			if err := row.Scan(&setId, &nRd); err != nil {
+				return err
+			}
+			return nil
+		})
+	switch {
+	case err == sql.ErrNoRows:
+		return errors.New("failed to update: workset not found: " + setName)
+	case err != nil:
+		return err
+	case nRd != 1:
+		return errors.New("failed to update: workset is read-only: " + setName)
+	}
+	if err = trxCheckWorksetUpdateDt(trx, setId, ifUpdateDt); err != nil {
+		return err
+	}
+	sId := strconv.Itoa(setId)
+
+	// return sub-value count and default sub-value id of workset parameter
+	subCount := func(p *ParamMeta) (int, int, error) {
+
+		var nSub, defId int
+		err := TrxSelectFirst(trx,
+			"SELECT sub_count, default_sub_id FROM workset_parameter WHERE set_id = "+sId+" AND parameter_hid = "+strconv.Itoa(p.ParamHid),
+			func(row *sql.Row) error {
+				if err := row.Scan(&nSub, &defId); err != nil {
+					return err
+				}
+				return nil
+			})
+		switch {
+		case err == sql.ErrNoRows:
+			return 0, 0, errors.New("failed to update: workset " + setName + " must contain parameter: " + p.Name)
+		case err != nil:
+			return 0, 0, err
+		}
+		return nSub, defId, nil
+	}
+
+	// parameter must be in workset, other parameter must have one sub-value or the same sub-values count
+	nSub, _, err := subCount(param)
+	if err != nil {
+		return err
+	}
+	argSubId := -1
+
+	if argParam != nil {
+		nArg, defArg, err := subCount(argParam)
+		if err != nil {
+			return err
+		}
+		if nArg != 1 && nArg != nSub {
+			return errors.New("failed to update: parameter " + argParam.Name + " sub-values count " + strconv.Itoa(nArg) + " must be 1 or " + strconv.Itoa(nSub))
+		}
+		if nArg == 1 {
+			argSubId = defArg
+		}
+	}
+
+	// save current parameter values as new workset version and update parameter values
+	q, err := makeParamTransformSql(param, setId, tf, argParam, argSubId)
+	if err != nil {
+		return err
+	}
+
+	// other parameter used as divisor must not have zero values
+	if argParam != nil && tf.Op == DivTransformOp {
+
+		zq, err := makeParamDivZeroSql(param, setId, tf, argParam, argSubId)
+		if err != nil {
+			return err
+		}
+		nZero := 0
+		err = TrxSelectFirst(trx, zq, func(row *sql.Row) error {
+			return row.Scan(&nZero)
+		})
+		if err != nil {
+			return err
+		}
+		if nZero > 0 {
+			return errors.New("invalid transform of parameter " + param.Name + ": division by zero, parameter " + argParam.Name + " has zero values")
+		}
+	}
+	if _, err = trxSaveWorksetVersion(trx, modelDef, setId, false, []string{param.Name}, versionNote); err != nil {
+		return err
+	}
+	if err = TrxUpdate(trx, q); err != nil {
+		return err
+	}
+	if err = trxCheckWorksetParamRules(trx, modelDef, setId, rules, []string{param.Name}); err != nil {
+		return err
+	}
+
+	// "unlock" workset: restore original value of is_readonly=0
+	dt, err := trxWorksetUpdateDt(trx, setId)
+	if err != nil {
+		return err
+	}
 	err = TrxUpdate(trx,
 		"UPDATE workset_lst"+
 			" SET is_readonly = 0,"+
-			" update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
+			" update_dt = "+ToQuoted(dt)+
 			" WHERE set_id = "+sId)
 	if err != nil {
 		return err
@@ -391,3 +698,165 @@ func doUpdateWorksetParameterText(trx *sql.Tx, modelDef *ModelMeta, setName stri
 
 	return nil
 }
+
+// makeParamTransformSql return sql to apply arithmetic operation to workset parameter values:
+//
+//	UPDATE ageSex_w2012_817 SET param_value = param_value * 1.05
+//	WHERE set_id = 9876 AND dim1 >= 12
+//
+// or if argument is other parameter:
+//
+//	UPDATE ageSex_w2012_817 SET param_value = param_value +
+//	  (SELECT A.param_value FROM salary_w2012_818 A
+//	  WHERE A.set_id = 9876 AND A.sub_id = ageSex_w2012_817.sub_id AND A.dim0 = ageSex_w2012_817.dim1)
+//	WHERE set_id = 9876
+//	AND EXISTS
+//	  (SELECT * FROM salary_w2012_818 A
+//	  WHERE A.set_id = 9876 AND A.sub_id = ageSex_w2012_817.sub_id AND A.dim0 = ageSex_w2012_817.dim1)
+//
+// Division is done in floating point: param_value * 1.0 / 2, result of integer parameter is rounded.
+// If argSubId >= 0 then other parameter has only one sub-value and it is used for all sub-values of parameter.
+func makeParamTransformSql(param *ParamMeta, setId int, tf *ParamTransform, argParam *ParamMeta, argSubId int) (string, error) {
+
+	arg, where, err := makeParamTransformWhere(param, setId, tf, argParam, argSubId)
+	if err != nil {
+		return "", err
+	}
+
+	// make new value expression, round the result for integer parameter
+	v := ""
+	switch tf.Op {
+	case MulTransformOp, AddTransformOp, SubTransformOp:
+		v = "param_value " + string(tf.Op) + " " + arg
+	case DivTransformOp:
+		v = "param_value * 1.0 / " + arg
+	case SetTransformOp:
+		v = arg
+	default:
+		return "", errors.New("invalid transform operation of parameter " + param.Name + ": " + string(tf.Op))
+	}
+	if param.typeOf.IsInt() {
+		v = "ROUND(" + v + ", 0)"
+	}
+
+	return "UPDATE " + param.DbSetTable + " SET param_value = " + v + where, nil
+}
+
+// makeParamDivZeroSql return sql to count zero values of other parameter used as divisor of workset parameter values:
+//
+//	SELECT COUNT(*) FROM ageSex_w2012_817
+//	WHERE set_id = 9876
+//	AND EXISTS
+//	  (SELECT * FROM salary_w2012_818 A
+//	  WHERE A.set_id = 9876 AND A.sub_id = ageSex_w2012_817.sub_id AND A.dim0 = ageSex_w2012_817.dim1)
+//	AND (SELECT A.param_value FROM salary_w2012_818 A
+//	  WHERE A.set_id = 9876 AND A.sub_id = ageSex_w2012_817.sub_id AND A.dim0 = ageSex_w2012_817.dim1) = 0
+func makeParamDivZeroSql(param *ParamMeta, setId int, tf *ParamTransform, argParam *ParamMeta, argSubId int) (string, error) {
+
+	arg, where, err := makeParamTransformWhere(param, setId, tf, argParam, argSubId)
+	if err != nil {
+		return "", err
+	}
+	return "SELECT COUNT(*) FROM " + param.DbSetTable + where + " AND " + arg + " = 0", nil
+}
+
+// return transform argument: constant or select from other parameter, and WHERE clause of parameter values to transform.
+func makeParamTransformWhere(param *ParamMeta, setId int, tf *ParamTransform, argParam *ParamMeta, argSubId int) (string, string, error) {
+
+	// parameter and argument must be numeric
+	if !param.typeOf.IsFloat() && !param.typeOf.IsInt() || !param.typeOf.IsBuiltIn() {
+		return "", "", errors.New("parameter " + param.Name + " must be numeric to transform values")
+	}
+	if argParam != nil && (!argParam.typeOf.IsFloat() && !argParam.typeOf.IsInt() || !argParam.typeOf.IsBuiltIn()) {
+		return "", "", errors.New("parameter " + argParam.Name + " must be numeric to use as transform argument")
+	}
+	sId := strconv.Itoa(setId)
+
+	// make argument: constant or select from other parameter
+	arg := ""
+	argWhere := ""
+
+	if argParam == nil {
+
+		if tf.Op == DivTransformOp && tf.Value == 0 {
+			return "", "", errors.New("invalid transform of parameter " + param.Name + ": division by zero")
+		}
+		if math.IsNaN(tf.Value) || math.IsInf(tf.Value, 0) {
+			return "", "", errors.New("invalid transform value of parameter " + param.Name)
+		}
+		arg = strconv.FormatFloat(tf.Value, 'g', -1, 64)
+	} else {
+
+		argWhere = " FROM " + argParam.DbSetTable + " A" +
+			" WHERE A.set_id = " + sId
+
+		if argSubId >= 0 {
+			argWhere += " AND A.sub_id = " + strconv.Itoa(argSubId)
+		} else {
+			argWhere += " AND A.sub_id = " + param.DbSetTable + ".sub_id"
+		}
+
+		// each dimension of other parameter must match to the parameter dimension by name and type
+		for j := range argParam.Dim {
+
+			dix := -1
+			for k := range param.Dim {
+				if param.Dim[k].Name == argParam.Dim[j].Name && param.Dim[k].TypeId == argParam.Dim[j].TypeId {
+					dix = k
+					break
+				}
+			}
+			if dix < 0 {
+				return "", "", errors.New("parameter " + param.Name + " does not have dimension " + argParam.Dim[j].Name + " of parameter " + argParam.Name)
+			}
+			argWhere += " AND A." + argParam.Dim[j].colName + " = " + param.DbSetTable + "." + param.Dim[dix].colName
+		}
+		arg = "(SELECT A.param_value" + argWhere + ")"
+	}
+
+	q := " WHERE set_id = " + sId
+
+	if tf.IsSubId {
+		q += " AND sub_id = " + strconv.Itoa(tf.SubId)
+	}
+	if argParam != nil {
+		q += " AND EXISTS (SELECT *" + argWhere + ")"
+	}
+
+	// append dimension enum code filters, if specified
+	for k := range tf.Filter {
+
+		// filter parameter value or find dimension index by name
+		var err error
+		f := ""
+
+		if tf.Filter[k].Name == "param_value" {
+
+			f, err = makeWhereValueFilter(
+				&tf.Filter[k], "", "param_value", "", 0, param.typeOf, "param_value", "parameter "+param.Name)
+			if err != nil {
+				return "", "", err
+			}
+		} else {
+
+			dix := -1
+			for j := range param.Dim {
+				if param.Dim[j].Name == tf.Filter[k].Name {
+					dix = j
+					break
+				}
+			}
+			if dix < 0 {
+				return "", "", errors.New("parameter " + param.Name + " does not have dimension " + tf.Filter[k].Name)
+			}
+			f, err = makeWhereFilter(
+				&tf.Filter[k], "", param.Dim[dix].colName, param.Dim[dix].typeOf, false, param.Dim[dix].Name, "parameter "+param.Name)
+			if err != nil {
+				return "", "", err
+			}
+		}
+		q += " AND " + f
+	}
+
+	return arg, q, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"testing"
+)
+
+func TestParseParamTransformExpr(t *testing.T) {
+
+	for _, c := range []struct {
+		expr  string
+		op    ParamTransformOp
+		value float64
+		param string
+	}{
+		{"value * 1.05", MulTransformOp, 1.05, ""},
+		{"Value/2", DivTransformOp, 2, ""},
+		{" value + other_param ", AddTransformOp, 0, "other_param"},
+		{"value - -10", SubTransformOp, -10, ""},
+		{"value = 0", SetTransformOp, 0, ""},
+		{"= 7", SetTransformOp, 7, ""},
+		{"3.5", SetTransformOp, 3.5, ""},
+		{"value_factor", SetTransformOp, 0, "value_factor"},
+	} {
+		op, v, pn, err := ParseParamTransformExpr(c.expr)
+		if err != nil {
+			t.Errorf("error at: %s: %s", c.expr, err.Error())
+			continue
+		}
+		if op != c.op || v != c.value || pn != c.param {
+			t.Errorf("invalid result of: %s: %s %g %s", c.expr, op, v, pn)
+		}
+	}
+
+	for _, expr := range []string{"", "value", "value ^ 2", "value *", "value + 2x", "value + a b"} {
+		if _, _, _, err := ParseParamTransformExpr(expr); err == nil {
+			t.Errorf("expected error for: %s", expr)
+		}
+	}
+}
+
+func TestMakeParamTransformSql(t *testing.T) {
+
+	// parameter: ageYear[age][year] and other parameter: growth[year], dimensions: age and year are integers
+	intType := &TypeMeta{TypeDicRow: TypeDicRow{Name: "int", TypeId: 7}}
+	dblType := &TypeMeta{TypeDicRow: TypeDicRow{Name: "double", TypeId: 14}}
+
+	param := &ParamMeta{
+		ParamDicRow: ParamDicRow{Name: "ageYear", Rank: 2, DbSetTable: "ageYear_w"},
+		Dim: []ParamDimsRow{
+			{Name: "age", TypeId: 7, typeOf: intType, colName: "dim0"},
+			{Name: "year", TypeId: 7, typeOf: intType, colName: "dim1"},
+		},
+		typeOf: dblType,
+	}
+	growth := &ParamMeta{
+		ParamDicRow: ParamDicRow{Name: "growth", Rank: 1, DbSetTable: "growth_w"},
+		Dim:         []ParamDimsRow{{Name: "year", TypeId: 7, typeOf: intType, colName: "dim0"}},
+		typeOf:      dblType,
+	}
+
+	tf := ParamTransform{
+		Name:   "ageYear",
+		Op:     MulTransformOp,
+		Value:  1.05,
+		Filter: []FilterColumn{{Name: "year", Op: GeOpFilter, Values: []string{"2030"}}},
+	}
+	q, err := makeParamTransformSql(param, 11, &tf, nil, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "UPDATE ageYear_w SET param_value = param_value * 1.05 WHERE set_id = 11 AND dim1 >= 2030"; q != e {
+		t.Errorf("invalid sql:\n%s\nexpected:\n%s", q, e)
+	}
+
+	tf = ParamTransform{Name: "ageYear", Op: AddTransformOp, Param: "growth", IsSubId: true, SubId: 2}
+	q, err = makeParamTransformSql(param, 11, &tf, growth, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := " FROM growth_w A WHERE A.set_id = 11 AND A.sub_id = 0 AND A.dim0 = ageYear_w.dim1"
+	if e := "UPDATE ageYear_w SET param_value = param_value + (SELECT A.param_value" + sel + ")" +
+		" WHERE set_id = 11 AND sub_id = 2 AND EXISTS (SELECT *" + sel + ")"; q != e {
+		t.Errorf("invalid sql:\n%s\nexpected:\n%s", q, e)
+	}
+
+	// integer parameter result must be rounded
+	intParam := &ParamMeta{ParamDicRow: ParamDicRow{Name: "count", DbSetTable: "count_w"}, typeOf: intType}
+	tf = ParamTransform{Name: "count", Op: SetTransformOp, Value: 4}
+	q, err = makeParamTransformSql(intParam, 11, &tf, nil, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "UPDATE count_w SET param_value = ROUND(4, 0) WHERE set_id = 11"; q != e {
+		t.Errorf("invalid sql:\n%s\nexpected:\n%s", q, e)
+	}
+
+	// integer division must be done in floating point
+	tf = ParamTransform{Name: "count", Op: DivTransformOp, Value: 2}
+	q, err = makeParamTransformSql(intParam, 11, &tf, nil, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "UPDATE count_w SET param_value = ROUND(param_value * 1.0 / 2, 0) WHERE set_id = 11"; q != e {
+		t.Errorf("invalid sql:\n%s\nexpected:\n%s", q, e)
+	}
+
+	// count zero values of other parameter divisor
+	tf = ParamTransform{Name: "ageYear", Op: DivTransformOp, Param: "growth"}
+	q, err = makeParamDivZeroSql(param, 11, &tf, growth, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "SELECT COUNT(*) FROM ageYear_w WHERE set_id = 11 AND EXISTS (SELECT *" + sel + ")" +
+		" AND (SELECT A.param_value" + sel + ") = 0"; q != e {
+		t.Errorf("invalid sql:\n%s\nexpected:\n%s", q, e)
+	}
+
+	// errors: division by zero, other parameter dimension not found, unknown dimension filter, non-numeric parameter
+	tf = ParamTransform{Name: "ageYear", Op: DivTransformOp, Value: 0}
+	if _, err = makeParamTransformSql(param, 11, &tf, nil, -1); err == nil {
+		t.Error("expected error: division by zero")
+	}
+	tf = ParamTransform{Name: "growth", Op: AddTransformOp, Param: "ageYear"}
+	if _, err = makeParamTransformSql(growth, 11, &tf, param, -1); err == nil {
+		t.Error("expected error: dimension not found")
+	}
+	tf = ParamTransform{Name: "ageYear", Op: MulTransformOp, Value: 2, Filter: []FilterColumn{{Name: "sex", Op: EqOpFilter, Values: []string{"1"}}}}
+	if _, err = makeParamTransformSql(param, 11, &tf, nil, -1); err == nil {
+		t.Error("expected error: filter dimension not found")
+	}
+	bp := &ParamMeta{ParamDicRow: ParamDicRow{Name: "isOk"}, typeOf: &TypeMeta{TypeDicRow: TypeDicRow{Name: "bool", TypeId: 6}}}
+	tf = ParamTransform{Name: "isOk", Op: SetTransformOp, Value: 1}
+	if _, err = makeParamTransformSql(bp, 11, &tf, nil, -1); err == nil {
+		t.Error("expected error: boolean parameter")
+	}
+}
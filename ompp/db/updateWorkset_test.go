@@ -0,0 +1,30 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextUpdateDt(t *testing.T) {
+
+	now := time.Date(2024, 7, 1, 12, 30, 59, 999*int(time.Millisecond), time.Local)
+
+	for _, c := range []struct {
+		last     string
+		expected string
+	}{
+		{"", "2024-07-01 12:30:59.999"},
+		{"2024-06-30 10:00:00.000", "2024-07-01 12:30:59.999"},
+		{"2024-07-01 12:30:59.999", "2024-07-01 12:31:00.000"}, // update within the same millisecond
+		{"2024-07-01 12:31:00.000", "2024-07-01 12:31:00.001"}, // two updates within the same millisecond
+		{"2099-12-31 23:59:59.999", "2100-01-01 00:00:00.000"},
+		{"not a date-time", "2024-07-01 12:30:59.999"},
+	} {
+		if dt := nextUpdateDt(c.last, now); dt != c.expected {
+			t.Errorf("last update: %q expected: %s result: %s", c.last, c.expected, dt)
+		}
+	}
+}
@@ -0,0 +1,610 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openmpp/go/ompp/helper"
+)
+
+// ParamRule is declarative validation rule of parameter values.
+//
+// Rules can be defined in json file next to the model: models/bin/dir/modelName.validation.json
+// or as profile options, see ParamRulesFromOptions() for details.
+type ParamRule struct {
+	Name          string   // parameter name
+	Min           *float64 // if not nil then value must be greater or equal to the minimum
+	Max           *float64 // if not nil then value must be less or equal to the maximum
+	IsNonNegative bool     // if true then value must be non-negative
+	IsNotNull     bool     // if true then value cannot be NULL
+	SumToOne      string   // if not empty then dimension name: sum of values along that dimension must be equal to one
+	Increase      string   // if not empty then dimension name: values must not decrease along that dimension, e.g. over time
+	Decrease      string   // if not empty then dimension name: values must not increase along that dimension
+	Tolerance     float64  // tolerance of sum-to-one check, if zero then default: 1.0e-6
+}
+
+// ParamRuleViolation is parameter value which does not satisfy validation rule.
+type ParamRuleViolation struct {
+	Name  string   // parameter name
+	Rule  string   // rule name: min, max, non-negative, not-null, sum-to-one, increase, decrease
+	SubId int      // parameter sub-value id
+	Dims  []string // dimension items as enum codes, for sum-to-one rule item of rule dimension is empty ""
+	Value string   // value which does not satisfy the rule, for sum-to-one it is a sum of values
+}
+
+// String return rule violation as text, for example: ageSex: min: sub-value 0 [10-20, M] = -1
+func (v ParamRuleViolation) String() string {
+	return v.Name + ": " + v.Rule + ": sub-value " + strconv.Itoa(v.SubId) + " [" + strings.Join(v.Dims, ", ") + "] = " + v.Value
+}
+
+// ParamRuleError is returned if workset parameter values does not satisfy validation rules.
+type ParamRuleError struct {
+	Violation []ParamRuleViolation // list of rule violations
+}
+
+// Error return list of rule violations, one violation per line.
+func (e *ParamRuleError) Error() string {
+
+	var sb strings.Builder
+	sb.WriteString("parameter validation failed")
+	for _, v := range e.Violation {
+		sb.WriteString("\n" + v.String())
+	}
+	return sb.String()
+}
+
+// parameter validation rule names
+const (
+	ParamRuleMin         = "min"          // value must be greater or equal to the minimum
+	ParamRuleMax         = "max"          // value must be less or equal to the maximum
+	ParamRuleNonNegative = "non-negative" // value must be non-negative
+	ParamRuleNotNull     = "not-null"     // value cannot be NULL
+	ParamRuleSumToOne    = "sum-to-one"   // sum of values along dimension must be equal to one
+	ParamRuleIncrease    = "increase"     // values must not decrease along dimension
+	ParamRuleDecrease    = "decrease"     // values must not increase along dimension
+)
+
+const (
+	paramRuleOptPrefix     = "Validate."        // profile options prefix of parameter validation rules: Validate.ParamName.Min
+	defaultParamRuleTol    = 1.0e-6             // default tolerance of sum-to-one rule
+	maxParamRuleViolations = 100                // max number of violations reported for each rule
+	paramRuleFileSuffix    = ".validation.json" // suffix of validation rules file name: modelName.validation.json
+)
+
+// ParamRulesFileName return name of parameter validation rules json file: modelName.validation.json
+func ParamRulesFileName(modelName string) string {
+	return modelName + paramRuleFileSuffix
+}
+
+// ReadParamRulesFile read parameter validation rules from json file.
+// Json file must contain array of ParamRule, if file not exist then return empty list of rules.
+func ReadParamRulesFile(jsonPath string) ([]ParamRule, error) {
+
+	var rules []ParamRule
+
+	isExist, err := helper.FromJsonFile(jsonPath, &rules)
+	if err != nil {
+		return nil, errors.New("failed to read parameter validation rules: " + jsonPath + ": " + err.Error())
+	}
+	if !isExist {
+		return []ParamRule{}, nil
+	}
+	return rules, nil
+}
+
+// ModelParamRules return parameter validation rules of the model:
+// rules from json file modelName.validation.json in rulesDir directory
+// and from profile options of the model profile, profile name is a model name.
+// If profile name is not empty and not the same as model name then rules from that profile also included.
+func ModelParamRules(dbConn *sql.DB, modelDef *ModelMeta, rulesDir string, profile string) ([]ParamRule, error) {
+
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata, look like model not found")
+	}
+
+	// rules from json file next to the model
+	rules, err := ReadParamRulesFile(filepath.Join(rulesDir, ParamRulesFileName(modelDef.Model.Name)))
+	if err != nil {
+		return nil, err
+	}
+
+	// append rules from model profile and from run profile
+	pLst := []string{modelDef.Model.Name}
+	if profile != "" && profile != modelDef.Model.Name {
+		pLst = append(pLst, profile)
+	}
+	for _, pn := range pLst {
+
+		pm, err := GetProfile(dbConn, pn)
+		if err != nil {
+			return nil, errors.New("failed to get profile: " + pn + ": " + err.Error())
+		}
+		pr, err := ParamRulesFromOptions(pm.Opts)
+		if err != nil {
+			return nil, errors.New("invalid profile: " + pn + ": " + err.Error())
+		}
+		rules = append(rules, pr...)
+	}
+	return rules, nil
+}
+
+// ParamRulesFromOptions return parameter validation rules from profile options.
+//
+// Option key must be: Validate.ParamName.RuleName, for example:
+//
+//	Validate.ageSex.Min         = 0
+//	Validate.ageSex.Max         = 1
+//	Validate.ageSex.NonNegative = true
+//	Validate.ageSex.NotNull     = true
+//	Validate.ageSex.SumToOne    = sex
+//	Validate.ageSex.Increase    = age
+//	Validate.ageSex.Decrease    = age
+//	Validate.ageSex.Tolerance   = 1.0e-6
+//
+// Rule names are case-insensitive. Options without Validate. prefix are ignored.
+// Rules are sorted by parameter name.
+func ParamRulesFromOptions(opts map[string]string) ([]ParamRule, error) {
+
+	rm := map[string]*ParamRule{}
+
+	for key, val := range opts {
+
+		if len(key) <= len(paramRuleOptPrefix) || !strings.EqualFold(key[:len(paramRuleOptPrefix)], paramRuleOptPrefix) {
+			continue // not a validation rule
+		}
+		k := strings.LastIndex(key, ".")
+		if k <= len(paramRuleOptPrefix) {
+			return nil, errors.New("invalid parameter validation rule: " + key)
+		}
+		name := key[len(paramRuleOptPrefix):k]
+		rn := key[k+1:]
+		val = strings.TrimSpace(val)
+
+		r, ok := rm[name]
+		if !ok {
+			r = &ParamRule{Name: name}
+			rm[name] = r
+		}
+
+		// parse rule value
+		var err error
+		switch strings.ToLower(rn) {
+		case "min":
+			var f float64
+			if f, err = strconv.ParseFloat(val, 64); err == nil {
+				r.Min = &f
+			}
+		case "max":
+			var f float64
+			if f, err = strconv.ParseFloat(val, 64); err == nil {
+				r.Max = &f
+			}
+		case "tolerance":
+			r.Tolerance, err = strconv.ParseFloat(val, 64)
+		case "nonnegative":
+			r.IsNonNegative, err = strconv.ParseBool(val)
+		case "notnull":
+			r.IsNotNull, err = strconv.ParseBool(val)
+		case "sumtoone":
+			r.SumToOne = val
+		case "increase":
+			r.Increase = val
+		case "decrease":
+			r.Decrease = val
+		default:
+			return nil, errors.New("invalid parameter validation rule: " + key)
+		}
+		if err != nil {
+			return nil, errors.New("invalid value of parameter validation rule: " + key + " = " + val)
+		}
+	}
+
+	rules := make([]ParamRule, 0, len(rm))
+	for _, r := range rm {
+		rules = append(rules, *r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+
+	return rules, nil
+}
+
+// ValidateWorksetParameters check workset parameters values using validation rules.
+//
+// Only parameters which are in the workset are validated, rules for other parameters are ignored.
+// Return list of rule violations, it is empty if all values are valid.
+// For each rule only first 100 violations are returned.
+func ValidateWorksetParameters(dbConn *sql.DB, modelDef *ModelMeta, setId int, rules []ParamRule) ([]ParamRuleViolation, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata, look like model not found")
+	}
+	if setId <= 0 {
+		return nil, errors.New("invalid workset id: " + strconv.Itoa(setId))
+	}
+
+	// read workset parameters in transaction scope
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	vLst, err := trxValidateWorksetParameters(trx, modelDef, setId, rules, nil)
+	if err != nil {
+		trx.Rollback()
+		return nil, err
+	}
+	if err = trx.Commit(); err != nil {
+		return nil, err
+	}
+	return vLst, nil
+}
+
+// trxCheckWorksetParamRules check workset parameters values using validation rules, it does select inside of transaction.
+// If names list is not empty then only rules of those parameters are used.
+// Return ParamRuleError if any of parameter values does not satisfy validation rules.
+func trxCheckWorksetParamRules(trx *sql.Tx, modelDef *ModelMeta, setId int, rules []ParamRule, names []string) error {
+
+	if len(rules) <= 0 {
+		return nil // no validation rules
+	}
+	vLst, err := trxValidateWorksetParameters(trx, modelDef, setId, rules, names)
+	if err != nil {
+		return err
+	}
+	if len(vLst) > 0 {
+		return &ParamRuleError{Violation: vLst}
+	}
+	return nil
+}
+
+// trxValidateWorksetParameters check workset parameters values using validation rules, it does select inside of transaction.
+// Only parameters which are in the workset are validated, rules for other parameters are ignored.
+// If names list is not empty then only rules of those parameters are used.
+func trxValidateWorksetParameters(trx *sql.Tx, modelDef *ModelMeta, setId int, rules []ParamRule, names []string) ([]ParamRuleViolation, error) {
+
+	sId := strconv.Itoa(setId)
+	vLst := []ParamRuleViolation{}
+
+	for k := range rules {
+
+		if len(names) > 0 && !slices.Contains(names, rules[k].Name) {
+			continue // skip rules of other parameters
+		}
+		idx, ok := modelDef.ParamByName(rules[k].Name)
+		if !ok {
+			return nil, errors.New("parameter validation rule error: parameter not found: " + rules[k].Name)
+		}
+		param := &modelDef.Param[idx]
+
+		// skip rule if parameter not in workset
+		n := 0
+		err := TrxSelectFirst(trx,
+			"SELECT COUNT(*) FROM workset_parameter WHERE set_id = "+sId+" AND parameter_hid = "+strconv.Itoa(param.ParamHid),
+			func(row *sql.Row) error {
+				return row.Scan(&n)
+			})
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if n <= 0 {
+			continue
+		}
+
+		// read all parameter values
+		// SELECT sub_id, dim0, dim1, param_value FROM ageSex_w2012817 WHERE set_id = 2
+		q := "SELECT sub_id, "
+		for j := range param.Dim {
+			q += param.Dim[j].colName + ", "
+		}
+		q += "param_value FROM " + param.DbSetTable + " WHERE set_id = " + sId
+
+		cLst := []CellParam{}
+		err = trxReadParameterTo(trx, param, q, func(src interface{}) error {
+			if c, ok := src.(CellParam); ok {
+				cLst = append(cLst, c)
+			}
+			return nil
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return nil, errors.New("failed to read parameter: " + param.Name + ": " + err.Error())
+		}
+
+		// check parameter values
+		rLst, err := checkParamRuleCodes(modelDef, param, &rules[k], cLst)
+		if err != nil {
+			return nil, err
+		}
+		vLst = append(vLst, rLst...)
+	}
+	return vLst, nil
+}
+
+// ValidateParameterCells check parameter values using validation rules, for example, before parameter values written into workset.
+//
+// Only rules for that parameter are used, rules for other parameters are ignored.
+// Return list of rule violations, it is empty if all values are valid.
+// For each rule only first 100 violations are returned.
+func ValidateParameterCells(modelDef *ModelMeta, name string, rules []ParamRule, cLst []CellParam) ([]ParamRuleViolation, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata, look like model not found")
+	}
+	idx, ok := modelDef.ParamByName(name)
+	if !ok {
+		return nil, errors.New("parameter not found: " + name)
+	}
+	param := &modelDef.Param[idx]
+
+	vLst := []ParamRuleViolation{}
+
+	for k := range rules {
+		if rules[k].Name != name {
+			continue
+		}
+		rLst, err := checkParamRuleCodes(modelDef, param, &rules[k], cLst)
+		if err != nil {
+			return nil, err
+		}
+		vLst = append(vLst, rLst...)
+	}
+	return vLst, nil
+}
+
+// ValidateParameterFrom check parameter values of model run or workset using validation rules,
+// for example, before parameter copied from model run or workset into other workset.
+//
+// If isFromSet is true then fromId is a workset id else it is model run id.
+// Only rules for that parameter are used, rules for other parameters are ignored.
+// Return list of rule violations, it is empty if all values are valid.
+func ValidateParameterFrom(dbConn *sql.DB, modelDef *ModelMeta, name string, fromId int, isFromSet bool, rules []ParamRule) ([]ParamRuleViolation, error) {
+
+	isAny := false
+	for k := range rules {
+		if isAny = rules[k].Name == name; isAny {
+			break
+		}
+	}
+	if !isAny {
+		return []ParamRuleViolation{}, nil // no rules for that parameter
+	}
+
+	cLst, err := readParamCells(dbConn, modelDef, name, fromId, isFromSet)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateParameterCells(modelDef, name, rules, cLst)
+}
+
+// read all parameter values from model run or from workset.
+func readParamCells(dbConn *sql.DB, modelDef *ModelMeta, name string, fromId int, isFromSet bool) ([]CellParam, error) {
+
+	cLst := []CellParam{}
+
+	layout := ReadParamLayout{ReadLayout: ReadLayout{Name: name, FromId: fromId}, IsFromSet: isFromSet}
+
+	_, err := ReadParameterTo(dbConn, modelDef, &layout, func(src interface{}) (bool, error) {
+		if c, ok := src.(CellParam); ok {
+			cLst = append(cLst, c)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.New("failed to read parameter: " + name + ": " + err.Error())
+	}
+	return cLst, nil
+}
+
+// check parameter values using validation rule and return rule violations with dimension items as enum codes.
+func checkParamRuleCodes(modelDef *ModelMeta, param *ParamMeta, rule *ParamRule, cLst []CellParam) ([]ParamRuleViolation, error) {
+
+	rLst, err := checkParamRule(param, rule, cLst)
+	if err != nil {
+		return nil, err
+	}
+	if len(rLst) <= 0 {
+		return []ParamRuleViolation{}, nil
+	}
+
+	// convert dimension items id's to enum codes
+	cvt := CellParamConverter{ModelDef: modelDef, Name: param.Name}
+	cvtCode, err := cvt.IdToCodeCell(modelDef, param.Name)
+	if err != nil {
+		return nil, err
+	}
+	vLst := make([]ParamRuleViolation, 0, len(rLst))
+
+	for j := range rLst {
+
+		rLst[j].Name = param.Name
+		c := CellParam{cellIdValue: cellIdValue{DimIds: rLst[j].dimIds}, SubId: rLst[j].SubId}
+
+		cc, err := cvtCode(c)
+		if err != nil {
+			return nil, err
+		}
+		if ccp, ok := cc.(CellCodeParam); ok {
+			rLst[j].Dims = ccp.Dims
+		}
+		if rLst[j].emptyDim >= 0 && rLst[j].emptyDim < len(rLst[j].Dims) {
+			rLst[j].Dims[rLst[j].emptyDim] = "" // sum-to-one: rule dimension item is empty
+		}
+		vLst = append(vLst, rLst[j].ParamRuleViolation)
+	}
+	return vLst, nil
+}
+
+// rule violation with dimension items id's
+type paramRuleCell struct {
+	ParamRuleViolation
+	dimIds   []int // dimension items id's
+	emptyDim int   // if not negative then index of rule dimension of sum-to-one, item of that dimension is empty
+}
+
+// checkParamRule check parameter values and return list of cells which does not satisfy validation rule.
+// Only first maxParamRuleViolations are returned for each rule.
+func checkParamRule(param *ParamMeta, rule *ParamRule, cLst []CellParam) ([]paramRuleCell, error) {
+
+	// numeric rules can be applied only to float or integer parameters
+	isNum := param.typeOf != nil && (param.typeOf.IsFloat() || param.typeOf.IsInt())
+
+	if !isNum && (rule.Min != nil || rule.Max != nil || rule.IsNonNegative || rule.SumToOne != "" || rule.Increase != "" || rule.Decrease != "") {
+		return nil, errors.New("parameter validation rule error: parameter must be float or integer type: " + param.Name)
+	}
+
+	// find dimension index by name
+	dimIndex := func(dn string) (int, error) {
+		if dn == "" {
+			return -1, nil
+		}
+		for k := range param.Dim {
+			if param.Dim[k].Name == dn {
+				return k, nil
+			}
+		}
+		return -1, errors.New("parameter validation rule error: dimension " + dn + " not found in parameter: " + param.Name)
+	}
+	sumIdx, err := dimIndex(rule.SumToOne)
+	if err != nil {
+		return nil, err
+	}
+	incIdx, err := dimIndex(rule.Increase)
+	if err != nil {
+		return nil, err
+	}
+	decIdx, err := dimIndex(rule.Decrease)
+	if err != nil {
+		return nil, err
+	}
+
+	tol := rule.Tolerance
+	if tol <= 0 {
+		tol = defaultParamRuleTol
+	}
+
+	// check each value: not null, min, max, non-negative
+	vLst := []paramRuleCell{}
+	nRule := map[string]int{}
+
+	addViolation := func(rn string, subId int, dimIds []int, emptyDim int, val string) {
+		if nRule[rn] >= maxParamRuleViolations {
+			return
+		}
+		nRule[rn]++
+		vLst = append(vLst, paramRuleCell{
+			ParamRuleViolation: ParamRuleViolation{Rule: rn, SubId: subId, Value: val},
+			dimIds:             append([]int{}, dimIds...),
+			emptyDim:           emptyDim,
+		})
+	}
+
+	fv := make([]float64, len(cLst)) // cell values as float
+
+	for k := range cLst {
+
+		if cLst[k].IsNull {
+			if rule.IsNotNull {
+				addViolation(ParamRuleNotNull, cLst[k].SubId, cLst[k].DimIds, -1, "null")
+			}
+			continue
+		}
+		if !isNum {
+			continue
+		}
+
+		switch v := cLst[k].Value.(type) {
+		case float64:
+			fv[k] = v
+		case float32:
+			fv[k] = float64(v)
+		default:
+			if i, ok := helper.ToIntValue(v); ok {
+				fv[k] = float64(i)
+			}
+		}
+		sv := strconv.FormatFloat(fv[k], 'g', -1, 64)
+
+		if rule.Min != nil && fv[k] < *rule.Min {
+			addViolation(ParamRuleMin, cLst[k].SubId, cLst[k].DimIds, -1, sv)
+		}
+		if rule.Max != nil && fv[k] > *rule.Max {
+			addViolation(ParamRuleMax, cLst[k].SubId, cLst[k].DimIds, -1, sv)
+		}
+		if rule.IsNonNegative && fv[k] < 0 {
+			addViolation(ParamRuleNonNegative, cLst[k].SubId, cLst[k].DimIds, -1, sv)
+		}
+	}
+
+	// group cells by sub-value id and all dimensions except of rule dimension
+	// return groups in order of cells and indices of cells in each group sorted by rule dimension item
+	groupBy := func(dimIdx int) [][]int {
+
+		gm := map[string]int{}
+		gLst := [][]int{}
+
+		for k := range cLst {
+			if cLst[k].IsNull {
+				continue
+			}
+			key := strconv.Itoa(cLst[k].SubId)
+			for i, d := range cLst[k].DimIds {
+				if i != dimIdx {
+					key += "," + strconv.Itoa(d)
+				}
+			}
+			n, ok := gm[key]
+			if !ok {
+				n = len(gLst)
+				gm[key] = n
+				gLst = append(gLst, []int{})
+			}
+			gLst[n] = append(gLst[n], k)
+		}
+		for _, g := range gLst {
+			sort.SliceStable(g, func(i, j int) bool { return cLst[g[i]].DimIds[dimIdx] < cLst[g[j]].DimIds[dimIdx] })
+		}
+		return gLst
+	}
+
+	// sum of values along dimension must be equal to one
+	if sumIdx >= 0 {
+		for _, g := range groupBy(sumIdx) {
+
+			s := 0.0
+			for _, k := range g {
+				s += fv[k]
+			}
+			if math.Abs(s-1.0) > tol {
+				addViolation(ParamRuleSumToOne, cLst[g[0]].SubId, cLst[g[0]].DimIds, sumIdx, strconv.FormatFloat(s, 'g', -1, 64))
+			}
+		}
+	}
+
+	// values must not decrease or must not increase along dimension
+	checkOrder := func(dimIdx int, rn string, isInc bool) {
+		for _, g := range groupBy(dimIdx) {
+			for i := 1; i < len(g); i++ {
+				if isInc && fv[g[i]] < fv[g[i-1]] || !isInc && fv[g[i]] > fv[g[i-1]] {
+					addViolation(rn, cLst[g[i]].SubId, cLst[g[i]].DimIds, -1, strconv.FormatFloat(fv[g[i]], 'g', -1, 64))
+				}
+			}
+		}
+	}
+	if incIdx >= 0 {
+		checkOrder(incIdx, ParamRuleIncrease, true)
+	}
+	if decIdx >= 0 {
+		checkOrder(decIdx, ParamRuleDecrease, false)
+	}
+
+	return vLst, nil
+}
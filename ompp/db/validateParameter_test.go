@@ -0,0 +1,119 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParamRulesFromOptions(t *testing.T) {
+
+	opts := map[string]string{
+		"OpenM.SubValues":             "4",
+		"Validate.ageSex.Min":         "0",
+		"Validate.ageSex.max":         "1.5",
+		"validate.ageSex.SumToOne":    "sex",
+		"Validate.salary.NotNull":     "true",
+		"Validate.salary.Increase":    "year",
+		"Validate.salary.Tolerance":   "0.01",
+		"Validate.salary.NonNegative": "1",
+	}
+	rules, err := ParamRulesFromOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[0].Name != "ageSex" || rules[1].Name != "salary" {
+		t.Fatalf("invalid rules: %v", rules)
+	}
+	if rules[0].Min == nil || *rules[0].Min != 0 || rules[0].Max == nil || *rules[0].Max != 1.5 || rules[0].SumToOne != "sex" {
+		t.Errorf("invalid ageSex rule: %v", rules[0])
+	}
+	if !rules[1].IsNotNull || !rules[1].IsNonNegative || rules[1].Increase != "year" || rules[1].Tolerance != 0.01 || rules[1].Min != nil {
+		t.Errorf("invalid salary rule: %v", rules[1])
+	}
+
+	for _, bad := range []map[string]string{
+		{"Validate.ageSex.Unknown": "1"},
+		{"Validate.ageSex.Min": "zero"},
+		{"Validate.Min": "0"},
+	} {
+		if _, err = ParamRulesFromOptions(bad); err == nil {
+			t.Errorf("expected error for: %v", bad)
+		}
+	}
+}
+
+func TestCheckParamRule(t *testing.T) {
+
+	// parameter: ageSex[age][sex], age: 0, 1, 2 and sex: 0, 1
+	param := &ParamMeta{
+		ParamDicRow: ParamDicRow{Name: "ageSex", Rank: 2},
+		Dim:         []ParamDimsRow{{Name: "age"}, {Name: "sex"}},
+		typeOf:      &TypeMeta{TypeDicRow: TypeDicRow{Name: "double"}},
+	}
+	cell := func(age, sex int, v float64) CellParam {
+		return CellParam{cellIdValue: cellIdValue{DimIds: []int{age, sex}, Value: v}}
+	}
+	cLst := []CellParam{
+		cell(0, 0, 0.4), cell(0, 1, 0.6),
+		cell(1, 0, 0.5), cell(1, 1, 0.2),
+		cell(2, 0, 0.45), cell(2, 1, -0.1),
+		{cellIdValue: cellIdValue{DimIds: []int{2, 1}, IsNull: true}, SubId: 1},
+	}
+
+	fMin := 0.0
+	rule := ParamRule{Name: "ageSex", Min: &fMin, IsNotNull: true, SumToOne: "sex", Increase: "age"}
+
+	vLst, err := checkParamRule(param, &rule, cLst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nr := map[string]int{}
+	for _, v := range vLst {
+		nr[v.Rule]++
+	}
+	if nr[ParamRuleMin] != 1 || nr[ParamRuleNotNull] != 1 || nr[ParamRuleSumToOne] != 2 || nr[ParamRuleIncrease] != 3 || len(vLst) != 7 {
+		t.Errorf("invalid rule violations: %v", vLst)
+	}
+	for _, v := range vLst {
+		if v.Rule == ParamRuleSumToOne && (v.emptyDim != 1 || v.Value != "0.7" && v.Value != "0.35") {
+			t.Errorf("invalid sum-to-one violation: %v", v)
+		}
+	}
+
+	// error: dimension not found
+	rule = ParamRule{Name: "ageSex", Decrease: "year"}
+	if _, err = checkParamRule(param, &rule, cLst); err == nil {
+		t.Error("expected error: dimension not found")
+	}
+
+	// error: numeric rule for boolean parameter
+	bp := &ParamMeta{ParamDicRow: ParamDicRow{Name: "isOk"}, typeOf: &TypeMeta{TypeDicRow: TypeDicRow{Name: "bool"}}}
+	rule = ParamRule{Name: "isOk", IsNonNegative: true}
+	if _, err = checkParamRule(bp, &rule, nil); err == nil {
+		t.Error("expected error: numeric rule for boolean parameter")
+	}
+}
+
+func TestParamRuleError(t *testing.T) {
+
+	var err error = &ParamRuleError{Violation: []ParamRuleViolation{
+		{Name: "ageSex", Rule: ParamRuleMin, SubId: 0, Dims: []string{"10-20", "M"}, Value: "-1"},
+		{Name: "ageSex", Rule: ParamRuleSumToOne, SubId: 1, Dims: []string{"20-30", ""}, Value: "1.1"},
+	}}
+
+	msg := "parameter validation failed" +
+		"\nageSex: min: sub-value 0 [10-20, M] = -1" +
+		"\nageSex: sum-to-one: sub-value 1 [20-30, ] = 1.1"
+	if err.Error() != msg {
+		t.Errorf("invalid error message: %q, expected: %q", err.Error(), msg)
+	}
+
+	var re *ParamRuleError
+	if !errors.As(err, &re) || len(re.Violation) != 2 {
+		t.Errorf("expected ParamRuleError with 2 violations: %v", err)
+	}
+}
@@ -0,0 +1,442 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// VerifyIssue is an integrity problem found in model database or in model text files (.json and .csv)
+type VerifyIssue struct {
+	Kind string // issue kind, for example: run-digest, parameter-digest, missing-parameter
+	Id   int    // model run id, workset id or task id, zero if not applicable
+	Name string // model run, workset or task name
+	Item string // parameter or output table name, if applicable
+	Msg  string // issue description
+}
+
+// integrity issue kinds
+const (
+	VerifyModelDigest  = "model-digest"      // model digest mismatch
+	VerifyRunDigest    = "run-digest"        // model run metadata digest mismatch
+	VerifyValueDigest  = "value-digest"      // model run value digest mismatch
+	VerifyParamDigest  = "parameter-digest"  // run parameter value digest mismatch
+	VerifyTableDigest  = "table-digest"      // run output table value digest mismatch
+	VerifyMissingParam = "missing-parameter" // model run does not contain parameter or parameter values
+	VerifyMissingTable = "missing-table"     // model run output table values not found
+	VerifyBaseRun      = "base-run"          // run parameter or output table values refer to missing base run
+	VerifyDeletedRun   = "deleted-run"       // model run delete was not completed
+	VerifySetBaseRun   = "workset-base-run"  // workset base run does not exist
+	VerifyOrphanSet    = "orphan-workset"    // workset parameter rows without workset
+	VerifyTaskRun      = "task-run"          // modeling task run refer to missing model run or workset
+	VerifyMissingFile  = "missing-file"      // .json or .csv file not found
+	VerifyInvalidFile  = "invalid-file"      // .json or .csv file cannot be read
+)
+
+// String return issue as text message, for example: parameter-digest: run 101 Default: ageSex: digest mismatch
+func (vi *VerifyIssue) String() string {
+
+	s := vi.Kind + ":"
+	switch {
+	case vi.Id > 0 && vi.Name != "":
+		s += " " + strconv.Itoa(vi.Id) + " " + vi.Name + ":"
+	case vi.Id > 0:
+		s += " " + strconv.Itoa(vi.Id) + ":"
+	case vi.Name != "":
+		s += " " + vi.Name + ":"
+	}
+	if vi.Item != "" {
+		s += " " + vi.Item + ":"
+	}
+	return s + " " + vi.Msg
+}
+
+// DigestParameterFrom calculate run parameter value digest from parameter cells returned by from().
+//
+// Cells must be in primary key order: sub_id, dim0, dim1,... otherwise digest is incorrect and false is returned.
+// Double format is used for float model types digest calculation, if non-empty format supplied.
+func DigestParameterFrom(modelDef *ModelMeta, name string, doubleFmt string, from func() (interface{}, error)) (string, bool, error) {
+
+	k, ok := modelDef.ParamByName(name)
+	if !ok {
+		return "", false, errors.New("parameter not found: " + name)
+	}
+
+	hMd5, digestRow, isOrderBy, err := digestParameterFrom(modelDef, &modelDef.Param[k], doubleFmt)
+	if err != nil {
+		return "", false, err
+	}
+	if err = digestAllFrom(from, digestRow); err != nil {
+		return "", false, err
+	}
+
+	return fmt.Sprintf("%x", hMd5.Sum(nil)), isOrderBy != nil && *isOrderBy, nil
+}
+
+// DigestOutputTableFrom calculate run output table value digest from accumulators cells returned by accFrom()
+// and expressions cells returned by exprFrom().
+//
+// Cells must be in primary key order otherwise digest is incorrect and false is returned.
+// Double format is used for float model types digest calculation, if non-empty format supplied.
+func DigestOutputTableFrom(
+	modelDef *ModelMeta, name string, doubleFmt string, accFrom func() (interface{}, error), exprFrom func() (interface{}, error),
+) (string, bool, error) {
+
+	k, ok := modelDef.OutTableByName(name)
+	if !ok {
+		return "", false, errors.New("output table not found: " + name)
+	}
+	meta := &modelDef.Table[k]
+
+	hMd5, digestAcc, isAccOrder, err := digestAccumulatorsFrom(modelDef, meta, doubleFmt)
+	if err != nil {
+		return "", false, err
+	}
+	if err = digestAllFrom(accFrom, digestAcc); err != nil {
+		return "", false, err
+	}
+
+	digestExpr, isExprOrder, err := digestExpressionsFrom(modelDef, meta, doubleFmt, hMd5)
+	if err != nil {
+		return "", false, err
+	}
+	if err = digestAllFrom(exprFrom, digestExpr); err != nil {
+		return "", false, err
+	}
+
+	isOrderBy := isAccOrder != nil && *isAccOrder && isExprOrder != nil && *isExprOrder
+
+	return fmt.Sprintf("%x", hMd5.Sum(nil)), isOrderBy, nil
+}
+
+// append to digest all cells returned by from(), from() must return nil cell at the end of data.
+func digestAllFrom(from func() (interface{}, error), digestRow func(interface{}) error) error {
+	for {
+		c, err := from()
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return nil // end of data
+		}
+		if err = digestRow(c); err != nil {
+			return err
+		}
+	}
+}
+
+// VerifyRun recalculate model run digests and compare it with stored in database run_lst, run_parameter and run_table digests.
+//
+// Only completed model runs are verified, for other runs empty list of issues returned.
+// Run metadata digest and run value digest verified if run status is success or exit.
+// Run must contain all model parameters and parameter values and output tables values digests must be the same as stored in database.
+// Double format is used for float model types digest calculation, if non-empty format supplied.
+func VerifyRun(dbConn *sql.DB, modelDef *ModelMeta, run *RunRow, doubleFmt string) ([]VerifyIssue, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata, look like model not found")
+	}
+	if run == nil {
+		return nil, errors.New("invalid (empty) model run")
+	}
+	if !IsRunCompleted(run.Status) {
+		return []VerifyIssue{}, nil // run not completed: nothing to verify
+	}
+
+	viLst := []VerifyIssue{}
+	addIssue := func(kind, item, msg string) {
+		viLst = append(viLst, VerifyIssue{Kind: kind, Id: run.RunId, Name: run.Name, Item: item, Msg: msg})
+	}
+	srId := strconv.Itoa(run.RunId)
+
+	// select stored parameters value digests and output tables value digests
+	pDgst := make([]sql.NullString, len(modelDef.Param))
+	isParam := make([]bool, len(modelDef.Param))
+
+	err := SelectRows(dbConn,
+		"SELECT parameter_hid, value_digest FROM run_parameter WHERE run_id = "+srId,
+		func(rows *sql.Rows) error {
+			var hId int
+			var sd sql.NullString
+			if err := rows.Scan(&hId, &sd); err != nil {
+				return err
+			}
+			if k, ok := modelDef.ParamByHid(hId); ok {
+				pDgst[k] = sd
+				isParam[k] = true
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	tDgst := make([]sql.NullString, len(modelDef.Table))
+	isTable := make([]bool, len(modelDef.Table))
+
+	err = SelectRows(dbConn,
+		"SELECT table_hid, value_digest FROM run_table WHERE run_id = "+srId,
+		func(rows *sql.Rows) error {
+			var hId int
+			var sd sql.NullString
+			if err := rows.Scan(&hId, &sd); err != nil {
+				return err
+			}
+			if k, ok := modelDef.OutTableByHid(hId); ok {
+				tDgst[k] = sd
+				isTable[k] = true
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// verify run metadata digest and run value digest
+	if run.Status == DoneRunStatus || run.Status == ExitRunStatus {
+
+		if dg := RunMetaDigest(modelDef.Model.Digest, run.Name, run.SubCount, run.CreateDateTime, run.RunStamp); dg != run.RunDigest {
+			addIssue(VerifyRunDigest, "", "run digest mismatch: "+run.RunDigest+" expected: "+dg)
+		}
+
+		pl := []string{}
+		for k := range pDgst {
+			if isParam[k] {
+				pl = append(pl, pDgst[k].String)
+			}
+		}
+		tl := []string{}
+		for k := range tDgst {
+			if isTable[k] {
+				tl = append(tl, tDgst[k].String)
+			}
+		}
+		if dg := RunValueDigest(run.SubCount, run.SubCompleted, run.Status, pl, tl); dg != run.ValueDigest {
+			addIssue(VerifyValueDigest, "", "value digest mismatch: "+run.ValueDigest+" expected: "+dg)
+		}
+	}
+
+	// all model parameters must be included in model run, recalculate parameter values digest
+	for k := range modelDef.Param {
+
+		name := modelDef.Param[k].Name
+		if !isParam[k] {
+			addIssue(VerifyMissingParam, name, "parameter not found in model run")
+			continue
+		}
+
+		hMd5, digestRow, _, err := digestParameterFrom(modelDef, &modelDef.Param[k], doubleFmt)
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		_, err = ReadParameterTo(dbConn, modelDef,
+			&ReadParamLayout{ReadLayout: ReadLayout{Name: name, FromId: run.RunId}},
+			func(src interface{}) (bool, error) {
+				n++
+				return true, digestRow(src)
+			})
+		if err != nil {
+			addIssue(VerifyMissingParam, name, "error at read parameter values: "+err.Error())
+			continue
+		}
+		if n <= 0 {
+			addIssue(VerifyMissingParam, name, "parameter values not found")
+			continue
+		}
+
+		if dg := fmt.Sprintf("%x", hMd5.Sum(nil)); dg != pDgst[k].String {
+			addIssue(VerifyParamDigest, name, "value digest mismatch: "+pDgst[k].String+" expected: "+dg)
+		}
+	}
+
+	// recalculate output tables values digest, if the table included in run results
+	for k := range modelDef.Table {
+
+		if !isTable[k] {
+			continue // skip table: it is suppressed and not in run results
+		}
+		meta := &modelDef.Table[k]
+
+		hMd5, digestAcc, _, err := digestAccumulatorsFrom(modelDef, meta, doubleFmt)
+		if err != nil {
+			return nil, err
+		}
+		layout := ReadTableLayout{ReadLayout: ReadLayout{Name: meta.Name, FromId: run.RunId}, IsAccum: true}
+		n := 0
+
+		_, err = ReadOutputTableTo(dbConn, modelDef, &layout, func(src interface{}) (bool, error) {
+			n++
+			return true, digestAcc(src)
+		})
+		if err != nil {
+			addIssue(VerifyMissingTable, meta.Name, "error at read output table accumulators: "+err.Error())
+			continue
+		}
+
+		digestExpr, _, err := digestExpressionsFrom(modelDef, meta, doubleFmt, hMd5)
+		if err != nil {
+			return nil, err
+		}
+		layout.IsAccum = false
+
+		_, err = ReadOutputTableTo(dbConn, modelDef, &layout, func(src interface{}) (bool, error) {
+			n++
+			return true, digestExpr(src)
+		})
+		if err != nil {
+			addIssue(VerifyMissingTable, meta.Name, "error at read output table expressions: "+err.Error())
+			continue
+		}
+		if n <= 0 {
+			addIssue(VerifyMissingTable, meta.Name, "output table values not found")
+			continue
+		}
+
+		if dg := fmt.Sprintf("%x", hMd5.Sum(nil)); dg != tDgst[k].String {
+			addIssue(VerifyTableDigest, meta.Name, "value digest mismatch: "+tDgst[k].String+" expected: "+dg)
+		}
+	}
+
+	return viLst, nil
+}
+
+// VerifyModelRefs check referential consistency of model runs, worksets and modeling tasks.
+//
+// It does report:
+// model runs where delete was not completed,
+// run parameters or output tables which refer to missing base run values,
+// worksets where base run does not exist,
+// workset parameters rows without workset,
+// modeling task runs which refer to deleted model runs or missing worksets.
+func VerifyModelRefs(dbConn *sql.DB, modelId int) ([]VerifyIssue, error) {
+
+	// validate parameters
+	if modelId <= 0 {
+		return nil, errors.New("invalid model id: " + strconv.Itoa(modelId))
+	}
+	smId := strconv.Itoa(modelId)
+
+	viLst := []VerifyIssue{}
+
+	// select rows and append issue for each row
+	selectIssues := func(kind string, q string, msg func(ref int) string) error {
+
+		return SelectRows(dbConn, q,
+			func(rows *sql.Rows) error {
+				var id, ref int
+				var name string
+				if err := rows.Scan(&id, &name, &ref); err != nil {
+					return err
+				}
+				viLst = append(viLst, VerifyIssue{Kind: kind, Id: id, Name: name, Msg: msg(ref)})
+				return nil
+			})
+	}
+
+	// model runs where delete was not completed
+	err := selectIssues(VerifyDeletedRun,
+		"SELECT H.run_id, H.run_name, 0 FROM run_lst H"+
+			" WHERE H.model_id = "+smId+
+			" AND H.status = "+ToQuoted(DeleteRunStatus)+
+			" ORDER BY 1",
+		func(ref int) string {
+			return "model run delete was not completed"
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// run parameters and output tables values must exist in base run
+	err = selectIssues(VerifyBaseRun,
+		"SELECT H.run_id, H.run_name, R.base_run_id FROM run_parameter R"+
+			" INNER JOIN run_lst H ON (H.run_id = R.run_id)"+
+			" WHERE H.model_id = "+smId+
+			" AND NOT EXISTS (SELECT B.run_id FROM run_lst B WHERE B.run_id = R.base_run_id)"+
+			" ORDER BY 1, 3",
+		func(ref int) string {
+			return "parameter values base run not found: " + strconv.Itoa(ref)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = selectIssues(VerifyBaseRun,
+		"SELECT H.run_id, H.run_name, R.base_run_id FROM run_table R"+
+			" INNER JOIN run_lst H ON (H.run_id = R.run_id)"+
+			" WHERE H.model_id = "+smId+
+			" AND NOT EXISTS (SELECT B.run_id FROM run_lst B WHERE B.run_id = R.base_run_id)"+
+			" ORDER BY 1, 3",
+		func(ref int) string {
+			return "output table values base run not found: " + strconv.Itoa(ref)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// workset base run must exist
+	err = selectIssues(VerifySetBaseRun,
+		"SELECT W.set_id, W.set_name, W.base_run_id FROM workset_lst W"+
+			" WHERE W.model_id = "+smId+
+			" AND W.base_run_id IS NOT NULL"+
+			" AND NOT EXISTS"+
+			" (SELECT R.run_id FROM run_lst R WHERE R.run_id = W.base_run_id AND R.status <> "+ToQuoted(DeleteRunStatus)+")"+
+			" ORDER BY 1",
+		func(ref int) string {
+			return "workset base run not found: " + strconv.Itoa(ref)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// workset parameters rows must belong to existing workset
+	err = selectIssues(VerifyOrphanSet,
+		"SELECT DISTINCT WP.set_id, '', 0 FROM workset_parameter WP"+
+			" INNER JOIN model_parameter_dic M ON (M.parameter_hid = WP.parameter_hid)"+
+			" WHERE M.model_id = "+smId+
+			" AND NOT EXISTS (SELECT W.set_id FROM workset_lst W WHERE W.set_id = WP.set_id)"+
+			" ORDER BY 1",
+		func(ref int) string {
+			return "workset parameter rows without workset"
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// modeling task runs must refer to existing model runs and worksets
+	err = selectIssues(VerifyTaskRun,
+		"SELECT T.task_id, T.task_name, TRS.run_id FROM task_run_set TRS"+
+			" INNER JOIN task_lst T ON (T.task_id = TRS.task_id)"+
+			" WHERE T.model_id = "+smId+
+			" AND TRS.run_id > 0"+
+			" AND NOT EXISTS"+
+			" (SELECT R.run_id FROM run_lst R WHERE R.run_id = TRS.run_id AND R.status <> "+ToQuoted(DeleteRunStatus)+")"+
+			" ORDER BY 1, 3",
+		func(ref int) string {
+			return "task run refer to deleted model run: " + strconv.Itoa(ref)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = selectIssues(VerifyTaskRun,
+		"SELECT T.task_id, T.task_name, TRS.set_id FROM task_run_set TRS"+
+			" INNER JOIN task_lst T ON (T.task_id = TRS.task_id)"+
+			" WHERE T.model_id = "+smId+
+			" AND TRS.set_id > 0"+
+			" AND NOT EXISTS (SELECT W.set_id FROM workset_lst W WHERE W.set_id = TRS.set_id)"+
+			" ORDER BY 1, 3",
+		func(ref int) string {
+			return "task run refer to deleted workset: " + strconv.Itoa(ref)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return viLst, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"crypto/md5"
+	"fmt"
+	"testing"
+)
+
+func TestRunDigest(t *testing.T) {
+
+	md5Str := func(s string) string {
+		return fmt.Sprintf("%x", md5.Sum([]byte(s)))
+	}
+
+	// run metadata digest
+	dg := RunMetaDigest("a1b2c3", "Default", 4, "2024-07-16 13:40:53.882", "2024_07_16_13_40_53_882")
+	e := md5Str("model_digest,run_name,sub_count,create_dt,run_stamp\n" +
+		"a1b2c3,Default,4,2024-07-16 13:40:53.882,2024_07_16_13_40_53_882\n")
+	if dg != e {
+		t.Errorf("run metadata digest: expected %s, got %s", e, dg)
+	}
+
+	// successful run: parameters and output tables digests
+	dg = RunValueDigest(4, 4, DoneRunStatus, []string{"p1", ""}, []string{"t1"})
+	e = md5Str("sub_count,sub_completed,status\n" + "4,4,s\n" +
+		"value_digest\n" + "p1\n" + "\n" +
+		"value_digest\n" + "t1\n")
+	if dg != e {
+		t.Errorf("success run value digest: expected %s, got %s", e, dg)
+	}
+
+	// exit run: only parameters digests
+	dg = RunValueDigest(4, 2, ExitRunStatus, []string{"p1", "p2"}, []string{"t1"})
+	e = md5Str("sub_count,sub_completed,status\n" + "4,2,x\n" +
+		"value_digest\n" + "p1\n" + "p2\n")
+	if dg != e {
+		t.Errorf("exit run value digest: expected %s, got %s", e, dg)
+	}
+}
+
+func TestVerifyIssueString(t *testing.T) {
+
+	vi := VerifyIssue{Kind: VerifyParamDigest, Id: 101, Name: "Default", Item: "ageSex", Msg: "value digest mismatch"}
+	if s := vi.String(); s != "parameter-digest: 101 Default: ageSex: value digest mismatch" {
+		t.Errorf("unexpected issue message: %s", s)
+	}
+	vi = VerifyIssue{Kind: VerifyMissingFile, Name: "modelOne.run.Default.json", Msg: "file not found"}
+	if s := vi.String(); s != "missing-file: modelOne.run.Default.json: file not found" {
+		t.Errorf("unexpected issue message: %s", s)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// ParamCellValue is a new value of all parameter cells or of one parameter cell
+type ParamCellValue struct {
+	Name  string   // parameter name
+	Dims  []string // if not empty then dimension items codes of parameter cell else value assigned to all cells
+	Value float64  // new parameter value
+}
+
+// CreateWorksetFromBase create new workset from base model run or base workset and assign new values to parameter cells.
+//
+// If base is a model run then workset contains only updated parameters and all other parameters are from the base run.
+// If base is a workset then all parameters of base workset are copied and base run of new workset is the same.
+// If neither base run nor base set specified then default workset of the model used as base.
+// Only float or integer parameters can be updated. Workset is read-only, ready to run the model.
+// If validation rules not empty then workset parameters values must satisfy the rules, otherwise ParamRuleError returned.
+// It is an error if workset already exist.
+func CreateWorksetFromBase(
+	dbConn *sql.DB, modelDef *ModelMeta, langDef *LangMeta, setName string, baseRunDigestStampName, baseSetName string, pvLst []ParamCellValue, txt []DescrNote, rules []ParamRule,
+) error {
+
+	// validate parameters
+	if modelDef == nil {
+		return errors.New("invalid (empty) model metadata")
+	}
+	if langDef == nil {
+		return errors.New("invalid (empty) language list")
+	}
+	if setName == "" {
+		return errors.New("invalid (empty) workset name")
+	}
+
+	// find parameters and make parameter cell filters
+	pmLst := make([]*ParamMeta, len(pvLst))
+	uLst := make([]paramCellUpdate, len(pvLst))
+
+	for k := range pvLst {
+
+		i, ok := modelDef.ParamByName(pvLst[k].Name)
+		if !ok {
+			return errors.New("model: " + modelDef.Model.Name + " parameter " + pvLst[k].Name + " not found")
+		}
+		pmLst[k] = &modelDef.Param[i]
+
+		if pmLst[k].typeOf == nil || !pmLst[k].typeOf.IsFloat() && !pmLst[k].typeOf.IsInt() {
+			return errors.New("error: parameter must be float or integer type: " + pmLst[k].Name)
+		}
+		v := pvLst[k].Value
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("invalid parameter value: " + pmLst[k].Name + ": " + strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		if pmLst[k].typeOf.IsInt() {
+			v = math.Round(v)
+		}
+
+		w, err := paramCellWhere(pmLst[k], pvLst[k].Dims)
+		if err != nil {
+			return err
+		}
+		uLst[k] = paramCellUpdate{pm: pmLst[k], where: w, value: v}
+	}
+
+	// find base model run or base workset, list of parameters to copy and check: workset must not exist
+	baseRun, baseSet, err := findWorksetBase(dbConn, modelDef, baseRunDigestStampName, baseSetName)
+	if err != nil {
+		return err
+	}
+	hLst, err := worksetCopyParamList(dbConn, pmLst, baseSet)
+	if err != nil {
+		return err
+	}
+	ws, err := GetWorksetByName(dbConn, modelDef.Model.ModelId, setName)
+	if err != nil {
+		return err
+	}
+	if ws != nil {
+		return errors.New("error: workset already exist: " + setName)
+	}
+
+	wm := WorksetMeta{
+		Set: WorksetRow{
+			ModelId:    modelDef.Model.ModelId,
+			Name:       setName,
+			IsReadonly: false,
+		},
+		Txt:        []WorksetTxtRow{},
+		Param:      []worksetParam{},
+		ParamRules: rules,
+	}
+	if baseRun != nil {
+		wm.Set.BaseRunId = baseRun.RunId
+	}
+	if baseSet != nil {
+		wm.Set.BaseRunId = baseSet.BaseRunId
+	}
+	for _, dn := range txt {
+		wm.Txt = append(wm.Txt, WorksetTxtRow{LangCode: dn.LangCode, Descr: dn.Descr, Note: dn.Note})
+	}
+
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	if err = doCreateWorksetFromBase(trx, modelDef, langDef, &wm, hLst, baseRun, baseSet, uLst, true); err != nil {
+		trx.Rollback()
+		return err
+	}
+	return trx.Commit()
+}
+
+// find base model run or base workset.
+// If neither base run nor base set specified then default workset of the model is used as base.
+// Base model run must be completed and base workset must be read-only.
+func findWorksetBase(dbConn *sql.DB, modelDef *ModelMeta, runDigestStampName, setName string) (*RunRow, *WorksetRow, error) {
+
+	if runDigestStampName != "" && setName != "" {
+		return nil, nil, errors.New("invalid base, it must be either model run or workset: " + runDigestStampName + ", " + setName)
+	}
+	var baseRun *RunRow
+	var baseSet *WorksetRow
+	var err error
+
+	switch {
+	case runDigestStampName != "":
+		if baseRun, err = GetRunByDigestStampName(dbConn, modelDef.Model.ModelId, runDigestStampName); err != nil {
+			return nil, nil, err
+		}
+		if baseRun == nil {
+			return nil, nil, errors.New("model run not found: " + runDigestStampName)
+		}
+		if !IsRunCompleted(baseRun.Status) {
+			return nil, nil, errors.New("model run is not completed: " + runDigestStampName)
+		}
+	case setName != "":
+		if baseSet, err = GetWorksetByName(dbConn, modelDef.Model.ModelId, setName); err != nil {
+			return nil, nil, err
+		}
+		if baseSet == nil {
+			return nil, nil, errors.New("workset not found: " + setName)
+		}
+	default:
+		if baseSet, err = GetDefaultWorkset(dbConn, modelDef.Model.ModelId); err != nil {
+			return nil, nil, err
+		}
+		if baseSet == nil {
+			return nil, nil, errors.New("default workset not found: " + modelDef.Model.Name)
+		}
+	}
+	if baseSet != nil && !baseSet.IsReadonly {
+		return nil, nil, errors.New("base workset must be read-only: " + baseSet.Name)
+	}
+	return baseRun, baseSet, nil
+}
+
+// return list of parameters Hid to copy into new workset: parameters to update and all parameters of base workset
+func worksetCopyParamList(dbConn *sql.DB, pmLst []*ParamMeta, baseSet *WorksetRow) ([]int, error) {
+
+	hLst := []int{}
+	isHid := func(h int) bool {
+		for j := range hLst {
+			if hLst[j] == h {
+				return true
+			}
+		}
+		return false
+	}
+	for _, pm := range pmLst {
+		if !isHid(pm.ParamHid) {
+			hLst = append(hLst, pm.ParamHid)
+		}
+	}
+	if baseSet != nil {
+
+		hs, _, _, err := GetWorksetParamList(dbConn, baseSet.SetId)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hs {
+			if !isHid(h) {
+				hLst = append(hLst, h)
+			}
+		}
+	}
+	return hLst, nil
+}
+
+// paramCellUpdate is a new value or multiplicative factor for parameter cells.
+// If where is empty then all parameter cells updated else it is dimensions filter, for example: dim0 = 1 AND dim1 = 2
+type paramCellUpdate struct {
+	pm       *ParamMeta // parameter to update
+	where    string     // if not empty then dimensions filter of parameter cell
+	value    float64    // new value or multiplicative factor
+	isFactor bool       // if true then value is a multiplicative factor
+}
+
+// doCreateWorksetFromBase create new workset, copy parameters from base run or base workset and update parameter cells.
+// It does update as part of transaction, workset must not exist.
+// If isReadonly is true then workset is read-only after update, ready to run the model,
+// and workset parameters values must satisfy wm.ParamRules validation rules, otherwise ParamRuleError returned.
+func doCreateWorksetFromBase(
+	trx *sql.Tx,
+	modelDef *ModelMeta,
+	langDef *LangMeta,
+	wm *WorksetMeta,
+	hLst []int,
+	baseRun *RunRow,
+	baseSet *WorksetRow,
+	uLst []paramCellUpdate,
+	isReadonly bool,
+) error {
+
+	// workset must not exist
+	n := 0
+	err := TrxSelectFirst(trx,
+		"SELECT COUNT(*) FROM workset_lst WHERE model_id = "+strconv.Itoa(modelDef.Model.ModelId)+" AND set_name = "+ToQuoted(wm.Set.Name),
+		func(row *sql.Row) error {
+			return row.Scan(&n)
+		})
+	switch {
+	case err == sql.ErrNoRows:
+		return errors.New("invalid destination database, likely not an openM++ database")
+	case err != nil:
+		return err
+	case n > 0:
+		return errors.New("failed to create: workset already exist: " + wm.Set.Name)
+	}
+
+	// create new empty workset
+	if err := doUpdateWorkset(trx, modelDef, wm, true, langDef); err != nil {
+		return err
+	}
+
+	// copy parameters from base run or base workset
+	for _, h := range hLst {
+
+		i, ok := modelDef.ParamByHid(h)
+		if !ok {
+			return errors.New("parameter not found, id: " + strconv.Itoa(h))
+		}
+		var err error
+		if baseRun != nil {
+			err = dbCopyParameterFromRun(trx, &wm.Set, &modelDef.Param[i], false, baseRun)
+		} else {
+			err = dbCopyParameterFromWorkset(trx, &wm.Set, &modelDef.Param[i], false, baseSet)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// apply new values or factors to parameter cells
+	sId := strconv.Itoa(wm.Set.SetId)
+
+	for _, u := range uLst {
+
+		v := strconv.FormatFloat(u.value, 'g', -1, 64)
+
+		q := "UPDATE " + u.pm.DbSetTable + " SET param_value = " + v
+		if u.isFactor {
+			if u.pm.typeOf.IsInt() {
+				q = "UPDATE " + u.pm.DbSetTable + " SET param_value = ROUND(param_value * " + v + ", 0)"
+			} else {
+				q = "UPDATE " + u.pm.DbSetTable + " SET param_value = param_value * " + v
+			}
+		}
+		q += " WHERE set_id = " + sId
+		if u.where != "" {
+			q += " AND " + u.where
+		}
+		if err := TrxUpdate(trx, q); err != nil {
+			return err
+		}
+	}
+
+	// make workset read-only to allow the model run, parameters values must satisfy validation rules
+	if !isReadonly {
+		return nil
+	}
+	if err := trxCheckWorksetParamRules(trx, modelDef, wm.Set.SetId, wm.ParamRules, nil); err != nil {
+		return err
+	}
+	return TrxUpdate(trx, "UPDATE workset_lst SET is_readonly = 1 WHERE set_id = "+sId)
+}
@@ -0,0 +1,923 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openmpp/go/ompp/helper"
+)
+
+// WorksetVersionPub is a version of workset: version id, date-time, author note and parameters saved in that version.
+//
+// Version contains values of workset parameters as it was before the update.
+// Parameter value at version N is a value from the first version >= N where that parameter saved
+// or current parameter value if parameter was not updated since version N.
+type WorksetVersionPub struct {
+	ModelName      string                // model name
+	ModelDigest    string                // model digest
+	SetName        string                // workset name
+	VersionId      int                   // version id, one-based, unique inside of workset
+	UpdateDateTime string                // version date-time
+	Note           string                // author note
+	Param          []WorksetVersionParam // parameters saved in that version
+}
+
+// WorksetVersionParam is a parameter saved in workset version.
+type WorksetVersionParam struct {
+	Name         string // parameter name
+	IsInSet      bool   // if false then parameter was not included in workset
+	SubCount     int    // number of parameter sub-values
+	DefaultSubId int    // default sub-value id
+}
+
+// WorksetVersionDiff is a difference of parameter cell value between two versions of workset.
+type WorksetVersionDiff struct {
+	Name   string   // parameter name
+	SubId  int      // sub-value id
+	Dims   []string // dimension(s) items codes
+	IsFrom bool     // if true then cell exist in "from" version
+	From   string   // value in "from" version, "null" if value is NULL
+	IsTo   bool     // if true then cell exist in "to" version
+	To     string   // value in "to" version, "null" if value is NULL
+}
+
+// state of workset parameter: workset_parameter row and parameter values as text
+type verParamState struct {
+	isInSet  bool               // if true then parameter included in workset
+	subCount int                // sub_count
+	defSubId int                // default_sub_id
+	cells    map[string]verCell // parameter cells by key: sub-value id and dimension(s) items id
+}
+
+// workset version parameter cell: sub-value id, dimensions items id and value as text
+type verCell struct {
+	subId  int    // sub-value id
+	dimIds []int  // dimension(s) items id
+	isNull bool   // if true then value is NULL
+	value  string // parameter value as text
+}
+
+// WorksetVersionSchema is a minimal db schema version which contains workset version tables
+const WorksetVersionSchema = 105
+
+// SaveWorksetVersion save current values of workset parameters as new version of workset.
+//
+// If isAll is true then all workset parameters are saved, else only parameters from the list.
+// Parameters from the list which are not included in workset saved as not in workset.
+// Workset updates also save version as part of update transaction if version note is not empty,
+// use this method only to save current state of workset without any update.
+// Return new version id or zero if workset not found, read-only, there are no parameters to save
+// or database schema version is older than WorksetVersionSchema.
+func SaveWorksetVersion(dbConn *sql.DB, modelDef *ModelMeta, setName string, isAll bool, paramNames []string, note string) (int, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return 0, errors.New("invalid (empty) model metadata")
+	}
+	if setName == "" {
+		return 0, errors.New("invalid (empty) workset name")
+	}
+
+	ws, err := GetWorksetByName(dbConn, modelDef.Model.ModelId, setName)
+	if err != nil {
+		return 0, err
+	}
+	if ws == nil || ws.IsReadonly {
+		return 0, nil // workset not found or read-only: it cannot be updated, nothing to save
+	}
+
+	// save parameters values in transaction scope
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	verId, err := trxSaveWorksetVersion(trx, modelDef, ws.SetId, isAll, paramNames, note)
+	if err != nil {
+		trx.Rollback()
+		return 0, err
+	}
+	if err = trx.Commit(); err != nil {
+		return 0, err
+	}
+	return verId, nil
+}
+
+// trxSaveWorksetVersion save current values of workset parameters as new version of workset.
+// It does update as part of transaction, version is saved only if workset update transaction committed.
+//
+// If isAll is true then all workset parameters are saved, else only parameters from the list.
+// Parameters from the list which are not included in workset saved as not in workset.
+// Return new version id or zero if version note is empty, there are no parameters to save
+// or database schema version is older than WorksetVersionSchema.
+func trxSaveWorksetVersion(trx *sql.Tx, modelDef *ModelMeta, setId int, isAll bool, paramNames []string, note string) (int, error) {
+
+	if note == "" {
+		return 0, nil // workset version not requested
+	}
+	if ok, err := trxIsWorksetVersionExist(trx); err != nil || !ok {
+		return 0, err
+	}
+
+	// make list of parameters: all workset parameters and parameters from the list
+	pmLst := []*ParamMeta{}
+
+	addParam := func(idx int) {
+		for _, pm := range pmLst {
+			if pm.ParamHid == modelDef.Param[idx].ParamHid {
+				return
+			}
+		}
+		pmLst = append(pmLst, &modelDef.Param[idx])
+	}
+
+	if isAll {
+		err := TrxSelectRows(trx,
+			"SELECT parameter_hid FROM workset_parameter WHERE set_id = "+strconv.Itoa(setId)+" ORDER BY 1",
+			func(rows *sql.Rows) error {
+				h := 0
+				if err := rows.Scan(&h); err != nil {
+					return err
+				}
+				if k, ok := modelDef.ParamByHid(h); ok {
+					addParam(k)
+				}
+				return nil
+			})
+		if err != nil && err != sql.ErrNoRows {
+			return 0, err
+		}
+	}
+	for _, name := range paramNames {
+		k, ok := modelDef.ParamByName(name)
+		if !ok {
+			return 0, errors.New("model: " + modelDef.Model.Name + " parameter " + name + " not found")
+		}
+		addParam(k)
+	}
+	if len(pmLst) <= 0 {
+		return 0, nil // no parameters: nothing to save
+	}
+
+	return doSaveWorksetVersion(trx, setId, pmLst, note)
+}
+
+// GetWorksetVersionList return list of workset versions, ordered by version id.
+// If version tables not exist in database then return empty list.
+func GetWorksetVersionList(dbConn *sql.DB, modelDef *ModelMeta, ws *WorksetRow) ([]WorksetVersionPub, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata")
+	}
+	if ws == nil {
+		return nil, errors.New("invalid (empty) workset")
+	}
+	if !isWorksetVersionExist(dbConn) {
+		return []WorksetVersionPub{}, nil
+	}
+	sId := strconv.Itoa(ws.SetId)
+
+	// select versions
+	vLst := []WorksetVersionPub{}
+
+	err := SelectRows(dbConn,
+		"SELECT version_id, update_dt, author_note FROM workset_version WHERE set_id = "+sId+" ORDER BY 1",
+		func(rows *sql.Rows) error {
+
+			var v WorksetVersionPub
+			var note sql.NullString
+			if err := rows.Scan(&v.VersionId, &v.UpdateDateTime, &note); err != nil {
+				return err
+			}
+			v.ModelName = modelDef.Model.Name
+			v.ModelDigest = modelDef.Model.Digest
+			v.SetName = ws.Name
+			v.Note = note.String
+			v.Param = []WorksetVersionParam{}
+			vLst = append(vLst, v)
+			return nil
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// select parameters of each version
+	err = SelectRows(dbConn,
+		"SELECT version_id, parameter_hid, is_in_set, sub_count, default_sub_id FROM workset_version_param WHERE set_id = "+sId+" ORDER BY 1, 2",
+		func(rows *sql.Rows) error {
+
+			var verId, hId, nIn int
+			var vp WorksetVersionParam
+			if err := rows.Scan(&verId, &hId, &nIn, &vp.SubCount, &vp.DefaultSubId); err != nil {
+				return err
+			}
+			k, ok := modelDef.ParamByHid(hId)
+			if !ok {
+				return errors.New("parameter not found by Hid: " + strconv.Itoa(hId))
+			}
+			vp.Name = modelDef.Param[k].Name
+			vp.IsInSet = nIn != 0
+
+			for j := range vLst {
+				if vLst[j].VersionId == verId {
+					vLst[j].Param = append(vLst[j].Param, vp)
+					break
+				}
+			}
+			return nil
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return vLst, nil
+}
+
+// GetWorksetVersionDiff return differences of parameter values between two versions of workset.
+//
+// If toVerId is zero then "from" version compared with current parameter values.
+// If parameter name is not empty then only that parameter compared.
+func GetWorksetVersionDiff(dbConn *sql.DB, modelDef *ModelMeta, ws *WorksetRow, fromVerId, toVerId int, paramName string) ([]WorksetVersionDiff, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return nil, errors.New("invalid (empty) model metadata")
+	}
+	if ws == nil {
+		return nil, errors.New("invalid (empty) workset")
+	}
+	if fromVerId <= 0 || toVerId < 0 {
+		return nil, errors.New("invalid workset version: " + strconv.Itoa(fromVerId) + ", " + strconv.Itoa(toVerId))
+	}
+	if !isWorksetVersionExist(dbConn) {
+		return nil, errors.New("workset version not found: " + ws.Name + ": " + strconv.Itoa(fromVerId))
+	}
+
+	// read parameters values inside of transaction to get consistent state of workset
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer trx.Rollback()
+
+	for _, v := range []int{fromVerId, toVerId} {
+		if v > 0 {
+			if ok, err := trxIsVersionExist(trx, ws.SetId, v); err != nil {
+				return nil, err
+			} else if !ok {
+				return nil, errors.New("workset version not found: " + ws.Name + ": " + strconv.Itoa(v))
+			}
+		}
+	}
+
+	// only parameters saved in versions after earliest of two versions can be different
+	minId := fromVerId
+	if toVerId > 0 && toVerId < minId {
+		minId = toVerId
+	}
+	pmLst, err := trxVersionParamList(trx, modelDef, ws.SetId, minId, paramName)
+	if err != nil {
+		return nil, err
+	}
+
+	// compare parameter cells
+	dLst := []WorksetVersionDiff{}
+
+	for _, pm := range pmLst {
+
+		fromSt, err := trxParamStateAt(trx, pm, ws.SetId, fromVerId)
+		if err != nil {
+			return nil, err
+		}
+		toSt, err := trxParamStateAt(trx, pm, ws.SetId, toVerId)
+		if err != nil {
+			return nil, err
+		}
+
+		// enum id to code converters for each dimension
+		fd := make([]func(int) (string, error), pm.Rank)
+		for k := range pm.Dim {
+			if fd[k], err = pm.Dim[k].typeOf.itemIdToCode(pm.Name+"."+pm.Dim[k].Name, false); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, key := range sortedCellKeys(fromSt.cells, toSt.cells) {
+
+			cf, isFrom := fromSt.cells[key]
+			ct, isTo := toSt.cells[key]
+			if isFrom && isTo && cf.isNull == ct.isNull && cf.value == ct.value {
+				continue // same value in both versions
+			}
+
+			d := WorksetVersionDiff{Name: pm.Name, IsFrom: isFrom, IsTo: isTo, Dims: make([]string, pm.Rank)}
+			c := cf
+			if !isFrom {
+				c = ct
+			}
+			d.SubId = c.subId
+			for k := range fd {
+				if d.Dims[k], err = fd[k](c.dimIds[k]); err != nil {
+					return nil, err
+				}
+			}
+			if isFrom {
+				d.From = verCellText(cf)
+			}
+			if isTo {
+				d.To = verCellText(ct)
+			}
+			dLst = append(dLst, d)
+		}
+	}
+
+	return dLst, nil
+}
+
+// RestoreWorksetVersion restore workset parameters values to the state of specified workset version.
+//
+// If parameter name is not empty then only that parameter restored else all parameters updated since that version.
+// Current values of restored parameters saved as new workset version, it allows to undo restore.
+// Workset must be read-write.
+// If ifUpdateDt is not empty then it is conditional update:
+// workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+// If validation rules not empty then restored parameters values must satisfy the rules, otherwise ParamRuleError returned.
+// Return new version id or zero if there are no parameters to restore.
+func RestoreWorksetVersion(
+	dbConn *sql.DB, modelDef *ModelMeta, langDef *LangMeta, setName string, verId int, paramName string, rules []ParamRule, ifUpdateDt string, note string,
+) (int, error) {
+
+	// validate parameters
+	if modelDef == nil {
+		return 0, errors.New("invalid (empty) model metadata")
+	}
+	if langDef == nil {
+		return 0, errors.New("invalid (empty) language list")
+	}
+	if verId <= 0 {
+		return 0, errors.New("invalid workset version: " + strconv.Itoa(verId))
+	}
+
+	ws, err := GetWorksetByName(dbConn, modelDef.Model.ModelId, setName)
+	if err != nil {
+		return 0, err
+	}
+	if ws == nil {
+		return 0, errors.New("workset not found: " + setName)
+	}
+	if ws.IsReadonly {
+		return 0, errors.New("failed to restore: workset is read-only: " + setName)
+	}
+	if !isWorksetVersionExist(dbConn) {
+		return 0, errors.New("workset version not found: " + setName + ": " + strconv.Itoa(verId))
+	}
+
+	// do restore in transaction scope
+	trx, err := dbConn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	newId, err := doRestoreWorksetVersion(trx, modelDef, langDef, ws, verId, paramName, ifUpdateDt, note)
+	if err == nil {
+		var names []string
+		if paramName != "" {
+			names = []string{paramName}
+		}
+		err = trxCheckWorksetParamRules(trx, modelDef, ws.SetId, rules, names)
+	}
+	if err != nil {
+		trx.Rollback()
+		return 0, err
+	}
+	if err = trx.Commit(); err != nil {
+		return 0, err
+	}
+	return newId, nil
+}
+
+// doRestoreWorksetVersion save current state of parameters as new version and restore parameters to specified version.
+// It does update as part of transaction.
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time.
+func doRestoreWorksetVersion(
+	trx *sql.Tx, modelDef *ModelMeta, langDef *LangMeta, ws *WorksetRow, verId int, paramName string, ifUpdateDt string, note string,
+) (int, error) {
+
+	if err := trxCheckWorksetUpdateDt(trx, ws.SetId, ifUpdateDt); err != nil {
+		return 0, err
+	}
+	if ok, err := trxIsVersionExist(trx, ws.SetId, verId); err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, errors.New("workset version not found: " + ws.Name + ": " + strconv.Itoa(verId))
+	}
+
+	// parameters updated since that version
+	pmLst, err := trxVersionParamList(trx, modelDef, ws.SetId, verId, paramName)
+	if err != nil {
+		return 0, err
+	}
+	if len(pmLst) <= 0 {
+		return 0, nil // nothing to restore
+	}
+
+	// get target state of each parameter before current state saved as new version
+	stLst := make([]*verParamState, len(pmLst))
+	for k, pm := range pmLst {
+		if stLst[k], err = trxParamStateAt(trx, pm, ws.SetId, verId); err != nil {
+			return 0, err
+		}
+	}
+
+	// save current state as new version
+	if note == "" {
+		note = "Restore version " + strconv.Itoa(verId)
+	}
+	newId, err := doSaveWorksetVersion(trx, ws.SetId, pmLst, note)
+	if err != nil {
+		return 0, err
+	}
+
+	// restore each parameter: delete parameter from workset or replace parameter values
+	wm := WorksetMeta{Set: *ws}
+
+	for k, pm := range pmLst {
+
+		st := stLst[k]
+		if !st.isInSet {
+			if _, err = dbDeleteWorksetParameter(trx, modelDef, ws.Name, pm.Name, "", ""); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		param := ParamRunSetPub{
+			ParamRunSetTxtPub: ParamRunSetTxtPub{Name: pm.Name},
+			SubCount:          st.subCount,
+			DefaultSubId:      st.defSubId,
+		}
+		if _, err = doUpdateWorksetParameterMeta(trx, modelDef, &wm, false, &param, true, langDef); err != nil {
+			return 0, err
+		}
+
+		// parameter cells from version
+		cLst := make([]verCell, 0, len(st.cells))
+		for _, key := range sortedCellKeys(st.cells, nil) {
+			cLst = append(cLst, st.cells[key])
+		}
+		cvt := verTextToParamValue(pm)
+		idx := 0
+
+		from := func() (interface{}, error) {
+			if idx >= len(cLst) {
+				return nil, nil // end of data
+			}
+			c := cLst[idx]
+			idx++
+
+			v, err := cvt(c.isNull, c.value)
+			if err != nil {
+				return nil, err
+			}
+			return CellParam{cellIdValue: cellIdValue{DimIds: c.dimIds, IsNull: c.isNull, Value: v}, SubId: c.subId}, nil
+		}
+
+		if err = doWriteSetParameterFrom(trx, pm, ws.SetId, st.subCount, st.defSubId, false, from, ""); err != nil {
+			return 0, err
+		}
+	}
+
+	return newId, nil
+}
+
+// doSaveWorksetVersion insert new version of workset: version row, parameters metadata and values.
+// It does update as part of transaction.
+// Return new version id.
+func doSaveWorksetVersion(trx *sql.Tx, setId int, pmLst []*ParamMeta, note string) (int, error) {
+
+	sId := strconv.Itoa(setId)
+
+	// new version id: max + 1
+	var maxId sql.NullInt64
+	err := TrxSelectFirst(trx,
+		"SELECT MAX(version_id) FROM workset_version WHERE set_id = "+sId,
+		func(row *sql.Row) error {
+			return row.Scan(&maxId)
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	verId := 1
+	if maxId.Valid {
+		verId = int(maxId.Int64) + 1
+	}
+	sVer := strconv.Itoa(verId)
+
+	err = TrxUpdate(trx,
+		"INSERT INTO workset_version (set_id, version_id, update_dt, author_note) VALUES ("+
+			sId+", "+sVer+", "+ToQuoted(helper.MakeDateTime(time.Now()))+", "+toQuotedOrNullMax(note, noteDbMax)+")")
+	if err != nil {
+		return 0, err
+	}
+
+	// save parameters metadata and values
+	for _, pm := range pmLst {
+
+		st, err := trxReadWorksetParamState(trx, pm, setId)
+		if err != nil {
+			return 0, err
+		}
+		sHid := strconv.Itoa(pm.ParamHid)
+
+		err = TrxUpdate(trx,
+			"INSERT INTO workset_version_param (set_id, version_id, parameter_hid, is_in_set, sub_count, default_sub_id) VALUES ("+
+				sId+", "+sVer+", "+sHid+", "+toBoolSqlConst(st.isInSet)+", "+strconv.Itoa(st.subCount)+", "+strconv.Itoa(st.defSubId)+")")
+		if err != nil {
+			return 0, err
+		}
+
+		keys := sortedCellKeys(st.cells, nil)
+		idx := 0
+		row := make([]interface{}, 2)
+
+		err = TrxUpdateStatement(trx,
+			"INSERT INTO workset_version_value (set_id, version_id, parameter_hid, cell_key, param_value)"+
+				" VALUES ("+sId+", "+sVer+", "+sHid+", ?, ?)",
+			func() (bool, []interface{}, error) {
+				if idx >= len(keys) {
+					return false, nil, nil // end of data
+				}
+				c := st.cells[keys[idx]]
+				row[0] = keys[idx]
+				row[1] = sql.NullString{String: c.value, Valid: !c.isNull}
+				idx++
+				return true, row, nil
+			})
+		if err != nil {
+			return 0, errors.New("insert workset version failed: " + pm.Name + " " + err.Error())
+		}
+	}
+
+	return verId, nil
+}
+
+// trxReadWorksetParamState return current state of workset parameter: sub-values count, default sub-value id and values.
+func trxReadWorksetParamState(trx *sql.Tx, pm *ParamMeta, setId int) (*verParamState, error) {
+
+	sId := strconv.Itoa(setId)
+	st := verParamState{cells: map[string]verCell{}}
+
+	err := TrxSelectFirst(trx,
+		"SELECT sub_count, default_sub_id FROM workset_parameter WHERE set_id = "+sId+" AND parameter_hid = "+strconv.Itoa(pm.ParamHid),
+		func(row *sql.Row) error {
+			return row.Scan(&st.subCount, &st.defSubId)
+		})
+	switch {
+	case err == sql.ErrNoRows:
+		return &st, nil // parameter not in workset
+	case err != nil:
+		return nil, err
+	}
+	st.isInSet = true
+
+	// SELECT sub_id, dim0, dim1, param_value FROM ageSex_w2012817 WHERE set_id = 2
+	q := "SELECT sub_id, "
+	for k := range pm.Dim {
+		q += pm.Dim[k].colName + ", "
+	}
+	q += "param_value FROM " + pm.DbSetTable + " WHERE set_id = " + sId
+
+	cvt := verParamValueToText(pm)
+
+	err = trxReadParameterTo(trx, pm, q, func(src interface{}) error {
+
+		cell, ok := src.(CellParam)
+		if !ok {
+			return errors.New("invalid type, expected: parameter cell (internal error)")
+		}
+		c := verCell{subId: cell.SubId, dimIds: append([]int{}, cell.DimIds...), isNull: cell.IsNull}
+		if !c.isNull {
+			c.value = cvt(cell.Value)
+		}
+		st.cells[verCellKey(c.subId, c.dimIds)] = c
+		return nil
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// trxParamStateAt return state of workset parameter at specified version:
+// state saved in first version >= version id or current state if there is no such version.
+// If version id is zero then return current state of workset parameter.
+func trxParamStateAt(trx *sql.Tx, pm *ParamMeta, setId int, verId int) (*verParamState, error) {
+
+	if verId <= 0 {
+		return trxReadWorksetParamState(trx, pm, setId)
+	}
+	sId := strconv.Itoa(setId)
+	sHid := strconv.Itoa(pm.ParamHid)
+
+	// find first version where parameter saved
+	var minId sql.NullInt64
+	err := TrxSelectFirst(trx,
+		"SELECT MIN(version_id) FROM workset_version_param"+
+			" WHERE set_id = "+sId+" AND parameter_hid = "+sHid+" AND version_id >= "+strconv.Itoa(verId),
+		func(row *sql.Row) error {
+			return row.Scan(&minId)
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if !minId.Valid {
+		return trxReadWorksetParamState(trx, pm, setId) // parameter not updated since that version
+	}
+	sVer := strconv.FormatInt(minId.Int64, 10)
+
+	st := verParamState{cells: map[string]verCell{}}
+	nIn := 0
+
+	err = TrxSelectFirst(trx,
+		"SELECT is_in_set, sub_count, default_sub_id FROM workset_version_param"+
+			" WHERE set_id = "+sId+" AND version_id = "+sVer+" AND parameter_hid = "+sHid,
+		func(row *sql.Row) error {
+			return row.Scan(&nIn, &st.subCount, &st.defSubId)
+		})
+	if err != nil {
+		return nil, err
+	}
+	st.isInSet = nIn != 0
+
+	err = TrxSelectRows(trx,
+		"SELECT cell_key, param_value FROM workset_version_value"+
+			" WHERE set_id = "+sId+" AND version_id = "+sVer+" AND parameter_hid = "+sHid,
+		func(rows *sql.Rows) error {
+
+			var key string
+			var v sql.NullString
+			if err := rows.Scan(&key, &v); err != nil {
+				return err
+			}
+			c, err := parseVerCellKey(key, pm.Rank)
+			if err != nil {
+				return err
+			}
+			c.isNull = !v.Valid
+			c.value = v.String
+			st.cells[key] = c
+			return nil
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// trxVersionParamList return list of parameters saved in workset versions starting from version id.
+// If parameter name is not empty then return only that parameter.
+func trxVersionParamList(trx *sql.Tx, modelDef *ModelMeta, setId int, verId int, paramName string) ([]*ParamMeta, error) {
+
+	q := "SELECT DISTINCT parameter_hid FROM workset_version_param" +
+		" WHERE set_id = " + strconv.Itoa(setId) + " AND version_id >= " + strconv.Itoa(verId)
+
+	if paramName != "" {
+		k, ok := modelDef.ParamByName(paramName)
+		if !ok {
+			return nil, errors.New("model: " + modelDef.Model.Name + " parameter " + paramName + " not found")
+		}
+		q += " AND parameter_hid = " + strconv.Itoa(modelDef.Param[k].ParamHid)
+	}
+
+	pmLst := []*ParamMeta{}
+	err := TrxSelectRows(trx, q+" ORDER BY 1",
+		func(rows *sql.Rows) error {
+			h := 0
+			if err := rows.Scan(&h); err != nil {
+				return err
+			}
+			k, ok := modelDef.ParamByHid(h)
+			if !ok {
+				return errors.New("parameter not found by Hid: " + strconv.Itoa(h))
+			}
+			pmLst = append(pmLst, &modelDef.Param[k])
+			return nil
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return pmLst, nil
+}
+
+// trxIsVersionExist return true if workset version exist
+func trxIsVersionExist(trx *sql.Tx, setId int, verId int) (bool, error) {
+
+	n := 0
+	err := TrxSelectFirst(trx,
+		"SELECT COUNT(*) FROM workset_version WHERE set_id = "+strconv.Itoa(setId)+" AND version_id = "+strconv.Itoa(verId),
+		func(row *sql.Row) error {
+			return row.Scan(&n)
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// trxDeleteWorksetVersions delete all versions of workset.
+// It does update as part of transaction.
+func trxDeleteWorksetVersions(trx *sql.Tx, setId int) error {
+
+	sId := strconv.Itoa(setId)
+
+	if err := TrxUpdate(trx, "DELETE FROM workset_version_value WHERE set_id = "+sId); err != nil {
+		return err
+	}
+	if err := TrxUpdate(trx, "DELETE FROM workset_version_param WHERE set_id = "+sId); err != nil {
+		return err
+	}
+	return TrxUpdate(trx, "DELETE FROM workset_version WHERE set_id = "+sId)
+}
+
+// isWorksetVersionExist return true if database schema contains workset version tables
+func isWorksetVersionExist(dbConn *sql.DB) bool {
+
+	nVer, err := OpenmppSchemaVersion(dbConn)
+	return err == nil && nVer >= WorksetVersionSchema
+}
+
+// trxIsWorksetVersionExist return true if database schema contains workset version tables
+func trxIsWorksetVersionExist(trx *sql.Tx) (bool, error) {
+
+	nVer := 0
+	err := TrxSelectFirst(trx,
+		"SELECT id_value FROM id_lst WHERE id_key = 'openmpp'",
+		func(row *sql.Row) error {
+			return row.Scan(&nVer)
+		})
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	return nVer >= WorksetVersionSchema, nil
+}
+
+// return key of parameter cell: sub-value id and dimension(s) items id, for example: 0,12,3
+func verCellKey(subId int, dimIds []int) string {
+
+	key := strconv.Itoa(subId)
+	for _, d := range dimIds {
+		key += "," + strconv.Itoa(d)
+	}
+	return key
+}
+
+// parse key of parameter cell into sub-value id and dimension(s) items id
+func parseVerCellKey(key string, rank int) (verCell, error) {
+
+	sLst := strings.Split(key, ",")
+	if len(sLst) != rank+1 {
+		return verCell{}, errors.New("invalid parameter cell key: " + key)
+	}
+	c := verCell{dimIds: make([]int, rank)}
+
+	var err error
+	if c.subId, err = strconv.Atoi(sLst[0]); err != nil {
+		return verCell{}, errors.New("invalid parameter cell key: " + key)
+	}
+	for k := 0; k < rank; k++ {
+		if c.dimIds[k], err = strconv.Atoi(sLst[k+1]); err != nil {
+			return verCell{}, errors.New("invalid parameter cell key: " + key)
+		}
+	}
+	return c, nil
+}
+
+// return sorted keys of parameter cells from both maps, sorted by sub-value id and dimension(s) items id
+func sortedCellKeys(left, right map[string]verCell) []string {
+
+	cLst := make([]verCell, 0, len(left)+len(right))
+	kLst := make([]string, 0, len(left)+len(right))
+
+	for key, c := range left {
+		cLst = append(cLst, c)
+		kLst = append(kLst, key)
+	}
+	for key, c := range right {
+		if _, ok := left[key]; !ok {
+			cLst = append(cLst, c)
+			kLst = append(kLst, key)
+		}
+	}
+
+	idx := make([]int, len(cLst))
+	for k := range idx {
+		idx[k] = k
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		ci, cj := cLst[idx[i]], cLst[idx[j]]
+		if ci.subId != cj.subId {
+			return ci.subId < cj.subId
+		}
+		for k := 0; k < len(ci.dimIds) && k < len(cj.dimIds); k++ {
+			if ci.dimIds[k] != cj.dimIds[k] {
+				return ci.dimIds[k] < cj.dimIds[k]
+			}
+		}
+		return false
+	})
+
+	sLst := make([]string, len(idx))
+	for k := range idx {
+		sLst[k] = kLst[idx[k]]
+	}
+	return sLst
+}
+
+// return parameter cell value as text or "null" if value is NULL
+func verCellText(c verCell) string {
+	if c.isNull {
+		return "null"
+	}
+	return c.value
+}
+
+// return converter of parameter value to text to save in workset version, value must not be NULL
+func verParamValueToText(pm *ParamMeta) func(src interface{}) string {
+
+	switch {
+	case pm.typeOf.IsBool():
+		return func(src interface{}) string {
+			if is, ok := src.(bool); ok && is {
+				return "true"
+			}
+			return "false"
+		}
+	case pm.typeOf.IsString():
+		return func(src interface{}) string {
+			if s, ok := src.(string); ok {
+				return s
+			}
+			return ""
+		}
+	case pm.typeOf.IsFloat():
+		return func(src interface{}) string {
+			switch f := src.(type) {
+			case float64:
+				return strconv.FormatFloat(f, 'g', -1, 64)
+			case float32:
+				return strconv.FormatFloat(float64(f), 'g', -1, 32)
+			}
+			if i, ok := helper.ToIntValue(src); ok {
+				return strconv.Itoa(i)
+			}
+			return ""
+		}
+	}
+	// integer or enum id
+	return func(src interface{}) string {
+		if i, ok := helper.ToIntValue(src); ok {
+			return strconv.Itoa(i)
+		}
+		return ""
+	}
+}
+
+// return converter of parameter value from workset version text into parameter value
+func verTextToParamValue(pm *ParamMeta) func(isNull bool, src string) (interface{}, error) {
+
+	switch {
+	case pm.typeOf.IsBool():
+		return func(isNull bool, src string) (interface{}, error) {
+			return src == "true", nil
+		}
+	case pm.typeOf.IsString():
+		return func(isNull bool, src string) (interface{}, error) {
+			return src, nil
+		}
+	case pm.typeOf.IsFloat():
+		return func(isNull bool, src string) (interface{}, error) {
+			if isNull {
+				return nil, nil
+			}
+			f, err := strconv.ParseFloat(src, 64)
+			if err != nil {
+				return nil, errors.New("invalid parameter value: " + pm.Name + ": " + src)
+			}
+			return f, nil
+		}
+	}
+	// integer or enum id
+	return func(isNull bool, src string) (interface{}, error) {
+		i, err := strconv.Atoi(src)
+		if err != nil {
+			return nil, errors.New("invalid parameter value: " + pm.Name + ": " + src)
+		}
+		return i, nil
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVerCellKey(t *testing.T) {
+
+	key := verCellKey(1, []int{12, 3})
+	if key != "1,12,3" {
+		t.Errorf("expected: 1,12,3 result: %s", key)
+	}
+	c, err := parseVerCellKey(key, 2)
+	if err != nil || c.subId != 1 || !reflect.DeepEqual(c.dimIds, []int{12, 3}) {
+		t.Errorf("invalid parsed cell key: %v %v", c, err)
+	}
+
+	// scalar parameter: key is sub-value id only
+	if c, err = parseVerCellKey(verCellKey(0, []int{}), 0); err != nil || c.subId != 0 || len(c.dimIds) != 0 {
+		t.Errorf("invalid parsed scalar cell key: %v %v", c, err)
+	}
+
+	for _, bad := range []string{"", "1,2", "1,x,3", "1,2,3,4"} {
+		if _, err = parseVerCellKey(bad, 2); err == nil {
+			t.Errorf("expected error for invalid cell key: %s", bad)
+		}
+	}
+}
+
+func TestSortedCellKeys(t *testing.T) {
+
+	left := map[string]verCell{}
+	right := map[string]verCell{}
+
+	for _, c := range []verCell{{subId: 1, dimIds: []int{0, 1}}, {subId: 0, dimIds: []int{2, 0}}, {subId: 0, dimIds: []int{10, 0}}} {
+		left[verCellKey(c.subId, c.dimIds)] = c
+	}
+	for _, c := range []verCell{{subId: 0, dimIds: []int{2, 0}}, {subId: 0, dimIds: []int{1, 5}}} {
+		right[verCellKey(c.subId, c.dimIds)] = c
+	}
+
+	expected := []string{"0,1,5", "0,2,0", "0,10,0", "1,0,1"}
+	if kLst := sortedCellKeys(left, right); !reflect.DeepEqual(kLst, expected) {
+		t.Errorf("expected: %v result: %v", expected, kLst)
+	}
+	if kLst := sortedCellKeys(right, nil); !reflect.DeepEqual(kLst, []string{"0,1,5", "0,2,0"}) {
+		t.Errorf("invalid sorted keys: %v", kLst)
+	}
+}
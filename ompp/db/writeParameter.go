@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"hash"
 	"strconv"
-	"time"
 
 	"github.com/openmpp/go/ompp/helper"
 )
@@ -25,6 +24,12 @@ import (
 // then each row deleted by primary key before insert else all rows deleted by one delete by set id.
 //
 // Double format is used for float model types digest calculation, if non-empty format supplied.
+//
+// If layout.IfUpdateDateTime is not empty then it is conditional workset update:
+// workset must not be changed since that update date-time, otherwise ErrWorksetChanged returned.
+// If layout.VersionNote is not empty then current workset parameter values saved as new workset version in the same transaction.
+// If layout.ParamRules is not empty then workset parameter values must satisfy validation rules after update,
+// otherwise ParamRuleError returned and workset not updated.
 func WriteParameterFrom(dbConn *sql.DB, modelDef *ModelMeta, layout *WriteParamLayout, from func() (interface{}, error)) error {
 
 	// validate parameters
@@ -78,7 +83,16 @@ func WriteParameterFrom(dbConn *sql.DB, modelDef *ModelMeta, layout *WriteParamL
 	if layout.IsToRun {
 		err = doWriteRunParameterFrom(trx, modelDef, param, layout.ToId, layout.SubCount, from, layout.DoubleFmt)
 	} else {
-		err = doWriteSetParameterFrom(trx, param, layout.ToId, layout.SubCount, defSubId, layout.IsPage, from, layout.DoubleFmt)
+		err = trxCheckWorksetUpdateDt(trx, layout.ToId, layout.IfUpdateDateTime)
+		if err == nil {
+			_, err = trxSaveWorksetVersion(trx, modelDef, layout.ToId, false, []string{param.Name}, layout.VersionNote)
+		}
+		if err == nil {
+			err = doWriteSetParameterFrom(trx, param, layout.ToId, layout.SubCount, defSubId, layout.IsPage, from, layout.DoubleFmt)
+		}
+		if err == nil {
+			err = trxCheckWorksetParamRules(trx, modelDef, layout.ToId, layout.ParamRules, []string{param.Name})
+		}
 	}
 	if err != nil {
 		trx.Rollback()
@@ -263,9 +277,13 @@ func doWriteSetParameterFrom(
 
 	// start workset update
 	sId := strconv.Itoa(setId)
-	err := TrxUpdate(trx,
+	dt, err := trxWorksetUpdateDt(trx, setId)
+	if err != nil {
+		return err
+	}
+	err = TrxUpdate(trx,
 		"UPDATE workset_lst"+
-			" SET is_readonly = is_readonly + 1, update_dt = "+ToQuoted(helper.MakeDateTime(time.Now()))+
+			" SET is_readonly = is_readonly + 1, update_dt = "+ToQuoted(dt)+
 			" WHERE set_id = "+sId)
 	if err != nil {
 		return err
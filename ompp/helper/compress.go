@@ -0,0 +1,95 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package helper
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// file extensions of compressed files: .csv.gz or .csv.zst
+const (
+	GzipExt = ".gz"  // gzip compressed file extension
+	ZstdExt = ".zst" // zstd compressed file extension
+)
+
+// CompressExt return compressed file extension by compression name: .gz for "gzip" and .zst for "zstd".
+// Return "" empty extension if compression name is "" empty or "none" and error if compression name is unknown.
+func CompressExt(name string) (string, error) {
+
+	switch strings.ToLower(name) {
+	case "", "none":
+		return "", nil
+	case "gzip", "gz":
+		return GzipExt, nil
+	case "zstd", "zst":
+		return ZstdExt, nil
+	}
+	return "", errors.New("invalid compression, expected one of: gzip or zstd: " + name)
+}
+
+// CompressExtOf return compression extension of the file path: .gz or .zst or "" empty if file is not compressed.
+func CompressExtOf(filePath string) string {
+
+	switch {
+	case strings.HasSuffix(strings.ToLower(filePath), GzipExt):
+		return GzipExt
+	case strings.HasSuffix(strings.ToLower(filePath), ZstdExt):
+		return ZstdExt
+	}
+	return ""
+}
+
+// NewCompressWriter return a writer which compress data into destination writer, compression selected by extension: .gz or .zst.
+// If extension is "" empty then return destination writer as is.
+// Close() of result must be called to flush compressed data, it does not close destination writer.
+func NewCompressWriter(dst io.Writer, ext string) (io.WriteCloser, error) {
+
+	switch ext {
+	case "":
+		return nopWriteCloser{dst}, nil
+	case GzipExt:
+		return gzip.NewWriter(dst), nil
+	case ZstdExt:
+		zw, err := zstd.NewWriter(dst)
+		if err != nil {
+			return nil, errors.New("failed to create zstd writer: " + err.Error())
+		}
+		return zw, nil
+	}
+	return nil, errors.New("invalid compressed file extension: " + ext)
+}
+
+// NewDecompressReader return a reader to decompress source data, compression selected by file path extension: .gz or .zst.
+// If file path is not ends with .gz or .zst then return source reader as is.
+// Close() of result must be called to release decompressor resources, it does not close source reader.
+func NewDecompressReader(src io.Reader, filePath string) (io.ReadCloser, error) {
+
+	switch CompressExtOf(filePath) {
+	case GzipExt:
+		gr, err := gzip.NewReader(src)
+		if err != nil {
+			return nil, errors.New("failed to create gzip reader: " + filePath + ": " + err.Error())
+		}
+		return gr, nil
+	case ZstdExt:
+		zr, err := zstd.NewReader(src)
+		if err != nil {
+			return nil, errors.New("failed to create zstd reader: " + filePath + ": " + err.Error())
+		}
+		return zr.IOReadCloser(), nil
+	}
+	return io.NopCloser(src), nil
+}
+
+// writer with no-op Close()
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,79 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package helper
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCompressExt(t *testing.T) {
+
+	for _, c := range []struct{ name, ext string }{{"", ""}, {"none", ""}, {"gzip", GzipExt}, {"GZ", GzipExt}, {"zstd", ZstdExt}, {"zst", ZstdExt}} {
+		ext, err := CompressExt(c.name)
+		if err != nil || ext != c.ext {
+			t.Errorf("compression %s: expected %s, got %s %v", c.name, c.ext, ext, err)
+		}
+	}
+	if _, err := CompressExt("bzip2"); err == nil {
+		t.Error("expected error: unknown compression bzip2")
+	}
+
+	if e := CompressExtOf("ageSex.csv.gz"); e != GzipExt {
+		t.Errorf("ageSex.csv.gz: expected %s, got %s", GzipExt, e)
+	}
+	if e := CompressExtOf("ageSex.CSV.ZST"); e != ZstdExt {
+		t.Errorf("ageSex.CSV.ZST: expected %s, got %s", ZstdExt, e)
+	}
+	if e := CompressExtOf("ageSex.csv"); e != "" {
+		t.Errorf("ageSex.csv: expected empty extension, got %s", e)
+	}
+}
+
+func TestCompressWriterReader(t *testing.T) {
+
+	src := "age,sex,param_value\n10-20,M,0.1\n10-20,F,0.2\n"
+
+	for _, ext := range []string{"", GzipExt, ZstdExt} {
+
+		// compress and append second compressed part: result must be concatenation of both
+		var buf bytes.Buffer
+
+		for k := 0; k < 2; k++ {
+			w, err := NewCompressWriter(&buf, ext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = io.WriteString(w, src); err != nil {
+				t.Fatal(err)
+			}
+			if err = w.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if ext == GzipExt && !bytes.HasPrefix(buf.Bytes(), []byte{0x1f, 0x8b}) ||
+			ext == ZstdExt && !bytes.HasPrefix(buf.Bytes(), []byte{0x28, 0xb5, 0x2f, 0xfd}) {
+			t.Errorf("%s: data not compressed", ext)
+		}
+
+		r, err := NewDecompressReader(&buf, "ageSex.csv"+ext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bt, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Close()
+
+		if string(bt) != src+src {
+			t.Errorf("%s: unexpected result: %s", ext, string(bt))
+		}
+	}
+
+	if _, err := NewCompressWriter(&bytes.Buffer{}, ".bz2"); err == nil {
+		t.Error("expected error: unknown compression .bz2")
+	}
+}
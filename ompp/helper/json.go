@@ -24,9 +24,15 @@ func FromJsonFile(jsonPath string, dst interface{}) (bool, error) {
 	}
 	defer f.Close()
 
+	return FromJsonReader(f, dst)
+}
+
+// FromJsonReader reads json from source reader, for example from a file inside of zip archive, and convert to destination pointer.
+func FromJsonReader(src io.Reader, dst interface{}) (bool, error) {
+
 	// make utf-8 converter:
 	// assume utf-8 as default encoding on any OS because json file must be unicode and cannot be "windows code page"
-	rd, err := Utf8Reader(f, "utf-8")
+	rd, err := Utf8ReaderFrom(src, "utf-8")
 	if err != nil {
 		return false, errors.New("json file read error: " + err.Error())
 	}
@@ -64,7 +70,13 @@ func ToJsonFile(jsonPath string, src interface{}) error {
 	}
 	defer f.Close()
 
-	err = json.NewEncoder(f).Encode(src)
+	return ToJsonWriter(f, src)
+}
+
+// ToJsonWriter convert source to json and write into destination writer, for example into a file inside of zip archive.
+func ToJsonWriter(dst io.Writer, src interface{}) error {
+
+	err := json.NewEncoder(dst).Encode(src)
 	if err != nil {
 		return errors.New("json encode error: " + err.Error())
 	}
@@ -4,6 +4,7 @@
 package helper
 
 import (
+	"bufio"
 	"errors"
 	"io"
 	"os"
@@ -144,6 +145,88 @@ func Utf8Reader(f *os.File, encodingName string) (io.Reader, error) {
 		}
 	}
 
+	return encodingReader(f, encodingName)
+}
+
+// Utf8ReaderFrom return a reader to transform source stream content to utf-8.
+//
+// It is the same as Utf8Reader() but source is not required to be a file, for example, it can be a file inside of zip archive.
+// Source stream is buffered to detect BOM and to probe for utf-8 content.
+func Utf8ReaderFrom(src io.Reader, encodingName string) (io.Reader, error) {
+
+	// validate parameters
+	if src == nil {
+		return nil, errors.New("invalid (nil) source reader")
+	}
+	rd := bufio.NewReaderSize(src, utf8ProbeLen)
+
+	// detect BOM
+	bom, err := rd.Peek(utf8.UTFMax)
+	if err != nil && err != io.EOF {
+		return nil, errors.New("file read error: " + err.Error())
+	}
+	nBom := len(bom)
+	if nBom == 0 {
+		return rd, nil // empty source: return it as is
+	}
+
+	// if utf-8 BOM then skip it and return source reader
+	if nBom >= len(Utf8bom) && bom[0] == Utf8bom[0] && bom[1] == Utf8bom[1] && bom[2] == Utf8bom[2] {
+		if _, err := rd.Discard(len(Utf8bom)); err != nil {
+			return nil, errors.New("file read error: " + err.Error())
+		}
+		return rd, nil
+	}
+
+	// ambiguos utf-16LE and utf32-LE detection: assume utf-32LE because 00 00 is very unlikely in text file
+	if nBom >= len(Utf32LEbom) && bom[0] == Utf32LEbom[0] && bom[1] == Utf32LEbom[1] && bom[2] == Utf32LEbom[2] && bom[3] == Utf32LEbom[3] {
+		return transform.NewReader(rd, utf32.UTF32(utf32.LittleEndian, utf32.UseBOM).NewDecoder()), nil
+	}
+	if nBom >= len(Utf32BEbom) && bom[0] == Utf32BEbom[0] && bom[1] == Utf32BEbom[1] && bom[2] == Utf32BEbom[2] && bom[3] == Utf32BEbom[3] {
+		return transform.NewReader(rd, utf32.UTF32(utf32.BigEndian, utf32.UseBOM).NewDecoder()), nil
+	}
+	if nBom >= len(Utf16LEbom) && bom[0] == Utf16LEbom[0] && bom[1] == Utf16LEbom[1] {
+		return transform.NewReader(rd, unicode.BOMOverride(encoding.Nop.NewDecoder())), nil
+	}
+	if nBom >= len(Utf16BEbom) && bom[0] == Utf16BEbom[0] && bom[1] == Utf16BEbom[1] {
+		return transform.NewReader(rd, unicode.BOMOverride(encoding.Nop.NewDecoder())), nil
+	}
+	// no BOM detected
+
+	// encoding not specified then probe source to check is it utf-8
+	if encodingName == "" {
+
+		buf, err := rd.Peek(utf8ProbeLen)
+		if err != nil && err != io.EOF {
+			return nil, errors.New("file read error: " + err.Error())
+		}
+		nProbe := len(buf)
+
+		// check if all runes are utf-8
+		nPos := 0
+		for nPos < nProbe {
+			r, n := utf8.DecodeRune(buf)
+			if n <= 0 || r == utf8.RuneError { // if eof or not utf-8 rune
+				break
+			}
+			nPos += n
+			buf = buf[n:]
+		}
+
+		// source is utf-8 if:
+		// all runes are utf-8 and source size less than max probe size or source size exceeds probe size
+		if nPos >= nProbe || nPos >= utf8ProbeLen-utf8.UTFMax {
+			return rd, nil // utf-8 source: return source reader
+		}
+	}
+
+	return encodingReader(rd, encodingName)
+}
+
+// return a reader to transform source from specified encoding to utf-8.
+// If encoding is "" empty then use OS default: "windows-1252" on Windows and "utf-8" on Linux.
+func encodingReader(rd io.Reader, encodingName string) (io.Reader, error) {
+
 	// if encoding is not explicitly specified then use OS default
 	if encodingName == "" {
 		if runtime.GOOS == "windows" {
@@ -159,5 +242,5 @@ func Utf8Reader(f *os.File, encodingName string) (io.Reader, error) {
 		return nil, errors.New("invalid encoding: " + encodingName + " " + err.Error())
 	}
 
-	return transform.NewReader(f, unicode.BOMOverride(enc.NewDecoder())), nil
+	return transform.NewReader(rd, unicode.BOMOverride(enc.NewDecoder())), nil
 }
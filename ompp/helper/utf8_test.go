@@ -5,6 +5,7 @@ package helper
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"testing"
 )
@@ -97,3 +98,32 @@ func TestUtf8Reader(t *testing.T) {
 
 	checkString("tst_win1252.txt + tst_win1251.txt:", buf.String(), expectedUtf8Test)
 }
+
+func TestUtf8ReaderFrom(t *testing.T) {
+
+	// read file content into utf-8 string from not seekable reader
+	readFrom := func(path, encodingName string) string {
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		rd, err := Utf8ReaderFrom(struct{ io.Reader }{f}, encodingName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bt, err := io.ReadAll(rd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(bt)
+	}
+
+	for _, fn := range []string{"tst_utf8_no_bom.txt", "tst_utf8_bom.txt", "tst_utf16_LE.txt", "tst_utf16_BE.txt", "tst_utf32_LE.txt", "tst_utf32_BE.txt"} {
+		if s := readFrom("testdata/"+fn, ""); s != expectedUtf8Test {
+			t.Errorf("%s: INVALID \n:%s:", fn, s)
+		}
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 )
 
 // PackZip create new (overwrite) zip archive from specified file or directory and all subdirs.
@@ -154,3 +156,224 @@ func UnpackZip(zipPath string, isCleanDstDir bool, dstDir string) error {
 	}
 	return nil
 }
+
+// ZipWriter write files directly into zip archive stream without creating temporary directory.
+//
+// File path is converted into archive name relative to base directory,
+// for example: if base directory is /out then /out/modelOne/modelOne.model.json stored as modelOne/modelOne.model.json.
+// Zip archive is a stream: only one file at a time can be written, file must be completely written before next file created.
+type ZipWriter struct {
+	baseDir string          // base directory of archive names
+	zwr     *zip.Writer     // zip stream writer
+	f       *os.File        // if not nil then zip archive file to close
+	names   map[string]bool // names of files and directories already stored in archive
+	last    string          // name of the last file created in archive
+	lastWr  io.Writer       // writer of the last file created in archive
+}
+
+// NewZipWriter return new zip archive writer into w stream, archive names are relative to base directory.
+func NewZipWriter(w io.Writer, baseDir string) *ZipWriter {
+	return &ZipWriter{
+		baseDir: filepath.Clean(baseDir),
+		zwr:     zip.NewWriter(w),
+		names:   map[string]bool{},
+	}
+}
+
+// CreateZipWriter create new (overwrite) zip archive file and return zip archive writer, archive names are relative to base directory.
+func CreateZipWriter(zipPath string, baseDir string) (*ZipWriter, error) {
+
+	zf, err := os.OpenFile(zipPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.New("create file failed at pack to zip: " + err.Error())
+	}
+	zw := NewZipWriter(zf, baseDir)
+	zw.f = zf
+	return zw, nil
+}
+
+// Close zip archive stream: write zip directory and close archive file, if it was created by CreateZipWriter.
+func (zw *ZipWriter) Close() error {
+
+	err := zw.zwr.Close()
+	if zw.f != nil {
+		if e := zw.f.Close(); e != nil && err == nil {
+			err = e
+		}
+		zw.f = nil
+	}
+	if err != nil {
+		return errors.New("failed pack to zip: " + err.Error())
+	}
+	return nil
+}
+
+// MkdirAll store directory and all parent directories in zip archive, it is no-op if directory already stored.
+func (zw *ZipWriter) MkdirAll(dirPath string) error {
+
+	name, err := zw.nameOf(dirPath)
+	if err != nil {
+		return err
+	}
+	if name == "." {
+		return nil // base directory itself is not stored in archive
+	}
+
+	// store parent directories first, archive directory names are ending with /
+	ps := strings.Split(name, "/")
+	for k := range ps {
+
+		dn := strings.Join(ps[:k+1], "/") + "/"
+		if zw.names[dn] {
+			continue
+		}
+		if _, err = zw.zwr.Create(dn); err != nil {
+			return errors.New("failed pack to zip: " + err.Error())
+		}
+		zw.names[dn] = true
+	}
+	return nil
+}
+
+// Create new file in zip archive and return a writer for that file.
+// Previously created file is closed and must not be used.
+// Return error if file already exist in archive.
+func (zw *ZipWriter) Create(filePath string) (io.Writer, error) {
+
+	name, err := zw.nameOf(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if zw.names[name] {
+		return nil, errors.New("failed pack to zip, file already exist: " + name)
+	}
+	if dn := path.Dir(name); dn != "." {
+		if err = zw.MkdirAll(filepath.Join(zw.baseDir, filepath.FromSlash(dn))); err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := zw.zwr.Create(name)
+	if err != nil {
+		return nil, errors.New("failed pack to zip: " + err.Error())
+	}
+	zw.names[name] = true
+	zw.last = name
+	zw.lastWr = w
+	return w, nil
+}
+
+// Append return a writer to append into the last file created in archive.
+// Return error if file is not the last created because zip stream does not allow to re-open previous files.
+func (zw *ZipWriter) Append(filePath string) (io.Writer, error) {
+
+	name, err := zw.nameOf(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if name != zw.last || zw.lastWr == nil {
+		return nil, errors.New("failed pack to zip, unable to append to: " + name)
+	}
+	return zw.lastWr, nil
+}
+
+// return archive name of the file: file path relative to base directory with / slashes
+func (zw *ZipWriter) nameOf(filePath string) (string, error) {
+
+	rel, err := filepath.Rel(zw.baseDir, filepath.Clean(filePath))
+	if err != nil {
+		return "", errors.New("failed pack to zip: " + err.Error())
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", errors.New("failed pack to zip, path is outside of base directory: " + filePath)
+	}
+	return rel, nil
+}
+
+// ZipReader read files directly from zip archive without unpacking it into temporary directory.
+//
+// File path is converted into archive name relative to base directory,
+// for example: if base directory is /in then /in/modelOne/modelOne.model.json is read from modelOne/modelOne.model.json.
+type ZipReader struct {
+	baseDir string          // base directory of archive names
+	zrd     *zip.ReadCloser // zip archive reader
+}
+
+// OpenZipReader open zip archive for reading, archive names are relative to base directory.
+func OpenZipReader(zipPath string, baseDir string) (*ZipReader, error) {
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, errors.New("open zip file failed at unpack from zip: " + err.Error())
+	}
+	return &ZipReader{baseDir: filepath.Clean(baseDir), zrd: zr}, nil
+}
+
+// Close zip archive.
+func (zr *ZipReader) Close() error {
+	return zr.zrd.Close()
+}
+
+// Open file from zip archive for reading.
+// Return error which satisfy os.IsNotExist() if file not found in archive.
+func (zr *ZipReader) Open(filePath string) (io.ReadCloser, error) {
+
+	name, ok := zr.nameOf(filePath)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: filePath, Err: fs.ErrNotExist}
+	}
+	f, err := zr.zrd.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi, e := f.Stat(); e == nil && fi.IsDir() {
+		f.Close()
+		return nil, &fs.PathError{Op: "open", Path: filePath, Err: errors.New("is a directory")}
+	}
+	return f, nil
+}
+
+// Stat return file or directory info from zip archive.
+// Return error which satisfy os.IsNotExist() if file or directory not found in archive.
+func (zr *ZipReader) Stat(filePath string) (fs.FileInfo, error) {
+
+	name, ok := zr.nameOf(filePath)
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: filePath, Err: fs.ErrNotExist}
+	}
+	return fs.Stat(zr.zrd, name)
+}
+
+// Glob return paths of files in zip archive matching the pattern, pattern syntax is the same as filepath.Match().
+func (zr *ZipReader) Glob(pattern string) ([]string, error) {
+
+	name, ok := zr.nameOf(pattern)
+	if !ok {
+		return []string{}, nil
+	}
+	nl, err := fs.Glob(zr.zrd, name)
+	if err != nil {
+		return nil, err
+	}
+
+	fl := make([]string, len(nl))
+	for k := range nl {
+		fl[k] = filepath.Join(zr.baseDir, filepath.FromSlash(nl[k]))
+	}
+	return fl, nil
+}
+
+// return archive name of the file: file path relative to base directory with / slashes, false if path is outside of base directory
+func (zr *ZipReader) nameOf(filePath string) (string, bool) {
+
+	rel, err := filepath.Rel(zr.baseDir, filepath.Clean(filePath))
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package helper
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestZipWriterReader(t *testing.T) {
+
+	// write files into zip archive stream, names relative to base directory
+	tmpDir := t.TempDir()
+	baseDir := filepath.Join(tmpDir, "out")
+	zipPath := filepath.Join(tmpDir, "modelOne.zip")
+
+	zw, err := CreateZipWriter(zipPath, baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile := func(p, content string, isAppend bool) {
+		var w io.Writer
+		var e error
+		if !isAppend {
+			w, e = zw.Create(p)
+		} else {
+			w, e = zw.Append(p)
+		}
+		if e != nil {
+			t.Fatal(e)
+		}
+		if _, e = io.WriteString(w, content); e != nil {
+			t.Fatal(e)
+		}
+	}
+	writeFile(filepath.Join(baseDir, "modelOne", "modelOne.model.json"), `{"Name":"modelOne"}`, false)
+	writeFile(filepath.Join(baseDir, "modelOne", "run.Default", "parameters", "ageSex.csv"), "age,sex,param_value\n", false)
+	writeFile(filepath.Join(baseDir, "modelOne", "run.Default", "parameters", "ageSex.csv"), "10-20,M,0.1\n", true)
+
+	if err = zw.MkdirAll(filepath.Join(baseDir, "modelOne", "run.Default", "output-tables")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = zw.Create(filepath.Join(baseDir, "modelOne", "modelOne.model.json")); err == nil {
+		t.Error("expected error: create the same file twice")
+	}
+	if _, err = zw.Append(filepath.Join(baseDir, "modelOne", "modelOne.model.json")); err == nil {
+		t.Error("expected error: append to file which is not the last created")
+	}
+	if _, err = zw.Create(filepath.Join(tmpDir, "outside.txt")); err == nil {
+		t.Error("expected error: file outside of base directory")
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// read files from zip archive, mounted into different base directory
+	inpDir := filepath.Join(tmpDir, "inp")
+
+	zr, err := OpenZipReader(zipPath, inpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+
+	readFile := func(p string) string {
+		r, e := zr.Open(p)
+		if e != nil {
+			t.Fatal(e)
+		}
+		defer r.Close()
+		bt, e := io.ReadAll(r)
+		if e != nil {
+			t.Fatal(e)
+		}
+		return string(bt)
+	}
+	if s := readFile(filepath.Join(inpDir, "modelOne", "modelOne.model.json")); s != `{"Name":"modelOne"}` {
+		t.Errorf("model.json: unexpected content: %s", s)
+	}
+	if s := readFile(filepath.Join(inpDir, "modelOne", "run.Default", "parameters", "ageSex.csv")); s != "age,sex,param_value\n10-20,M,0.1\n" {
+		t.Errorf("ageSex.csv: unexpected content: %s", s)
+	}
+
+	fi, err := zr.Stat(filepath.Join(inpDir, "modelOne", "run.Default", "output-tables"))
+	if err != nil || !fi.IsDir() {
+		t.Errorf("output-tables: directory expected: %v", err)
+	}
+	if _, err = zr.Stat(filepath.Join(inpDir, "modelOne", "run.Default", "microdata")); !os.IsNotExist(err) {
+		t.Errorf("microdata: expected not exist error, got: %v", err)
+	}
+	if _, err = zr.Open(filepath.Join(inpDir, "modelOne", "run.Default")); err == nil {
+		t.Error("expected error: open directory as a file")
+	}
+
+	fl, err := zr.Glob(filepath.Join(inpDir, "modelOne", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fl) != 1 || fl[0] != filepath.Join(inpDir, "modelOne", "modelOne.model.json") {
+		t.Errorf("glob *.json: unexpected result: %v", fl)
+	}
+	fl, err = zr.Glob(filepath.Join(tmpDir, "*.zip"))
+	if err != nil || len(fl) != 0 {
+		t.Errorf("glob outside of base directory: expected empty result, got: %v %v", fl, err)
+	}
+}
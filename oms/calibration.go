@@ -0,0 +1,672 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openmpp/go/ompp/db"
+	"github.com/openmpp/go/ompp/helper"
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// calibration optimization methods
+const (
+	CalibrationNelderMead = "nelder-mead" // Nelder-Mead simplex method
+	CalibrationGrid       = "grid"        // regular grid search
+	CalibrationCoordinate = "coordinate"  // coordinate search: one parameter at a time
+)
+
+// calibration status
+const (
+	CalibrationRunning = "run"     // calibration in progress
+	CalibrationDone    = "done"    // calibration completed
+	CalibrationStopped = "stopped" // calibration stopped by user
+	CalibrationError   = "error"   // calibration failed
+)
+
+const calibrationPollSeconds = 2           // interval to check model run status during calibration, in seconds
+const calibrationRunTimeoutDefault = 86400 // default maximum model run time during calibration, in seconds
+
+// CalibrationRequest is a request to calibrate the model: find values of free parameters
+// which minimize weighted sum of squared differences between model output and observed values.
+//
+// Each iteration of calibration creates new read-only workset from base run or base workset,
+// workset name is calibration name, calibration start time stamp and iteration number: Name_Stamp_Iter,
+// submits model run using that workset, waits until run completed and reads target values from output tables.
+// Model runs are submitted in the same way as POST /api/run requests, by default using model executable
+// and options from Run request, for example, by run template which can start any stub executable.
+type CalibrationRequest struct {
+	Name        string              // calibration name, used as prefix of workset and model run names
+	ModelName   string              // model name
+	ModelDigest string              // model digest, if empty then model name used to find the model
+	BaseRun     string              // base run digest, stamp or name, if not empty then worksets are based on that run
+	BaseSet     string              // base workset name, if not empty then worksets are based on that workset
+	Method      string              // optimization method: nelder-mead (default), grid or coordinate
+	MaxIter     int                 // maximum number of model runs, default: 100
+	Tolerance   float64             // convergence tolerance of objective function or step size as part of parameter range
+	GridCount   int                 // grid search: number of values of each parameter, default: 5
+	RunTimeout  int                 // maximum model run time in seconds, default: 86400 (one day)
+	Param       []CalibrationParam  // free parameters
+	Target      []CalibrationTarget // calibration targets: output table cells and observed values
+	Run         RunRequest          // model run options, model run name, run stamp and workset name are set by calibration
+	Txt         []db.DescrNote      // language-specific description and notes of calibration worksets
+}
+
+// CalibrationParam is a free parameter of calibration: parameter name, cell and bounds.
+type CalibrationParam struct {
+	Name  string   // parameter name
+	Dims  []string // if not empty then items codes of parameter cell else all parameter cells have the same value
+	Min   float64  // minimum value
+	Max   float64  // maximum value
+	Start *float64 // if not nil then start value else middle of the [Min, Max] interval
+}
+
+// CalibrationTarget is an output table cell and observed value.
+type CalibrationTarget struct {
+	Table  string   // output table name
+	Expr   string   // output table expression name
+	Dims   []string // items codes of output table cell, one for each table dimension
+	Value  float64  // observed value
+	Weight float64  // weight of target, if zero then 1.0
+}
+
+// CalibrationIteration is a result of model run at one iteration of calibration
+type CalibrationIteration struct {
+	Iter      int       // iteration number, one-based
+	SetName   string    // workset name
+	RunStamp  string    // model run stamp
+	RunDigest string    // model run digest
+	Status    string    // model run status
+	Param     []float64 // parameter values
+	Value     []float64 // model output values of targets
+	Objective float64   // weighted sum of squared differences between output values and targets
+	IsOk      bool      // if true then model run completed successfully and all target values found
+	Msg       string    // error message if model run failed
+}
+
+// CalibrationState is calibration request, status and all iterations
+type CalibrationState struct {
+	CalibrationRequest                        // calibration request
+	Stamp              string                 // calibration start time stamp, part of workset names
+	Status             string                 // calibration status: run, done, stopped, error
+	Msg                string                 // status message
+	StartDateTime      string                 // start date-time
+	UpdateDateTime     string                 // last update date-time
+	BestIter           int                    // best iteration number, zero if none of runs completed successfully
+	BestObjective      float64                // best (minimal) objective value
+	BestParam          []float64              // best parameter values
+	Iteration          []CalibrationIteration // all iterations
+}
+
+// calibration catalog: in-memory state of calibrations
+type calibrationCatalog struct {
+	theLock sync.Mutex                  // mutex to lock for calibration state update
+	items   map[string]*calibrationItem // calibrations by name
+	runIter calibrationIterFunc         // if not nil then used instead of model run, for example: stub model in tests
+}
+
+// create workset, run the model and return iteration results.
+// Return error if calibration must be stopped, for example: workset cannot be created or calibration stopped by user.
+type calibrationIterFunc func(req *CalibrationRequest, setName string, nIter int, pv []float64) (CalibrationIteration, error)
+
+// calibration state and stop request flag
+type calibrationItem struct {
+	state  CalibrationState // calibration state
+	isStop bool             // if true then user requested to stop calibration
+}
+
+var theCalibration calibrationCatalog
+
+var errCalibrationStop = errors.New("calibration stopped")                           // stopped by user
+var errCalibrationMaxIter = errors.New("maximum number of calibration runs reached") // maximum number of runs reached
+
+// CalibrationList return list of calibration states without iterations, sorted by name.
+func (cc *calibrationCatalog) CalibrationList() []CalibrationState {
+
+	cc.theLock.Lock()
+	defer cc.theLock.Unlock()
+
+	cLst := make([]CalibrationState, 0, len(cc.items))
+	for _, ci := range cc.items {
+		cs := ci.state
+		cs.Iteration = []CalibrationIteration{}
+		cLst = append(cLst, cs)
+	}
+	sort.Slice(cLst, func(i, j int) bool { return cLst[i].Name < cLst[j].Name })
+	return cLst
+}
+
+// CalibrationState return copy of calibration state by name.
+func (cc *calibrationCatalog) CalibrationState(name string) (*CalibrationState, bool) {
+
+	cc.theLock.Lock()
+	defer cc.theLock.Unlock()
+
+	ci, ok := cc.items[name]
+	if !ok {
+		return nil, false
+	}
+	cs := ci.state
+	cs.Iteration = append([]CalibrationIteration{}, ci.state.Iteration...)
+	return &cs, true
+}
+
+// StopCalibration set stop flag of calibration, return false if calibration not found or not running.
+// Calibration stopped after current model run completed.
+func (cc *calibrationCatalog) StopCalibration(name string) bool {
+
+	cc.theLock.Lock()
+	defer cc.theLock.Unlock()
+
+	ci, ok := cc.items[name]
+	if !ok || ci.state.Status != CalibrationRunning {
+		return false
+	}
+	ci.isStop = true
+	return true
+}
+
+// StartCalibration validate calibration request and start calibration.
+// Return false if model not found or calibration with the same name is running.
+func (cc *calibrationCatalog) StartCalibration(req CalibrationRequest) (*CalibrationState, bool, error) {
+
+	// find the model
+	dn := req.ModelDigest
+	if dn == "" {
+		dn = req.ModelName
+	}
+	if dn == "" {
+		omppLog.Log("Warning: invalid (empty) model digest and name")
+		return nil, false, nil
+	}
+	meta, _, ok := theCatalog.modelMeta(dn)
+	if !ok {
+		omppLog.Log("Warning: model digest or name not found: ", dn)
+		return nil, false, nil
+	}
+	req.ModelName = meta.Model.Name
+	req.ModelDigest = meta.Model.Digest
+
+	// validate request and set defaults
+	if err := checkCalibrationRequest(meta, &req); err != nil {
+		return nil, false, err
+	}
+
+	// add new calibration state, calibration with the same name must not be running
+	stamp, tNow := theCatalog.getNewTimeStamp()
+
+	cc.theLock.Lock()
+	if ci, ok := cc.items[req.Name]; ok && ci.state.Status == CalibrationRunning {
+		cc.theLock.Unlock()
+		return nil, false, errors.New("Error: calibration is running: " + req.Name)
+	}
+	if cc.items == nil {
+		cc.items = map[string]*calibrationItem{}
+	}
+	ci := &calibrationItem{
+		state: CalibrationState{
+			CalibrationRequest: req,
+			Stamp:              stamp,
+			Status:             CalibrationRunning,
+			StartDateTime:      helper.MakeDateTime(tNow),
+			UpdateDateTime:     helper.MakeDateTime(tNow),
+			Iteration:          []CalibrationIteration{},
+		},
+	}
+	cc.items[req.Name] = ci
+	cs := ci.state
+	cc.theLock.Unlock()
+
+	go cc.runCalibration(req, stamp)
+
+	return &cs, true, nil
+}
+
+// validate calibration request and set default values
+func checkCalibrationRequest(meta *db.ModelMeta, req *CalibrationRequest) error {
+
+	if req.Name == "" || helper.CleanFileName(req.Name) != req.Name {
+		return errors.New("Error: invalid calibration name: " + req.Name)
+	}
+	if req.Method == "" {
+		req.Method = CalibrationNelderMead
+	}
+	if req.Method != CalibrationNelderMead && req.Method != CalibrationGrid && req.Method != CalibrationCoordinate {
+		return errors.New("Error: invalid calibration method: " + req.Method)
+	}
+	if req.MaxIter <= 0 {
+		req.MaxIter = 100
+	}
+	if req.Tolerance <= 0 {
+		req.Tolerance = 1.0e-6
+	}
+	if req.GridCount <= 0 {
+		req.GridCount = 5
+	}
+	if req.RunTimeout <= 0 {
+		req.RunTimeout = calibrationRunTimeoutDefault
+	}
+
+	// check free parameters: parameter must exist, must be float or integer type and bounds must be valid
+	if len(req.Param) <= 0 {
+		return errors.New("Error: calibration parameters list is empty")
+	}
+	for k := range req.Param {
+
+		p := &req.Param[k]
+		idx, ok := meta.ParamByName(p.Name)
+		if !ok {
+			return errors.New("Error: parameter not found: " + p.Name)
+		}
+		if len(p.Dims) != 0 && len(p.Dims) != meta.Param[idx].Rank {
+			return errors.New("Error: invalid number of dimension items of parameter: " + p.Name)
+		}
+		if math.IsNaN(p.Min) || math.IsNaN(p.Max) || math.IsInf(p.Min, 0) || math.IsInf(p.Max, 0) || p.Min >= p.Max {
+			return errors.New("Error: invalid bounds of parameter: " + p.Name)
+		}
+		if p.Start == nil || math.IsNaN(*p.Start) || *p.Start < p.Min || *p.Start > p.Max {
+			v := (p.Min + p.Max) / 2
+			p.Start = &v
+		}
+	}
+
+	// check targets: output table, expression and number of dimension items
+	if len(req.Target) <= 0 {
+		return errors.New("Error: calibration targets list is empty")
+	}
+	for k := range req.Target {
+
+		t := &req.Target[k]
+		idx, ok := meta.OutTableByName(t.Table)
+		if !ok {
+			return errors.New("Error: output table not found: " + t.Table)
+		}
+		isExpr := false
+		for j := range meta.Table[idx].Expr {
+			if isExpr = meta.Table[idx].Expr[j].Name == t.Expr; isExpr {
+				break
+			}
+		}
+		if !isExpr {
+			return errors.New("Error: output table expression not found: " + t.Table + "." + t.Expr)
+		}
+		if len(t.Dims) != len(meta.Table[idx].Dim) {
+			return errors.New("Error: invalid number of dimension items of output table: " + t.Table)
+		}
+		if math.IsNaN(t.Value) || math.IsInf(t.Value, 0) {
+			return errors.New("Error: invalid target value: " + t.Table + "." + t.Expr)
+		}
+		if t.Weight < 0 || math.IsNaN(t.Weight) || math.IsInf(t.Weight, 0) {
+			return errors.New("Error: invalid target weight: " + t.Table + "." + t.Expr)
+		}
+		if t.Weight == 0 {
+			t.Weight = 1
+		}
+	}
+	return nil
+}
+
+// run calibration: create workset and run the model at each point requested by optimization method.
+// Workset names are: Name_Stamp_1, Name_Stamp_2,... where stamp is calibration start time stamp.
+func (cc *calibrationCatalog) runCalibration(req CalibrationRequest, stamp string) {
+
+	omppLog.Log("Calibration started: ", req.Name, " ", req.ModelName, " ", req.ModelDigest, " method: ", req.Method)
+
+	runIter := cc.runIter
+	if runIter == nil {
+		runIter = cc.calibrationRunIteration
+	}
+
+	// evaluated points: objective value by parameter values
+	nIter := 0
+	done := map[string]float64{}
+
+	objFn := func(u []float64) (float64, error) {
+
+		// convert from [0, 1] into parameter values
+		pv := make([]float64, len(req.Param))
+		for k := range pv {
+			pv[k] = req.Param[k].Min + u[k]*(req.Param[k].Max-req.Param[k].Min)
+		}
+
+		// check if the same point already evaluated
+		key := ""
+		for k := range pv {
+			key += strconv.FormatFloat(pv[k], 'g', -1, 64) + ","
+		}
+		if v, ok := done[key]; ok {
+			return v, nil
+		}
+
+		// check if stop requested or maximum number of runs reached
+		if cc.isStopRequested(req.Name) {
+			return math.Inf(1), errCalibrationStop
+		}
+		if nIter >= req.MaxIter {
+			return math.Inf(1), errCalibrationMaxIter
+		}
+		nIter++
+
+		it, err := runIter(&req, req.Name+"_"+stamp+"_"+strconv.Itoa(nIter), nIter, pv)
+		if err != nil {
+			return math.Inf(1), err
+		}
+
+		v := math.Inf(1)
+		if it.IsOk {
+			v = it.Objective
+		}
+		done[key] = v
+
+		cc.addIteration(req.Name, it)
+		return v, nil
+	}
+
+	// starting point as part of parameter range
+	start := make([]float64, len(req.Param))
+	for k := range start {
+		start[k] = (*req.Param[k].Start - req.Param[k].Min) / (req.Param[k].Max - req.Param[k].Min)
+	}
+
+	var err error
+	switch req.Method {
+	case CalibrationGrid:
+		err = gridSearch(objFn, len(req.Param), req.GridCount)
+	case CalibrationCoordinate:
+		err = coordinateSearch(objFn, start, req.Tolerance, req.MaxIter)
+	default:
+		err = nelderMead(objFn, start, req.Tolerance, req.MaxIter)
+	}
+
+	// update calibration status
+	status := CalibrationDone
+	msg := ""
+	switch {
+	case err == nil:
+	case errors.Is(err, errCalibrationMaxIter):
+		msg = err.Error()
+	case errors.Is(err, errCalibrationStop):
+		status = CalibrationStopped
+	default:
+		status = CalibrationError
+		msg = err.Error()
+	}
+	cs := cc.setStatus(req.Name, status, msg)
+
+	omppLog.Log("Calibration ", status, ": ", req.Name, " runs: ", nIter, " best iteration: ", cs.BestIter, " ", msg)
+}
+
+// create workset, run the model and read target values from output tables.
+// Return iteration results, if IsOk is true then model run completed and objective value calculated.
+// Return error if workset cannot be created, model run rejected or calibration stopped by user.
+func (cc *calibrationCatalog) calibrationRunIteration(req *CalibrationRequest, setName string, nIter int, pv []float64) (CalibrationIteration, error) {
+
+	it := CalibrationIteration{
+		Iter:    nIter,
+		SetName: setName,
+		Param:   pv,
+		Value:   []float64{},
+	}
+
+	// create workset with new parameter values
+	pvLst := make([]db.ParamCellValue, len(req.Param))
+	for k, p := range req.Param {
+		pvLst[k] = db.ParamCellValue{Name: p.Name, Dims: p.Dims, Value: pv[k]}
+	}
+	if ok, err := theCatalog.CreateWorksetFromBase(req.ModelDigest, it.SetName, req.BaseRun, req.BaseSet, pvLst, req.Txt); !ok || err != nil {
+		if err != nil {
+			return it, errors.New("Failed to create workset: " + it.SetName + ": " + err.Error())
+		}
+		return it, errors.New("Failed to create workset: " + it.SetName)
+	}
+
+	// submit model run using new workset
+	if theCfg.isJobControl && isDrainJobQueue() {
+		return it, errors.New("Model run rejected: oms instance is draining jobs queue")
+	}
+	if isOver, _ := theRunCatalog.getDiskUseStatus(); isOver {
+		return it, errors.New("Disk space usage exceeds quota, model run disabled")
+	}
+
+	rReq := req.Run
+	rReq.ModelName = req.ModelName
+	rReq.ModelDigest = req.ModelDigest
+	rReq.RunStamp, _ = theCatalog.getNewTimeStamp()
+	rReq.Opts = map[string]string{}
+	for key, val := range req.Run.Opts {
+		if !strings.EqualFold(strings.TrimPrefix(key, "-"), "OpenM.SetName") && !strings.EqualFold(strings.TrimPrefix(key, "-"), "OpenM.RunName") {
+			rReq.Opts[key] = val
+		}
+	}
+	rReq.Opts["OpenM.SetName"] = it.SetName
+	rReq.Opts["OpenM.RunName"] = it.SetName
+
+	// check workset parameters using validation rules of the model, model run rejected if any rule violated
+	if err := checkRunWorkset(rReq); err != nil {
+		return it, err
+	}
+
+	rs, err := submitRunRequest(rReq, RunResume{})
+	if err != nil {
+		it.Msg = err.Error()
+		return it, nil
+	}
+	it.RunStamp = rs.RunStamp
+	if it.RunStamp == "" {
+		it.RunStamp = helper.CleanFileName(rReq.RunStamp)
+	}
+
+	// stop model run or remove it from the queue
+	stopRun := func() {
+		_, submitStamp, jobPath, isRunning := theRunCatalog.stopModelRun(req.ModelDigest, it.RunStamp)
+		if !isRunning {
+			moveJobQueueToFailed(jobPath, submitStamp, req.ModelName, req.ModelDigest, it.RunStamp) // model was not running, move job control file to history
+		}
+	}
+
+	// wait until model run completed, calibration stopped by user or model run timeout
+	tStart := time.Now()
+	for {
+		time.Sleep(calibrationPollSeconds * time.Second)
+
+		if rp, ok := theCatalog.RunStatus(req.ModelDigest, it.RunStamp); ok && db.IsRunCompleted(rp.Status) {
+			it.Status = rp.Status
+			it.RunDigest = rp.RunDigest
+			break
+		}
+		if isRunStateFinal(req.ModelDigest, it.RunStamp) {
+			if rp, ok := theCatalog.RunStatus(req.ModelDigest, it.RunStamp); !ok || !db.IsRunCompleted(rp.Status) {
+				it.Msg = "Model run failed: " + it.RunStamp
+				return it, nil
+			}
+			continue // model run completed: get run status at next check
+		}
+		if cc.isStopRequested(req.Name) {
+			stopRun()
+			return it, errCalibrationStop
+		}
+		if time.Since(tStart) > time.Duration(req.RunTimeout)*time.Second {
+			stopRun()
+			it.Msg = "Model run timeout: " + it.RunStamp
+			return it, nil
+		}
+	}
+	if it.Status != db.DoneRunStatus {
+		it.Msg = "Model run not completed successfully: " + it.RunStamp + ": " + db.NameOfRunStatus(it.Status)
+		return it, nil
+	}
+
+	// read target values and calculate objective
+	for _, t := range req.Target {
+
+		v, ok := readCalibrationTarget(req.ModelDigest, it.RunStamp, t)
+		if !ok {
+			it.Msg = "Target value not found: " + t.Table + "." + t.Expr + " [" + strings.Join(t.Dims, ",") + "]"
+			return it, nil
+		}
+		it.Value = append(it.Value, v)
+	}
+	return calibrationObjective(req, it), nil
+}
+
+// calculate objective of iteration: weighted sum of squared differences between model output values and targets.
+// If objective is not a finite number then iteration result is not valid.
+func calibrationObjective(req *CalibrationRequest, it CalibrationIteration) CalibrationIteration {
+
+	obj := 0.0
+	for k, t := range req.Target {
+		obj += t.Weight * (it.Value[k] - t.Value) * (it.Value[k] - t.Value)
+	}
+	it.Objective = obj
+	it.IsOk = !math.IsNaN(obj) && !math.IsInf(obj, 0)
+	if !it.IsOk {
+		it.Objective = 0
+		it.Msg = "Invalid objective value"
+	}
+	return it
+}
+
+// return true if model run state is final: model process completed or failed to start
+func isRunStateFinal(modelDigest, runStamp string) bool {
+
+	theRunCatalog.rscLock.Lock()
+	defer theRunCatalog.rscLock.Unlock()
+
+	rsl := theRunCatalog.findRunStateLog(modelDigest, runStamp)
+	return rsl != nil && rsl.IsFinal
+}
+
+// read target value: output table expression cell value from model run
+func readCalibrationTarget(dn, rdsn string, t CalibrationTarget) (float64, bool) {
+
+	meta, _, ok := theCatalog.modelMeta(dn)
+	if !ok {
+		return 0, false
+	}
+	idx, ok := meta.OutTableByName(t.Table)
+	if !ok || len(t.Dims) != len(meta.Table[idx].Dim) {
+		return 0, false
+	}
+
+	layout := db.ReadTableLayout{
+		ReadLayout: db.ReadLayout{
+			Name:   t.Table,
+			Filter: []db.FilterColumn{},
+		},
+		ValueName: t.Expr,
+	}
+	for k, d := range meta.Table[idx].Dim {
+		layout.Filter = append(layout.Filter, db.FilterColumn{Name: d.Name, Op: db.EqOpFilter, Values: []string{t.Dims[k]}})
+	}
+
+	val := 0.0
+	isFound := false
+
+	cvt := func(src interface{}) (bool, error) {
+
+		c, ok := src.(db.CellExpr)
+		if !ok || c.IsNull {
+			return false, nil
+		}
+		switch v := c.Value.(type) {
+		case float64:
+			val = v
+		case int64:
+			val = float64(v)
+		default:
+			return false, nil
+		}
+		isFound = true
+		return false, nil // single cell expected
+	}
+
+	if _, ok := theCatalog.ReadOutTableTo(dn, rdsn, &layout, cvt); !ok {
+		return 0, false
+	}
+	return val, isFound
+}
+
+// return true if user requested to stop calibration
+func (cc *calibrationCatalog) isStopRequested(name string) bool {
+
+	cc.theLock.Lock()
+	defer cc.theLock.Unlock()
+
+	ci, ok := cc.items[name]
+	return !ok || ci.isStop
+}
+
+// append iteration to calibration state, update best result and save calibration state
+func (cc *calibrationCatalog) addIteration(name string, it CalibrationIteration) {
+
+	_, tNow := theCatalog.getNewTimeStamp()
+
+	cc.theLock.Lock()
+	ci, ok := cc.items[name]
+	if !ok {
+		cc.theLock.Unlock()
+		return
+	}
+	ci.state.Iteration = append(ci.state.Iteration, it)
+	ci.state.UpdateDateTime = helper.MakeDateTime(tNow)
+
+	if it.IsOk && (ci.state.BestIter <= 0 || it.Objective < ci.state.BestObjective) {
+		ci.state.BestIter = it.Iter
+		ci.state.BestObjective = it.Objective
+		ci.state.BestParam = it.Param
+	}
+	cs := ci.state
+	cc.theLock.Unlock()
+
+	saveCalibrationState(&cs)
+}
+
+// set calibration status and save calibration state, return copy of calibration state
+func (cc *calibrationCatalog) setStatus(name string, status, msg string) CalibrationState {
+
+	_, tNow := theCatalog.getNewTimeStamp()
+
+	cc.theLock.Lock()
+	ci, ok := cc.items[name]
+	if !ok {
+		cc.theLock.Unlock()
+		return CalibrationState{}
+	}
+	ci.state.Status = status
+	ci.state.Msg = msg
+	ci.state.UpdateDateTime = helper.MakeDateTime(tNow)
+	cs := ci.state
+	cc.theLock.Unlock()
+
+	saveCalibrationState(&cs)
+	return cs
+}
+
+// if job control enabled then save calibration state into job/calibration/name.json file
+func saveCalibrationState(cs *CalibrationState) {
+
+	if !theCfg.isJobControl {
+		return
+	}
+	dir := filepath.Join(theCfg.jobDir, "calibration")
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		omppLog.Log("Error at create calibration directory: ", dir, ": ", err.Error())
+		return
+	}
+	p := filepath.Join(dir, cs.Name+".json")
+
+	if err := helper.ToJsonIndentFile(p, cs); err != nil {
+		omppLog.Log("Error at save calibration state: ", p, ": ", err.Error())
+	}
+}
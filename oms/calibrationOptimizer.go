@@ -0,0 +1,249 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"math"
+	"sort"
+
+	"github.com/openmpp/go/ompp/db"
+)
+
+// objective function of calibration: return weighted sum of squared differences between model results and targets.
+// Function arguments are parameter values scaled into [0, 1] interval: 0 is a parameter minimum and 1 is a maximum.
+// If error returned then optimization stopped, for example, if calibration stopped by user or maximum number of model runs reached.
+type objectiveFunc func(u []float64) (float64, error)
+
+// clamp value into [0, 1] interval
+func clampUnit(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// nelderMead find minimum of objective function using Nelder-Mead simplex method.
+// Search is bounded by unit cube: each vertex of simplex clamped into [0, 1] interval.
+// Search stopped if difference of objective values at simplex vertices or simplex size is less or equal to tolerance
+// or after maxLoop iterations of simplex method.
+func nelderMead(fn objectiveFunc, start []float64, tol float64, maxLoop int) error {
+
+	const (
+		alpha = 1.0 // reflection
+		gamma = 2.0 // expansion
+		rho   = 0.5 // contraction
+		sigma = 0.5 // shrink
+		step  = 0.1 // initial simplex size
+	)
+	n := len(start)
+	if n <= 0 {
+		return nil
+	}
+
+	// make initial simplex: start point and one step along each coordinate
+	xs := make([][]float64, n+1)
+	fs := make([]float64, n+1)
+
+	for i := 0; i <= n; i++ {
+		xs[i] = make([]float64, n)
+		for j := range start {
+			xs[i][j] = clampUnit(start[j])
+		}
+		if i > 0 {
+			if xs[i][i-1]+step <= 1 {
+				xs[i][i-1] += step
+			} else {
+				xs[i][i-1] -= step
+			}
+		}
+		v, err := fn(xs[i])
+		if err != nil {
+			return err
+		}
+		fs[i] = v
+	}
+
+	// evaluate objective at point: centroid + coef * (centroid - worst)
+	pointAt := func(c []float64, w []float64, coef float64) ([]float64, float64, error) {
+		p := make([]float64, n)
+		for j := range p {
+			p[j] = clampUnit(c[j] + coef*(c[j]-w[j]))
+		}
+		v, err := fn(p)
+		return p, v, err
+	}
+
+	for nLoop := 0; nLoop < maxLoop; nLoop++ {
+
+		// order vertices by objective value
+		idx := make([]int, n+1)
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(i, j int) bool { return fs[idx[i]] < fs[idx[j]] })
+
+		xo := make([][]float64, n+1)
+		fo := make([]float64, n+1)
+		for i, k := range idx {
+			xo[i] = xs[k]
+			fo[i] = fs[k]
+		}
+		xs, fs = xo, fo
+
+		// check convergence: objective values and simplex size
+		if math.Abs(fs[n]-fs[0]) <= tol {
+			return nil
+		}
+		sz := 0.0
+		for i := 1; i <= n; i++ {
+			for j := 0; j < n; j++ {
+				sz = math.Max(sz, math.Abs(xs[i][j]-xs[0][j]))
+			}
+		}
+		if sz <= tol {
+			return nil
+		}
+
+		// centroid of all vertices except the worst
+		c := make([]float64, n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				c[j] += xs[i][j] / float64(n)
+			}
+		}
+
+		// reflection
+		xr, fr, err := pointAt(c, xs[n], alpha)
+		if err != nil {
+			return err
+		}
+		if fs[0] <= fr && fr < fs[n-1] {
+			xs[n], fs[n] = xr, fr
+			continue
+		}
+
+		// expansion
+		if fr < fs[0] {
+			xe, fe, err := pointAt(c, xs[n], gamma)
+			if err != nil {
+				return err
+			}
+			if fe < fr {
+				xs[n], fs[n] = xe, fe
+			} else {
+				xs[n], fs[n] = xr, fr
+			}
+			continue
+		}
+
+		// contraction: outside if reflected point is better than the worst else inside
+		coef := -rho
+		fw := fs[n]
+		if fr < fs[n] {
+			coef = rho * alpha
+			fw = fr
+		}
+		xc, fc, err := pointAt(c, xs[n], coef)
+		if err != nil {
+			return err
+		}
+		if fc < fw {
+			xs[n], fs[n] = xc, fc
+			continue
+		}
+
+		// shrink toward the best vertex
+		for i := 1; i <= n; i++ {
+			for j := 0; j < n; j++ {
+				xs[i][j] = xs[0][j] + sigma*(xs[i][j]-xs[0][j])
+			}
+			v, err := fn(xs[i])
+			if err != nil {
+				return err
+			}
+			fs[i] = v
+		}
+	}
+	return nil
+}
+
+// gridSearch evaluate objective function at each node of regular grid.
+// Grid has count levels for each dimension, if count is one then only middle of the interval used.
+func gridSearch(fn objectiveFunc, dimCount int, count int) error {
+
+	if dimCount <= 0 || count <= 0 {
+		return nil
+	}
+	levels := make([]int, dimCount)
+	for j := range levels {
+		levels[j] = count
+	}
+
+	for _, c := range db.SweepCombinations(levels, false) {
+
+		u := make([]float64, dimCount)
+		for j := range u {
+			if count > 1 {
+				u[j] = float64(c[j]) / float64(count-1)
+			} else {
+				u[j] = 0.5
+			}
+		}
+		if _, err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// coordinateSearch find minimum of objective function by moving along one coordinate at a time.
+// If there is no improvement along any coordinate then step size is halved.
+// Search stopped if step size is less or equal to tolerance or after maxLoop iterations.
+func coordinateSearch(fn objectiveFunc, start []float64, tol float64, maxLoop int) error {
+
+	n := len(start)
+	if n <= 0 {
+		return nil
+	}
+	x := make([]float64, n)
+	for j := range start {
+		x[j] = clampUnit(start[j])
+	}
+	fx, err := fn(x)
+	if err != nil {
+		return err
+	}
+
+	step := 0.25
+	for nLoop := 0; nLoop < maxLoop && step > tol; nLoop++ {
+
+		isBetter := false
+		for j := 0; j < n; j++ {
+			for _, d := range []float64{step, -step} {
+
+				p := append([]float64{}, x...)
+				p[j] = clampUnit(x[j] + d)
+				if p[j] == x[j] {
+					continue // bound reached
+				}
+				v, err := fn(p)
+				if err != nil {
+					return err
+				}
+				if v < fx {
+					x, fx = p, v
+					isBetter = true
+					break
+				}
+			}
+		}
+		if !isBetter {
+			step /= 2
+		}
+	}
+	return nil
+}
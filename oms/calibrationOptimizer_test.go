@@ -0,0 +1,107 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// quadratic objective with known minimum at center point, it records best point found by optimizer
+type quadObjective struct {
+	center []float64 // minimum of objective function
+	nCall  int       // number of objective function calls
+	best   []float64 // best point found
+	fBest  float64   // best objective value found
+}
+
+func (q *quadObjective) fn(u []float64) (float64, error) {
+
+	q.nCall++
+	v := 0.0
+	for j := range u {
+		v += (u[j] - q.center[j]) * (u[j] - q.center[j])
+	}
+	if q.best == nil || v < q.fBest {
+		q.best = append([]float64{}, u...)
+		q.fBest = v
+	}
+	return v, nil
+}
+
+func TestCalibrationOptimizer(t *testing.T) {
+
+	for _, c := range []struct {
+		name   string
+		center []float64
+		start  []float64
+		maxErr float64
+		search func(fn objectiveFunc, start []float64) error
+	}{
+		{"nelder-mead 1d", []float64{0.3}, []float64{0.5}, 1.0e-4,
+			func(fn objectiveFunc, start []float64) error { return nelderMead(fn, start, 1.0e-10, 500) }},
+		{"nelder-mead 2d", []float64{0.3, 0.7}, []float64{0.5, 0.5}, 1.0e-4,
+			func(fn objectiveFunc, start []float64) error { return nelderMead(fn, start, 1.0e-10, 500) }},
+		{"nelder-mead 3d at bound", []float64{0.2, 1.0, 0.6}, []float64{0.5, 0.5, 0.5}, 1.0e-3,
+			func(fn objectiveFunc, start []float64) error { return nelderMead(fn, start, 1.0e-10, 1000) }},
+		{"coordinate 2d", []float64{0.3, 0.7}, []float64{0.5, 0.5}, 1.0e-4,
+			func(fn objectiveFunc, start []float64) error { return coordinateSearch(fn, start, 1.0e-6, 500) }},
+		{"coordinate 3d at bound", []float64{0.0, 0.45, 0.9}, []float64{0.5, 0.5, 0.5}, 1.0e-4,
+			func(fn objectiveFunc, start []float64) error { return coordinateSearch(fn, start, 1.0e-6, 500) }},
+		{"grid 2d", []float64{0.3, 0.7}, nil, 1.0e-9,
+			func(fn objectiveFunc, start []float64) error { return gridSearch(fn, 2, 11) }},
+		{"grid 2d between nodes", []float64{0.3, 0.7}, nil, 0.1,
+			func(fn objectiveFunc, start []float64) error { return gridSearch(fn, 2, 5) }},
+	} {
+		q := &quadObjective{center: c.center}
+
+		if err := c.search(q.fn, c.start); err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		for j := range c.center {
+			if len(q.best) != len(c.center) || math.Abs(q.best[j]-c.center[j]) > c.maxErr {
+				t.Errorf("%s: invalid minimum: %v, expected: %v, calls: %d", c.name, q.best, c.center, q.nCall)
+				break
+			}
+		}
+	}
+
+	// grid search must evaluate each grid node
+	q := &quadObjective{center: []float64{0.5, 0.5, 0.5}}
+	if err := gridSearch(q.fn, 3, 4); err != nil || q.nCall != 4*4*4 {
+		t.Errorf("grid search: expected %d calls, got: %d, error: %v", 4*4*4, q.nCall, err)
+	}
+	q = &quadObjective{center: []float64{0.2}}
+	if err := gridSearch(q.fn, 1, 1); err != nil || q.nCall != 1 || q.best[0] != 0.5 {
+		t.Errorf("grid search with one level: expected single call at 0.5, got: %d %v %v", q.nCall, q.best, err)
+	}
+}
+
+func TestCalibrationOptimizerStop(t *testing.T) {
+
+	errStop := errors.New("stop")
+
+	for _, c := range []struct {
+		name   string
+		search func(fn objectiveFunc) error
+	}{
+		{"nelder-mead", func(fn objectiveFunc) error { return nelderMead(fn, []float64{0.5, 0.5}, 1.0e-10, 500) }},
+		{"coordinate", func(fn objectiveFunc) error { return coordinateSearch(fn, []float64{0.5, 0.5}, 1.0e-10, 500) }},
+		{"grid", func(fn objectiveFunc) error { return gridSearch(fn, 2, 10) }},
+	} {
+		// objective function returns error after 7 calls, optimizer must stop and return that error
+		q := &quadObjective{center: []float64{0.3, 0.7}}
+		fn := func(u []float64) (float64, error) {
+			if q.nCall >= 7 {
+				return math.Inf(1), errStop
+			}
+			return q.fn(u)
+		}
+		if err := c.search(fn); !errors.Is(err, errStop) || q.nCall != 7 {
+			t.Errorf("%s: expected stop after 7 calls, got: %d, error: %v", c.name, q.nCall, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// stub model: output table has two cells, first cell value is 2*a + b and second is a - b,
+// where a and b are free parameters of calibration
+func stubModelIteration(req *CalibrationRequest, setName string, nIter int, pv []float64) (CalibrationIteration, error) {
+
+	it := CalibrationIteration{
+		Iter:     nIter,
+		SetName:  setName,
+		RunStamp: "stub_" + strconv.Itoa(nIter),
+		Status:   "s",
+		Param:    pv,
+		Value:    []float64{2*pv[0] + pv[1], pv[0] - pv[1]},
+	}
+	return calibrationObjective(req, it), nil
+}
+
+// make calibration request for stub model: targets are 2*a + b = 1.6 and a - b = 0.2, solution is a = 0.6, b = 0.4
+func stubCalibrationRequest(name, method string) CalibrationRequest {
+
+	return CalibrationRequest{
+		Name:       name,
+		ModelName:  "stubModel",
+		Method:     method,
+		MaxIter:    500,
+		Tolerance:  1.0e-10,
+		GridCount:  11,
+		RunTimeout: calibrationRunTimeoutDefault,
+		Param: []CalibrationParam{
+			{Name: "a", Min: 0, Max: 1, Start: new(float64)},
+			{Name: "b", Min: -1, Max: 1, Start: new(float64)},
+		},
+		Target: []CalibrationTarget{
+			{Table: "T", Expr: "expr0", Dims: []string{"0"}, Value: 1.6, Weight: 1},
+			{Table: "T", Expr: "expr0", Dims: []string{"1"}, Value: 0.2, Weight: 1},
+		},
+	}
+}
+
+// add calibration state and run calibration until completed
+func runStubCalibration(cc *calibrationCatalog, req CalibrationRequest) CalibrationState {
+
+	cc.items[req.Name] = &calibrationItem{state: CalibrationState{CalibrationRequest: req, Stamp: "stamp", Status: CalibrationRunning}}
+	cc.runCalibration(req, "stamp")
+
+	cs, _ := cc.CalibrationState(req.Name)
+	return *cs
+}
+
+func TestCalibrationStubModel(t *testing.T) {
+
+	cc := &calibrationCatalog{items: map[string]*calibrationItem{}, runIter: stubModelIteration}
+
+	for _, c := range []struct {
+		method string
+		maxErr float64
+	}{
+		{CalibrationNelderMead, 1.0e-3},
+		{CalibrationCoordinate, 1.0e-3},
+		{CalibrationGrid, 1.0e-9},
+	} {
+		cs := runStubCalibration(cc, stubCalibrationRequest("calib_"+c.method, c.method))
+
+		if cs.Status != CalibrationDone {
+			t.Errorf("%s: invalid calibration status: %s %s", c.method, cs.Status, cs.Msg)
+			continue
+		}
+		if cs.BestIter <= 0 || len(cs.BestParam) != 2 || math.Abs(cs.BestParam[0]-0.6) > c.maxErr || math.Abs(cs.BestParam[1]-0.4) > c.maxErr {
+			t.Errorf("%s: invalid best parameters: %v, expected: [0.6 0.4], iteration: %d", c.method, cs.BestParam, cs.BestIter)
+		}
+		if len(cs.Iteration) <= 0 || cs.Iteration[0].SetName != "calib_"+c.method+"_stamp_1" {
+			t.Errorf("%s: invalid iterations: %d", c.method, len(cs.Iteration))
+		}
+	}
+}
+
+func TestCalibrationStubModelStop(t *testing.T) {
+
+	// maximum number of model runs reached: calibration completed with message
+	cc := &calibrationCatalog{items: map[string]*calibrationItem{}, runIter: stubModelIteration}
+
+	req := stubCalibrationRequest("calib_max", CalibrationNelderMead)
+	req.MaxIter = 5
+
+	cs := runStubCalibration(cc, req)
+	if cs.Status != CalibrationDone || cs.Msg != errCalibrationMaxIter.Error() || len(cs.Iteration) != 5 {
+		t.Errorf("max iterations: invalid calibration state: %s %s, iterations: %d", cs.Status, cs.Msg, len(cs.Iteration))
+	}
+
+	// workset cannot be created at third iteration: calibration failed
+	errWs := errors.New("workset already exist")
+	cc.runIter = func(req *CalibrationRequest, setName string, nIter int, pv []float64) (CalibrationIteration, error) {
+		if nIter >= 3 {
+			return CalibrationIteration{}, errWs
+		}
+		return stubModelIteration(req, setName, nIter, pv)
+	}
+	cs = runStubCalibration(cc, stubCalibrationRequest("calib_err", CalibrationCoordinate))
+	if cs.Status != CalibrationError || cs.Msg != errWs.Error() || len(cs.Iteration) != 2 {
+		t.Errorf("iteration error: invalid calibration state: %s %s, iterations: %d", cs.Status, cs.Msg, len(cs.Iteration))
+	}
+
+	// user request to stop calibration at fourth iteration
+	cc.runIter = func(req *CalibrationRequest, setName string, nIter int, pv []float64) (CalibrationIteration, error) {
+		if nIter >= 4 {
+			cc.StopCalibration(req.Name)
+		}
+		return stubModelIteration(req, setName, nIter, pv)
+	}
+	cs = runStubCalibration(cc, stubCalibrationRequest("calib_stop", CalibrationGrid))
+	if cs.Status != CalibrationStopped || len(cs.Iteration) != 4 {
+		t.Errorf("stop: invalid calibration state: %s %s, iterations: %d", cs.Status, cs.Msg, len(cs.Iteration))
+	}
+}
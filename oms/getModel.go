@@ -155,6 +155,38 @@ func (mc *ModelCatalog) ModelMetaAllTextByDigestOrName(dn string) (*db.ModelTxtM
 	return mc.modelLst[idx].txtMeta, nil
 }
 
+// ModelDiff return differences between two versions of model metadata by model digest or name.
+// Models can be in the same or in different databases, description and notes are compared in all languages.
+func (mc *ModelCatalog) ModelDiff(dn, withDn string) (*db.ModelDiff, bool, error) {
+
+	fromMeta, _, ok := mc.modelMeta(dn)
+	if !ok {
+		return &db.ModelDiff{}, false, nil // model not found
+	}
+	toMeta, _, ok := mc.modelMeta(withDn)
+	if !ok {
+		return &db.ModelDiff{}, false, nil // model not found
+	}
+
+	fromTxt, err := mc.ModelMetaAllTextByDigestOrName(fromMeta.Model.Digest)
+	if err != nil {
+		omppLog.Log("Error at model language-specific metadata search: ", dn, ": ", err.Error())
+		return &db.ModelDiff{}, false, err
+	}
+	toTxt, err := mc.ModelMetaAllTextByDigestOrName(toMeta.Model.Digest)
+	if err != nil {
+		omppLog.Log("Error at model language-specific metadata search: ", withDn, ": ", err.Error())
+		return &db.ModelDiff{}, false, err
+	}
+
+	md, err := db.CompareModel(fromMeta, toMeta, fromTxt, toTxt)
+	if err != nil {
+		omppLog.Log("Error at compare models: ", dn, ": ", withDn, ": ", err.Error())
+		return &db.ModelDiff{}, false, err
+	}
+	return md, true, nil
+}
+
 // loadModelText reads language-specific model metadata from db by digest or name.
 // If metadata already loaded then skip db reading and return success.
 func (mc *ModelCatalog) loadModelText(dn string) bool {
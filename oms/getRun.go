@@ -197,6 +197,51 @@ func (mc *ModelCatalog) FirstOrLastRunStatus(dn string, isFirst, isCompleted boo
 	return rp, true
 }
 
+// RunResumeState return run_lst db row, run_progress db rows and run options of incomplete model run
+// by model digest-or-name and run digest-or-stamp-or-name.
+func (mc *ModelCatalog) RunResumeState(dn, rdsn string) (*db.RunRow, []db.RunProgress, map[string]string, bool) {
+
+	// if model digest-or-name or run digest-or-name is empty then return empty results
+	if dn == "" {
+		omppLog.Log("Warning: invalid (empty) model digest and name")
+		return nil, []db.RunProgress{}, map[string]string{}, false
+	}
+	if rdsn == "" {
+		omppLog.Log("Warning: invalid (empty) run digest or stamp or name")
+		return nil, []db.RunProgress{}, map[string]string{}, false
+	}
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		omppLog.Log("Warning: model digest or name not found: ", dn)
+		return nil, []db.RunProgress{}, map[string]string{}, false
+	}
+
+	// get run_lst db row by digest, stamp or run name
+	r, err := db.GetRunByDigestStampName(dbConn, meta.Model.ModelId, rdsn)
+	if err != nil {
+		omppLog.Log("Error at get run status: ", dn, ": ", rdsn, ": ", err.Error())
+		return nil, []db.RunProgress{}, map[string]string{}, false // return empty result: run select error
+	}
+	if r == nil {
+		omppLog.Log("Warning: run not found: ", dn, ": ", rdsn)
+		return nil, []db.RunProgress{}, map[string]string{}, false // return empty result: run_lst row not found
+	}
+
+	// get run sub-values progress and run options for that run id
+	rpRs, err := db.GetRunProgress(dbConn, r.RunId)
+	if err != nil {
+		omppLog.Log("Error at get run progress: ", dn, ": ", rdsn, ": ", err.Error())
+		return nil, []db.RunProgress{}, map[string]string{}, false // return empty result: run progress select error
+	}
+	opts, err := db.GetRunOptions(dbConn, r.RunId)
+	if err != nil {
+		omppLog.Log("Error at get run options: ", dn, ": ", rdsn, ": ", err.Error())
+		return nil, []db.RunProgress{}, map[string]string{}, false // return empty result: run options select error
+	}
+
+	return r, rpRs, opts, true
+}
+
 // RunRowList return list of run_lst db rows by model digest-or-name and run digest-stamp-or-name sorted by run_id.
 // If there are multiple rows with same run stamp or run digest then multiple rows returned.
 func (mc *ModelCatalog) RunRowList(dn string, rdsn string) ([]db.RunRow, bool) {
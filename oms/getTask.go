@@ -269,6 +269,70 @@ func (mc *ModelCatalog) TaskRunStatus(dn, tn, trsn string) (*db.TaskRunRow, bool
 	return rst, true
 }
 
+// TaskSensitivity return sensitivity analysis of modeling task run results by model digest-or-name, task name and task run stamp or name.
+// If task run stamp or name is empty then last completed task run is used.
+func (mc *ModelCatalog) TaskSensitivity(dn, tn, trsn string) (*db.TaskSensitivityPub, bool) {
+
+	// if model digest-or-name or task name is empty then return empty results
+	if dn == "" {
+		omppLog.Log("Warning: invalid (empty) model digest and name")
+		return &db.TaskSensitivityPub{}, false
+	}
+	if tn == "" {
+		omppLog.Log("Warning: invalid (empty) task name")
+		return &db.TaskSensitivityPub{}, false
+	}
+
+	// get model metadata and database connection
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		omppLog.Log("Warning: model digest or name not found: ", dn)
+		return &db.TaskSensitivityPub{}, false
+	}
+
+	sp, err := db.GetTaskSensitivity(dbConn, meta, tn, trsn, nil)
+	if err != nil {
+		omppLog.Log("Error at modeling task sensitivity analysis: ", dn, ": ", tn, ": ", trsn, ": ", err.Error())
+		return &db.TaskSensitivityPub{}, false
+	}
+	return sp, true
+}
+
+// TaskCompare return scenario matrix of modeling task run results by model digest-or-name, task name, task run stamp or name and output table name.
+// If task run stamp or name is empty then last completed task run is used.
+// If calculation is empty then first output table expression is used.
+// Baseline run digest, stamp or name is optional and used for [base] and [variant] comparison.
+func (mc *ModelCatalog) TaskCompare(dn, tn, trsn, tableName, calc, baseRdsn string) (*db.TaskComparePub, bool) {
+
+	// if model digest-or-name or task name or table name is empty then return empty results
+	if dn == "" {
+		omppLog.Log("Warning: invalid (empty) model digest and name")
+		return &db.TaskComparePub{}, false
+	}
+	if tn == "" {
+		omppLog.Log("Warning: invalid (empty) task name")
+		return &db.TaskComparePub{}, false
+	}
+	if tableName == "" {
+		omppLog.Log("Warning: invalid (empty) output table name")
+		return &db.TaskComparePub{}, false
+	}
+
+	// get model metadata and database connection
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		omppLog.Log("Warning: model digest or name not found: ", dn)
+		return &db.TaskComparePub{}, false
+	}
+
+	tc, err := db.GetTaskCompare(dbConn, meta, tn, trsn, tableName, calc, baseRdsn)
+	if err != nil {
+		omppLog.Log("Error at modeling task comparison: ", dn, ": ", tn, ": ", trsn, ": ", tableName, ": ", err.Error())
+		return &db.TaskComparePub{}, false
+	}
+	return tc, true
+}
+
 // TaskRunStatusList return list of task_run_lst db rows by model digest-or-name, task name and task run stamp or run name.
 func (mc *ModelCatalog) TaskRunStatusList(dn, tn, trsn string) ([]db.TaskRunRow, bool) {
 
@@ -4,6 +4,12 @@
 package main
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+
 	"github.com/openmpp/go/ompp/db"
 	"github.com/openmpp/go/ompp/omppLog"
 	"golang.org/x/text/language"
@@ -275,3 +281,359 @@ func (mc *ModelCatalog) WorksetTextFull(dn, wsn string, isAllLang bool, preferre
 
 	return wp, true, nil
 }
+
+// WorksetVersionList return list of workset versions by model digest-or-name and workset name.
+func (mc *ModelCatalog) WorksetVersionList(dn, wsn string) ([]db.WorksetVersionPub, bool) {
+
+	ws, ok := mc.WorksetByName(dn, wsn)
+	if !ok {
+		return []db.WorksetVersionPub{}, false // workset not found or error
+	}
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return []db.WorksetVersionPub{}, false // model not found
+	}
+
+	vLst, err := db.GetWorksetVersionList(dbConn, meta, ws)
+	if err != nil {
+		omppLog.Log("Error at get workset versions: ", dn, ": ", wsn, ": ", err.Error())
+		return []db.WorksetVersionPub{}, false
+	}
+	return vLst, true
+}
+
+// WorksetVersionDiff return differences of parameter values between two versions of workset.
+// If "to" version id is zero then compare with current parameter values.
+// If parameter name is not empty then only that parameter compared.
+func (mc *ModelCatalog) WorksetVersionDiff(dn, wsn string, fromVerId, toVerId int, name string) ([]db.WorksetVersionDiff, bool, error) {
+
+	ws, ok := mc.WorksetByName(dn, wsn)
+	if !ok {
+		return []db.WorksetVersionDiff{}, false, nil // workset not found or error
+	}
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return []db.WorksetVersionDiff{}, false, nil // model not found
+	}
+
+	dLst, err := db.GetWorksetVersionDiff(dbConn, meta, ws, fromVerId, toVerId, name)
+	if err != nil {
+		omppLog.Log("Error at compare workset versions: ", dn, ": ", wsn, ": ", err.Error())
+		return []db.WorksetVersionDiff{}, false, err
+	}
+	return dLst, true, nil
+}
+
+// ParamRules return parameter validation rules of the model.
+// Rules are from json file next to the model: models/bin/dir/modelName.validation.json
+// and from profile options of the model profile, profile name is a model name.
+// If profile name is not empty and not the same as model name then rules from that profile also included.
+func (mc *ModelCatalog) ParamRules(dn, profile string) ([]db.ParamRule, error) {
+
+	mb, ok := mc.modelBasicByDigestOrName(dn)
+	if !ok {
+		return nil, errors.New("Error: model digest or name not found: " + dn)
+	}
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return nil, errors.New("Error: model digest or name not found: " + dn)
+	}
+	return db.ModelParamRules(dbConn, meta, mb.binDir, profile)
+}
+
+// ValidateWorkset check workset parameters values using validation rules of the model.
+// Profile name is optional, if not empty then validation rules from that profile also used.
+// Return list of rule violations, it is empty if all values are valid, and false if workset not found.
+func (mc *ModelCatalog) ValidateWorkset(dn, wsn, profile string) ([]db.ParamRuleViolation, bool, error) {
+
+	ws, ok := mc.WorksetByName(dn, wsn)
+	if !ok {
+		return []db.ParamRuleViolation{}, false, nil // workset not found or error
+	}
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return []db.ParamRuleViolation{}, false, nil // model not found
+	}
+
+	rules, err := mc.ParamRules(dn, profile)
+	if err != nil {
+		omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+		return []db.ParamRuleViolation{}, false, err
+	}
+	if len(rules) <= 0 {
+		return []db.ParamRuleViolation{}, true, nil // no validation rules
+	}
+
+	vLst, err := db.ValidateWorksetParameters(dbConn, meta, ws.SetId, rules)
+	if err != nil {
+		omppLog.Log("Error at workset validation: ", dn, ": ", wsn, ": ", err.Error())
+		return []db.ParamRuleViolation{}, false, err
+	}
+	return vLst, true, nil
+}
+
+// ValidateWorksetCreate check parameters values of new workset using validation rules of the model, before workset is created.
+// Parameter values are enum code cells from request or copied from model run or from other workset.
+// Return list of rule violations, it is empty if all values are valid.
+func (mc *ModelCatalog) ValidateWorksetCreate(dn string, pLst []db.ParamValuePub) ([]db.ParamRuleViolation, error) {
+
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return []db.ParamRuleViolation{}, errors.New("Model digest or name not found: " + dn)
+	}
+
+	rules, err := mc.ParamRules(dn, "")
+	if err != nil {
+		omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+		return []db.ParamRuleViolation{}, err
+	}
+	vLst := []db.ParamRuleViolation{}
+	if len(rules) <= 0 {
+		return vLst, nil // no validation rules
+	}
+
+	for k := range pLst {
+
+		var pv []db.ParamRuleViolation
+		var e error
+
+		switch pLst[k].Kind {
+		case "run":
+			r, ok := mc.CompletedRunByDigestOrStampOrName(dn, pLst[k].From)
+			if !ok || r == nil {
+				return []db.ParamRuleViolation{}, errors.New("Model not found or not completed: " + dn + ": " + pLst[k].From)
+			}
+			pv, e = db.ValidateParameterFrom(dbConn, meta, pLst[k].Name, r.RunId, false, rules)
+
+		case "set":
+			ws, ok := mc.WorksetByName(dn, pLst[k].From)
+			if !ok || ws == nil {
+				return []db.ParamRuleViolation{}, errors.New("Workset not found or error at get workset status: " + dn + ": " + pLst[k].From)
+			}
+			pv, e = db.ValidateParameterFrom(dbConn, meta, pLst[k].Name, ws.SetId, true, rules)
+
+		default:
+			// convert cells from enum codes to enum id's
+			csvCvt := db.CellParamConverter{
+				ModelDef:  meta,
+				Name:      pLst[k].Name,
+				DoubleFmt: theCfg.doubleFmt,
+			}
+			cvt, err := csvCvt.CodeToIdCell(meta, pLst[k].Name)
+			if err != nil {
+				return []db.ParamRuleViolation{}, errors.New("Failed to create parameter cell value converter: " + pLst[k].Name + " : " + err.Error())
+			}
+			cLst := make([]db.CellParam, 0, len(pLst[k].Value))
+
+			for j := range pLst[k].Value {
+				c, err := cvt(pLst[k].Value[j])
+				if err != nil {
+					return []db.ParamRuleViolation{}, errors.New("Failed to convert value of parameter: " + pLst[k].Name + " : " + err.Error())
+				}
+				if cp, ok := c.(db.CellParam); ok {
+					cLst = append(cLst, cp)
+				}
+			}
+			pv, e = db.ValidateParameterCells(meta, pLst[k].Name, rules, cLst)
+		}
+		if e != nil {
+			omppLog.Log("Error at parameter validation: ", dn, ": ", pLst[k].Name, ": ", e.Error())
+			return []db.ParamRuleViolation{}, e
+		}
+		vLst = append(vLst, pv...)
+	}
+	return vLst, nil
+}
+
+// ValidateWorksetUpdate check parameters values of workset using validation rules of the model, before workset is updated.
+// Parameter values from csv files are checked, each csv must start from header line.
+// Existing workset parameters which are not in csv map are checked if they remain in workset after update:
+// on merge all existing parameters remain, on replace only parameters from keepLst remain in workset.
+// Return list of rule violations, it is empty if all values are valid.
+func (mc *ModelCatalog) ValidateWorksetUpdate(dn, wsn string, isReplace bool, keepLst []string, csvMap map[string][]byte) ([]db.ParamRuleViolation, error) {
+
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return []db.ParamRuleViolation{}, errors.New("Model digest or name not found: " + dn)
+	}
+
+	rules, err := mc.ParamRules(dn, "")
+	if err != nil {
+		omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+		return []db.ParamRuleViolation{}, err
+	}
+	vLst := []db.ParamRuleViolation{}
+	if len(rules) <= 0 {
+		return vLst, nil // no validation rules
+	}
+
+	// check parameters values from csv files
+	for name, b := range csvMap {
+
+		csvRd := csv.NewReader(bytes.NewReader(b))
+		csvRd.TrimLeadingSpace = true
+		csvRd.ReuseRecord = true
+
+		from, err := paramCsvCellReader(meta, name, csvRd)
+		if err != nil {
+			return []db.ParamRuleViolation{}, err
+		}
+		cLst := []db.CellParam{}
+		for {
+			c, err := from()
+			if err != nil {
+				return []db.ParamRuleViolation{}, err
+			}
+			if c == nil {
+				break // end of csv file
+			}
+			if cp, ok := c.(db.CellParam); ok {
+				cLst = append(cLst, cp)
+			}
+		}
+
+		pv, err := db.ValidateParameterCells(meta, name, rules, cLst)
+		if err != nil {
+			omppLog.Log("Error at parameter validation: ", dn, ": ", name, ": ", err.Error())
+			return []db.ParamRuleViolation{}, err
+		}
+		vLst = append(vLst, pv...)
+	}
+
+	// check existing workset parameters which are kept by update
+	ws, ok := mc.WorksetByName(dn, wsn)
+	if !ok || ws == nil {
+		return vLst, nil // new workset: there are no existing parameters
+	}
+	wsRules := []db.ParamRule{}
+	for k := range rules {
+		if _, ok := csvMap[rules[k].Name]; ok {
+			continue // parameter values replaced by csv file
+		}
+		isKeep := !isReplace
+		for j := 0; !isKeep && j < len(keepLst); j++ {
+			isKeep = keepLst[j] == rules[k].Name
+		}
+		if isKeep {
+			wsRules = append(wsRules, rules[k])
+		}
+	}
+	if len(wsRules) <= 0 {
+		return vLst, nil
+	}
+
+	pv, err := db.ValidateWorksetParameters(dbConn, meta, ws.SetId, wsRules)
+	if err != nil {
+		omppLog.Log("Error at workset validation: ", dn, ": ", wsn, ": ", err.Error())
+		return []db.ParamRuleViolation{}, err
+	}
+	return append(vLst, pv...), nil
+}
+
+// RunWorksetNames return names of worksets which are used by model run with run options.
+// Worksets are from OpenM.SetName, OpenM.SetId and worksets of modeling task from OpenM.TaskName or OpenM.TaskId run options.
+// If none of those options specified then model run is using default workset of the model.
+func (mc *ModelCatalog) RunWorksetNames(dn string, opts map[string]string) ([]string, error) {
+
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return []string{}, errors.New("Model digest or name not found: " + dn)
+	}
+
+	// find run option value by key, key can be prefixed by dash: -OpenM.SetName
+	optVal := func(key string) string {
+		for k, v := range opts {
+			if strings.EqualFold(strings.TrimPrefix(k, "-"), key) {
+				return v
+			}
+		}
+		return ""
+	}
+	wLst := []string{}
+	isAny := false
+
+	if wsn := optVal("OpenM.SetName"); wsn != "" {
+		isAny = true
+		wLst = append(wLst, wsn)
+	}
+
+	if sId := optVal("OpenM.SetId"); sId != "" {
+		isAny = true
+		id, err := strconv.Atoi(sId)
+		if err != nil || id <= 0 {
+			return []string{}, errors.New("Invalid workset id: " + sId)
+		}
+		ws, err := db.GetWorkset(dbConn, id)
+		if err != nil {
+			return []string{}, errors.New("Error at get workset: " + sId + ": " + err.Error())
+		}
+		if ws == nil || ws.ModelId != meta.Model.ModelId {
+			return []string{}, errors.New("Model workset not found: " + dn + ": " + sId)
+		}
+		wLst = append(wLst, ws.Name)
+	}
+
+	// modeling task: all worksets of the task are used
+	var task *db.TaskRow
+	var err error
+
+	if tn := optVal("OpenM.TaskName"); tn != "" {
+		if task, err = db.GetTaskByName(dbConn, meta.Model.ModelId, tn); err != nil {
+			return []string{}, errors.New("Error at get modeling task: " + tn + ": " + err.Error())
+		}
+		if task == nil {
+			return []string{}, errors.New("Modeling task not found: " + dn + ": " + tn)
+		}
+	}
+	if sId := optVal("OpenM.TaskId"); task == nil && sId != "" {
+		id, err := strconv.Atoi(sId)
+		if err != nil || id <= 0 {
+			return []string{}, errors.New("Invalid modeling task id: " + sId)
+		}
+		if task, err = db.GetTask(dbConn, id); err != nil {
+			return []string{}, errors.New("Error at get modeling task: " + sId + ": " + err.Error())
+		}
+		if task == nil || task.ModelId != meta.Model.ModelId {
+			return []string{}, errors.New("Modeling task not found: " + dn + ": " + sId)
+		}
+	}
+	if task != nil {
+		isAny = true
+		idLst, err := db.GetTaskSetIds(dbConn, task.TaskId)
+		if err != nil {
+			return []string{}, errors.New("Error at get modeling task worksets: " + task.Name + ": " + err.Error())
+		}
+		for _, id := range idLst {
+			ws, err := db.GetWorkset(dbConn, id)
+			if err != nil {
+				return []string{}, errors.New("Error at get workset: " + strconv.Itoa(id) + ": " + err.Error())
+			}
+			if ws != nil {
+				wLst = append(wLst, ws.Name)
+			}
+		}
+	}
+
+	// model run without workset or task is using default workset
+	if !isAny {
+		ws, err := db.GetDefaultWorkset(dbConn, meta.Model.ModelId)
+		if err != nil {
+			return []string{}, errors.New("Error at get default workset: " + dn + ": " + err.Error())
+		}
+		if ws != nil {
+			wLst = append(wLst, ws.Name)
+		}
+	}
+	return wLst, nil
+}
+
+// return error with list of parameter rule violations, error message starts with msg.
+func ruleViolationError(msg, dn, wsn string, vLst []db.ParamRuleViolation) error {
+
+	var sb strings.Builder
+	sb.WriteString(msg + ", parameter validation failed: " + dn + " : " + wsn)
+	for _, v := range vLst {
+		sb.WriteString("\n" + v.String())
+	}
+	return errors.New(sb.String())
+}
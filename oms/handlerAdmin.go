@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/openmpp/go/ompp/helper"
 	"github.com/openmpp/go/ompp/omppLog"
 )
 
@@ -154,6 +155,14 @@ func jobsAllPauseHandler(w http.ResponseWriter, r *http.Request) {
 	doJobsPause(jobAllQueuePausedPath(), "/api/admin-all/jobs-pause/", w, r)
 }
 
+// start or stop draining of jobs queue by this oms instance.
+// Draining oms instance does not accept new jobs and queue processing is paused, active jobs are running until completed.
+//
+//	POST /api/admin/jobs-drain/:pause
+func jobsDrainHandler(w http.ResponseWriter, r *http.Request) {
+	doJobsPause(jobQueueDrainPath(theCfg.omsName), "/api/admin/jobs-drain/", w, r)
+}
+
 // Pause or resume jobs queue processing by this oms instance all by all oms instances
 //
 //	POST /api/admin/jobs-pause/:pause
@@ -184,6 +193,133 @@ func doJobsPause(filePath, msgPath string, w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "text/plain")
 }
 
+// move this oms instance queue jobs to other oms instance.
+//
+//	POST /api/admin/jobs-queue/move-to/:oms
+//
+// Jobs queue of this instance must be paused or draining and destination oms instance must be alive.
+// Return list of submission stamps of moved jobs.
+func jobsQueueMoveHandler(w http.ResponseWriter, r *http.Request) {
+
+	// url or query parameters: destination oms instance name
+	dstOms := getRequestParam(r, "oms")
+	if dstOms == "" || dstOms == theCfg.omsName || dstOms != helper.CleanFileName(dstOms) {
+		http.Error(w, "Invalid destination oms instance name: "+dstOms, http.StatusBadRequest)
+		return
+	}
+	if !theCfg.isJobControl {
+		http.Error(w, "Job control disabled", http.StatusBadRequest)
+		return
+	}
+	if !isPausedJobQueue() {
+		http.Error(w, "Jobs queue must be paused or draining: "+theCfg.omsName, http.StatusBadRequest)
+		return
+	}
+	if !isOmsAlive(dstOms) {
+		http.Error(w, "Destination oms instance is not active: "+dstOms, http.StatusBadRequest)
+		return
+	}
+
+	// rename queue job files: replace oms instance name part of the file name
+	stamps := []string{}
+
+	for _, f := range ownQueueJobFiles() {
+
+		stamp, _, _, _, _, _, _, _, _, _ := parseQueuePath(f)
+		fn := strings.Replace(filepath.Base(f), "-#-"+theCfg.omsName+"-#-", "-#-"+dstOms+"-#-", 1)
+
+		if fileMoveAndLog(true, f, filepath.Join(filepath.Dir(f), fn)) {
+			stamps = append(stamps, stamp)
+		}
+	}
+
+	w.Header().Set("Content-Location", "/api/admin/jobs-queue/move-to/"+dstOms)
+	jsonResponse(w, r, stamps)
+}
+
+// export this oms instance jobs queue as json.
+//
+//	GET /api/admin/jobs-queue/export
+//
+// Queue jobs are in the order of queue position.
+func jobsQueueExportHandler(w http.ResponseWriter, r *http.Request) {
+
+	qe := jobQueueExport{
+		OmsName:        theCfg.omsName,
+		ExportDateTime: helper.MakeDateTime(time.Now()),
+		Queue:          []RunJob{},
+	}
+
+	if theCfg.isJobControl {
+		for _, f := range ownQueueJobFiles() {
+
+			var job RunJob
+
+			isOk, err := helper.FromJsonFile(f, &job)
+			if err != nil {
+				omppLog.Log(err)
+			}
+			if isOk && err == nil {
+				qe.Queue = append(qe.Queue, job)
+			}
+		}
+	}
+
+	jsonResponse(w, r, qe)
+}
+
+// import jobs into this oms instance jobs queue from json.
+//
+//	POST /api/admin/jobs-queue/import
+//
+// Json body expected to be the same as jobs queue export results.
+// Job is skipped if it has empty submission stamp or if it is already in the queue, active or in the history.
+// Return list of submission stamps of imported jobs.
+func jobsQueueImportHandler(w http.ResponseWriter, r *http.Request) {
+
+	if !theCfg.isJobControl {
+		http.Error(w, "Job control disabled", http.StatusBadRequest)
+		return
+	}
+	if isDrainJobQueue() {
+		http.Error(w, "Jobs import rejected: oms instance is draining jobs queue", http.StatusServiceUnavailable)
+		return
+	}
+
+	var qe jobQueueExport
+	if !jsonRequestDecode(w, r, true, &qe) {
+		return // error at json decode, response done with http error
+	}
+
+	stamps := []string{}
+
+	for k := range qe.Queue {
+
+		stamp := qe.Queue[k].SubmitStamp
+		if stamp == "" || stamp != helper.CleanFileName(stamp) || qe.Queue[k].ModelName == "" || qe.Queue[k].ModelDigest == "" {
+			omppLog.Log("Warning: skip invalid job: ", stamp, " ", qe.Queue[k].ModelName, " ", qe.Queue[k].ModelDigest)
+			continue
+		}
+		if len(filesByPattern(filepath.Join(theCfg.jobDir, "*", stamp+"-#-*.json"), "Error at job files search")) > 0 {
+			omppLog.Log("Warning: skip existing job: ", stamp, " ", qe.Queue[k].ModelName)
+			continue
+		}
+
+		qe.Queue[k].Pid = 0
+		qe.Queue[k].CmdPath = ""
+		qe.Queue[k].QueuePos = 0
+
+		if _, err := theRunCatalog.addJobToQueue(&qe.Queue[k]); err != nil {
+			http.Error(w, "Jobs import failed: "+stamp+" "+qe.Queue[k].ModelName, http.StatusBadRequest)
+			return
+		}
+		stamps = append(stamps, stamp)
+	}
+
+	w.Header().Set("Content-Location", "/api/admin/jobs-queue/import")
+	jsonResponse(w, r, stamps)
+}
+
 // async start of model database cleanup and retrun LogFileName on success
 //
 //	POST /api/admin/db-cleanup/:path
@@ -264,86 +400,211 @@ func modelDbCleanupHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		cmd := exec.Command(cmdPath, cArgs...)
 
-		// connect console output to output log file
-		outPipe, err := cmd.StdoutPipe()
-		if err != nil {
-			omppLog.Log("Error at join to stdout log", ": ", logPath, ": ", err)
-			return
-		}
-		errPipe, err := cmd.StderrPipe()
-		if err != nil {
-			omppLog.Log("Error at join to stderr log", ": ", logPath, ": ", err)
-			return
-		}
-		outDoneC := make(chan bool, 1)
-		errDoneC := make(chan bool, 1)
-		logTck := time.NewTicker(logTickTimeout * time.Millisecond)
-
-		// start console output listners
-		isLogOk := fileCreateEmpty(false, logPath)
-		if !isLogOk {
-			omppLog.Log("Error at creating log file", ": ", logPath)
+		if runDbCleanupCmd(cmd, logPath) {
+			refreshDiskScanC <- true // refresh disk usage
 		}
+	}(diskUse.dbCleanupCmd, srcPath, name, digest, lp)
+
+	// db cleanup is starting now: return path to log file
+	jsonResponse(w, r, struct{ LogFileName string }{LogFileName: ln})
+}
 
-		doLog := func(path string, r io.Reader, done chan<- bool) {
-			sc := bufio.NewScanner(r)
-			for sc.Scan() {
-				if isLogOk {
-					isLogOk = writeToCmdLog(path, false, sc.Text())
-				}
+// runDbCleanupCmd start db cleanup or db purge command, wait until it is completed and write console output into log file.
+// Return true if command completed successfully.
+func runDbCleanupCmd(cmd *exec.Cmd, logPath string) bool {
+
+	// connect console output to output log file
+	outPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		omppLog.Log("Error at join to stdout log", ": ", logPath, ": ", err)
+		return false
+	}
+	errPipe, err := cmd.StderrPipe()
+	if err != nil {
+		omppLog.Log("Error at join to stderr log", ": ", logPath, ": ", err)
+		return false
+	}
+	outDoneC := make(chan bool, 1)
+	errDoneC := make(chan bool, 1)
+	logTck := time.NewTicker(logTickTimeout * time.Millisecond)
+
+	// start console output listners
+	isLogOk := fileCreateEmpty(false, logPath)
+	if !isLogOk {
+		omppLog.Log("Error at creating log file", ": ", logPath)
+	}
+
+	doLog := func(path string, r io.Reader, done chan<- bool) {
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			if isLogOk {
+				isLogOk = writeToCmdLog(path, false, sc.Text())
 			}
-			done <- true
-			close(done)
 		}
-		go doLog(logPath, outPipe, outDoneC)
-		go doLog(logPath, errPipe, errDoneC)
+		done <- true
+		close(done)
+	}
+	go doLog(logPath, outPipe, outDoneC)
+	go doLog(logPath, errPipe, errDoneC)
 
-		// start db cleanup
-		omppLog.Log(strings.Join(cmd.Args, " "))
-		isLogOk = writeToCmdLog(logPath, true, strings.Join(cmd.Args, " "))
+	// start db cleanup
+	omppLog.Log(strings.Join(cmd.Args, " "))
+	isLogOk = writeToCmdLog(logPath, true, strings.Join(cmd.Args, " "))
 
-		err = cmd.Start()
-		if err != nil {
-			omppLog.Log("Error at", ": ", logPath, ": ", err)
-			writeToCmdLog(logPath, true, err.Error())
-			return
-		}
-		// else db cleanup started: wait until completed
-
-		// wait until stdout and stderr closed
-		for outDoneC != nil || errDoneC != nil {
-			select {
-			case _, ok := <-outDoneC:
-				if !ok {
-					outDoneC = nil
-				}
-			case _, ok := <-errDoneC:
-				if !ok {
-					errDoneC = nil
-				}
-			case <-logTck.C:
+	err = cmd.Start()
+	if err != nil {
+		omppLog.Log("Error at", ": ", logPath, ": ", err)
+		writeToCmdLog(logPath, true, err.Error())
+		return false
+	}
+	// else db cleanup started: wait until completed
+
+	// wait until stdout and stderr closed
+	for outDoneC != nil || errDoneC != nil {
+		select {
+		case _, ok := <-outDoneC:
+			if !ok {
+				outDoneC = nil
+			}
+		case _, ok := <-errDoneC:
+			if !ok {
+				errDoneC = nil
 			}
+		case <-logTck.C:
 		}
+	}
 
-		// wait for db cleanup to be completed
-		e := cmd.Wait()
-		if e != nil {
-			omppLog.Log("Error at: ", cmd.Args)
-			writeToCmdLog(logPath, true, e.Error())
-			return
-		}
-		// else: completed OK
-		if isLogOk {
-			writeToCmdLog(logPath, true, "Done.")
-		} else {
-			omppLog.Log("Warning: db cleanup log output may be incomplete")
-		}
-		// refresh disk usage
-		refreshDiskScanC <- true
+	// wait for db cleanup to be completed
+	e := cmd.Wait()
+	if e != nil {
+		omppLog.Log("Error at: ", cmd.Args)
+		writeToCmdLog(logPath, true, e.Error())
+		return false
+	}
+	// else: completed OK
+	if isLogOk {
+		writeToCmdLog(logPath, true, "Done.")
+	} else {
+		omppLog.Log("Warning: db cleanup log output may be incomplete")
+	}
+	return true
+}
 
-	}(diskUse.dbCleanupCmd, srcPath, name, digest, lp)
+// async start of model runs purge by retention policy and retrun LogFileName on success
+//
+//	POST /api/admin/db-purge/:path/keep-last/:count
+//	POST /api/admin/db-purge/:path/keep-last/:count/name/:name
+//	POST /api/admin/db-purge/:path/keep-last/:count/name/:name/digest/:digest
+//
+// Relative path to model database file is required, slash / in the path must be replaced with * star.
+// Number of last model runs to keep for each run name is required.
+// Model name and digest are optional parameters, by default model name is database file name.
+// Optional ?dryRun=true parameter can be used to write into log file list of model runs to delete without actual delete.
+// Purge is done on separate thread by dbcopy -dbcopy.Purge:
+// model runs to delete are archived into modelName.purge.timestamp.zip in download directory (or model database directory),
+// deleted from database and SQLite database file is compacted by VACUUM.
+// Model runs referenced by worksets or modeling tasks and model runs which are not completed are always kept.
+// Model database must be closed, for example by: POST /api/admin/model/:model/close.
+func modelDbPurgeHandler(w http.ResponseWriter, r *http.Request) {
 
-	// db cleanup is starting now: return path to log file
+	// validate parameters: path to database file and number of runs to keep are required
+	dbPath := getRequestParam(r, "path")
+	name := getRequestParam(r, "name")
+	digest := getRequestParam(r, "digest")
+
+	if dbPath == "" {
+		omppLog.Log("Error: invalid (empty) path to model database file")
+		http.Error(w, "Invalid (empty) path to model database file", http.StatusBadRequest)
+		return
+	}
+	dbPath = strings.ReplaceAll(dbPath, "*", "/") // restore slashed / path
+
+	keepLast, ok := getIntRequestParam(r, "count", -1)
+	if !ok || keepLast < 0 {
+		http.Error(w, "Invalid number of model runs to keep: "+getRequestParam(r, "count"), http.StatusBadRequest)
+		return
+	}
+	isDryRun, ok := getBoolRequestParam(r, "dryRun")
+	if !ok {
+		http.Error(w, "Invalid dry run parameter: "+getRequestParam(r, "dryRun"), http.StatusBadRequest)
+		return
+	}
+
+	if theCfg.dbcopyPath == "" {
+		omppLog.Log("Error: dbcopy not found, model runs purge disabled")
+		http.Error(w, "Error: dbcopy not found, model runs purge disabled", http.StatusInternalServerError)
+		return
+	}
+
+	// check if database file is exists and belong to current oms instance: it must be in the list of instance database files
+	_, dbUse := theRunCatalog.getDiskUse()
+
+	if i := slices.IndexFunc(
+		dbUse, func(du dbDiskUse) bool { return du.DbPath == dbPath }); i < 0 || i >= len(dbUse) {
+		http.Error(w, "Error: model database not found"+" "+name+" "+digest, http.StatusBadRequest)
+		return
+	}
+
+	// check if model database is closed: it should not be in the list of model db files
+	mbs := theCatalog.allModels()
+
+	if i := slices.IndexFunc(mbs, func(mb modelBasic) bool { return mb.relPath == dbPath }); i >= 0 && i < len(mbs) {
+		http.Error(w, "Error: model database must be closed"+" "+name+" "+digest, http.StatusBadRequest)
+		return
+	}
+
+	// join db path with models/bin root
+	srcPath := dbPath
+	if mr, isOk := theCatalog.getModelDir(); isOk {
+		srcPath = filepath.Join(mr, dbPath)
+	}
+	srcPath = filepath.Clean(srcPath)
+
+	// by default model name is database file name
+	ln := filepath.Base(dbPath)
+	if ln == "." || ln == "/" || ln == "\\" {
+		ln = "no-name"
+	}
+	if name == "" {
+		name = strings.TrimSuffix(ln, filepath.Ext(ln))
+	}
+
+	// archive into download directory or into model database directory
+	arcDir := theCfg.downloadDir
+	if arcDir == "" {
+		arcDir = filepath.Dir(srcPath)
+	}
+
+	// make log file name and path
+	ld, _ := theCatalog.getModelLogDir()
+
+	ln, lp := dbCleanupLogNamePath("purge."+ln, ld)
+
+	// make dbcopy purge command
+	cArgs := []string{
+		"-m", name,
+		"-dbcopy.Purge",
+		"-dbcopy.KeepLast", strconv.Itoa(keepLast),
+		"-dbcopy.FromSqlite", srcPath,
+		"-dbcopy.OutputDir", arcDir,
+	}
+	if digest != "" {
+		cArgs = append(cArgs, "-dbcopy.ModelDigest", digest)
+	}
+	if isDryRun {
+		cArgs = append(cArgs, "-dbcopy.DryRun")
+	}
+	cmd := exec.Command(theCfg.dbcopyPath, cArgs...)
+
+	// start model runs purge
+	go func(c *exec.Cmd, logPath string) {
+
+		if runDbCleanupCmd(c, logPath) {
+			refreshDiskScanC <- true // refresh disk usage
+		}
+	}(cmd, lp)
+
+	// purge is starting now: return path to log file
 	jsonResponse(w, r, struct{ LogFileName string }{LogFileName: ln})
 }
 
@@ -0,0 +1,84 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// calibrationStartHandler start model calibration:
+// POST /api/calibration
+// Json is posted to specify calibration name, free parameters, targets, optimization method and model run options.
+// Calibration runs in background: it creates workset and submits model run at each iteration.
+func calibrationStartHandler(w http.ResponseWriter, r *http.Request) {
+
+	// decode json calibration request
+	var req CalibrationRequest
+	if !jsonRequestDecode(w, r, true, &req) {
+		return // error at json decode, response done with http error
+	}
+
+	// if calibration name is empty then automatically generate name
+	if req.Name == "" {
+		ts, _ := theCatalog.getNewTimeStamp()
+		req.Name = "calibration_" + ts
+	}
+
+	// reject calibration if this oms instance is draining jobs queue
+	if theCfg.isJobControl && isDrainJobQueue() {
+		http.Error(w, "Calibration rejected: oms instance is draining jobs queue", http.StatusServiceUnavailable)
+		return
+	}
+
+	cs, ok, err := theCalibration.StartCalibration(req)
+	if err != nil {
+		omppLog.Log(err.Error())
+		http.Error(w, "Calibration failed "+req.ModelName+" "+req.ModelDigest+": "+req.Name+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		http.Error(w, "Calibration failed "+req.ModelName+" "+req.ModelDigest+": "+req.Name, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Location", "/api/calibration/"+cs.Name)
+	jsonResponse(w, r, cs)
+}
+
+// calibrationStateHandler return calibration state and all iterations:
+// GET /api/calibration/:name
+func calibrationStateHandler(w http.ResponseWriter, r *http.Request) {
+
+	name := getRequestParam(r, "name")
+
+	cs, ok := theCalibration.CalibrationState(name)
+	if !ok {
+		http.Error(w, "Calibration not found: "+name, http.StatusBadRequest)
+		return
+	}
+	jsonResponse(w, r, cs)
+}
+
+// calibrationListHandler return list of calibrations: status and best result without iterations:
+// GET /api/calibration-list
+func calibrationListHandler(w http.ResponseWriter, r *http.Request) {
+
+	jsonResponse(w, r, theCalibration.CalibrationList())
+}
+
+// calibrationStopHandler request to stop calibration after current model run completed:
+// PUT /api/calibration/:name/stop
+func calibrationStopHandler(w http.ResponseWriter, r *http.Request) {
+
+	name := getRequestParam(r, "name")
+
+	if !theCalibration.StopCalibration(name) {
+		http.Error(w, "Calibration not found or not running: "+name, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Location", "/api/calibration/"+name)
+	w.Header().Set("Content-Type", "text/plain")
+}
@@ -112,6 +112,9 @@ func modelDownloadPostHandler(w http.ResponseWriter, r *http.Request) {
 // it is only to analyze model output values CSV data using some other tools
 // If NoMicrodata is true then microdata not included in result.
 // If Utf8BomIntoCsv is true then add utf-8 byte order mark into csv files
+// If StreamZip is true then zip archive is sent to the client in response body as it is produced by dbcopy,
+// client does not need to wait until download completed and zip archive is also stored in home/io/download folder,
+// model run download folder is not created in that case, only zip archive.
 func runDownloadPostHandler(w http.ResponseWriter, r *http.Request) {
 
 	// url or query parameters
@@ -123,6 +126,7 @@ func runDownloadPostHandler(w http.ResponseWriter, r *http.Request) {
 		NoAccumulatorsCsv bool
 		NoMicrodata       bool
 		Utf8BomIntoCsv    bool
+		StreamZip         bool
 	}{}
 	if !jsonRequestDecode(w, r, false, &opts) {
 		return // error at json decode, response done with http error
@@ -203,12 +207,37 @@ func runDownloadPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// create model run download files on separate thread
-	cmd, cmdMsg := makeRunDownloadCommand(mb, r0.RunId, logPath, opts.NoAccumulatorsCsv, opts.NoMicrodata, opts.Utf8BomIntoCsv)
+	cmd, cmdMsg := makeRunDownloadCommand(mb, r0.RunId, logPath, opts.NoAccumulatorsCsv, opts.NoMicrodata, opts.Utf8BomIntoCsv, opts.StreamZip)
 
-	go makeDownload(baseName, cmd, cmdMsg, logPath)
+	if !opts.StreamZip {
+
+		go makeDownload(baseName, cmd, cmdMsg, logPath)
+
+		// report to the client results location
+		w.Header().Set("Content-Location", "/api/download/model/"+dn+"/run/"+rdsn+"/"+baseName)
+		return
+	}
+
+	// stream zip archive to the client while it is produced by dbcopy
+	if cmd == nil {
+		http.Error(w, "Model run download failed: "+baseName, http.StatusBadRequest)
+		return // error at dbcopy command line
+	}
+	zipPath := filepath.Join(theCfg.downloadDir, baseName+".zip")
+	if !removeDownloadFile(zipPath, logPath, baseName+".zip") {
+		http.Error(w, "Model run download failed: "+baseName, http.StatusBadRequest)
+		return // error: unable to delete previous zip archive
+	}
+	doneC := make(chan bool)
+
+	go func() {
+		makeDownload(baseName, cmd, cmdMsg, logPath)
+		close(doneC)
+	}()
 
-	// report to the client results location
 	w.Header().Set("Content-Location", "/api/download/model/"+dn+"/run/"+rdsn+"/"+baseName)
+
+	streamDownloadZip(w, zipPath, logPath, doneC)
 }
 
 // worksetDownloadPostHandler initate creation of model workset zip archive in home/io/download folder.
@@ -177,6 +177,29 @@ func modelAllTextHandler(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, r, mf)
 }
 
+// return differences between two versions of model metadata:
+//
+//	GET /api/model/:model/diff/:with
+//
+// Models can be in the same or in different databases, description and notes are compared in all languages.
+// Result contains added, removed and changed types, enum items, parameters, output tables, entities and groups.
+func modelDiffHandler(w http.ResponseWriter, r *http.Request) {
+
+	dn := getRequestParam(r, "model")
+	wdn := getRequestParam(r, "with")
+
+	md, ok, err := theCatalog.ModelDiff(dn, wdn)
+	if err != nil {
+		http.Error(w, "Failed to compare models "+dn+": "+wdn+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		http.Error(w, "Model digest or name not found: "+dn+": "+wdn, http.StatusBadRequest)
+		return
+	}
+	jsonResponse(w, r, md)
+}
+
 // return list of model langauages:
 //
 //	GET /api/model/:model/lang-list
@@ -427,6 +450,7 @@ func worksetListTextHandler(w http.ResponseWriter, r *http.Request) {
 //
 // If multiple models with same name exist only one is returned.
 // If no such workset exist in database then empty result returned.
+// Response ETag header is workset entity tag, it can be used as If-Match header of workset update.
 func worksetStatusHandler(w http.ResponseWriter, r *http.Request) {
 
 	dn := getRequestParam(r, "model")
@@ -435,11 +459,90 @@ func worksetStatusHandler(w http.ResponseWriter, r *http.Request) {
 	ws, ok := theCatalog.WorksetByName(dn, wsn)
 	if !ok {
 		omppLog.Log("Warning workset status not found: ", dn, ": ", wsn)
+	} else {
+		w.Header().Set("ETag", worksetETag(ws))
 	}
 
 	jsonResponse(w, r, ws) // return non-empty workset_lst row if no errors and workset exist
 }
 
+// return list of workset versions by model digest-or-name and workset name:
+//
+//	GET /api/model/:model/workset/:set/version-list
+//
+// Each version contains date-time, author note and list of parameters saved in that version.
+// If no such workset exist in database then empty result returned.
+func worksetVersionListHandler(w http.ResponseWriter, r *http.Request) {
+
+	dn := getRequestParam(r, "model")
+	wsn := getRequestParam(r, "set")
+
+	vLst, _ := theCatalog.WorksetVersionList(dn, wsn)
+	jsonResponse(w, r, vLst)
+}
+
+// check workset parameters values using validation rules of the model:
+//
+//	GET /api/model/:model/workset/:set/validate
+//	GET /api/model/:model/workset/:set/validate/profile/:profile
+//
+// Validation rules are from models/bin/dir/modelName.validation.json file and from model profile options.
+// If optional profile name specified then rules from that profile options also used.
+// Return list of rule violations, it is empty if all parameters values are valid.
+func worksetValidateHandler(w http.ResponseWriter, r *http.Request) {
+
+	dn := getRequestParam(r, "model")
+	wsn := getRequestParam(r, "set")
+	profile := getRequestParam(r, "profile")
+
+	vLst, ok, err := theCatalog.ValidateWorkset(dn, wsn, profile)
+	if err != nil {
+		http.Error(w, "Workset validation failed "+dn+" : "+wsn+" : "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		http.Error(w, "Workset not found "+dn+" : "+wsn, http.StatusBadRequest)
+		return
+	}
+	jsonResponse(w, r, vLst)
+}
+
+// return differences of parameter values between two versions of workset:
+//
+//	GET /api/model/:model/workset/:set/version/:from/diff/:to
+//	GET /api/model/:model/workset/:set/version/:from/diff/:to/parameter/:name
+//
+// If "to" version is zero then "from" version compared with current parameter values.
+// If optional parameter name specified then only that parameter compared.
+func worksetVersionDiffHandler(w http.ResponseWriter, r *http.Request) {
+
+	dn := getRequestParam(r, "model")
+	wsn := getRequestParam(r, "set")
+	name := getRequestParam(r, "name")
+
+	fromId, ok := getIntRequestParam(r, "from", 0)
+	if !ok || fromId <= 0 {
+		http.Error(w, "Invalid workset version: "+getRequestParam(r, "from"), http.StatusBadRequest)
+		return
+	}
+	toId, ok := getIntRequestParam(r, "to", 0)
+	if !ok || toId < 0 {
+		http.Error(w, "Invalid workset version: "+getRequestParam(r, "to"), http.StatusBadRequest)
+		return
+	}
+
+	dLst, ok, err := theCatalog.WorksetVersionDiff(dn, wsn, fromId, toId, name)
+	if err != nil {
+		http.Error(w, "Failed to compare workset versions "+wsn+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		http.Error(w, "Workset not found: "+dn+": "+wsn, http.StatusBadRequest)
+		return
+	}
+	jsonResponse(w, r, dLst)
+}
+
 // return workset_lst db row of default workset by model digest-or-name:
 //
 //	GET /api/model/:model/workset/status/default
@@ -468,6 +571,7 @@ func worksetTextHandler(w http.ResponseWriter, r *http.Request) {
 	wsn := getRequestParam(r, "set")
 	rqLangTags := getRequestLang(r, "lang") // get optional language argument and languages accepted by browser
 
+	setWorksetETag(w, dn, wsn)
 	wp, _, _ := theCatalog.WorksetTextFull(dn, wsn, false, rqLangTags)
 	jsonResponse(w, r, wp)
 }
@@ -484,6 +588,7 @@ func worksetAllTextHandler(w http.ResponseWriter, r *http.Request) {
 	dn := getRequestParam(r, "model")
 	wsn := getRequestParam(r, "set")
 
+	setWorksetETag(w, dn, wsn)
 	wp, _, _ := theCatalog.WorksetTextFull(dn, wsn, true, nil)
 	jsonResponse(w, r, wp)
 }
@@ -626,6 +731,59 @@ func lastCompletedTaskRunStatusHandler(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, r, rst)
 }
 
+// return sensitivity analysis of modeling task run results by model digest-or-name, task name and optional task run stamp or name:
+//
+//	GET /api/model/:model/task/:task/sensitivity
+//	GET /api/model/:model/task/:task/sensitivity/run/:run
+//
+// If task run not specified then last completed task run is used.
+// Result contains correlation and regression coefficients of each output table expression versus input parameters varied in task worksets
+// and tornado chart data for one-at-a-time changes of parameters.
+// If multiple models with same name exist only one is returned.
+func taskSensitivityHandler(w http.ResponseWriter, r *http.Request) {
+
+	dn := getRequestParam(r, "model")
+	tn := getRequestParam(r, "task")
+	trsn := getRequestParam(r, "run")
+
+	sp, ok := theCatalog.TaskSensitivity(dn, tn, trsn)
+	if !ok {
+		http.Error(w, "Task sensitivity analysis failed "+dn+": "+tn+" "+trsn, http.StatusBadRequest)
+		return
+	}
+	jsonResponse(w, r, sp)
+}
+
+// return scenario matrix of modeling task run results by model digest-or-name, task name, output table name and optional task run stamp or name:
+//
+//	GET /api/model/:model/task/:task/compare/table/:table
+//	GET /api/model/:model/task/:task/compare/table/:table/run/:run
+//	GET /api/model/:model/task/:task/compare/table/:table?calc=expr0[variant]-expr0[base]&base=Default
+//
+// If task run not specified then last completed task run is used.
+// Optional calc is output table expression name or calculation, by default first table expression is used.
+// Optional base is baseline run digest, stamp or name for [base] and [variant] comparison,
+// by default it is the base run of task worksets or first scenario run.
+// Result contains list of scenarios with values of parameters which are different across scenarios
+// and rows of output table cells with calculated value for each scenario.
+// If multiple models with same name exist only one is returned.
+func taskCompareHandler(w http.ResponseWriter, r *http.Request) {
+
+	dn := getRequestParam(r, "model")
+	tn := getRequestParam(r, "task")
+	trsn := getRequestParam(r, "run")
+	tableName := getRequestParam(r, "table")
+	calc := getRequestParam(r, "calc")
+	base := getRequestParam(r, "base")
+
+	tc, ok := theCatalog.TaskCompare(dn, tn, trsn, tableName, calc, base)
+	if !ok {
+		http.Error(w, "Task comparison failed "+dn+": "+tn+" "+trsn+": "+tableName, http.StatusBadRequest)
+		return
+	}
+	jsonResponse(w, r, tc)
+}
+
 // return full task metadata, description, notes, run history by model digest-or-name and task name
 // from db-tables: task_lst, task_txt, task_set, task_run_lst, task_run_set and also from workset_txt, run_txt.
 //
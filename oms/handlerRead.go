@@ -72,6 +72,9 @@ func doReadParameterPageHandler(w http.ResponseWriter, r *http.Request, srcArg s
 	}
 
 	// write to response: page layout and page data
+	if isSet {
+		setWorksetETag(w, dn, src) // workset ETag before reading the data
+	}
 	jsonSetHeaders(w, r) // start response with set json headers, i.e. content type
 
 	w.Write([]byte("{\"Page\":[")) // start of data page and start of json output array
@@ -448,6 +451,9 @@ func doParameterGetPageHandler(w http.ResponseWriter, r *http.Request, srcArg st
 	}
 
 	// write to response
+	if isSet {
+		setWorksetETag(w, dn, src) // workset ETag before reading the data
+	}
 	jsonSetHeaders(w, r) // start response with set json headers, i.e. content type
 
 	w.Write([]byte{'['}) // start of json output array
@@ -96,6 +96,9 @@ func doParameterGetCsvHandler(w http.ResponseWriter, r *http.Request, srcArg str
 	}
 
 	// set response headers: Content-Disposition: attachment; filename=name.csv
+	if isSet {
+		setWorksetETag(w, dn, src) // workset ETag before reading the data
+	}
 	csvSetHeaders(w, name)
 
 	// write csv body
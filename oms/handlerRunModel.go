@@ -4,12 +4,15 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/text/language"
 
+	"github.com/openmpp/go/ompp/db"
 	"github.com/openmpp/go/ompp/helper"
 	"github.com/openmpp/go/ompp/omppLog"
 )
@@ -21,6 +24,7 @@ import (
 // Json RunRequest structure is posted to specify model digest-or-name, run stamp and othe run options.
 // If multiple models with same name exist then result is undefined.
 // Model run console output redirected to log file: models/log/modelName.runStamp.console.log
+// If model run workset specified by OpenM.SetName run option then workset parameters are checked by validation rules of the model.
 func runModelHandler(w http.ResponseWriter, r *http.Request) {
 
 	// decode json request body
@@ -38,12 +42,88 @@ func runModelHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// check workset parameters values before model run
+	if !validateRunWorkset(w, req) {
+		return // validation failed, response done with http error
+	}
+
+	runModelRequest(w, r, req, RunResume{})
+}
+
+// check parameters values of model run workset using validation rules of the model.
+// Return false on error or if any rule violation found and write http error response.
+func validateRunWorkset(w http.ResponseWriter, req RunRequest) bool {
+
+	if err := checkRunWorkset(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// check parameters values of model run worksets using validation rules of the model.
+// Worksets are from OpenM.SetName, OpenM.SetId, modeling task OpenM.TaskName or OpenM.TaskId run options,
+// if none of those specified then default workset of the model is checked.
+// Optional profile name is OpenM.Profile run option.
+// Return error if any rule violation found.
+func checkRunWorkset(req RunRequest) error {
+
+	dn := req.ModelDigest
+	if dn == "" {
+		dn = req.ModelName
+	}
+
+	profile := ""
+	for krq, val := range req.Opts {
+		if strings.EqualFold(krq, "-OpenM.Profile") || strings.EqualFold(krq, "OpenM.Profile") {
+			profile = val
+		}
+	}
+
+	wLst, err := theCatalog.RunWorksetNames(dn, req.Opts)
+	if err != nil {
+		return errors.New("Model run rejected: " + dn + " : " + err.Error())
+	}
+	for _, wsn := range wLst {
+		if err = worksetValidationError(dn, wsn, profile, "Model run rejected"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// submit model run request: start the model if job control disabled or else append run request to the queue.
+// If resume run id is not zero then it is a resume of incomplete model run.
+func runModelRequest(w http.ResponseWriter, r *http.Request, req RunRequest, resume RunResume) {
+
+	// reject model run if this oms instance is draining jobs queue
+	if theCfg.isJobControl && isDrainJobQueue() {
+		http.Error(w, "Model run rejected: oms instance is draining jobs queue", http.StatusServiceUnavailable)
+		return
+	}
+
 	// block model run if disk space usage exceed the limits
 	if isOver, _ := theRunCatalog.getDiskUseStatus(); isOver {
 		http.Error(w, "Disk space usage exceeds quota, model run disabled", http.StatusBadRequest)
 		return
 	}
 
+	// start the model or append run request to the queue
+	rs, err := submitRunRequest(req, resume)
+	if err != nil {
+		omppLog.Log(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Location", "/api/model/"+rs.ModelDigest+"/run/"+rs.RunStamp)
+	jsonResponse(w, r, rs)
+}
+
+// submit model run request: start the model if job control disabled or else append run request to the queue.
+// Return model run state with run stamp and submit stamp.
+// If resume run id is not zero then it is a resume of incomplete model run.
+func submitRunRequest(req RunRequest, resume RunResume) (*RunState, error) {
+
 	// find model metadata by digest or name
 	dn := req.ModelDigest
 	if dn == "" {
@@ -51,8 +131,7 @@ func runModelHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	m, ok := theCatalog.ModelDicByDigestOrName(dn)
 	if !ok {
-		http.Error(w, "Model not found: "+dn, http.StatusBadRequest)
-		return // empty result: model digest not found
+		return nil, errors.New("Model not found: " + dn) // empty result: model digest not found
 	}
 	req.ModelDigest = m.Digest
 	req.ModelName = m.Name
@@ -81,14 +160,14 @@ func runModelHandler(w http.ResponseWriter, r *http.Request) {
 	job := RunJob{
 		SubmitStamp: submitStamp,
 		RunRequest:  req,
+		Resume:      resume,
 	}
 
 	// get number of modelling cpu
 	// for backward compatibility: check if number of threads specified using run options
 	job.Res, job.Mpi.IsNotOnRoot, ok = resFromRequest(req)
 	if !ok {
-		http.Error(w, "Model start failed: "+dn, http.StatusBadRequest)
-		return
+		return nil, errors.New("Model start failed: " + dn)
 	}
 	job.Threads = job.Res.ThreadCount
 
@@ -98,31 +177,24 @@ func runModelHandler(w http.ResponseWriter, r *http.Request) {
 		rs, err := theRunCatalog.runModel(&job, "", hostIni{}, []computeUse{}) // no job control: use empty arguments
 		if err != nil {
 			omppLog.Log(err)
-			http.Error(w, "Model start failed: "+dn, http.StatusBadRequest)
-			return
+			return nil, errors.New("Model start failed: " + dn)
 		}
-		w.Header().Set("Content-Location", "/api/model/"+job.ModelDigest+"/run/"+rs.RunStamp)
-		jsonResponse(w, r, rs)
-		return
+		return rs, nil
 	}
 	// else append run request to the queue and return submit stamp
 
 	_, err := theRunCatalog.addJobToQueue(&job)
 	if err != nil {
-		http.Error(w, "Model run submission failed: "+dn, http.StatusBadRequest)
-		return
+		return nil, errors.New("Model run submission failed: " + dn)
 	}
-	rStamp := helper.CleanFileName(job.RunStamp)
-
-	w.Header().Set("Content-Location", "/api/model/"+job.ModelDigest+"/run/"+rStamp)
-	jsonResponse(w, r,
-		&RunState{
-			ModelName:      job.ModelName,
-			ModelDigest:    job.ModelDigest,
-			RunStamp:       rStamp,
-			SubmitStamp:    submitStamp,
-			UpdateDateTime: helper.MakeDateTime(tNow),
-		})
+
+	return &RunState{
+		ModelName:      job.ModelName,
+		ModelDigest:    job.ModelDigest,
+		RunStamp:       helper.CleanFileName(job.RunStamp),
+		SubmitStamp:    submitStamp,
+		UpdateDateTime: helper.MakeDateTime(tNow),
+	}, nil
 }
 
 // return cpu modelling count, MPI not-on-root flag and error flag
@@ -226,6 +298,156 @@ func stopModelHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 }
 
+// resume incomplete model run identified by model digest-or-name and run digest-or-stamp-or-name.
+//
+//	POST /api/run/resume/model/:model/run/:run
+//
+// Model is started again from first not completed sub-value and results are merged into the same model run.
+// When model run completed then run status updated and run value digest recalculated.
+// Model run options are restored from original run options and merged with options from optional Json RunRequest body,
+// RunRequest can also be used to specify run resources: threads, MPI processes, template and environment.
+// Model run cannot be resumed if it is completed successfully or if it is running now.
+// If multiple models with same name exist then result is undefined.
+func runResumeHandler(w http.ResponseWriter, r *http.Request) {
+
+	// url or query parameters: model digest-or-name and run digest-or-stamp-or-name
+	dn := getRequestParam(r, "model")
+	rdsn := getRequestParam(r, "run")
+
+	// decode optional json request body
+	var req RunRequest
+	if !jsonRequestDecode(w, r, false, &req) {
+		return // error at json decode, response done with http error
+	}
+
+	// find model metadata by digest or name
+	m, ok := theCatalog.ModelDicByDigestOrName(dn)
+	if !ok {
+		http.Error(w, "Model not found: "+dn, http.StatusBadRequest)
+		return // empty result: model digest not found
+	}
+
+	// find model run, sub-values progress and run options
+	rRow, rpLst, rOpts, ok := theCatalog.RunResumeState(m.Digest, rdsn)
+	if !ok || rRow == nil {
+		http.Error(w, "Model run not found: "+dn+": "+rdsn, http.StatusBadRequest)
+		return
+	}
+	if rRow.Status == db.DoneRunStatus {
+		http.Error(w, "Model run already completed: "+dn+": "+rdsn, http.StatusBadRequest)
+		return
+	}
+	if theRunCatalog.isRunActive(m.Digest, rRow.RunStamp, rRow.RunId) {
+		http.Error(w, "Model run is in progress: "+dn+": "+rdsn, http.StatusBadRequest)
+		return
+	}
+
+	resume := RunResume{
+		RunId:     rRow.RunId,
+		RunDigest: rRow.RunDigest,
+		SubFrom:   firstIncompleteSubValue(rRow.SubCount, rpLst),
+		SubCount:  rRow.SubCount,
+	}
+
+	// if all sub-values are completed then update run status and value digest
+	if resume.SubFrom >= resume.SubCount {
+
+		st, err := theCatalog.UpdateResumedRunStatus(m.Digest, rRow.RunId)
+		if err != nil || st == "" {
+			http.Error(w, "Model run status update failed: "+dn+": "+rdsn, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Location", "/api/model/"+m.Digest+"/run/"+rRow.RunStamp)
+		jsonResponse(w, r,
+			&RunState{
+				ModelName:      m.Name,
+				ModelDigest:    m.Digest,
+				RunStamp:       rRow.RunStamp,
+				IsFinal:        true,
+				UpdateDateTime: helper.MakeDateTime(time.Now()),
+				RunName:        rRow.Name,
+			})
+		return
+	}
+	omppLog.Log("Resume model run: ", m.Name, " ", rRow.Name, " from sub-value: ", resume.SubFrom, " of: ", resume.SubCount)
+
+	// merge original run options and request run options
+	opts := map[string]string{}
+	for key, val := range rOpts {
+		if isResumeRunOption(key) {
+			opts[key] = val
+		}
+	}
+	for key, val := range req.Opts {
+		opts[key] = val
+	}
+
+	// restore tables to retain and microdata from original run options, if not specified by request
+	if len(req.Tables) <= 0 {
+		if s, ok := opts["Tables.Retain"]; ok && s != "" {
+			req.Tables = helper.ParseCsvLine(s, ',')
+		}
+	}
+	if !req.Microdata.IsToDb && len(req.Microdata.Entity) <= 0 {
+
+		for key, val := range opts {
+
+			sk := strings.SplitN(key, ".", 2)
+			if len(sk) < 2 || !strings.EqualFold(sk[0], "Microdata") {
+				continue
+			}
+			switch {
+			case strings.EqualFold(sk[1], "ToDb"):
+				req.Microdata.IsToDb, _ = strconv.ParseBool(val)
+			case strings.EqualFold(sk[1], "UseInternal"):
+				req.Microdata.IsInternal, _ = strconv.ParseBool(val)
+			case strings.EqualFold(sk[1], "All") || strings.EqualFold(sk[1], "CsvDir"):
+			default:
+				req.Microdata.Entity = append(req.Microdata.Entity, struct {
+					Name string
+					Attr []string
+				}{
+					Name: sk[1],
+					Attr: helper.ParseCsvLine(val, ','),
+				})
+			}
+		}
+	}
+	for key := range opts {
+		if strings.EqualFold(key, "Tables.Retain") ||
+			(strings.HasPrefix(strings.ToLower(key), "microdata.") && !strings.EqualFold(key, "Microdata.CsvDir")) {
+			delete(opts, key)
+		}
+	}
+
+	req.ModelDigest = m.Digest
+	req.ModelName = m.Name
+	req.RunStamp = ""
+	req.Opts = opts
+
+	runModelRequest(w, r, req, resume)
+}
+
+// return true if original run option can be used to resume incomplete model run.
+// Run stamp, log file, task and restart options are excluded.
+func isResumeRunOption(key string) bool {
+
+	if key == "" {
+		return false
+	}
+	k := strings.TrimPrefix(key, "-")
+
+	for _, ek := range []string{
+		"OpenM.RunStamp", "OpenM.RunId", "OpenM.LogFilePath", "OpenM.IniFile", "OpenM.SubValues",
+		"OpenM.TaskId", "OpenM.TaskName", "OpenM.TaskRunId", "OpenM.TaskRunName", "OpenM.TaskWait",
+	} {
+		if strings.EqualFold(k, ek) {
+			return false
+		}
+	}
+	return !strings.HasPrefix(strings.ToLower(k), strings.ToLower("OpenM.Restart"))
+}
+
 // return model run status and log by model digest-or-name and run-or-submit stamp.
 //
 //	GET /api/run/log/model/:model/stamp/:stamp
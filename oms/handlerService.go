@@ -5,6 +5,7 @@ package main
 
 import (
 	"net/http"
+	"sort"
 	"strconv"
 
 	"github.com/openmpp/go/ompp/db"
@@ -71,6 +72,14 @@ func serviceStateHandler(w http.ResponseWriter, r *http.Request) {
 		ErrorCount int        // number of incomplete starts, stops and errors
 		LastUsedTs int64      // last time for model run (unix milliseconds)
 	}
+	// actual resources used by model runs from jobs history
+	type uItem struct {
+		Name       string // model name or oms instance name
+		JobCount   int    // number of model runs in jobs history
+		WallTimeMs int64  // total wall time in milliseconds
+		CpuTimeMs  int64  // total user and system cpu time in milliseconds
+		MaxRssMb   int    // maximum of peak resident memory size in megabytes
+	}
 	st := struct {
 		IsJobControl    bool             // if true then job control enabled
 		JobServiceState                  // jobs service state: paused, resources usage and limits
@@ -78,6 +87,8 @@ func serviceStateHandler(w http.ResponseWriter, r *http.Request) {
 		Active          []RunJob         // list of active (currently running) model run jobs
 		History         []historyJobFile // history of model runs
 		ComputeState    []cItem          // state of computational servers or clusters
+		ModelUsage      []uItem          // actual resources used by model runs: total for each model
+		OmsUsage        []uItem          // actual resources used by model runs: total for each oms instance
 		IsDiskUse       bool             // if true then storage usage control enabled
 		IsDiskOver      bool             // if true then storage use reach the limit
 		diskUseConfig                    // storage use settings
@@ -87,6 +98,8 @@ func serviceStateHandler(w http.ResponseWriter, r *http.Request) {
 		Active:       []RunJob{},
 		History:      []historyJobFile{},
 		ComputeState: []cItem{},
+		ModelUsage:   []uItem{},
+		OmsUsage:     []uItem{},
 		IsDiskUse:    theCfg.isDiskUse,
 	}
 
@@ -115,6 +128,27 @@ func serviceStateHandler(w http.ResponseWriter, r *http.Request) {
 			st.History[k] = hJobs[k]
 		}
 
+		// aggregate actual resources usage by model name and by oms instance name
+		addUsage := func(uLst []uItem, name string, ru RunUsage) []uItem {
+			n := sort.Search(len(uLst), func(i int) bool { return uLst[i].Name >= name })
+			if n >= len(uLst) || uLst[n].Name != name {
+				uLst = append(uLst, uItem{})
+				copy(uLst[n+1:], uLst[n:])
+				uLst[n] = uItem{Name: name}
+			}
+			uLst[n].JobCount++
+			uLst[n].WallTimeMs += ru.WallTimeMs
+			uLst[n].CpuTimeMs += ru.CpuTimeMs
+			if uLst[n].MaxRssMb < ru.MaxRssMb {
+				uLst[n].MaxRssMb = ru.MaxRssMb
+			}
+			return uLst
+		}
+		for k := range hJobs {
+			st.ModelUsage = addUsage(st.ModelUsage, hJobs[k].ModelName, hJobs[k].Usage)
+			st.OmsUsage = addUsage(st.OmsUsage, hJobs[k].oms, hJobs[k].Usage)
+		}
+
 		st.ComputeState = make([]cItem, len(cState))
 		for k := range cState {
 			st.ComputeState[k].Name = cState[k].name
@@ -265,6 +265,112 @@ func taskDefMergeHandler(w http.ResponseWriter, r *http.Request) {
 	taskDefUpdateHandler(w, r, false)
 }
 
+// taskSweepCreateHandler create parameter sweep worksets and modeling task from json request:
+// PUT  /api/task-sweep
+// Json body expected to contain SweepPub: task name, base run or workset and list of sweep parameters.
+// For each sweep parameter it is a list or range of parameter values or multiplicative factors.
+// One workset created for each combination of parameter values: full factorial or one-at-a-time sweep.
+// If task name is empty in json request then automatically generate unique task name.
+// Model can be identified by digest or name and base model run also identified by run digest, stamp or name.
+// If multiple models with same name exist then result is undefined.
+func taskSweepCreateHandler(w http.ResponseWriter, r *http.Request) {
+
+	// decode json parameter sweep
+	var sw db.SweepPub
+	if !jsonRequestDecode(w, r, true, &sw) {
+		return // error at json decode, response done with http error
+	}
+
+	// create sweep worksets and modeling task
+	tpd, ok := taskWorksetsCreate(w, "Parameter sweep", "sweep_", sw.ModelName, sw.ModelDigest, &sw.Name,
+		func() (bool, *db.TaskDefPub, error) {
+			return theCatalog.CreateSweepTask(&sw)
+		})
+	if !ok {
+		return // error at create worksets and task, response done with http error
+	}
+
+	jsonResponse(w, r,
+		struct {
+			Name string   // task name
+			Set  []string // sweep worksets
+		}{
+			Name: tpd.Name,
+			Set:  tpd.Set,
+		},
+	)
+}
+
+// taskSampleCreateHandler create worksets from samples of uncertain parameters and modeling task from json request:
+// PUT  /api/task-sample
+// Json body expected to contain SamplePub: task name, base run or workset, sampling method, number of samples and parameters distributions.
+// Distribution can be uniform, normal, triangular or discrete and applied to all parameter cells or to one parameter cell.
+// Sampling method can be plain random (default), Latin hypercube "lhs" or "sobol" sequence.
+// One workset created for each sample, random seed and sampling plan stored in task notes.
+// If task name is empty in json request then automatically generate unique task name.
+// If random seed is zero then it is generated and returned in response.
+// Model can be identified by digest or name and base model run also identified by run digest, stamp or name.
+// If multiple models with same name exist then result is undefined.
+func taskSampleCreateHandler(w http.ResponseWriter, r *http.Request) {
+
+	// decode json sampling plan
+	var sp db.SamplePub
+	if !jsonRequestDecode(w, r, true, &sp) {
+		return // error at json decode, response done with http error
+	}
+
+	// create sample worksets and modeling task
+	tpd, ok := taskWorksetsCreate(w, "Parameter sampling", "sample_", sp.ModelName, sp.ModelDigest, &sp.Name,
+		func() (bool, *db.TaskDefPub, error) {
+			return theCatalog.CreateSampleTask(&sp)
+		})
+	if !ok {
+		return // error at create worksets and task, response done with http error
+	}
+
+	jsonResponse(w, r,
+		struct {
+			Name   string   // task name
+			Method string   // sampling method
+			Seed   int64    // random seed
+			Set    []string // sample worksets
+		}{
+			Name:   tpd.Name,
+			Method: sp.Method,
+			Seed:   sp.Seed,
+			Set:    tpd.Set,
+		},
+	)
+}
+
+// taskWorksetsCreate create worksets and modeling task with all created worksets, return task definition.
+// If task name is empty then automatically generate unique task name: namePrefix and timestamp.
+// On error response is done with http error and return false.
+func taskWorksetsCreate(
+	w http.ResponseWriter, what, namePrefix, modelName, digest string, taskName *string, create func() (bool, *db.TaskDefPub, error),
+) (*db.TaskDefPub, bool) {
+
+	// if task name is empty then automatically generate name
+	if *taskName == "" {
+		ts, _ := theCatalog.getNewTimeStamp()
+		*taskName = namePrefix + ts
+	}
+
+	ok, tpd, err := create()
+	if err != nil {
+		omppLog.Log(err.Error())
+		http.Error(w, what+" failed "+modelName+" "+digest+": "+*taskName+": "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if !ok {
+		http.Error(w, what+" failed "+modelName+" "+digest+": "+*taskName, http.StatusBadRequest)
+		return nil, false
+	}
+
+	w.Header().Set("Content-Location", "/api/model/"+tpd.ModelDigest+"/task/"+tpd.Name)
+	return tpd, true
+}
+
 // taskDefUpdateHandler replace or merge task definition: task text (description and notes) and task input worksets into database.
 // It does replace or merge task_txt and task_set db rows.
 // If task does not exist then new task created.
@@ -4,8 +4,11 @@
 package main
 
 import (
+	"bytes"
 	"encoding/csv"
+	"errors"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"path"
 	"strconv"
@@ -19,6 +22,8 @@ import (
 // POST /api/model/:model/workset/:set/readonly/:readonly
 // If multiple models with same name exist then result is undefined.
 // If no such workset exist in database then empty result returned.
+// Before workset marked as read-only parameter values are checked by validation rules of the model.
+// If If-Match header does not match workset ETag then return 412 Precondition Failed.
 func worksetReadonlyUpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 	dn := getRequestParam(r, "model")
@@ -31,13 +36,24 @@ func worksetReadonlyUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// update workset read-only status
-	digest, ws, ok, err := theCatalog.UpdateWorksetReadonly(dn, wsn, isReadonly)
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, wsn)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
+	// update workset read-only status, parameter values checked before workset marked as read-only
+	digest, ws, ok, err := theCatalog.UpdateWorksetReadonly(dn, wsn, isReadonly, ifDt)
 	if err != nil {
-		http.Error(w, "Error at updating workset read-only flag "+wsn, http.StatusBadRequest)
+		if errors.Is(err, db.ErrWorksetChanged) {
+			http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+			return
+		}
+		http.Error(w, "Error at updating workset read-only flag "+wsn+": "+err.Error(), http.StatusBadRequest)
 		return
 	}
 	if ok {
+		w.Header().Set("ETag", worksetETag(ws))
 		w.Header().Set("Content-Location", "/api/model/"+digest+"/workset/"+ws.Name)
 	} else {
 		ws = &db.WorksetRow{}
@@ -91,6 +107,18 @@ func worksetCreateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	// else workset not exist
 
+	// if workset must be read-only then check parameter values before workset created
+	if wp.IsReadonly {
+		vLst, err := theCatalog.ValidateWorksetCreate(dn, wp.Param)
+		if err == nil && len(vLst) > 0 {
+			err = ruleViolationError("Failed to create read-only workset", dn, wsn, vLst)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// insert workset metadata with empty list of parameters and read-write status
 	newWp := db.WorksetPub{
 		WorksetHdrPub: db.WorksetHdrPub{
@@ -105,7 +133,7 @@ func worksetCreateHandler(w http.ResponseWriter, r *http.Request) {
 		Param: []db.ParamRunSetPub{},
 	}
 
-	ok, _, wsRow, err := theCatalog.UpdateWorkset(true, &newWp)
+	ok, _, wsRow, err := theCatalog.UpdateWorkset(true, &newWp, "", "", nil)
 	if err != nil {
 		http.Error(w, "Failed create workset metadata "+dn+" : "+wsn+" : "+err.Error(), http.StatusBadRequest)
 		return
@@ -119,13 +147,13 @@ func worksetCreateHandler(w http.ResponseWriter, r *http.Request) {
 	for k := range wp.Param {
 		switch wp.Param[k].Kind {
 		case "run":
-			if e := theCatalog.CopyParameterToWsFromRun(dn, wsn, wp.Param[k].Name, false, wp.Param[k].From); e != nil {
+			if e := theCatalog.CopyParameterToWsFromRun(dn, wsn, wp.Param[k].Name, false, wp.Param[k].From, "", ""); e != nil {
 				http.Error(w, "Failed to copy parameter from model run "+wsn+" : "+wp.Param[k].Name+": "+wp.Param[k].From+" : "+e.Error(), http.StatusBadRequest)
 				return
 			}
 			continue
 		case "set":
-			if e := theCatalog.CopyParameterBetweenWs(dn, wsn, wp.Param[k].Name, false, wp.Param[k].From); e != nil {
+			if e := theCatalog.CopyParameterBetweenWs(dn, wsn, wp.Param[k].Name, false, wp.Param[k].From, "", ""); e != nil {
 				http.Error(w, "Failed to copy parameter from workset "+wsn+" : "+wp.Param[k].Name+": "+wp.Param[k].From+" : "+e.Error(), http.StatusBadRequest)
 				return
 			}
@@ -142,9 +170,12 @@ func worksetCreateHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// if required make workset read-only
+	// if required make workset read-only, parameter values are checked again before workset marked as read-only
 	if wp.IsReadonly {
-		theCatalog.UpdateWorksetReadonly(dn, wsn, wp.IsReadonly)
+		if _, _, _, err = theCatalog.UpdateWorksetReadonly(dn, wsn, wp.IsReadonly, ""); err != nil {
+			http.Error(w, "Workset created but it is not read-only: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 	w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+wsn) // respond with workset location
 	jsonResponse(w, r, wsRow)
@@ -194,6 +225,7 @@ func worksetMergeHandler(w http.ResponseWriter, r *http.Request) {
 // Json content: workset "public" metadata.
 // If parameter not already exist in workset then parameter values must be supplied.
 // It is an error to add parameter metadata without parameter values.
+// If If-Match header does not match workset ETag then return 412 Precondition Failed.
 func worksetUpdateHandler(isReplace bool, w http.ResponseWriter, r *http.Request) {
 
 	// parse multipart form: first part must be workset metadata
@@ -240,11 +272,71 @@ func worksetUpdateHandler(isReplace bool, w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, newWp.Name)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
+	// current parameters values saved as new workset version as part of workset update
+	vLst := make([]string, len(newParamLst))
+	for k := range newParamLst {
+		vLst[k] = newParamLst[k].Name
+	}
+	vNote := "Merge workset"
+	if isReplace {
+		vNote = "Replace workset"
+	}
+
 	// update workset metadata, postpone read-only status until update completed
 	isReadonly := newWp.IsReadonly
 	newWp.IsReadonly = false
 
-	ok, _, wsRow, err := theCatalog.UpdateWorkset(isReplace, &newWp)
+	// if workset must be read-only then read all csv files and check parameter values before workset updated
+	csvMap := map[string][]byte{}
+	npLst := []int{}
+
+	if isReadonly {
+		for {
+			part, np, err := nextParamCsvPart(mr, newWp.Name, newParamLst)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if part == nil {
+				break // end of posted data
+			}
+			b, err := io.ReadAll(part)
+			part.Close() // done with csv parameter data
+			if err != nil {
+				http.Error(w, "Failed to read parameter csv "+newWp.Name+" : "+newParamLst[np].Name, http.StatusBadRequest)
+				return
+			}
+			if _, ok := csvMap[newParamLst[np].Name]; !ok {
+				npLst = append(npLst, np)
+			}
+			csvMap[newParamLst[np].Name] = b
+		}
+
+		keepLst := make([]string, len(newWp.Param))
+		for k := range newWp.Param {
+			keepLst[k] = newWp.Param[k].Name
+		}
+		vLst, err := theCatalog.ValidateWorksetUpdate(dn, newWp.Name, isReplace, keepLst, csvMap)
+		if err == nil && len(vLst) > 0 {
+			err = ruleViolationError("Failed to update read-only workset", dn, newWp.Name, vLst)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ok, _, wsRow, err := theCatalog.UpdateWorkset(isReplace, &newWp, ifDt, worksetVersionNote(r, vNote), vLst)
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+newWp.Name, http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed update workset metadata "+dn+" : "+newWp.Name+" : "+err.Error(), http.StatusBadRequest)
 		return
@@ -255,58 +347,48 @@ func worksetUpdateHandler(isReplace bool, w http.ResponseWriter, r *http.Request
 	}
 
 	// each parameter will be replaced in separate transaction
-	// decode multipart form csv files
 	pim := make(map[int]bool) // if index of parameter name in the map then csv supplied for that parameter
-	for {
-		part, err := mr.NextPart()
-		if err == io.EOF {
-			break // end of posted data
-		}
-		if err != nil {
-			http.Error(w, "Failed to get next part of multipart form "+dn+" : "+newWp.Name, http.StatusBadRequest)
-			return
-		}
 
-		// skip non parameter-csv data
-		if part.FormName() != "parameter-csv" {
-			part.Close()
-			continue
-		}
+	// read csv values and update parameter
+	updateCsv := func(np int, rd io.Reader) bool {
 
-		// validate: parameter name must be in the list of workset parameters
-		ext := path.Ext(part.FileName())
-		if ext != ".csv" {
-			part.Close()
-			http.Error(w, "Error: parameter file must have .csv extension "+newWp.Name+" : "+part.FileName(), http.StatusBadRequest)
-			return
-		}
-		name := strings.TrimSuffix(path.Base(part.FileName()), ext)
-
-		np := -1
-		for k := range newParamLst {
-			if name == newParamLst[k].Name {
-				np = k
-				break
-			}
-		}
-		if np < 0 {
-			part.Close()
-			http.Error(w, "Error: parameter must be in workset parameters list: "+newWp.Name+" : "+name, http.StatusBadRequest)
-			return
-		}
-
-		// read csv values and update parameter
-		csvRd := csv.NewReader(part)
+		csvRd := csv.NewReader(rd)
 		csvRd.TrimLeadingSpace = true
 		csvRd.ReuseRecord = true
 
-		_, err = theCatalog.UpdateWorksetParameterCsv(isReplace, &newWp, &newParamLst[np], csvRd)
-		part.Close() // done with csv parameter data
+		_, err := theCatalog.UpdateWorksetParameterCsv(isReplace, &newWp, &newParamLst[np], csvRd)
 		if err != nil {
-			http.Error(w, "Failed update workset parameter "+newWp.Name+" : "+name+" : "+err.Error(), http.StatusBadRequest)
-			return
+			http.Error(w, "Failed update workset parameter "+newWp.Name+" : "+newParamLst[np].Name+" : "+err.Error(), http.StatusBadRequest)
+			return false
 		}
 		pim[np] = true // parameter metadata and csv values updated
+		return true
+	}
+
+	if isReadonly {
+		// csv files already read and validated
+		for _, np := range npLst {
+			if !updateCsv(np, bytes.NewReader(csvMap[newParamLst[np].Name])) {
+				return // error at parameter update, response done with http error
+			}
+		}
+	} else {
+		// decode multipart form csv files
+		for {
+			part, np, err := nextParamCsvPart(mr, newWp.Name, newParamLst)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if part == nil {
+				break // end of posted data
+			}
+			ok := updateCsv(np, part)
+			part.Close() // done with csv parameter data
+			if !ok {
+				return // error at parameter update, response done with http error
+			}
+		}
 	}
 
 	// update parameter(s) metadata where parameter csv values not supplied
@@ -324,26 +406,78 @@ func worksetUpdateHandler(isReplace bool, w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	// if required make workset read-only
+	// if required make workset read-only, parameter values are checked again before workset marked as read-only
 	if isReadonly {
-		theCatalog.UpdateWorksetReadonly(dn, newWp.Name, isReadonly)
+		if _, _, _, err = theCatalog.UpdateWorksetReadonly(dn, newWp.Name, isReadonly, ""); err != nil {
+			http.Error(w, "Workset updated but it is not read-only: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 
+	setWorksetETag(w, dn, newWp.Name)
 	w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+newWp.Name) // respond with workset location
 	jsonResponse(w, r, wsRow)
 }
 
+// return next parameter csv part of multipart form and index of parameter in the list of workset parameters.
+// Parameter must be in the list of workset parameters. Return nil part at the end of posted data.
+func nextParamCsvPart(mr *multipart.Reader, wsn string, paramLst []db.ParamRunSetPub) (*multipart.Part, int, error) {
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, -1, nil // end of posted data
+		}
+		if err != nil {
+			return nil, -1, errors.New("Failed to get next part of multipart form " + wsn)
+		}
+
+		// skip non parameter-csv data
+		if part.FormName() != "parameter-csv" {
+			part.Close()
+			continue
+		}
+
+		// validate: parameter name must be in the list of workset parameters
+		ext := path.Ext(part.FileName())
+		if ext != ".csv" {
+			part.Close()
+			return nil, -1, errors.New("Error: parameter file must have .csv extension " + wsn + " : " + part.FileName())
+		}
+		name := strings.TrimSuffix(path.Base(part.FileName()), ext)
+
+		for k := range paramLst {
+			if name == paramLst[k].Name {
+				return part, k, nil
+			}
+		}
+		part.Close()
+		return nil, -1, errors.New("Error: parameter must be in workset parameters list: " + wsn + " : " + name)
+	}
+}
+
 // Delete workset and workset parameters:
 // DELETE /api/model/:model/workset/:set
 // If multiple models with same name exist then result is undefined.
 // If no such workset exist in database then no error, empty operation.
+// If If-Match header does not match workset ETag then return 412 Precondition Failed.
 func worksetDeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	dn := getRequestParam(r, "model")
 	wsn := getRequestParam(r, "set")
 
+	// check If-Match workset ETag before delete
+	ifDt, ok := worksetIfMatch(w, r, dn, wsn)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
 	// delete workset
-	ok, err := theCatalog.DeleteWorkset(dn, wsn)
+	ok, err := theCatalog.DeleteWorkset(dn, wsn, ifDt)
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Workset delete failed "+dn+": "+wsn, http.StatusBadRequest)
 		return
@@ -380,14 +514,14 @@ func worksetListDeleteHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// set read-write status
-		_, _, _, err := theCatalog.UpdateWorksetReadonly(dn, name, false)
+		_, _, _, err := theCatalog.UpdateWorksetReadonly(dn, name, false, "")
 		if err != nil {
 			http.Error(w, "Error at clear workset read-only "+dn+": "+name, http.StatusBadRequest)
 			return
 		}
 
 		// delete workset
-		ok, err := theCatalog.DeleteWorkset(dn, name)
+		ok, err := theCatalog.DeleteWorkset(dn, name, "")
 		if err != nil {
 			http.Error(w, "Workset delete failed "+dn+": "+name, http.StatusBadRequest)
 			return
@@ -421,6 +555,8 @@ func parameterIdPageUpdateHandler(w http.ResponseWriter, r *http.Request) {
 // doUpdateParameterPageHandler update a "page" of workset parameter values.
 // Page is part of parameter values defined by zero-based "start" row number and row count.
 // Dimension(s) and enum-based parameters can be as enum codes or enum id's.
+// Parameter values must satisfy validation rules of the model after update.
+// If If-Match header does not match workset ETag then return 412 Precondition Failed.
 func doUpdateParameterPageHandler(w http.ResponseWriter, r *http.Request, isCode bool) {
 
 	// url or query parameters
@@ -487,18 +623,116 @@ func doUpdateParameterPageHandler(w http.ResponseWriter, r *http.Request, isCode
 		}
 	}
 
-	// update parameter values
-	err := theCatalog.UpdateWorksetParameterPage(dn, wsn, name, from)
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, wsn)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
+	// save current parameter values as new workset version and update parameter values
+	err := theCatalog.UpdateWorksetParameterPage(dn, wsn, name, ifDt, worksetVersionNote(r, "Update parameter "+name), from)
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		omppLog.Log(err.Error())
+		var re *db.ParamRuleError
+		if errors.As(err, &re) {
+			http.Error(w, "Workset parameter update failed "+wsn+": "+name+": "+re.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Workset parameter update failed "+wsn+": "+name, http.StatusBadRequest)
 		return
 	}
 
+	setWorksetETag(w, dn, wsn)
 	w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+wsn+"/parameter/"+name) // respond with workset parameter location
 	w.Header().Set("Content-Type", "text/plain")
 }
 
+// worksetFromRunHandler creates new workset from model run parameters:
+// PUT /api/model/:model/run/:run/to-workset/:set
+// If multiple models with same name exist then result is undefined.
+// Model run can be identified by run digest, run stamp or run name and it must be completed, run status one of: s=success, x=exit, e=error.
+// New workset is based on model run and contains all run parameters with sub-values and value notes.
+// Optional json body can specify workset description and notes, read-only status
+// and list of parameters to copy, all other parameters are inherited from the base run, for example:
+//
+//	{"Param": ["ageSex", "salaryAge"], "IsReadonly": false, "Txt": [{"LangCode": "EN", "Descr": "Scenario from run"}]}
+//
+// If IsReadonly is true then parameter values must satisfy validation rules of the model.
+// If workset with the same name already exist then return error.
+func worksetFromRunHandler(w http.ResponseWriter, r *http.Request) {
+
+	dn := getRequestParam(r, "model")
+	rdsn := getRequestParam(r, "run")
+	wsn := getRequestParam(r, "set")
+
+	var req struct {
+		Param      []string       // if not empty then copy only listed parameters, the rest is inherited from the base run
+		IsReadonly bool           // if true then workset is read-only
+		Txt        []db.DescrNote // workset description and notes by language
+	}
+	if !jsonRequestDecode(w, r, false, &req) {
+		return // error at json decode, response done with http error
+	}
+
+	if _, err := theCatalog.CreateWorksetFromRun(dn, rdsn, wsn, req.Param, req.IsReadonly, req.Txt); err != nil {
+		omppLog.Log(err.Error())
+		http.Error(w, "Failed to create workset from model run "+dn+": "+rdsn+": "+wsn+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setWorksetETag(w, dn, wsn)
+	w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+wsn)
+	w.Header().Set("Content-Type", "text/plain")
+}
+
+// parameterTransformHandler apply arithmetic operation to existing workset parameter values:
+// PATCH /api/model/:model/workset/:set/parameter/:name/transform
+// Json is posted to specify expression and optional dimension filters, for example:
+//
+//	{"Expr": "value * 1.05", "Filter": [{"Name": "Year", "Op": ">=", "Values": ["2030"]}]}
+//	{"Op": "+", "Param": "other_param"}
+//
+// Workset must be in read-write state and must contain parameter and other parameter, if it is used as argument.
+// Parameter values must satisfy validation rules of the model after transform.
+// If If-Match header does not match workset ETag then return 412 Precondition Failed.
+func parameterTransformHandler(w http.ResponseWriter, r *http.Request) {
+
+	dn := getRequestParam(r, "model")
+	wsn := getRequestParam(r, "set")
+	name := getRequestParam(r, "name")
+
+	var tf db.ParamTransform
+	if !jsonRequestDecode(w, r, true, &tf) {
+		return // error at json decode, response done with http error
+	}
+	tf.Name = name
+
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, wsn)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
+	// save current parameter values as new workset version and update parameter values
+	err := theCatalog.TransformWorksetParameter(dn, wsn, &tf, ifDt, worksetVersionNote(r, "Transform parameter "+name))
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Workset parameter transform failed "+wsn+": "+name+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setWorksetETag(w, dn, wsn)
+	w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+wsn+"/parameter/"+name)
+	w.Header().Set("Content-Type", "text/plain")
+}
+
 // worksetParameterDeleteHandler delete workset parameter:
 // DELETE /api/model/:model/workset/:set/parameter/:name
 // If multiple models with same name exist then result is undefined.
@@ -509,13 +743,24 @@ func worksetParameterDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	wsn := getRequestParam(r, "set")
 	name := getRequestParam(r, "name")
 
-	// delete workset parameter
-	ok, err := theCatalog.DeleteWorksetParameter(dn, wsn, name)
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, wsn)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
+	// save current parameter values as new workset version and delete workset parameter
+	ok, err := theCatalog.DeleteWorksetParameter(dn, wsn, name, ifDt, worksetVersionNote(r, "Delete parameter "+name))
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Workset parameter delete failed "+wsn+": "+name, http.StatusBadRequest)
 		return
 	}
 	if ok {
+		setWorksetETag(w, dn, wsn)
 		w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+wsn+"/parameter/"+name)
 		w.Header().Set("Content-Type", "text/plain")
 	}
@@ -552,13 +797,24 @@ func worksetParameterRunCopy(isReplace bool, w http.ResponseWriter, r *http.Requ
 	name := getRequestParam(r, "name") // parameter name
 	rdsn := getRequestParam(r, "run")  // source run digest or stamp or name
 
-	// copy workset parameter from model run
-	err := theCatalog.CopyParameterToWsFromRun(dn, wsn, name, isReplace, rdsn)
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, wsn)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
+	// save current parameter values as new workset version and copy workset parameter from model run
+	err := theCatalog.CopyParameterToWsFromRun(dn, wsn, name, isReplace, rdsn, ifDt, worksetVersionNote(r, "Copy parameter "+name+" from run "+rdsn))
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		omppLog.Log(err.Error())
 		http.Error(w, "Workset parameter copy failed "+wsn+": "+name+" from run: "+rdsn, http.StatusBadRequest)
 		return
 	}
+	setWorksetETag(w, dn, wsn)
 	w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+wsn+"/parameter/"+name)
 	w.Header().Set("Content-Type", "text/plain")
 }
@@ -592,17 +848,155 @@ func worksetParameterCopyFromWs(isReplace bool, w http.ResponseWriter, r *http.R
 	name := getRequestParam(r, "name")          // parameter name
 	srcWsName := getRequestParam(r, "from-set") // source run digest or name
 
-	// copy workset parameter from other workset
-	err := theCatalog.CopyParameterBetweenWs(dn, dstWsName, name, isReplace, srcWsName)
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, dstWsName)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
+	// save current parameter values as new workset version and copy workset parameter from other workset
+	err := theCatalog.CopyParameterBetweenWs(dn, dstWsName, name, isReplace, srcWsName, ifDt, worksetVersionNote(r, "Copy parameter "+name+" from workset "+srcWsName))
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+dstWsName, http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		omppLog.Log(err.Error())
 		http.Error(w, "Workset parameter copy failed "+dstWsName+": "+name+" from run: "+srcWsName, http.StatusBadRequest)
 		return
 	}
+	setWorksetETag(w, dn, dstWsName)
 	w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+dstWsName+"/parameter/"+name)
 	w.Header().Set("Content-Type", "text/plain")
 }
 
+// worksetVersionRestoreHandler restore workset parameters values from workset version:
+// POST /api/model/:model/workset/:set/version/:version/restore
+// POST /api/model/:model/workset/:set/version/:version/restore/parameter/:name
+// If optional parameter name specified then only that parameter restored
+// else all parameters updated since that version are restored.
+// Optional ?note is a note of new workset version.
+// Current values of restored parameters saved as new version, it allows to undo restore.
+// Workset must be in read-write state.
+func worksetVersionRestoreHandler(w http.ResponseWriter, r *http.Request) {
+
+	// url or query parameters
+	dn := getRequestParam(r, "model")  // model digest-or-name
+	wsn := getRequestParam(r, "set")   // workset name
+	name := getRequestParam(r, "name") // optional parameter name
+	note := getRequestParam(r, "note") // optional version note
+
+	verId, ok := getIntRequestParam(r, "version", 0)
+	if !ok || verId <= 0 {
+		http.Error(w, "Invalid workset version: "+getRequestParam(r, "version"), http.StatusBadRequest)
+		return
+	}
+
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, wsn)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
+	newId, err := theCatalog.RestoreWorksetVersion(dn, wsn, verId, name, ifDt, note)
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Workset version restore failed "+wsn+": "+strconv.Itoa(verId)+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	setWorksetETag(w, dn, wsn)
+	w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+wsn+"/version/"+strconv.Itoa(newId))
+	w.Header().Set("Content-Type", "text/plain")
+}
+
+// worksetETag return workset entity tag: set id and digits of workset update date-time, for example: "22-20120817160559123".
+// Workset update date-time is a workset version: it is changed by any update of workset metadata or parameter values
+// and it is always different after each update, even if two updates done within the same millisecond.
+func worksetETag(ws *db.WorksetRow) string {
+
+	dt := strings.Map(func(c rune) rune {
+		if c >= '0' && c <= '9' {
+			return c
+		}
+		return -1
+	}, ws.UpdateDateTime)
+
+	return "\"" + strconv.Itoa(ws.SetId) + "-" + dt + "\""
+}
+
+// set ETag response header to current workset entity tag, if workset exist.
+func setWorksetETag(w http.ResponseWriter, dn, wsn string) {
+
+	if ws, ok := theCatalog.WorksetByName(dn, wsn); ok {
+		w.Header().Set("ETag", worksetETag(ws))
+	}
+}
+
+// check If-Match request header against current workset entity tag before workset update.
+// Return workset update date-time to do conditional update inside of database transaction
+// or empty "" string if If-Match header is empty or "*".
+// If workset changed since client received ETag then response is 412 Precondition Failed.
+// If If-Match header is empty then response is 428 Precondition Required, unless workset does not exist yet
+// or oms.RequireIfMatch option is false.
+// Return false on error and write http error response.
+func worksetIfMatch(w http.ResponseWriter, r *http.Request, dn, wsn string) (string, bool) {
+
+	im := strings.TrimSpace(r.Header.Get("If-Match"))
+	ws, isFound := theCatalog.WorksetByName(dn, wsn)
+
+	if im == "" {
+		if theCfg.isIfMatch && isFound {
+			http.Error(w, "Workset update requires If-Match header "+dn+" : "+wsn, http.StatusPreconditionRequired)
+			return "", false
+		}
+		return "", true // unconditional update
+	}
+	if !isFound {
+		http.Error(w, "Workset not found "+dn+" : "+wsn, http.StatusPreconditionFailed)
+		return "", false
+	}
+	if im == "*" {
+		return "", true // any version of existing workset
+	}
+
+	et := worksetETag(ws)
+	for _, s := range strings.Split(im, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(s), "W/") == et {
+			return ws.UpdateDateTime, true
+		}
+	}
+	w.Header().Set("ETag", et)
+	http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+	return "", false
+}
+
+// check parameters values of workset using validation rules of the model.
+// Return error with list of rule violations, error message starts with msg.
+func worksetValidationError(dn, wsn, profile string, msg string) error {
+
+	vLst, _, err := theCatalog.ValidateWorkset(dn, wsn, profile)
+	if err != nil {
+		return errors.New(msg + ": " + dn + " : " + wsn + " : " + err.Error())
+	}
+	if len(vLst) <= 0 {
+		return nil // all parameter values are valid
+	}
+	return ruleViolationError(msg, dn, wsn, vLst)
+}
+
+// return note of workset version which is saved as part of workset update.
+// Version note is optional ?note url parameter, if it is empty then default note used.
+func worksetVersionNote(r *http.Request, defaultNote string) string {
+
+	if note := getRequestParam(r, "note"); note != "" {
+		return note
+	}
+	return defaultNote
+}
+
 // worksetParameterTextMergeHandler do merge (insert or update) workset parameter(s) value notes, array of parameters expected.
 // PATCH /api/model/:model/workset/:set/parameter-text
 // Model can be identified by digest or name and model run also identified by run digest-or-stamp-or-name.
@@ -611,6 +1005,7 @@ func worksetParameterCopyFromWs(isReplace bool, w http.ResponseWriter, r *http.R
 // If parameter not exist in workset then return error.
 // Input json must be array of ParamRunSetTxtPub,
 // if parameters text array is empty then nothing updated, it is empty operation return is success
+// If If-Match header does not match workset ETag then return 412 Precondition Failed.
 func worksetParameterTextMergeHandler(w http.ResponseWriter, r *http.Request) {
 
 	// url or query parameters
@@ -623,14 +1018,25 @@ func worksetParameterTextMergeHandler(w http.ResponseWriter, r *http.Request) {
 		return // error at json decode, response done with http error
 	}
 
+	// check If-Match workset ETag before update
+	ifDt, ok := worksetIfMatch(w, r, dn, wsn)
+	if !ok {
+		return // workset changed or If-Match header missing, response done with http error
+	}
+
 	// update workset parameter value notes in model catalog
-	ok, err := theCatalog.UpdateWorksetParameterText(dn, wsn, pvtLst)
+	ok, err := theCatalog.UpdateWorksetParameterText(dn, wsn, pvtLst, ifDt)
+	if err == db.ErrWorksetChanged {
+		http.Error(w, "Workset was changed by another update "+dn+" : "+wsn, http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		omppLog.Log(err.Error())
 		http.Error(w, "Workset parameter(s) value notes update failed "+dn+": "+wsn+": "+err.Error(), http.StatusBadRequest)
 		return
 	}
 	if ok {
+		setWorksetETag(w, dn, wsn)
 		w.Header().Set("Content-Location", "/api/model/"+dn+"/workset/"+wsn+"/parameter-text")
 		w.Header().Set("Content-Type", "text/plain")
 	}
@@ -7,6 +7,8 @@ import (
 	"bufio"
 	"io"
 	"io/fs"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -84,8 +86,9 @@ func makeModelDownloadCommand(mb modelBasic, logPath string, isNoAcc bool, isNoM
 	return cmd, cmdMsg
 }
 
-// make dbcopy command to prepare model run download
-func makeRunDownloadCommand(mb modelBasic, runId int, logPath string, isNoAcc bool, isNoMd bool, isCsvBom bool) (*exec.Cmd, string) {
+// make dbcopy command to prepare model run download.
+// If isZipStream is true then dbcopy writes files directly into .zip archive stream and does not create download folder.
+func makeRunDownloadCommand(mb modelBasic, runId int, logPath string, isNoAcc bool, isNoMd bool, isCsvBom bool, isZipStream bool) (*exec.Cmd, string) {
 
 	// make dbcopy message for user log
 	cmdMsg := "dbcopy -m " + mb.model.Name +
@@ -102,6 +105,9 @@ func makeRunDownloadCommand(mb modelBasic, runId int, logPath string, isNoAcc bo
 	if isCsvBom {
 		cmdMsg += " -dbcopy.Utf8BomIntoCsv"
 	}
+	if isZipStream {
+		cmdMsg += " -dbcopy.ZipStream"
+	}
 
 	// make relative path arguments to dbcopy work directory: to a model bin directory
 	downDir, dbPathRel, err := makeRelDbCopyArgs(mb.binDir, theCfg.downloadDir, mb.dbPath)
@@ -128,6 +134,9 @@ func makeRunDownloadCommand(mb modelBasic, runId int, logPath string, isNoAcc bo
 	if isCsvBom {
 		cArgs = append(cArgs, "-dbcopy.Utf8BomIntoCsv")
 	}
+	if isZipStream {
+		cArgs = append(cArgs, "-dbcopy.ZipStream")
+	}
 
 	cmd := exec.Command(theCfg.dbcopyPath, cArgs...)
 	cmd.Dir = mb.binDir // dbcopy work directory is a model bin directory
@@ -284,6 +293,73 @@ func makeDownload(baseName string, cmd *exec.Cmd, cmdMsg string, logPath string)
 	runUpDownDbcopy("download", theCfg.downloadDir, baseName, cmd, cmdMsg, logPath)
 }
 
+// timeout in msec, wait for download .zip archive to be created or to grow while streaming it to the client
+const streamZipWait = 500
+
+// streamDownloadZip send download .zip archive into response body while it is produced by dbcopy.
+// Dbcopy write zip archive as a stream, archive file is growing until dbcopy completed and done channel closed.
+// If dbcopy failed after part of archive already sent then response is aborted and client receive an error.
+func streamDownloadZip(w http.ResponseWriter, zipPath string, logPath string, doneC <-chan bool) {
+
+	// wait until zip archive created by dbcopy or dbcopy completed
+	isDone := false
+	var f *os.File
+
+	for f == nil {
+		zf, err := os.Open(zipPath)
+		if err == nil {
+			f = zf
+			break
+		}
+		if !os.IsNotExist(err) || isDone {
+			omppLog.Log("Error: download failed: ", zipPath)
+			http.Error(w, "Download failed: "+filepath.Base(zipPath), http.StatusBadRequest)
+			return
+		}
+		select {
+		case <-doneC:
+			isDone = true
+		case <-time.After(streamZipWait * time.Millisecond):
+		}
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename="+`"`+url.QueryEscape(filepath.Base(zipPath))+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	fl, _ := w.(http.Flusher)
+
+	// copy zip archive content to the response until dbcopy completed and end of archive file
+	for {
+		isLast := isDone
+
+		n, err := io.Copy(w, f)
+		if err != nil {
+			omppLog.Log("Error: download failed: ", zipPath, ": ", err.Error())
+			panic(http.ErrAbortHandler) // client connection closed or write error
+		}
+		if n > 0 && fl != nil {
+			fl.Flush()
+		}
+		if isLast {
+			break
+		}
+
+		select {
+		case <-doneC:
+			isDone = true
+		case <-time.After(streamZipWait * time.Millisecond):
+		}
+	}
+
+	// if dbcopy failed then abort response: client must not receive incomplete zip as a valid result
+	if fileExist(logPath) || !fileExist(strings.TrimSuffix(logPath, ".progress.download.log")+".ready.download.log") {
+		omppLog.Log("Error: download failed: ", zipPath)
+		panic(http.ErrAbortHandler)
+	}
+}
+
 // makeUpload invoke dbcopy to create model upload directory and .zip file:
 // 1. delete existing: previous upload log file and model.xyz directory.
 // 2. start dbcopy to unzip uploaded file and import into it model database.
@@ -110,6 +110,14 @@ Following arguments supporetd by oms:
 
 	if true then disable shutdown route: /shutdown/
 
+-oms.RequireIfMatch true
+
+	if true then update or delete of existing workset requires If-Match header with workset ETag, it is true by default.
+	Workset ETag is returned by workset status, workset text and workset parameter read requests.
+	If If-Match header is empty then workset update rejected with 428 Precondition Required.
+	If workset changed by another update since ETag received then workset update rejected with 412 Precondition Failed.
+	Use -oms.RequireIfMatch false to allow unconditional updates from clients which do not send If-Match header.
+
 -oms.AdminAll
 
 	if true then allow global administrative routes: /admin-all/
@@ -186,6 +194,7 @@ const (
 	adminAllArgKey     = "oms.AdminAll"       // if true then allow global administrative routes: /admin-all/
 	noAdminArgKey      = "oms.NoAdmin"        // if true then disable loca administrative routes: /admin/
 	noShutdownArgKey   = "oms.NoShutdown"     // if true then disable shutdown route: /shutdown/
+	ifMatchArgKey      = "oms.RequireIfMatch" // if true then workset update requires If-Match header with workset ETag
 	uiLangsArgKey      = "oms.Languages"      // list of supported languages
 	encodingArgKey     = "oms.CodePage"       // code page for converting source files, e.g. windows-1252
 	doubleFormatArgKey = "oms.DoubleFormat"   // format to convert float or double value to string, e.g. %.15g
@@ -207,6 +216,7 @@ var theCfg = struct {
 	isJobControl bool              // if true then do job control: model run queue and resource allocation
 	isJobPast    bool              // if true then do job history shadow copy
 	isDiskUse    bool              // if true then storage usage control enabled
+	isIfMatch    bool              // if true then workset update requires If-Match header with workset ETag
 	jobDir       string            // job control directory
 	omsName      string            // oms instance name, if empty then derived from address to listen
 	dbcopyPath   string            // if download or upload allowed then it is path to dbcopy.exe
@@ -274,6 +284,7 @@ func mainBody(args []string) error {
 	_ = flag.Bool(adminAllArgKey, false, "if true then allow global administrative routes: /admin-all/")
 	_ = flag.Bool(noAdminArgKey, false, "if true then disable loca administrative routes: /admin/")
 	_ = flag.Bool(noShutdownArgKey, false, "if true then disable shutdown route: /shutdown/")
+	_ = flag.Bool(ifMatchArgKey, true, "if true then workset update requires If-Match header with workset ETag")
 	_ = flag.String(uiLangsArgKey, "en", "comma-separated list of supported languages")
 	_ = flag.String(encodingArgKey, "", "code page to convert source file into utf-8, e.g.: windows-1252")
 	_ = flag.String(doubleFormatArgKey, theCfg.doubleFmt, "format to convert float or double value to string")
@@ -297,6 +308,7 @@ func mainBody(args []string) error {
 	isAdminAll := runOpts.Bool(adminAllArgKey)
 	isAdmin := !runOpts.Bool(noAdminArgKey)
 	isShutdown := !runOpts.Bool(noShutdownArgKey)
+	theCfg.isIfMatch = !runOpts.IsExist(ifMatchArgKey) || runOpts.Bool(ifMatchArgKey)
 	theCfg.doubleFmt = runOpts.String(doubleFormatArgKey)
 	theCfg.codePage = runOpts.String(encodingArgKey)
 
@@ -92,6 +92,10 @@ func apiGetRoutes(router *vestigo.Router) {
 	// GET /api/model/:model/text-all
 	router.Get("/api/model/:model/text-all", modelAllTextHandler, logRequest)
 
+	// GET /api/model/:model/diff/:with
+	router.Get("/api/model/:model/diff/:with", modelDiffHandler, logRequest)
+	router.Get("/api/model/:model/diff/", http.NotFound)
+
 	//
 	// GET model extra: languages, profile(s)
 	//
@@ -172,6 +176,22 @@ func apiGetRoutes(router *vestigo.Router) {
 	// GET /api/model/:model/workset/:set/status
 	router.Get("/api/model/:model/workset/:set/status", worksetStatusHandler, logRequest)
 
+	// GET /api/model/:model/workset/:set/version-list
+	router.Get("/api/model/:model/workset/:set/version-list", worksetVersionListHandler, logRequest)
+
+	// GET /api/model/:model/workset/:set/validate
+	// GET /api/model/:model/workset/:set/validate/profile/:profile
+	router.Get("/api/model/:model/workset/:set/validate", worksetValidateHandler, logRequest)
+	router.Get("/api/model/:model/workset/:set/validate/profile/:profile", worksetValidateHandler, logRequest)
+	router.Get("/api/model/:model/workset/:set/validate/profile/", http.NotFound)
+
+	// GET /api/model/:model/workset/:set/version/:from/diff/:to
+	// GET /api/model/:model/workset/:set/version/:from/diff/:to/parameter/:name
+	router.Get("/api/model/:model/workset/:set/version/:from/diff/:to", worksetVersionDiffHandler, logRequest)
+	router.Get("/api/model/:model/workset/:set/version/:from/diff/:to/parameter/:name", worksetVersionDiffHandler, logRequest)
+	router.Get("/api/model/:model/workset/:set/version/:from/diff/", http.NotFound)
+	router.Get("/api/model/:model/workset/:set/version/:from/diff/:to/parameter/", http.NotFound)
+
 	// GET /api/model/:model/workset/status/default
 	router.Get("/api/model/:model/workset/status/default", worksetDefaultStatusHandler, logRequest)
 
@@ -222,6 +242,19 @@ func apiGetRoutes(router *vestigo.Router) {
 	// GET /api/model/:model/task/:task/run-status/last-completed
 	router.Get("/api/model/:model/task/:task/run-status/last-completed", lastCompletedTaskRunStatusHandler, logRequest)
 
+	// GET /api/model/:model/task/:task/sensitivity
+	// GET /api/model/:model/task/:task/sensitivity/run/:run
+	router.Get("/api/model/:model/task/:task/sensitivity", taskSensitivityHandler, logRequest)
+	router.Get("/api/model/:model/task/:task/sensitivity/run/:run", taskSensitivityHandler, logRequest)
+	router.Get("/api/model/:model/task/:task/sensitivity/run/", http.NotFound)
+
+	// GET /api/model/:model/task/:task/compare/table/:table
+	// GET /api/model/:model/task/:task/compare/table/:table/run/:run
+	router.Get("/api/model/:model/task/:task/compare/table/:table", taskCompareHandler, logRequest)
+	router.Get("/api/model/:model/task/:task/compare/table/:table/run/:run", taskCompareHandler, logRequest)
+	router.Get("/api/model/:model/task/:task/compare/table/", http.NotFound)
+	router.Get("/api/model/:model/task/:task/compare/table/:table/run/", http.NotFound)
+
 	// GET /api/model/:model/task/:task/text
 	// GET /api/model/:model/task/:task/text/lang/:lang
 	router.Get("/api/model/:model/task/:task/text", taskTextHandler, logRequest)
@@ -553,10 +586,17 @@ func apiUpdateRoutes(router *vestigo.Router) {
 	// PATCH /api/model/:model/workset/:set/parameter/:name/new/value-id
 	router.Patch("/api/model/:model/workset/:set/parameter/:name/new/value-id", parameterIdPageUpdateHandler, logRequest)
 
+	// PATCH /api/model/:model/workset/:set/parameter/:name/transform
+	router.Patch("/api/model/:model/workset/:set/parameter/:name/transform", parameterTransformHandler, logRequest)
+
 	// DELETE /api/model/:model/workset/:set/parameter/:name
 	router.Delete("/api/model/:model/workset/:set/parameter/:name", worksetParameterDeleteHandler, logRequest)
 	router.Delete("/api/model/:model/workset/:set/parameter/", http.NotFound)
 
+	// PUT  /api/model/:model/run/:run/to-workset/:set
+	router.Put("/api/model/:model/run/:run/to-workset/:set", worksetFromRunHandler, logRequest)
+	router.Put("/api/model/:model/run/:run/to-workset/", http.NotFound)
+
 	// PUT  /api/model/:model/workset/:set/copy/parameter/:name/from-run/:run
 	router.Put("/api/model/:model/workset/:set/copy/parameter/:name/from-run/:run", worksetParameterRunCopyHandler, logRequest)
 	router.Put("/api/model/:model/workset/:set/copy/parameter/:name/from-run/", http.NotFound)
@@ -573,6 +613,12 @@ func apiUpdateRoutes(router *vestigo.Router) {
 	router.Patch("/api/model/:model/workset/:set/merge/parameter/:name/from-workset/:from-set", worksetParameterMergeFromWsHandler, logRequest)
 	router.Patch("/api/model/:model/workset/:set/merge/parameter/:name/from-workset/", http.NotFound)
 
+	// POST /api/model/:model/workset/:set/version/:version/restore
+	// POST /api/model/:model/workset/:set/version/:version/restore/parameter/:name
+	router.Post("/api/model/:model/workset/:set/version/:version/restore", worksetVersionRestoreHandler, logRequest)
+	router.Post("/api/model/:model/workset/:set/version/:version/restore/parameter/:name", worksetVersionRestoreHandler, logRequest)
+	router.Post("/api/model/:model/workset/:set/version/:version/restore/parameter/", http.NotFound)
+
 	// PATCH /api/model/:model/workset/:set/parameter-text
 	router.Patch("/api/model/:model/workset/:set/parameter-text", worksetParameterTextMergeHandler, logRequest)
 
@@ -603,6 +649,12 @@ func apiUpdateRoutes(router *vestigo.Router) {
 	// PATCH /api/task
 	router.Patch("/api/task", taskDefMergeHandler, logRequest)
 
+	// PUT  /api/task-sweep
+	router.Put("/api/task-sweep", taskSweepCreateHandler, logRequest)
+
+	// PUT  /api/task-sample
+	router.Put("/api/task-sample", taskSampleCreateHandler, logRequest)
+
 	// DELETE /api/model/:model/task/:task
 	router.Delete("/api/model/:model/task/:task", taskDeleteHandler, logRequest)
 	router.Delete("/api/model/:model/task/", http.NotFound)
@@ -627,8 +679,25 @@ func apiRunModelRoutes(router *vestigo.Router) {
 	router.Put("/api/run/stop/model/:model/stamp/:stamp", stopModelHandler, logRequest)
 	router.Put("/api/run/stop/model/:model/stamp/", http.NotFound)
 
+	// POST /api/run/resume/model/:model/run/:run
+	router.Post("/api/run/resume/model/:model/run/:run", runResumeHandler, logRequest)
+	router.Post("/api/run/resume/model/:model/run/", http.NotFound)
+
 	// reject run log if request ill-formed
 	router.Get("/api/run/log/model/", http.NotFound)
+
+	// POST /api/calibration
+	router.Post("/api/calibration", calibrationStartHandler, logRequest)
+
+	// GET /api/calibration-list
+	router.Get("/api/calibration-list", calibrationListHandler, logRequest)
+
+	// GET /api/calibration/:name
+	router.Get("/api/calibration/:name", calibrationStateHandler, logRequest)
+
+	// PUT /api/calibration/:name/stop
+	router.Put("/api/calibration/:name/stop", calibrationStopHandler, logRequest)
+	router.Put("/api/calibration/", http.NotFound)
 }
 
 // add http web-service /api routes to download and manage files at home/io/download folder
@@ -848,6 +917,19 @@ func apiAdminRoutes(isAdminAll bool, router *vestigo.Router) {
 	router.Post("/api/admin/jobs-pause/:pause", jobsPauseHandler, logRequest)
 	router.Post("/api/admin/jobs-pause/", http.NotFound)
 
+	// POST /api/admin/jobs-drain/:pause
+	router.Post("/api/admin/jobs-drain/:pause", jobsDrainHandler, logRequest)
+	router.Post("/api/admin/jobs-drain/", http.NotFound)
+
+	// POST /api/admin/jobs-queue/move-to/:oms
+	router.Post("/api/admin/jobs-queue/move-to/:oms", jobsQueueMoveHandler, logRequest)
+	router.Post("/api/admin/jobs-queue/move-to/", http.NotFound)
+
+	// GET  /api/admin/jobs-queue/export
+	// POST /api/admin/jobs-queue/import
+	router.Get("/api/admin/jobs-queue/export", jobsQueueExportHandler, logRequest)
+	router.Post("/api/admin/jobs-queue/import", jobsQueueImportHandler, logRequest)
+
 	// POST /api/admin/db-cleanup/:path
 	// POST /api/admin/db-cleanup/:path/name/:name
 	// POST /api/admin/db-cleanup/:path/name/:name/digest/:digest
@@ -858,6 +940,17 @@ func apiAdminRoutes(isAdminAll bool, router *vestigo.Router) {
 	router.Post("/api/admin/db-cleanup/:path/name/", http.NotFound)
 	router.Post("/api/admin/db-cleanup/:path/name/:name/digest/", http.NotFound)
 
+	// POST /api/admin/db-purge/:path/keep-last/:count
+	// POST /api/admin/db-purge/:path/keep-last/:count/name/:name
+	// POST /api/admin/db-purge/:path/keep-last/:count/name/:name/digest/:digest
+	router.Post("/api/admin/db-purge/:path/keep-last/:count", modelDbPurgeHandler, logRequest)
+	router.Post("/api/admin/db-purge/:path/keep-last/:count/name/:name", modelDbPurgeHandler, logRequest)
+	router.Post("/api/admin/db-purge/:path/keep-last/:count/name/:name/digest/:digest", modelDbPurgeHandler, logRequest)
+	router.Post("/api/admin/db-purge/", http.NotFound)
+	router.Post("/api/admin/db-purge/:path/keep-last/", http.NotFound)
+	router.Post("/api/admin/db-purge/:path/keep-last/:count/name/", http.NotFound)
+	router.Post("/api/admin/db-purge/:path/keep-last/:count/name/:name/digest/", http.NotFound)
+
 	// GET /api/admin/db-cleanup/log-all
 	// GET /api/admin/db-cleanup/log/:name
 	router.Get("/api/admin/db-cleanup/log-all", dbCleanupAllLogGetHandler, logRequest)
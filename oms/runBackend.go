@@ -0,0 +1,80 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"io"
+	"os/exec"
+)
+
+// modelProcess is a model run started by compute backend: local process or batch scheduler job.
+type modelProcess interface {
+	outputPipes() (io.Reader, io.Reader, error) // return model stdout and stderr, must be called before start
+	start() (int, error)                        // start model run and return process id or batch job id
+	kill() error                                // kill model process or cancel batch job
+	wait() error                                // wait until model run completed, return error if model run failed
+	commandLine() (string, []string)            // return executable path and command line arguments
+	usage() RunUsage                            // return cpu time and peak memory of completed model run, if available
+}
+
+// localProcess is a model run as local process, it may be mpiexec process for MPI model run.
+type localProcess struct {
+	cmd *exec.Cmd // model run command
+}
+
+// return new local process to run the model.
+func newLocalProcess(cmd *exec.Cmd) *localProcess {
+	return &localProcess{cmd: cmd}
+}
+
+// return stdout and stderr pipes of local process
+func (lp *localProcess) outputPipes() (io.Reader, io.Reader, error) {
+
+	outPipe, err := lp.cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	errPipe, err := lp.cmd.StderrPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	return outPipe, errPipe, nil
+}
+
+// start local process and return process id
+func (lp *localProcess) start() (int, error) {
+
+	if err := lp.cmd.Start(); err != nil {
+		return 0, err
+	}
+	return lp.cmd.Process.Pid, nil
+}
+
+// kill local process
+func (lp *localProcess) kill() error {
+	return lp.cmd.Process.Kill()
+}
+
+// wait until local process completed
+func (lp *localProcess) wait() error {
+	return lp.cmd.Wait()
+}
+
+// return executable path and command line arguments of local process
+func (lp *localProcess) commandLine() (string, []string) {
+	return lp.cmd.Path, lp.cmd.Args
+}
+
+// return cpu time and peak memory of completed local process from process wait status
+func (lp *localProcess) usage() RunUsage {
+
+	ps := lp.cmd.ProcessState
+	if ps == nil {
+		return RunUsage{}
+	}
+	return RunUsage{
+		CpuTimeMs: (ps.UserTime() + ps.SystemTime()).Milliseconds(),
+		MaxRssMb:  processMaxRssMb(ps),
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// batchJob is a model run submitted to batch scheduler, for example: Slurm sbatch, squeue, scancel.
+// Model run command line is written into batch script, script output and exit code are written into files.
+type batchJob struct {
+	cfg        batchIni          // batch scheduler settings from job.ini
+	cmd        *exec.Cmd         // model run command to execute from batch script
+	data       batchTemplateData // batch script template arguments
+	tmplPath   string            // if not empty then batch script template path
+	scriptPath string            // batch script path
+	jobId      string            // batch job id, returned by submit command
+	outR       *io.PipeReader    // model console output reader
+	outW       *io.PipeWriter    // model console output writer
+	errR       *io.PipeReader    // empty stderr reader: model stdout and stderr are redirected into output file
+	errW       *io.PipeWriter    // empty stderr writer
+	doneC      chan bool         // closed when batch job completed
+}
+
+// arguments of batch script template
+type batchTemplateData struct {
+	ModelName string            // model name
+	RunStamp  string            // model run stamp
+	Dir       string            // work directory to run the model
+	Exe       string            // executable to run: model executable or mpiexec
+	Args      []string          // command line arguments
+	Env       map[string]string // environment variables to run the model
+	MpiNp     int               // number of MPI processes, zero if it is not MPI model run
+	Threads   int               // number of modelling threads
+	Cpu       int               // total number of cpu cores
+	Mem       int               // if not zero then memory size in gigabytes
+	OutPath   string            // model console output file path
+	ExitPath  string            // model exit code file path
+}
+
+// batch script created if batch template not found in etc/ directory
+const defaultBatchScript = `#!/bin/sh
+exec > {{quote .OutPath}} 2>&1
+cd {{quote .Dir}} || { echo 1 > {{quote .ExitPath}}; exit 1; }
+{{range $key, $val := .Env}}export {{$key}}={{quote $val}}
+{{end}}{{quote .Exe}}{{range .Args}} {{quote .}}{{end}}
+echo $? > {{quote .ExitPath}}
+`
+
+// return new batch job to run the model.
+// Batch script and output files are created in batch script directory or in model log directory.
+// Batch script template can be model specific: "batch.ModelName.template.txt" or default: "batch.ModelRun.template.txt".
+func newBatchJob(cfg batchIni, cmd *exec.Cmd, job *RunJob, rs *RunState, etcDir, workDir, logDir string) (*batchJob, error) {
+
+	sd := cfg.scriptDir
+	if sd == "" {
+		sd = logDir
+	}
+	sd, err := filepath.Abs(sd)
+	if err != nil {
+		return nil, err
+	}
+	wd, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Dir != "" {
+		if wd, err = filepath.Abs(cmd.Dir); err != nil {
+			return nil, err
+		}
+	}
+	fp := filepath.Join(sd, rs.ModelName+"."+rs.RunStamp+".batch")
+
+	np := 0
+	if job.IsMpi {
+		np = job.Res.ProcessCount
+	}
+	bj := &batchJob{
+		cfg:        cfg,
+		cmd:        cmd,
+		scriptPath: fp + ".sh",
+		doneC:      make(chan bool),
+		data: batchTemplateData{
+			ModelName: rs.ModelName,
+			RunStamp:  rs.RunStamp,
+			Dir:       wd,
+			Exe:       cmd.Args[0],
+			Env:       job.Env,
+			MpiNp:     np,
+			Threads:   job.Res.ThreadCount,
+			Cpu:       job.Res.Cpu,
+			Mem:       job.Res.Mem,
+			OutPath:   fp + ".out",
+			ExitPath:  fp + ".exit",
+		},
+	}
+	if len(cmd.Args) > 1 {
+		bj.data.Args = cmd.Args[1:]
+	}
+	if bj.data.Env == nil {
+		bj.data.Env = map[string]string{}
+	}
+
+	// search for model-specific batch template or default batch template
+	for _, tn := range []string{"batch." + job.ModelName + ".template.txt", "batch.ModelRun.template.txt"} {
+		if p := filepath.Join(etcDir, tn); etcDir != "" && fileExist(p) {
+			bj.tmplPath = p
+			break
+		}
+	}
+
+	bj.outR, bj.outW = io.Pipe()
+	bj.errR, bj.errW = io.Pipe()
+
+	return bj, nil
+}
+
+// return model console output and empty stderr, model stdout and stderr are redirected into output file
+func (bj *batchJob) outputPipes() (io.Reader, io.Reader, error) {
+	return bj.outR, bj.errR, nil
+}
+
+// create batch script and submit it to batch scheduler, return batch job id if it is a number.
+func (bj *batchJob) start() (int, error) {
+
+	// create batch script from template
+	tmpl := template.New("batch").Funcs(template.FuncMap{"quote": shellQuote})
+	var err error
+
+	if bj.tmplPath != "" {
+		tmpl, err = tmpl.ParseFiles(bj.tmplPath)
+		if err == nil {
+			tmpl = tmpl.Lookup(filepath.Base(bj.tmplPath))
+		}
+	} else {
+		tmpl, err = tmpl.Parse(defaultBatchScript)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var b strings.Builder
+	if err = tmpl.Execute(&b, bj.data); err != nil {
+		return 0, err
+	}
+
+	fileDeleteAndLog(false, bj.data.OutPath)
+	fileDeleteAndLog(false, bj.data.ExitPath)
+
+	if err = os.WriteFile(bj.scriptPath, []byte(b.String()), 0755); err != nil {
+		return 0, err
+	}
+
+	// submit batch script: batch job id expected as last word of first non-empty output line
+	// for example: Submitted batch job 1234 or 1234;cluster_name
+	args := append(splitIniArgs(bj.cfg.submitArgs, bj.cfg.argsBreak), bj.scriptPath)
+
+	omppLog.Log("Submit batch job: ", bj.cfg.submitExe, " ", strings.Join(args, " "))
+
+	bt, err := exec.Command(bj.cfg.submitExe, args...).Output()
+	if err != nil {
+		return 0, errors.New("batch job submit failed: " + bj.scriptPath + ": " + err.Error())
+	}
+	for _, ln := range strings.Split(strings.ReplaceAll(string(bt), "\r", "\n"), "\n") {
+		if f := strings.Fields(ln); len(f) > 0 {
+			bj.jobId, _, _ = strings.Cut(f[len(f)-1], ";")
+			break
+		}
+	}
+	if bj.jobId == "" {
+		return 0, errors.New("batch job submit failed, empty job id: " + bj.scriptPath)
+	}
+	omppLog.Log("Batch job id: ", bj.jobId, " ", bj.data.ModelName, " ", bj.data.RunStamp)
+
+	go bj.monitor()
+
+	return batchJobPid(bj.jobId), nil
+}
+
+// return batch job id as process id: leading digits of job id, for example: 123 from 123.server.
+// Return zero if job id does not start with digits.
+func batchJobPid(jobId string) int {
+
+	nd := 0
+	for nd < len(jobId) && '0' <= jobId[nd] && jobId[nd] <= '9' {
+		nd++
+	}
+	n, err := strconv.Atoi(jobId[:nd])
+	if err != nil {
+		omppLog.Log("Warning: batch job id is not a number: ", jobId)
+		return 0
+	}
+	return n
+}
+
+// poll batch job status until job completed and copy output file content into model console output pipe.
+// If status command failed then job status is unknown: retry with increasing poll interval
+// and stop polling after batchStatusMaxErrors failures in a row or if job exit code file created.
+func (bj *batchJob) monitor() {
+
+	pollTime := time.Duration(bj.cfg.pollSeconds) * time.Second
+	if pollTime <= 0 {
+		pollTime = batchPollDefault * time.Second
+	}
+	var offset int64
+
+	// copy new lines of output file into model console output
+	doCopy := func() {
+		f, err := os.Open(bj.data.OutPath)
+		if err != nil {
+			return // output file not created yet
+		}
+		defer f.Close()
+
+		if _, err = f.Seek(offset, io.SeekStart); err != nil {
+			return
+		}
+		n, _ := io.Copy(bj.outW, f)
+		offset += n
+	}
+
+	sleepTime := pollTime
+	nErr := 0
+
+	for {
+		isActive, err := bj.isActive()
+		if err != nil {
+			nErr++
+			omppLog.Log("Error at batch job status: ", bj.jobId, " [", nErr, "]: ", err.Error())
+
+			if nErr >= batchStatusMaxErrors {
+				omppLog.Log("Error: batch job status unknown, stop polling: ", bj.jobId, " ", bj.data.ModelName, " ", bj.data.RunStamp)
+				break
+			}
+			if sleepTime < batchPollMaxBackoff*pollTime {
+				sleepTime = 2 * sleepTime
+			}
+		} else {
+			nErr = 0
+			sleepTime = pollTime
+		}
+		if err == nil && !isActive || fileExist(bj.data.ExitPath) {
+			break // job completed
+		}
+		doCopy()
+		time.Sleep(sleepTime)
+	}
+	doCopy()
+
+	bj.outW.Close()
+	bj.errW.Close()
+	close(bj.doneC)
+}
+
+// return true if batch job is active: status command output is not empty.
+// Return error if status command failed, job status is unknown in that case.
+func (bj *batchJob) isActive() (bool, error) {
+
+	args := append(splitIniArgs(bj.cfg.statusArgs, bj.cfg.argsBreak), bj.jobId)
+
+	bt, err := exec.Command(bj.cfg.statusExe, args...).Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(bt)) != "", nil
+}
+
+// cancel batch job
+func (bj *batchJob) kill() error {
+
+	if bj.jobId == "" {
+		return errors.New("batch job is not started: " + bj.scriptPath)
+	}
+	args := append(splitIniArgs(bj.cfg.cancelArgs, bj.cfg.argsBreak), bj.jobId)
+
+	return exec.Command(bj.cfg.cancelExe, args...).Run()
+}
+
+// wait until batch job completed and return error if exit code not found or not zero
+func (bj *batchJob) wait() error {
+
+	<-bj.doneC
+
+	bt, err := os.ReadFile(bj.data.ExitPath)
+	if err != nil {
+		return errors.New("batch job exit code not found: " + bj.jobId + ": " + bj.data.ExitPath)
+	}
+	s := strings.TrimSpace(string(bt))
+	if s != "0" {
+		return errors.New("batch job exit code: " + s + ": " + bj.jobId)
+	}
+	return nil
+}
+
+// return batch submit executable and arguments
+func (bj *batchJob) commandLine() (string, []string) {
+	return bj.cfg.submitExe, append([]string{bj.cfg.submitExe}, append(splitIniArgs(bj.cfg.submitArgs, bj.cfg.argsBreak), bj.scriptPath)...)
+}
+
+// cpu time and memory usage of batch job is not available
+func (bj *batchJob) usage() RunUsage {
+	return RunUsage{}
+}
+
+// split job.ini arguments line by arguments delimiter, return empty list if line is empty
+func splitIniArgs(line, argsBreak string) []string {
+
+	if line == "" {
+		return []string{}
+	}
+	if argsBreak == "" {
+		return []string{line}
+	}
+	return strings.Split(line, argsBreak)
+}
+
+// return string quoted for POSIX shell
+func shellQuote(src string) string {
+	return "'" + strings.ReplaceAll(src, "'", `'\''`) + "'"
+}
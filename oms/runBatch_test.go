@@ -0,0 +1,91 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBatchJobPid(t *testing.T) {
+
+	for _, c := range []struct {
+		jobId string
+		pid   int
+	}{{"1234", 1234}, {"123.server", 123}, {"42;cluster", 42}, {"job-7", 0}, {"", 0}} {
+		if n := batchJobPid(c.jobId); n != c.pid {
+			t.Errorf("job id %q: expected pid %d, got %d", c.jobId, c.pid, n)
+		}
+	}
+}
+
+// fake batch scheduler: submit runs batch script in background and return job id 123.server,
+// first status call fails, next calls return job id until script exit code file created.
+func TestBatchJobFakeScheduler(t *testing.T) {
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake batch scheduler requires POSIX shell")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	exitPath := filepath.Join(dir, "modelOne.2026_10_19_01_02_03_456.batch.exit")
+
+	writeScript := func(name, body string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	cfg := batchIni{
+		isUse:       true,
+		submitExe:   writeScript("fake-submit.sh", "sh \"$1\" > /dev/null 2>&1 &\necho Submitted batch job 123.server\n"),
+		statusExe:   writeScript("fake-status.sh", "cnt="+shellQuote(filepath.Join(dir, "status.count"))+"\necho x >> \"$cnt\"\n[ $(wc -l < \"$cnt\") -eq 1 ] && exit 1\n[ -f "+shellQuote(exitPath)+" ] || echo \"$1 RUNNING\"\n"),
+		scriptDir:   dir,
+		pollSeconds: 1,
+	}
+
+	job := &RunJob{RunRequest: RunRequest{ModelName: "modelOne", Env: map[string]string{"OM_TEST": "fake batch"}}}
+	rs := &RunState{ModelName: "modelOne", RunStamp: "2026_10_19_01_02_03_456"}
+	cmd := exec.Command("sh", "-c", "sleep 1; echo model output: $OM_TEST")
+
+	bj, err := newBatchJob(cfg, cmd, job, rs, "", dir, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outR, errR, err := bj.outputPipes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outC := make(chan string)
+	go func() {
+		bt, _ := io.ReadAll(outR)
+		outC <- string(bt)
+	}()
+	go io.Copy(io.Discard, errR)
+
+	pid, err := bj.start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != 123 || bj.jobId != "123.server" {
+		t.Errorf("invalid batch job id: %s pid: %d", bj.jobId, pid)
+	}
+	if err = bj.wait(); err != nil {
+		t.Fatal(err)
+	}
+	if out := <-outC; !strings.Contains(out, "model output: fake batch") {
+		t.Errorf("invalid model output: %q", out)
+	}
+	if bt, err := os.ReadFile(filepath.Join(dir, "status.count")); err != nil || strings.Count(string(bt), "x") < 2 {
+		t.Errorf("expected status polling after status command error: %q %v", string(bt), err)
+	}
+}
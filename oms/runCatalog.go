@@ -95,16 +95,34 @@ type RunRequest struct {
 
 // RunJob is model run request and run job control: submission stamp and model process id
 type RunJob struct {
-	SubmitStamp string // submission timestamp
-	Pid         int    // process id
-	CmdPath     string // executable path
-	RunRequest         // model run request: model name, digest and run options
-	Res         RunRes // job run resources: CPU cores and memory
-	IsOverLimit bool   // if true then job run resource(s) exceed limit(s)
-	QueuePos    int    // one-based position of MPI job in global queue or any (MPI or non-MPI) job in localhost queue
-	LogFileName string // log file name
-	LogPath     string // log file path: log/dir/modelName.RunStamp.console.log
-	IniPath     string // if not empty then actual ini file path, may be relative to log directory
+	SubmitStamp string    // submission timestamp
+	Pid         int       // process id
+	CmdPath     string    // executable path
+	RunRequest            // model run request: model name, digest and run options
+	Res         RunRes    // job run resources: CPU cores and memory
+	IsOverLimit bool      // if true then job run resource(s) exceed limit(s)
+	QueuePos    int       // one-based position of MPI job in global queue or any (MPI or non-MPI) job in localhost queue
+	LogFileName string    // log file name
+	LogPath     string    // log file path: log/dir/modelName.RunStamp.console.log
+	IniPath     string    // if not empty then actual ini file path, may be relative to log directory
+	Resume      RunResume // if resume run id is not zero then it is a resume of incomplete model run
+	Usage       RunUsage  // actual resources used by completed model run
+}
+
+// RunResume is incomplete model run to resume from first not completed sub-value
+type RunResume struct {
+	RunId     int    // run id of incomplete model run
+	RunDigest string // run digest of incomplete model run
+	SubFrom   int    // zero-based index of first not completed sub-value
+	SubCount  int    // total number of sub-values in that model run
+}
+
+// RunUsage is actual resources used by model run: wall time, cpu time and peak memory.
+// Cpu time and memory are available only if model run as local process.
+type RunUsage struct {
+	WallTimeMs int64 // wall time in milliseconds
+	CpuTimeMs  int64 // user and system cpu time in milliseconds
+	MaxRssMb   int   // peak resident memory size in megabytes
 }
 
 // RunRes is model run computational resources
@@ -147,14 +165,16 @@ type queueJobFile struct {
 
 // job control file info for history job: parts of file name
 type historyJobFile struct {
-	filePath    string // job control file path
-	isError     bool   // if true then ignore that file due to error
-	SubmitStamp string // submission timestamp
-	ModelName   string // model name
-	ModelDigest string // model digest
-	RunStamp    string // run stamp, if empty then auto-generated as timestamp
-	JobStatus   string // run status
-	RunTitle    string // model run title: run name, task run name or workset name
+	filePath    string   // job control file path
+	isError     bool     // if true then ignore that file due to error
+	SubmitStamp string   // submission timestamp
+	ModelName   string   // model name
+	ModelDigest string   // model digest
+	RunStamp    string   // run stamp, if empty then auto-generated as timestamp
+	JobStatus   string   // run status
+	RunTitle    string   // model run title: run name, task run name or workset name
+	Usage       RunUsage // actual resources used by model run
+	oms         string   // oms instance name
 }
 
 // RunState is model run state.
@@ -194,29 +214,32 @@ type RunStateLogPage struct {
 
 // JobServiceState is a service state and job control state, it should NOT have any reference types members
 type JobServiceState struct {
-	IsQueuePaused     bool       // this oms instance: if true then jobs queue is paused, jobs are not selected from queue
-	IsAllQueuePaused  bool       // all oms instances: if true then jobs queue is paused, jobs are not selected from queue
-	JobUpdateDateTime string     // last date-time jobs list updated
-	MpiRes            ComputeRes // MPI total available resources available (CPU cores and memory) as sum of all servers or localhost resources
-	MaxOwnMpiRes      ComputeRes // resources limit (CPU cores and memory) for each oms instance
-	ActiveTotalRes    ComputeRes // MPI active run resources (CPU cores and memory) used by all oms instances
-	ActiveOwnRes      ComputeRes // MPI active run resources (CPU cores and memory) used by this oms instance
-	QueueTotalRes     ComputeRes // MPI queue run resources (CPU cores and memory) requested by all oms instances
-	QueueOwnRes       ComputeRes // MPI queue run resources (CPU cores and memory) requested by this oms instance
-	MpiErrorRes       ComputeRes // MPI computational resources on "error" servers
-	MpiMaxThreads     int        // max number of modelling threads per MPI process, zero means unlimited
-	LocalRes          ComputeRes // localhost non-MPI jobs total resources limits
-	LocalActiveRes    ComputeRes // localhost non-MPI jobs resources used by this instance to run models
-	LocalQueueRes     ComputeRes // localhost non-MPI jobs queue resources for this oms instance
-	isLeader          bool       // if true then this oms instance is a leader
-	maxStartTime      int64      // max time in milliseconds to start compute server or cluster
-	maxStopTime       int64      // max time in milliseconds to stop compute server or cluster
-	maxIdleTime       int64      // max idle in milliseconds time before stopping server or cluster
-	lastStartStopTs   int64      // last time when start or stop of computational servers done
-	maxComputeErrors  int        // errors threshold for compute server or cluster
-	jobLastPosition   int        // last job position in the queue
-	jobFirstPosition  int        // minimal job position in the queue
-	hostFile          hostIni    // MPI jobs hostfile settings
+	IsQueuePaused     bool         // this oms instance: if true then jobs queue is paused, jobs are not selected from queue
+	IsAllQueuePaused  bool         // all oms instances: if true then jobs queue is paused, jobs are not selected from queue
+	IsQueueDrain      bool         // this oms instance: if true then new jobs are not accepted and jobs queue is paused
+	JobUpdateDateTime string       // last date-time jobs list updated
+	MpiRes            ComputeRes   // MPI total available resources available (CPU cores and memory) as sum of all servers or localhost resources
+	MaxOwnMpiRes      ComputeRes   // resources limit (CPU cores and memory) for each oms instance
+	ActiveTotalRes    ComputeRes   // MPI active run resources (CPU cores and memory) used by all oms instances
+	ActiveOwnRes      ComputeRes   // MPI active run resources (CPU cores and memory) used by this oms instance
+	QueueTotalRes     ComputeRes   // MPI queue run resources (CPU cores and memory) requested by all oms instances
+	QueueOwnRes       ComputeRes   // MPI queue run resources (CPU cores and memory) requested by this oms instance
+	MpiErrorRes       ComputeRes   // MPI computational resources on "error" servers
+	MpiMaxThreads     int          // max number of modelling threads per MPI process, zero means unlimited
+	LocalRes          ComputeRes   // localhost non-MPI jobs total resources limits
+	LocalActiveRes    ComputeRes   // localhost non-MPI jobs resources used by this instance to run models
+	LocalQueueRes     ComputeRes   // localhost non-MPI jobs queue resources for this oms instance
+	isLeader          bool         // if true then this oms instance is a leader
+	maxStartTime      int64        // max time in milliseconds to start compute server or cluster
+	maxStopTime       int64        // max time in milliseconds to stop compute server or cluster
+	maxIdleTime       int64        // max idle in milliseconds time before stopping server or cluster
+	lastStartStopTs   int64        // last time when start or stop of computational servers done
+	maxComputeErrors  int          // errors threshold for compute server or cluster
+	jobLastPosition   int          // last job position in the queue
+	jobFirstPosition  int          // minimal job position in the queue
+	hostFile          hostIni      // MPI jobs hostfile settings
+	batch             batchIni     // batch scheduler settings
+	container         containerIni // container run settings
 }
 
 // computational server or cluster state
@@ -259,6 +282,37 @@ type hostIni struct {
 	hostLine string // HostLine = @-HOST-@ slots=@-CORES-@
 }
 
+// Batch scheduler config from job.ini file: commands to submit, check status and cancel batch job
+type batchIni struct {
+	isUse       bool   // if true then submit model runs to batch scheduler
+	isMpiOnly   bool   // if true then only MPI model runs submitted to batch scheduler
+	submitExe   string // SubmitExe = sbatch
+	submitArgs  string // SubmitArgs = --parsable
+	statusExe   string // StatusExe = squeue
+	statusArgs  string // StatusArgs = -h-@--j
+	cancelExe   string // CancelExe = scancel
+	cancelArgs  string // CancelArgs =
+	argsBreak   string // arguments delimiter, same as [Common] ArgsBreak
+	scriptDir   string // ScriptDir = models/log
+	pollSeconds int    // PollTimeout = 5
+}
+
+// Container config from job.ini file: docker or podman command to run the model inside of container
+type containerIni struct {
+	isUse     bool   // if true then run the model inside of container
+	exe       string // Exe = docker
+	image     string // Image = ubuntu:22.04
+	runArgs   string // RunArgs = --network=none
+	argsBreak string // arguments delimiter, same as [Common] ArgsBreak
+}
+
+// jobs queue of oms instance for export and import
+type jobQueueExport struct {
+	OmsName        string   // oms instance name
+	ExportDateTime string   // date-time of export
+	Queue          []RunJob // queue jobs in order of queue position
+}
+
 // job control state
 type jobControlState struct {
 	Queue []string // jobs queue
@@ -267,6 +321,15 @@ type jobControlState struct {
 // timeout in msec, wait on stdout and stderr polling.
 const logTickTimeout = 7
 
+// default batch job status polling interval in seconds
+const batchPollDefault = 5
+
+// max number of batch job status errors in a row, job status is unknown after that
+const batchStatusMaxErrors = 10
+
+// max multiplier of batch job status polling interval if status command failed
+const batchPollMaxBackoff = 12
+
 // file name of MPI model run template by default
 const defaultMpiTemplate = "mpi.ModelRun.template.txt"
 
@@ -0,0 +1,216 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openmpp/go/ompp/omppLog"
+)
+
+// containerProcess is a model run inside of container, for example: docker run or podman run.
+// Model directory, work directory and log directory are bind-mounted into container at the same path.
+type containerProcess struct {
+	localProcess        // container command line process: docker run ...image model.exe args
+	exe          string // container executable which started the container: [Container] Exe or template executable
+	name         string // container name
+}
+
+// arguments of container run template
+type containerTemplateData struct {
+	ModelName string            // model name
+	RunStamp  string            // model run stamp
+	Name      string            // container name
+	Image     string            // container image
+	RunArgs   []string          // additional arguments of container run command from job.ini
+	Mounts    []string          // directories to bind-mount into container at the same path
+	Dir       string            // work directory to run the model
+	BinDir    string            // bin directory where model exe is located
+	Exe       string            // model executable path
+	Args      []string          // model command line arguments
+	Env       map[string]string // environment variables to run the model
+	Cpu       int               // if not zero then container cpu limit
+	MemMb     int               // if not zero then container memory limit in megabytes
+}
+
+// return new container process to run the model.
+// Container run command line can be created from template: "container.ModelName.template.txt" or "container.ModelRun.template.txt".
+// If there is no template then command line is:
+//
+//	docker run --rm --name=... --cpus=... --memory=...m --volume=dir:dir --workdir=dir --env=key=val ...RunArgs image model.exe args
+func newContainerProcess(cfg containerIni, cmd *exec.Cmd, job *RunJob, rs *RunState, etcDir, binDir, workDir, logDir string) (*containerProcess, error) {
+
+	wd, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Dir != "" {
+		if wd, err = filepath.Abs(cmd.Dir); err != nil {
+			return nil, err
+		}
+	}
+	bd, err := filepath.Abs(binDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// model exe path inside of container must be absolute, it is the same as on the host
+	exe := cmd.Args[0]
+	if !filepath.IsAbs(exe) && strings.ContainsAny(exe, `/\`) {
+		exe = filepath.Join(wd, exe)
+	}
+
+	// bind-mount model bin directory, work directory, log directory and user files directory
+	mounts := []string{}
+	for _, d := range []string{bd, wd, logDir, theCfg.filesDir} {
+		if d == "" {
+			continue
+		}
+		p, e := filepath.Abs(d)
+		if e != nil {
+			return nil, e
+		}
+		isFound := false
+		for k := 0; !isFound && k < len(mounts); k++ {
+			isFound = mounts[k] == p
+		}
+		if !isFound {
+			mounts = append(mounts, p)
+		}
+	}
+
+	// container limits: cpu cores and memory
+	cpu := job.Res.Cpu
+	if cpu <= 0 {
+		cpu = job.Res.ThreadCount
+	}
+	memMb := job.Res.Mem * 1024
+	if memMb <= 0 {
+		memMb = job.Res.ProcessMemMb + job.Res.ThreadCount*job.Res.ThreadMemMb
+	}
+
+	d := containerTemplateData{
+		ModelName: rs.ModelName,
+		RunStamp:  rs.RunStamp,
+		Name:      containerName(theCfg.omsName, rs.ModelName, rs.RunStamp),
+		Image:     cfg.image,
+		RunArgs:   splitIniArgs(cfg.runArgs, cfg.argsBreak),
+		Mounts:    mounts,
+		Dir:       wd,
+		BinDir:    bd,
+		Exe:       exe,
+		Args:      []string{},
+		Env:       job.Env,
+		Cpu:       cpu,
+		MemMb:     memMb,
+	}
+	if len(cmd.Args) > 1 {
+		d.Args = cmd.Args[1:]
+	}
+	if d.Env == nil {
+		d.Env = map[string]string{}
+	}
+
+	// search for model-specific container template or default container template
+	tmplPath := ""
+	for _, tn := range []string{"container." + job.ModelName + ".template.txt", "container.ModelRun.template.txt"} {
+		if p := filepath.Join(etcDir, tn); etcDir != "" && fileExist(p) {
+			tmplPath = p
+			break
+		}
+	}
+
+	var cExe string
+	var cArgs []string
+
+	if tmplPath != "" {
+		if cExe, cArgs, err = templateCommand(tmplPath, d); err != nil {
+			return nil, errors.New("Error: " + err.Error() + ", cannot run the model in container: " + job.ModelName)
+		}
+	} else {
+		cExe, cArgs = cfg.exe, containerRunArgs(d)
+	}
+
+	return &containerProcess{
+		localProcess: localProcess{cmd: exec.Command(cExe, cArgs...)},
+		exe:          cExe,
+		name:         d.Name,
+	}, nil
+}
+
+// kill container by name, using the same container executable which started it, and kill container command line process
+func (cp *containerProcess) kill() error {
+
+	omppLog.Log("Kill container: ", cp.name)
+
+	err := exec.Command(cp.exe, "kill", cp.name).Run()
+	if e := cp.localProcess.kill(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// cpu time and memory usage of container command line process is not a model usage, it is not available
+func (cp *containerProcess) usage() RunUsage {
+	return RunUsage{}
+}
+
+// return container run command line arguments:
+//
+//	run --rm --name=... --cpus=... --memory=...m --volume=dir:dir --workdir=dir --env=key=val ...RunArgs image model.exe args
+func containerRunArgs(d containerTemplateData) []string {
+
+	args := []string{"run", "--rm", "--name=" + d.Name}
+
+	if d.Cpu > 0 {
+		args = append(args, "--cpus="+strconv.Itoa(d.Cpu))
+	}
+	if d.MemMb > 0 {
+		args = append(args, "--memory="+strconv.Itoa(d.MemMb)+"m")
+	}
+	for _, p := range d.Mounts {
+		args = append(args, "--volume="+p+":"+p)
+	}
+	args = append(args, "--workdir="+d.Dir)
+
+	// environment variables sorted by name to make command line reproducible
+	eKeys := make([]string, 0, len(d.Env))
+	for key, val := range d.Env {
+		if key != "" && val != "" {
+			eKeys = append(eKeys, key)
+		}
+	}
+	sort.Strings(eKeys)
+
+	for _, key := range eKeys {
+		args = append(args, "--env="+key+"="+d.Env[key])
+	}
+
+	args = append(args, d.RunArgs...)
+	args = append(args, d.Image, d.Exe)
+	return append(args, d.Args...)
+}
+
+// return container name: oms-modelName-runStamp where all characters except of [a-zA-Z0-9_.-] replaced by _
+func containerName(omsName, modelName, runStamp string) string {
+
+	src := "oms-" + modelName + "-" + runStamp
+	if omsName != "" {
+		src = omsName + "-" + modelName + "-" + runStamp
+	}
+
+	return strings.Map(
+		func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+				return r
+			}
+			return '_'
+		},
+		src)
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fake container executable: write command line arguments into dir/script.command.args file,
+// run: skip container options, change directory to --workdir, set --env variables and exec the model,
+// kill: kill the model started by run.
+func writeFakeContainer(t *testing.T, dir, name string) string {
+
+	p := filepath.Join(dir, name)
+	pidPath := shellQuote(filepath.Join(dir, name+".pid"))
+	body := "#!/bin/sh\n" +
+		"for a in \"$@\" ; do echo \"$a\" ; done > " + shellQuote(p) + ".\"$1\".args\n" +
+		"cmd=\"$1\"\nshift\n" +
+		"case \"$cmd\" in\n" +
+		"  run)\n" +
+		"    while [ $# -gt 0 ] ; do\n" +
+		"      case \"$1\" in\n" +
+		"        --workdir=*) cd \"${1#--workdir=}\" || exit 1 ;;\n" +
+		"        --env=*)     export \"${1#--env=}\" ;;\n" +
+		"        -*)          ;;\n" +
+		"        *)           break ;;\n" +
+		"      esac\n" +
+		"      shift\n" +
+		"    done\n" +
+		"    shift\n" +
+		"    echo $$ > " + pidPath + "\n" +
+		"    exec \"$@\" ;;\n" +
+		"  kill) kill $(cat " + pidPath + ") ;;\n" +
+		"  *) exit 1 ;;\n" +
+		"esac\n"
+
+	if err := os.WriteFile(p, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// return command line arguments of fake container command: run or kill
+func readFakeContainerArgs(t *testing.T, exePath, cmd string) []string {
+
+	bt, err := os.ReadFile(exePath + "." + cmd + ".args")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(bt)), "\n")
+}
+
+// fake docker: run the model with default container command line and check mounts, resource limits and model output
+func TestContainerFakeDockerRun(t *testing.T) {
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake container requires POSIX shell")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	binDir := filepath.Join(dir, "bin")
+	workDir := filepath.Join(dir, "work")
+	logDir := filepath.Join(dir, "log")
+	for _, d := range []string{binDir, workDir, logDir} {
+		if err := os.MkdirAll(d, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := containerIni{
+		isUse:   true,
+		exe:     writeFakeContainer(t, dir, "fake-docker.sh"),
+		image:   "fake:latest",
+		runArgs: "--network=none",
+	}
+	job := &RunJob{
+		RunRequest: RunRequest{ModelName: "modelOne", Env: map[string]string{"OM_TEST": "fake container"}},
+		Res:        RunRes{ComputeRes: ComputeRes{Cpu: 2, Mem: 1}, ThreadCount: 4},
+	}
+	rs := &RunState{ModelName: "modelOne", RunStamp: "2026_10_19_01_02_03_456"}
+	cmd := exec.Command("sh", "-c", "echo model output: $OM_TEST in $(pwd)")
+
+	cp, err := newContainerProcess(cfg, cmd, job, rs, "", binDir, workDir, logDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outR, errR, err := cp.outputPipes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outC := make(chan string)
+	go func() {
+		bt, _ := io.ReadAll(outR)
+		outC <- string(bt)
+	}()
+	go io.Copy(io.Discard, errR)
+
+	if _, err = cp.start(); err != nil {
+		t.Fatal(err)
+	}
+	out := <-outC
+	if err = cp.wait(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "model output: fake container in "+workDir) {
+		t.Errorf("invalid model output: %q", out)
+	}
+
+	args := readFakeContainerArgs(t, cfg.exe, "run")
+	for _, a := range []string{
+		"run",
+		"--rm",
+		"--name=oms-modelOne-2026_10_19_01_02_03_456",
+		"--cpus=2",
+		"--memory=1024m",
+		"--volume=" + binDir + ":" + binDir,
+		"--volume=" + workDir + ":" + workDir,
+		"--volume=" + logDir + ":" + logDir,
+		"--workdir=" + workDir,
+		"--env=OM_TEST=fake container",
+		"--network=none",
+		"fake:latest",
+	} {
+		if !slices.Contains(args, a) {
+			t.Errorf("container run argument not found: %q in %q", a, args)
+		}
+	}
+}
+
+// fake podman from model container template: kill must use template executable and not [Container] Exe,
+// if there is no explicit cpu and memory then container limits are threads count and process plus threads memory
+func TestContainerFakeTemplateKill(t *testing.T) {
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake container requires POSIX shell")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	podmanExe := writeFakeContainer(t, dir, "fake-podman.sh")
+
+	tmpl := podmanExe + "\n" +
+		"run\n" +
+		"--name={{.Name}}\n" +
+		"{{if .Cpu}}--cpus={{.Cpu}}{{end}}\n" +
+		"{{if .MemMb}}--memory={{.MemMb}}m{{end}}\n" +
+		"{{range .Mounts}}\n--volume={{.}}:{{.}}:Z\n{{end}}\n" +
+		"--workdir={{.Dir}}\n" +
+		"{{.Image}}\n" +
+		"{{.Exe}}\n" +
+		"{{range .Args}}\n{{.}}\n{{end}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "container.modelOne.template.txt"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := containerIni{
+		isUse: true,
+		exe:   writeFakeContainer(t, dir, "fake-docker.sh"),
+		image: "fake:latest",
+	}
+	job := &RunJob{
+		RunRequest: RunRequest{ModelName: "modelOne"},
+		Res:        RunRes{ThreadCount: 3, ProcessMemMb: 100, ThreadMemMb: 50},
+	}
+	rs := &RunState{ModelName: "modelOne", RunStamp: "2026_10_19_01_02_03_789"}
+	cmd := exec.Command("sleep", "30")
+
+	cp, err := newContainerProcess(cfg, cmd, job, rs, dir, dir, dir, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cp.start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait until model started inside of fake container
+	pidPath := podmanExe + ".pid"
+	for k := 0; k < 100 && !fileExist(pidPath); k++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err = cp.kill(); err != nil {
+		t.Error(err)
+	}
+	if err = cp.wait(); err == nil {
+		t.Error("expected model run error after container killed")
+	}
+
+	args := readFakeContainerArgs(t, podmanExe, "run")
+	for _, a := range []string{"--cpus=3", "--memory=250m", "--volume=" + dir + ":" + dir + ":Z", "--workdir=" + dir} {
+		if !slices.Contains(args, a) {
+			t.Errorf("container run argument not found: %q in %q", a, args)
+		}
+	}
+	if args = readFakeContainerArgs(t, podmanExe, "kill"); !slices.Equal(args, []string{"kill", "oms-modelOne-2026_10_19_01_02_03_789"}) {
+		t.Errorf("invalid container kill arguments: %q", args)
+	}
+	if fileExist(cfg.exe + ".kill.args") {
+		t.Error("container killed by [Container] Exe instead of template executable: " + cfg.exe)
+	}
+}
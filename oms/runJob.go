@@ -207,6 +207,24 @@ func (rsc *RunCatalog) getRunJobs() (JobServiceState, []string, []RunJob, []stri
 	return rsc.JobServiceState, qKeys, qJobs, aKeys, aJobs, hKeys, hJobs, cState
 }
 
+// Return batch scheduler settings from job.ini
+func (rsc *RunCatalog) getBatchIni() batchIni {
+
+	rsc.rscLock.Lock()
+	defer rsc.rscLock.Unlock()
+
+	return rsc.batch
+}
+
+// Return container settings from job.ini
+func (rsc *RunCatalog) getContainerIni() containerIni {
+
+	rsc.rscLock.Lock()
+	defer rsc.rscLock.Unlock()
+
+	return rsc.container
+}
+
 // Return active job control item and is found boolean flag
 func (rsc *RunCatalog) getActiveJobItem(submitStamp string) (runJobFile, bool) {
 
@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -98,6 +99,11 @@ func jobQueuePausedPath(oms string) string {
 	return filepath.Join(theCfg.jobDir, "state", "jobs.queue-#-"+oms+"-#-paused")
 }
 
+// Return this oms instance job queue drain file path e.g.: job/state/jobs.queue-#-_4040-#-drain
+func jobQueueDrainPath(oms string) string {
+	return filepath.Join(theCfg.jobDir, "state", "jobs.queue-#-"+oms+"-#-drain")
+}
+
 // return all job queue paused file path e.g.: job/state/jobs.queue.all.paused
 func jobAllQueuePausedPath() string {
 	return filepath.Join(theCfg.jobDir, "state", "jobs.queue.all.paused")
@@ -320,6 +326,21 @@ func parseQueuePausedPath(srcPath string) string {
 	return sp[1]
 }
 
+// Parse oms instance job queue drain file path e.g.: job/state/jobs.queue-#-_4040-#-drain
+// Return oms instance name.
+func parseQueueDrainPath(srcPath string) string {
+
+	p := filepath.Base(srcPath) // remove job state directory
+
+	// split file name and check result: it must be 3 non-empty parts
+	sp := strings.Split(p, "-#-")
+	if len(sp) != 3 || sp[0] != "jobs.queue" || sp[1] == "" || sp[2] != "drain" {
+		return "" // source file path is not job queue drain file
+	}
+
+	return sp[1]
+}
+
 // parse compute server or cluster ready file path and return server name, e.g.: job/state/comp-ready-#-name
 func parseCompReadyPath(srcPath string) string {
 
@@ -472,6 +493,30 @@ func moveJobToActive(queueJobPath string, rState *RunState, res RunRes, runStamp
 	return dst, true
 }
 
+// update active model run job control file with actual resources used by model run
+func updateActiveJobUsage(activePath string, usage RunUsage) bool {
+	if !theCfg.isJobControl || activePath == "" {
+		return true // job control disabled
+	}
+
+	var jc RunJob
+	isOk, err := helper.FromJsonFile(activePath, &jc)
+	if err != nil {
+		omppLog.Log(err)
+	}
+	if !isOk || err != nil {
+		return false
+	}
+	jc.Usage = usage
+
+	err = helper.ToJsonIndentFile(activePath, &jc)
+	if err != nil {
+		omppLog.Log(err)
+		return false
+	}
+	return true
+}
+
 // move active model run job control file to history
 func moveActiveJobToHistory(activePath, status string, submitStamp, modelName, modelDigest, runStamp string) bool {
 	if !theCfg.isJobControl {
@@ -537,10 +582,10 @@ func moveJobQueueToFailed(queuePath string, submitStamp, modelName, modelDigest,
 	return true
 }
 
-// read run title from job json file: return run name or task run name or workset name
-func getJobRunTitle(filePath string) string {
+// read run title and resources usage from job json file: return run name or task run name or workset name
+func getJobRunTitleUsage(filePath string) (string, RunUsage) {
 	if !theCfg.isJobControl {
-		return "" // job control disabled
+		return "", RunUsage{} // job control disabled
 	}
 
 	// read run request from job queue
@@ -550,7 +595,7 @@ func getJobRunTitle(filePath string) string {
 		omppLog.Log(err)
 	}
 	if !isOk || err != nil {
-		return ""
+		return "", RunUsage{}
 	}
 
 	// find run name or task run or workset name in model run options
@@ -577,12 +622,12 @@ func getJobRunTitle(filePath string) string {
 	}
 
 	if runName != "" {
-		return runName
+		return runName, jc.Usage
 	}
 	if taskRunName != "" {
-		return taskRunName
+		return taskRunName, jc.Usage
 	}
-	return wsName
+	return wsName, jc.Usage
 }
 
 // Remove all existing oms heart beat tick files and create new oms heart beat tick file with current timestamp and last run stamp.
@@ -652,9 +697,51 @@ func deleteCompStateFiles(name, state string) bool {
 	return isNoError
 }
 
-// Return true if jobs queue processing is paused for this oms instance
+// Return true if jobs queue processing is paused for this oms instance, queue is also paused if this oms instance is draining
 func isPausedJobQueue() bool {
-	return fileExist(jobQueuePausedPath(theCfg.omsName)) || fileExist(jobAllQueuePausedPath())
+	return fileExist(jobQueuePausedPath(theCfg.omsName)) || fileExist(jobAllQueuePausedPath()) || isDrainJobQueue()
+}
+
+// Return true if this oms instance is draining: new jobs are not accepted and queue processing is paused
+func isDrainJobQueue() bool {
+	return fileExist(jobQueueDrainPath(theCfg.omsName))
+}
+
+// Return true if oms instance is alive: heart beat tick file updated less than one minute ago
+func isOmsAlive(oms string) bool {
+
+	minTs := time.Now().Add(-1 * time.Minute).UnixMilli()
+
+	fl := filesByPattern(filepath.Join(theCfg.jobDir, "state", "oms-#-"+oms+"-#-*-#-*"), "Error at oms heart beat files search")
+	for _, f := range fl {
+		if name, _, ts, _ := parseOmsTickPath(f); name == oms && ts > minTs {
+			return true
+		}
+	}
+	return false
+}
+
+// Return list of this oms instance queue job files sorted by queue position
+func ownQueueJobFiles() []string {
+
+	fl := filesByPattern(
+		filepath.Join(theCfg.jobDir, "queue", "*-#-"+theCfg.omsName+"-#-*-#-*-#-*-#-cpu-#-*-#-mem-#-*.json"),
+		"Error at queue job files search")
+
+	pLst := []string{}
+	posLst := map[string]int{}
+
+	for _, f := range fl {
+		stamp, oms, _, _, _, _, _, _, _, pos := parseQueuePath(f)
+		if stamp == "" || oms != theCfg.omsName {
+			continue // file name is not a job file name or it is from other oms instance
+		}
+		pLst = append(pLst, f)
+		posLst[f] = pos
+	}
+	sort.SliceStable(pLst, func(i, j int) bool { return posLst[pLst[i]] < posLst[pLst[j]] })
+
+	return pLst
 }
 
 // Return true if jobs queue processing is paused for all oms instances
@@ -42,8 +42,10 @@ func scanStateJobs(doneC <-chan bool) {
 	// if oms instance file does not have last run stamp then use current date-time stamp
 	// oms instance heart beat tick:  oms-#-_4040-#-2022_07_08_23_45_12_123-#-1257894000000-#-2022_08_17_21_56_34_321
 	// oms instance job queue paused: jobs.queue-#-_4040-#-paused
+	// oms instance job queue drain:  jobs.queue-#-_4040-#-drain
 	omsTickPtrn := filepath.Join(theCfg.jobDir, "state") + string(filepath.Separator) + "oms-#-*-#-*-#-*"
 	omsPausedPtrn := filepath.Join(theCfg.jobDir, "state") + string(filepath.Separator) + "jobs.queue-#-*-#-paused"
+	omsDrainPtrn := filepath.Join(theCfg.jobDir, "state") + string(filepath.Separator) + "jobs.queue-#-*-#-drain"
 
 	// compute servers or clusters:
 	// server ready: comp-ready-#-name
@@ -78,6 +80,7 @@ func scanStateJobs(doneC <-chan bool) {
 		historyFiles := filesByPattern(historyPtrn, "Error at history job files search")
 		omsTickFiles := filesByPattern(omsTickPtrn, "Error at oms heart beat files search")
 		omsPausedFiles := filesByPattern(omsPausedPtrn, "Error at queue paused files search")
+		omsDrainFiles := filesByPattern(omsDrainPtrn, "Error at queue drain files search")
 		compReadyFiles := filesByPattern(compReadyPtrn, "Error at server ready files search")
 		compStartFiles := filesByPattern(compStartPtrn, "Error at server start files search")
 		compStopFiles := filesByPattern(compStopPtrn, "Error at server stop files search")
@@ -123,6 +126,13 @@ func scanStateJobs(doneC <-chan bool) {
 				omsPaused[oms] = true
 			}
 		}
+		for _, fp := range omsDrainFiles {
+
+			oms := parseQueueDrainPath(fp)
+			if oms != "" {
+				omsPaused[oms] = true // draining oms instance queue is paused
+			}
+		}
 
 		// computational resources state
 		// for each server or cluster detect current state: ready, start, stop or power off
@@ -243,6 +253,8 @@ func scanStateJobs(doneC <-chan bool) {
 			}
 
 			// add job into history jobs list
+			title, usage := getJobRunTitleUsage(f)
+
 			historyJobs[subStamp] = historyJobFile{
 				filePath:    f,
 				isError:     (mn == "" || dgst == "" || rStamp == "" || status == ""),
@@ -251,7 +263,9 @@ func scanStateJobs(doneC <-chan bool) {
 				ModelDigest: dgst,
 				RunStamp:    rStamp,
 				JobStatus:   status,
-				RunTitle:    getJobRunTitle(f),
+				RunTitle:    title,
+				Usage:       usage,
+				oms:         oms,
 			}
 		}
 
@@ -894,6 +908,7 @@ func initJobComputeState(jobIniPath string, updateTs time.Time, computeState map
 	jsState := JobServiceState{
 		IsQueuePaused:     isPausedJobQueue(),
 		IsAllQueuePaused:  isPausedJobAllQueue(),
+		IsQueueDrain:      isDrainJobQueue(),
 		JobUpdateDateTime: helper.MakeDateTime(updateTs),
 		maxStartTime:      serverTimeoutDefault,
 		maxStopTime:       serverTimeoutDefault,
@@ -953,6 +968,29 @@ func initJobComputeState(jobIniPath string, updateTs time.Time, computeState map
 	exeStart := opts.String("Common.StartExe")
 	exeStop := opts.String("Common.StopExe")
 	argsBreak := opts.String("Common.ArgsBreak")
+
+	// batch scheduler settings: submit, status and cancel commands
+	jsState.batch.submitExe = opts.String("Batch.SubmitExe")
+	jsState.batch.submitArgs = opts.String("Batch.SubmitArgs")
+	jsState.batch.statusExe = opts.String("Batch.StatusExe")
+	jsState.batch.statusArgs = opts.String("Batch.StatusArgs")
+	jsState.batch.cancelExe = opts.String("Batch.CancelExe")
+	jsState.batch.cancelArgs = opts.String("Batch.CancelArgs")
+	jsState.batch.scriptDir = opts.String("Batch.ScriptDir")
+	jsState.batch.pollSeconds = opts.Int("Batch.PollTimeout", batchPollDefault)
+	jsState.batch.isMpiOnly = opts.Bool("Batch.MpiOnly")
+	jsState.batch.argsBreak = argsBreak
+
+	jsState.batch.isUse = jsState.batch.submitExe != "" && jsState.batch.statusExe != "" && jsState.batch.cancelExe != ""
+
+	// container settings: docker or podman executable, image and additional run arguments
+	jsState.container.exe = opts.String("Container.Exe")
+	jsState.container.image = opts.String("Container.Image")
+	jsState.container.runArgs = opts.String("Container.RunArgs")
+	jsState.container.argsBreak = argsBreak
+
+	jsState.container.isUse = jsState.container.exe != "" && jsState.container.image != ""
+
 	argsStart := splitOpts("Common.StartArgs", argsBreak)
 	argsStop := splitOpts("Common.StopArgs", argsBreak)
 
@@ -135,6 +135,36 @@ func (rsc *RunCatalog) runModel(job *RunJob, queueJobPath string, hfCfg hostIni,
 		return rs, errors.New("Error at starting model " + rs.ModelName + ": " + err.Error())
 	}
 
+	// run the model as local process, inside of container or submit model run to batch scheduler
+	// MPI model runs are not using container
+	var mp modelProcess = newLocalProcess(cmd)
+
+	bCfg := rsc.getBatchIni()
+	isBatch := bCfg.isUse && (job.IsMpi || !bCfg.isMpiOnly)
+
+	if cCfg := rsc.getContainerIni(); !isBatch && cCfg.isUse && !job.IsMpi {
+
+		cp, e := newContainerProcess(cCfg, cmd, job, rs, rsc.etcDir, binDir, wDir, mb.logDir)
+		if e != nil {
+			omppLog.Log("Error at starting model: ", e)
+			moveJobQueueToFailed(queueJobPath, rs.SubmitStamp, rs.ModelName, rs.ModelDigest, rStamp)
+			rs.IsFinal = true
+			return rs, errors.New("Error at starting model " + rs.ModelName + ": " + e.Error())
+		}
+		mp = cp
+	}
+	if isBatch {
+
+		bj, e := newBatchJob(bCfg, cmd, job, rs, rsc.etcDir, wDir, mb.logDir)
+		if e != nil {
+			omppLog.Log("Error at starting model: ", e)
+			moveJobQueueToFailed(queueJobPath, rs.SubmitStamp, rs.ModelName, rs.ModelDigest, rStamp)
+			rs.IsFinal = true
+			return rs, errors.New("Error at starting model " + rs.ModelName + ": " + e.Error())
+		}
+		mp = bj
+	}
+
 	// create job usage file for each computational server
 	isErr := false
 	for k := 0; !isErr && k < len(compUse); k++ {
@@ -151,11 +181,7 @@ func (rsc *RunCatalog) runModel(job *RunJob, queueJobPath string, hfCfg hostIni,
 	}
 
 	// connect console output to log line array
-	outPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		return cleanAndReturn(err, rs, queueJobPath, compUse)
-	}
-	errPipe, err := cmd.StderrPipe()
+	outPipe, errPipe, err := mp.outputPipes()
 	if err != nil {
 		return cleanAndReturn(err, rs, queueJobPath, compUse)
 	}
@@ -187,11 +213,13 @@ func (rsc *RunCatalog) runModel(job *RunJob, queueJobPath string, hfCfg hostIni,
 	if rs.logPath != "" {
 		omppLog.Log("Run model: ", mExe, " log: ", rs.logPath)
 	}
-	omppLog.Log(strings.Join(cmd.Args, " "))
-	rs.cmdPath = cmd.Path
+	cmdPath, cmdArgs := mp.commandLine()
+	omppLog.Log(strings.Join(cmdArgs, " "))
+	rs.cmdPath = cmdPath
 	rsc.updateRunStateProcess(rs, false)
 
-	err = cmd.Start()
+	tStart := time.Now()
+	rs.pid, err = mp.start()
 	if err != nil {
 		omppLog.Log("Model run error: ", err)
 		delComputeUse(compUse)
@@ -201,14 +229,13 @@ func (rsc *RunCatalog) runModel(job *RunJob, queueJobPath string, hfCfg hostIni,
 		return rs, err // exit with error: model failed to start
 	}
 	// else model started
-	rs.pid = cmd.Process.Pid
 	rsc.updateRunStateProcess(rs, false)
 
 	// move job file form queue to active
 	activeJobPath, _ := moveJobToActive(queueJobPath, rs, job.Res, rs.RunStamp, iniPath)
 
 	//  wait until run completed or terminated
-	go func(rState *RunState, cmd *exec.Cmd, jobPath string, cuLst []computeUse) {
+	go func(rState *RunState, mp modelProcess, jobPath string, cuLst []computeUse, resume RunResume) {
 
 		// wait until stdout and stderr closed
 		for outDoneC != nil || errDoneC != nil {
@@ -227,7 +254,7 @@ func (rsc *RunCatalog) runModel(job *RunJob, queueJobPath string, hfCfg hostIni,
 				}
 				if isKill && ok {
 					omppLog.Log("Kill run: ", rState.ModelName, " ", rState.ModelDigest, " ", rState.RunName, " ", rState.RunStamp)
-					if e := cmd.Process.Kill(); e != nil {
+					if e := mp.kill(); e != nil {
 						omppLog.Log(e)
 					}
 				}
@@ -235,25 +262,48 @@ func (rsc *RunCatalog) runModel(job *RunJob, queueJobPath string, hfCfg hostIni,
 			}
 		}
 
-		// wait for model run to be completed
-		e := cmd.Wait()
+		// wait for model run to be completed and save actual resources usage in job control file
+		e := mp.wait()
+
+		ru := mp.usage()
+		ru.WallTimeMs = time.Since(tStart).Milliseconds()
+		updateActiveJobUsage(jobPath, ru)
+
 		if e != nil {
 			omppLog.Log("Model run error: ", e)
 			delComputeUse(cuLst)
 			rsc.updateRunStateLog(rState, true, e.Error())
 			moveActiveJobToHistory(jobPath, db.ErrorRunStatus, rState.SubmitStamp, rState.ModelName, rState.ModelDigest, rState.RunStamp)
-			_, e = theCatalog.UpdateRunStatus(rState.ModelDigest, rState.RunStamp, db.ErrorRunStatus)
+			if resume.RunId > 0 {
+				_, e = theCatalog.UpdateResumedRunStatus(rState.ModelDigest, resume.RunId)
+			} else {
+				_, e = theCatalog.UpdateRunStatus(rState.ModelDigest, rState.RunStamp, db.ErrorRunStatus)
+			}
 			if e != nil {
 				omppLog.Log(e)
 			}
 			return
 		}
 		// else: completed OK
+
+		// if this is resume of incomplete run then update run status and run value digest
+		jobStatus := db.DoneRunStatus
+		if resume.RunId > 0 {
+
+			st, e := theCatalog.UpdateResumedRunStatus(rState.ModelDigest, resume.RunId)
+			if e != nil {
+				omppLog.Log(e)
+			}
+			if st != db.DoneRunStatus {
+				omppLog.Log("Model run is not completed: ", rState.ModelName, " ", rState.ModelDigest, " run id: ", resume.RunId)
+				jobStatus = db.ErrorRunStatus
+			}
+		}
 		rsc.updateRunStateLog(rState, true, "")
 		delComputeUse(cuLst)
-		moveActiveJobToHistory(jobPath, db.DoneRunStatus, rState.SubmitStamp, rState.ModelName, rState.ModelDigest, rState.RunStamp)
+		moveActiveJobToHistory(jobPath, jobStatus, rState.SubmitStamp, rState.ModelName, rState.ModelDigest, rState.RunStamp)
 
-	}(rs, cmd, activeJobPath, compUse)
+	}(rs, mp, activeJobPath, compUse, job.Resume)
 
 	return rs, nil
 }
@@ -298,12 +348,6 @@ func (rsc *RunCatalog) makeCommand(mExe, binDir, workDir, dbPath string, mArgs [
 	// if template specified then process template to get exe name and arguments
 	if isTmpl {
 
-		// parse template
-		tmpl, err := template.ParseFiles(filepath.Join(rsc.etcDir, req.Template))
-		if err != nil {
-			return nil, err
-		}
-
 		// set template parameters
 		wd, err := filepath.Abs(workDir)
 		if err != nil {
@@ -336,38 +380,11 @@ func (rsc *RunCatalog) makeCommand(mExe, binDir, workDir, dbPath string, mArgs [
 			Env:       req.Env,
 		}
 
-		// execute template and convert results in array of text lines
-		var b strings.Builder
-
-		err = tmpl.Execute(&b, d)
+		// execute template and make command
+		cExe, cArgs, err := templateCommand(filepath.Join(rsc.etcDir, req.Template), d)
 		if err != nil {
-			return nil, err
+			return nil, errors.New("Error: " + err.Error() + ", cannot run the model: " + req.ModelName)
 		}
-		tLines := strings.Split(strings.ReplaceAll(b.String(), "\r", "\n"), "\n")
-
-		// from template processing results get:
-		//   exe name as first non-empty line
-		//   use all other non-empty lines as command line arguments
-		cExe := ""
-		cArgs := []string{}
-
-		for k := range tLines {
-
-			cl := strings.TrimSpace(tLines[k])
-			if cl == "" {
-				continue
-			}
-			if cExe == "" {
-				cExe = cl
-			} else {
-				cArgs = append(cArgs, cl)
-			}
-		}
-		if cExe == "" {
-			return nil, errors.New("Error: empty template processing results, cannot run the model: " + req.ModelName)
-		}
-
-		// make command
 		cmd = exec.Command(cExe, cArgs...)
 	}
 
@@ -392,6 +409,46 @@ func (rsc *RunCatalog) makeCommand(mExe, binDir, workDir, dbPath string, mArgs [
 	return cmd, nil
 }
 
+// templateCommand return command exe name and arguments from template processing results:
+//
+//	exe name as first non-empty line
+//	use all other non-empty lines as command line arguments
+func templateCommand(tmplPath string, data any) (string, []string, error) {
+
+	tmpl, err := template.ParseFiles(tmplPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// execute template and convert results in array of text lines
+	var b strings.Builder
+
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", nil, err
+	}
+	tLines := strings.Split(strings.ReplaceAll(b.String(), "\r", "\n"), "\n")
+
+	cExe := ""
+	cArgs := []string{}
+
+	for k := range tLines {
+
+		cl := strings.TrimSpace(tLines[k])
+		if cl == "" {
+			continue
+		}
+		if cExe == "" {
+			cExe = cl
+		} else {
+			cArgs = append(cArgs, cl)
+		}
+	}
+	if cExe == "" {
+		return "", nil, errors.New("empty template processing results: " + tmplPath)
+	}
+	return cExe, cArgs, nil
+}
+
 // RtopModelRun kill model run by run stamp
 // or remove run request from the queue by submit stamp or by run stamp.
 // Return submission stamp, job file path and two flags: if model run found and if model is runniing now
@@ -454,6 +511,7 @@ func makeRunArgsIni(binDir, workDir, logDir string, job *RunJob, rs *RunState) (
 	importDbLcDot := strings.ToLower("-ImportDb.")
 	microdataLcDot := strings.ToLower("-Microdata.")
 	dotRunDescrLc := strings.ToLower(".RunDescription")
+	restartLcDot := strings.ToLower("-OpenM.Restart")
 
 	entAttrs := theCatalog.entityAttrsByDigest(rs.ModelDigest)
 	descrNotes := []db.DescrNote{}
@@ -502,6 +560,11 @@ func makeRunArgsIni(binDir, workDir, logDir string, job *RunJob, rs *RunState) (
 		if strings.HasPrefix(strings.ToLower(key), importDbLcDot) {
 			continue // import database connection string not allowed as run option
 		}
+		// resume of incomplete run: restart run and sub-values options are set from resume request
+		if job.Resume.RunId > 0 &&
+			(strings.HasPrefix(strings.ToLower(key), restartLcDot) || strings.EqualFold(key, "-OpenM.SubValues")) {
+			continue
+		}
 
 		// directory value: substitute OM_USER_FILES if requierd and sanitize: it must be relative to oms root
 		if strings.EqualFold(key, "-OpenM.iniFile") || strings.EqualFold(key, "-ini") ||
@@ -609,6 +672,13 @@ func makeRunArgsIni(binDir, workDir, logDir string, job *RunJob, rs *RunState) (
 		mArgs = append(mArgs, "-OpenM.NotOnRoot")
 	}
 
+	// if this is resume of incomplete run then restart that run from first not completed sub-value
+	if job.Resume.RunId > 0 {
+		mArgs = append(mArgs, "-OpenM.RestartRunId", strconv.Itoa(job.Resume.RunId))
+		mArgs = append(mArgs, "-OpenM.RestartSubValue", strconv.Itoa(job.Resume.SubFrom))
+		mArgs = append(mArgs, "-OpenM.SubValues", strconv.Itoa(job.Resume.SubCount))
+	}
+
 	// create model run ini file if required to pass model run options
 	// merge ini file options with job model run options
 	//
@@ -45,6 +45,29 @@ func (rsc *RunCatalog) getRunStateBySubmitStamp(digest, submitStamp string) (boo
 	return true, rsl.RunState
 }
 
+// isRunActive return true if model run is running now or model run resume is in the queue or running now.
+// Model run is searched by model digest and run stamp, resume jobs are searched by model digest and run id.
+func (rsc *RunCatalog) isRunActive(modelDigest, runStamp string, runId int) bool {
+
+	rsc.rscLock.Lock()
+	defer rsc.rscLock.Unlock()
+
+	if rsl := rsc.findRunStateLog(modelDigest, runStamp); rsl != nil && !rsl.IsFinal {
+		return true
+	}
+	for _, qj := range rsc.queueJobs {
+		if qj.ModelDigest == modelDigest && qj.Resume.RunId == runId {
+			return true
+		}
+	}
+	for _, aj := range rsc.activeJobs {
+		if aj.ModelDigest == modelDigest && aj.Resume.RunId == runId {
+			return true
+		}
+	}
+	return false
+}
+
 // find runStateLog by model digest and run stamp or submit stamp
 // internal: use only inside of lock
 func (rsc *RunCatalog) findRunStateLog(modelDigest, stamp string) *runStateLog {
@@ -0,0 +1,28 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// return peak resident memory size in megabytes of completed process, rounded up
+func processMaxRssMb(ps *os.ProcessState) int {
+
+	ru, ok := ps.SysUsage().(*syscall.Rusage)
+	if !ok || ru == nil || ru.Maxrss <= 0 {
+		return 0
+	}
+
+	// max RSS is in bytes on MacOS and in kilobytes on Linux
+	kb := int64(ru.Maxrss)
+	if runtime.GOOS == "darwin" {
+		kb = (kb + 1023) / 1024
+	}
+	return int((kb + 1023) / 1024)
+}
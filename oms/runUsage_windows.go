@@ -0,0 +1,13 @@
+// Copyright (c) 2016 OpenM++
+// This code is licensed under the MIT license (see LICENSE.txt for details)
+
+package main
+
+import (
+	"os"
+)
+
+// peak memory size of completed process is not available on Windows
+func processMaxRssMb(ps *os.ProcessState) int {
+	return 0
+}
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"errors"
 	"slices"
+	"strconv"
 
 	"github.com/openmpp/go/ompp/db"
 	"github.com/openmpp/go/ompp/omppLog"
@@ -49,6 +50,76 @@ func (mc *ModelCatalog) UpdateRunStatus(dn, rdsn string, status string) (bool, e
 	return true, nil
 }
 
+// UpdateResumedRunStatus updates status of resumed model run and recalculate run value digest.
+// If all run sub-values completed successfully then run status is s=success else e=error.
+// Return run status.
+func (mc *ModelCatalog) UpdateResumedRunStatus(dn string, runId int) (string, error) {
+
+	if dn == "" {
+		omppLog.Log("Warning: invalid (empty) model digest and name")
+		return "", nil
+	}
+	_, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		omppLog.Log("Warning: model digest or name not found: ", dn)
+		return "", nil
+	}
+
+	// find model run by id
+	r, err := db.GetRun(dbConn, runId)
+	if err != nil {
+		omppLog.Log("Error at get model run: ", dn, ": ", runId, ": ", err.Error())
+		return "", err
+	}
+	if r == nil {
+		return "", errors.New("Error: model run not found: " + dn + ": " + strconv.Itoa(runId))
+	}
+
+	// check if all sub-values are completed
+	rpRs, err := db.GetRunProgress(dbConn, runId)
+	if err != nil {
+		omppLog.Log("Error at get run progress: ", dn, ": ", runId, ": ", err.Error())
+		return "", err
+	}
+	status := db.DoneRunStatus
+	if firstIncompleteSubValue(r.SubCount, rpRs) < r.SubCount {
+		status = db.ErrorRunStatus
+	}
+
+	// update run status and if run completed successfully then recalculate run value digest
+	err = db.UpdateRunStatus(dbConn, runId, status)
+	if err != nil {
+		omppLog.Log("Error at run status update: ", dn, ": ", runId, ": ", err.Error())
+		return "", err
+	}
+	if status == db.DoneRunStatus {
+
+		if _, err = db.UpdateRunValueDigest(dbConn, runId); err != nil {
+			omppLog.Log("Error at run value digest update: ", dn, ": ", runId, ": ", err.Error())
+			return "", err
+		}
+	}
+	return status, nil
+}
+
+// return zero-based index of first sub-value which is not completed successfully or sub-values count if all sub-values completed.
+func firstIncompleteSubValue(subCount int, progress []db.RunProgress) int {
+
+	isDone := make([]bool, subCount)
+
+	for _, p := range progress {
+		if p.SubId >= 0 && p.SubId < subCount && p.Status == db.DoneRunStatus {
+			isDone[p.SubId] = true
+		}
+	}
+	for k := range isDone {
+		if !isDone[k] {
+			return k
+		}
+	}
+	return subCount
+}
+
 // Start a separate thread to delete model run including output table values, input parameters and microdata.
 func (mc *ModelCatalog) DeleteRunStart(dn, rdsn string) (bool, error) {
 
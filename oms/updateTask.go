@@ -4,6 +4,7 @@
 package main
 
 import (
+	"database/sql"
 	"errors"
 
 	"github.com/openmpp/go/ompp/db"
@@ -83,6 +84,115 @@ func (mc *ModelCatalog) UpdateTaskDef(isReplace bool, tpd *db.TaskDefPub) (bool,
 	return true, dn, tn, nil
 }
 
+// CreateSweepTask create parameter sweep worksets and modeling task with all sweep worksets.
+// One workset created for each combination of sweep parameter values, worksets are read-only and ready to run the model.
+// Return task definition: task name and list of sweep worksets.
+func (mc *ModelCatalog) CreateSweepTask(sw *db.SweepPub) (bool, *db.TaskDefPub, error) {
+
+	if sw == nil {
+		omppLog.Log("Error: invalid (empty) parameter sweep")
+		return false, nil, errors.New("Error: invalid (empty) parameter sweep")
+	}
+	return mc.createWorksetsTask(sw.ModelDigest, sw.ModelName, sw.Name, sw.Txt, "parameter sweep",
+		func(dbConn *sql.DB, meta *db.ModelMeta, langMeta *db.LangMeta) (*db.TaskDefPub, error) {
+			rules, err := mc.ParamRules(meta.Model.Digest, "")
+			if err != nil {
+				return nil, err
+			}
+			return db.CreateSweepWorksets(dbConn, meta, langMeta, sw, rules)
+		})
+}
+
+// CreateSampleTask create worksets from samples of uncertain parameters and modeling task with all sample worksets.
+// One workset created for each sample, worksets are read-only and ready to run the model.
+// Random seed and sampling plan are stored in task notes.
+// Return task definition: task name and list of sample worksets.
+func (mc *ModelCatalog) CreateSampleTask(sp *db.SamplePub) (bool, *db.TaskDefPub, error) {
+
+	if sp == nil {
+		omppLog.Log("Error: invalid (empty) sampling plan")
+		return false, nil, errors.New("Error: invalid (empty) sampling plan")
+	}
+	return mc.createWorksetsTask(sp.ModelDigest, sp.ModelName, sp.Name, sp.Txt, "parameter sampling",
+		func(dbConn *sql.DB, meta *db.ModelMeta, langMeta *db.LangMeta) (*db.TaskDefPub, error) {
+			rules, err := mc.ParamRules(meta.Model.Digest, "")
+			if err != nil {
+				return nil, err
+			}
+			return db.CreateSampleWorksets(dbConn, meta, langMeta, sp, rules)
+		})
+}
+
+// createWorksetsTask create worksets using makeWorksets function and modeling task with all created worksets.
+// Modeling task must not exist, languages of task description and notes are matched to model languages.
+// Return task definition: task name and list of worksets.
+func (mc *ModelCatalog) createWorksetsTask(
+	digest, modelName, taskName string,
+	txt []db.DescrNote,
+	what string,
+	makeWorksets func(dbConn *sql.DB, meta *db.ModelMeta, langMeta *db.LangMeta) (*db.TaskDefPub, error),
+) (bool, *db.TaskDefPub, error) {
+
+	// validate parameters
+	dn := digest
+	if dn == "" {
+		dn = modelName
+	}
+	if dn == "" {
+		omppLog.Log("Warning: invalid (empty) model digest and name")
+		return false, nil, nil
+	}
+	if taskName == "" {
+		omppLog.Log("Warning: invalid (empty) ", what, " task name")
+		return false, nil, nil
+	}
+
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		omppLog.Log("Warning: model digest or name not found: ", dn)
+		return false, nil, nil
+	}
+
+	langMeta := mc.modelLangMeta(dn)
+	if langMeta == nil {
+		omppLog.Log("Error: invalid (empty) model language list: ", dn)
+		return false, nil, errors.New("Error: invalid (empty) model language list: " + dn)
+	}
+
+	// task must not exist
+	t, err := db.GetTaskByName(dbConn, meta.Model.ModelId, taskName)
+	if err != nil {
+		omppLog.Log("Error at get modeling task: ", dn, ": ", taskName, ": ", err.Error())
+		return false, nil, err
+	}
+	if t != nil {
+		omppLog.Log("Error: modeling task already exist: ", dn, ": ", taskName)
+		return false, nil, errors.New("Error: modeling task already exist: " + dn + ": " + taskName)
+	}
+
+	// match languages from request into model languages
+	for k := range txt {
+		lc := mc.languageCodeMatch(dn, txt[k].LangCode)
+		if lc != "" {
+			txt[k].LangCode = lc
+		}
+	}
+
+	// create worksets and modeling task from worksets
+	tpd, err := makeWorksets(dbConn, meta, langMeta)
+	if err != nil {
+		omppLog.Log("Error at create ", what, ": ", dn, ": ", taskName, ": ", err.Error())
+		return false, nil, err
+	}
+	omppLog.Log("Worksets created: ", len(tpd.Set), ": ", what, ": ", dn, ": ", taskName)
+
+	ok, _, _, err = mc.UpdateTaskDef(true, tpd)
+	if err != nil || !ok {
+		return false, tpd, err
+	}
+	return true, tpd, nil
+}
+
 // DeleteTask do delete modeling task, task run history from database.
 // Task run history deleted only from task_run_lst and task_run_set tables,
 // it does not delete model runs or any model input sets (worksets).
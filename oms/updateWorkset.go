@@ -16,7 +16,8 @@ import (
 )
 
 // UpdateWorksetReadonly update workset read-only status by model digest-or-name and workset name.
-func (mc *ModelCatalog) UpdateWorksetReadonly(dn, wsn string, isReadonly bool) (string, *db.WorksetRow, bool, error) {
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+func (mc *ModelCatalog) UpdateWorksetReadonly(dn, wsn string, isReadonly bool, ifUpdateDt string) (string, *db.WorksetRow, bool, error) {
 
 	// if model digest-or-name or workset name is empty then return empty results
 	if dn == "" {
@@ -45,7 +46,18 @@ func (mc *ModelCatalog) UpdateWorksetReadonly(dn, wsn string, isReadonly bool) (
 	}
 
 	// update workset readonly status
-	err = db.UpdateWorksetReadonly(dbConn, w.SetId, isReadonly)
+	// workset can be made read-only only if parameters values satisfy validation rules of the model
+	rules := []db.ParamRule{}
+	if isReadonly {
+		if rules, err = mc.ParamRules(dn, ""); err != nil {
+			omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+			return "", &db.WorksetRow{}, false, err
+		}
+	}
+	err = db.UpdateWorksetReadonly(dbConn, meta, w.SetId, isReadonly, rules, ifUpdateDt)
+	if re, ok := err.(*db.ParamRuleError); ok {
+		err = ruleViolationError("Failed to make workset read-only", dn, wsn, re.Violation)
+	}
 	if err != nil {
 		omppLog.Log("Error at update workset status: ", dn, ": ", wsn, ": ", err.Error())
 		return "", &db.WorksetRow{}, false, err // return empty result: workset select error
@@ -66,8 +78,11 @@ func (mc *ModelCatalog) UpdateWorksetReadonly(dn, wsn string, isReadonly bool) (
 }
 
 // UpdateWorkset update workset metadata: create new workset, replace existsing or merge metadata.
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+// If versionNote is not empty then current values of versionParam parameters saved as new workset version,
+// on replace all workset parameters also saved.
 // Return: isUpdated true/false flag, isEraseParam true/false warining flag, workset_lst db row and error
-func (mc *ModelCatalog) UpdateWorkset(isReplace bool, wp *db.WorksetPub) (bool, bool, *db.WorksetRow, error) {
+func (mc *ModelCatalog) UpdateWorkset(isReplace bool, wp *db.WorksetPub, ifUpdateDt string, versionNote string, versionParam []string) (bool, bool, *db.WorksetRow, error) {
 
 	// if model digest-or-name or workset name is empty then return empty results
 	dn := wp.ModelDigest
@@ -141,7 +156,19 @@ func (mc *ModelCatalog) UpdateWorkset(isReplace bool, wp *db.WorksetPub) (bool,
 		}
 	}
 
-	// update workset metadata
+	// read-only workset parameters values must satisfy validation rules of the model
+	if wm.Set.IsReadonly {
+		if wm.ParamRules, err = mc.ParamRules(dn, ""); err != nil {
+			omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+			return false, isEraseParam, nil, err
+		}
+	}
+
+	// update workset metadata, workset update date-time is always set by database update
+	wm.Set.UpdateDateTime = ""
+	wm.IfUpdateDateTime = ifUpdateDt
+	wm.VersionNote = versionNote
+	wm.VersionParam = versionParam
 	err = wm.UpdateWorkset(dbConn, meta, isReplace, langMeta)
 	if err != nil {
 		omppLog.Log("Error at update workset: ", dn, ": ", wp.Name, ": ", err.Error())
@@ -163,7 +190,8 @@ func (mc *ModelCatalog) UpdateWorkset(isReplace bool, wp *db.WorksetPub) (bool,
 }
 
 // DeleteWorkset do delete workset, including parameter values from database.
-func (mc *ModelCatalog) DeleteWorkset(dn, wsn string) (bool, error) {
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+func (mc *ModelCatalog) DeleteWorkset(dn, wsn string, ifUpdateDt string) (bool, error) {
 
 	// if model digest-or-name or workset name is empty then return empty results
 	if dn == "" {
@@ -191,7 +219,7 @@ func (mc *ModelCatalog) DeleteWorkset(dn, wsn string) (bool, error) {
 	}
 
 	// delete workset from database
-	err = db.DeleteWorkset(dbConn, w.SetId)
+	err = db.DeleteWorkset(dbConn, w.SetId, ifUpdateDt)
 	if err != nil {
 		omppLog.Log("Error at delete workset: ", dn, ": ", wsn, ": ", err.Error())
 		return false, err
@@ -250,6 +278,12 @@ func (mc *ModelCatalog) UpdateWorksetParameter(
 		}
 	}
 
+	// parameter values must satisfy validation rules of the model
+	if wm.ParamRules, err = mc.ParamRules(dn, ""); err != nil {
+		omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+		return false, err
+	}
+
 	// convert cell from emun codes to enum id's
 	csvCvt := db.CellParamConverter{
 		ModelDef:  meta,
@@ -286,7 +320,8 @@ func (mc *ModelCatalog) UpdateWorksetParameter(
 }
 
 // UpdateWorksetParameterText do merge (insert or update) parameters value notes.
-func (mc *ModelCatalog) UpdateWorksetParameterText(dn, wsn string, pvtLst []db.ParamRunSetTxtPub) (bool, error) {
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+func (mc *ModelCatalog) UpdateWorksetParameterText(dn, wsn string, pvtLst []db.ParamRunSetTxtPub, ifUpdateDt string) (bool, error) {
 
 	// validate parameters
 	if pvtLst == nil || len(pvtLst) <= 0 {
@@ -348,7 +383,10 @@ func (mc *ModelCatalog) UpdateWorksetParameterText(dn, wsn string, pvtLst []db.P
 	}
 
 	// update workset parameter notes
-	err = db.UpdateWorksetParameterText(dbConn, meta, wsn, pvtLst, langMeta)
+	err = db.UpdateWorksetParameterText(dbConn, meta, wsn, pvtLst, langMeta, ifUpdateDt)
+	if err == db.ErrWorksetChanged {
+		return false, err
+	}
 	if err != nil {
 		return false, errors.New("Error at update workset parameter notes: " + dn + ": " + wsn + ": " + err.Error())
 	}
@@ -403,61 +441,19 @@ func (mc *ModelCatalog) UpdateWorksetParameterCsv(
 		}
 	}
 
+	// parameter values must satisfy validation rules of the model
+	if wm.ParamRules, err = mc.ParamRules(dn, ""); err != nil {
+		omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+		return false, err
+	}
+
 	// if csv file exist then read csv file and convert and append lines into cell list
 	var from func() (interface{}, error) = nil
 
 	if csvRd != nil {
-
-		// converter from csv row []string to db cell
-		csvCvt := db.CellParamConverter{
-			ModelDef:  meta,
-			Name:      param.Name,
-			DoubleFmt: theCfg.doubleFmt,
-		}
-		cvt, err := csvCvt.ToCell()
-		if err != nil {
-			return false, errors.New("invalid converter from csv row: " + err.Error())
-		}
-
-		// validate header line
-		fhs, e := csvRd.Read()
-		switch {
-		case e == io.EOF:
-			return false, errors.New("Inavlid (empty) csv parameter values " + param.Name)
-		case e != nil:
-			return false, errors.New("Failed to read csv parameter values " + param.Name + ": " + e.Error())
-		}
-		if chs, e := csvCvt.CsvHeader(); e != nil {
-			return false, errors.New("Error at building csv parameter header " + param.Name)
-		} else {
-			fh := strings.Join(fhs, ",")
-			if strings.HasPrefix(fh, string(helper.Utf8bom)) {
-				fh = fh[len(helper.Utf8bom):]
-			}
-			ch := strings.Join(chs, ",")
-			if fh != ch {
-				return false, errors.New("Invalid csv parameter header " + param.Name + ": " + fh + " expected: " + ch)
-			}
+		if from, err = paramCsvCellReader(meta, param.Name, csvRd); err != nil {
+			return false, err
 		}
-
-		// convert each line into cell (id cell)
-		from = func() (interface{}, error) {
-			row, err := csvRd.Read()
-			switch {
-			case err == io.EOF:
-				return nil, nil // eof
-			case err != nil:
-				return nil, errors.New("Failed to read csv parameter values " + param.Name)
-			}
-
-			// convert and append cell to cell list
-			c, err := cvt(row)
-			if err != nil {
-				return nil, errors.New("Failed to convert csv parameter values " + param.Name)
-			}
-			return c, nil
-		}
-
 	}
 
 	// update workset parameter metadata and parameter values
@@ -469,9 +465,67 @@ func (mc *ModelCatalog) UpdateWorksetParameterCsv(
 	return hId > 0, nil // return success and true if parameter was found
 }
 
+// paramCsvCellReader return reader of parameter cells from csv file: each call return next cell or nil at the end of file.
+// Csv file must start with header line, header is validated.
+func paramCsvCellReader(meta *db.ModelMeta, name string, csvRd *csv.Reader) (func() (interface{}, error), error) {
+
+	// converter from csv row []string to db cell
+	csvCvt := db.CellParamConverter{
+		ModelDef:  meta,
+		Name:      name,
+		DoubleFmt: theCfg.doubleFmt,
+	}
+	cvt, err := csvCvt.ToCell()
+	if err != nil {
+		return nil, errors.New("invalid converter from csv row: " + err.Error())
+	}
+
+	// validate header line
+	fhs, e := csvRd.Read()
+	switch {
+	case e == io.EOF:
+		return nil, errors.New("Inavlid (empty) csv parameter values " + name)
+	case e != nil:
+		return nil, errors.New("Failed to read csv parameter values " + name + ": " + e.Error())
+	}
+	if chs, e := csvCvt.CsvHeader(); e != nil {
+		return nil, errors.New("Error at building csv parameter header " + name)
+	} else {
+		fh := strings.Join(fhs, ",")
+		if strings.HasPrefix(fh, string(helper.Utf8bom)) {
+			fh = fh[len(helper.Utf8bom):]
+		}
+		ch := strings.Join(chs, ",")
+		if fh != ch {
+			return nil, errors.New("Invalid csv parameter header " + name + ": " + fh + " expected: " + ch)
+		}
+	}
+
+	// convert each line into cell (id cell)
+	from := func() (interface{}, error) {
+		row, err := csvRd.Read()
+		switch {
+		case err == io.EOF:
+			return nil, nil // eof
+		case err != nil:
+			return nil, errors.New("Failed to read csv parameter values " + name)
+		}
+
+		// convert and append cell to cell list
+		c, err := cvt(row)
+		if err != nil {
+			return nil, errors.New("Failed to convert csv parameter values " + name)
+		}
+		return c, nil
+	}
+	return from, nil
+}
+
 // UpdateWorksetParameterPage merge "page" of parameter values into workset.
 // Parameter must be already in workset and identified by model digest-or-name, set name, parameter name.
-func (mc *ModelCatalog) UpdateWorksetParameterPage(dn, wsn, name string, from func() (interface{}, error)) error {
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version.
+func (mc *ModelCatalog) UpdateWorksetParameterPage(dn, wsn, name string, ifUpdateDt string, versionNote string, from func() (interface{}, error)) error {
 
 	// if model digest-or-name, set name or paramete name is empty then return empty results
 	if dn == "" {
@@ -508,10 +562,12 @@ func (mc *ModelCatalog) UpdateWorksetParameterPage(dn, wsn, name string, from fu
 		return errors.New("Workset " + wsn + " not found in model: " + dn)
 	}
 	layout := db.WriteParamLayout{
-		WriteLayout: db.WriteLayout{Name: name, ToId: ws.SetId},
-		IsToRun:     false,
-		IsPage:      true,
-		DoubleFmt:   theCfg.doubleFmt,
+		WriteLayout:      db.WriteLayout{Name: name, ToId: ws.SetId},
+		IsToRun:          false,
+		IsPage:           true,
+		DoubleFmt:        theCfg.doubleFmt,
+		IfUpdateDateTime: ifUpdateDt,
+		VersionNote:      versionNote,
 	}
 
 	// parameter must be in workset already
@@ -524,12 +580,57 @@ func (mc *ModelCatalog) UpdateWorksetParameterPage(dn, wsn, name string, from fu
 	}
 	layout.SubCount = nSub
 
+	// parameter values must satisfy validation rules of the model
+	if layout.ParamRules, err = mc.ParamRules(dn, ""); err != nil {
+		return errors.New("Error at get parameter validation rules: " + dn + ": " + err.Error())
+	}
+
 	// write parameter values
 	return db.WriteParameterFrom(dbConn, meta, &layout, from)
 }
 
+// TransformWorksetParameter apply arithmetic operation to existing workset parameter values, ex.: value * 1.05 where Year >= 2030.
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version.
+func (mc *ModelCatalog) TransformWorksetParameter(dn, wsn string, tf *db.ParamTransform, ifUpdateDt string, versionNote string) error {
+
+	// validate parameters
+	if dn == "" {
+		return errors.New("Invalid (empty) model digest and name")
+	}
+	if wsn == "" {
+		return errors.New("Invalid (empty) workset name. Model: " + dn)
+	}
+	if tf == nil || tf.Name == "" {
+		return errors.New("Invalid (empty) parameter name. Model: " + dn + " workset: " + wsn)
+	}
+
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return errors.New("Error: model digest or name not found: " + dn)
+	}
+
+	// parameter values must satisfy validation rules of the model
+	rules, err := mc.ParamRules(dn, "")
+	if err != nil {
+		return errors.New("Error at get parameter validation rules: " + dn + ": " + err.Error())
+	}
+
+	// update parameter values
+	err = db.TransformWorksetParameter(dbConn, meta, wsn, tf, rules, ifUpdateDt, versionNote)
+	if err != nil {
+		if err != db.ErrWorksetChanged {
+			omppLog.Log("Error at update workset parameter: ", dn, ": ", wsn, ": ", tf.Name, ": ", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
 // DeleteWorksetParameter do delete workset parameter metadata and values from database.
-func (mc *ModelCatalog) DeleteWorksetParameter(dn, wsn, name string) (bool, error) {
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version.
+func (mc *ModelCatalog) DeleteWorksetParameter(dn, wsn, name string, ifUpdateDt string, versionNote string) (bool, error) {
 
 	// if model digest-or-name or workset name is empty then return empty results
 	if dn == "" {
@@ -552,7 +653,7 @@ func (mc *ModelCatalog) DeleteWorksetParameter(dn, wsn, name string) (bool, erro
 	}
 
 	// delete workset from database
-	hId, err := db.DeleteWorksetParameter(dbConn, meta.Model.ModelId, wsn, name)
+	hId, err := db.DeleteWorksetParameter(dbConn, meta, wsn, name, ifUpdateDt, versionNote)
 	if err != nil {
 		omppLog.Log("Error at update workset: ", dn, ": ", wsn, ": ", err.Error())
 		return false, err
@@ -565,7 +666,9 @@ func (mc *ModelCatalog) DeleteWorksetParameter(dn, wsn, name string) (bool, erro
 // If isReplace is false then existing parameter values and metadata deleted and new inserted from model run.
 // Destination workset must be in read-write state.
 // Source model run must be completed, run status one of: s=success, x=exit, e=error.
-func (mc *ModelCatalog) CopyParameterToWsFromRun(dn, wsn, name string, isReplace bool, rdsn string) error {
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version.
+func (mc *ModelCatalog) CopyParameterToWsFromRun(dn, wsn, name string, isReplace bool, rdsn string, ifUpdateDt string, versionNote string) error {
 
 	// validate parameters
 	if dn == "" {
@@ -620,20 +723,93 @@ func (mc *ModelCatalog) CopyParameterToWsFromRun(dn, wsn, name string, isReplace
 		return errors.New("Model not found or not completed: " + dn + ": " + rdsn)
 	}
 
+	// parameter values must satisfy validation rules of the model
+	rules, err := mc.ParamRules(dn, "")
+	if err != nil {
+		return errors.New("Error at get parameter validation rules: " + dn + ": " + err.Error())
+	}
+
 	// copy parameter into workset from model run
-	err := db.CopyParameterFromRun(dbConn, meta, ws, name, isReplace, r)
+	err = db.CopyParameterFromRun(dbConn, meta, ws, name, isReplace, r, rules, ifUpdateDt, versionNote)
+	if err == db.ErrWorksetChanged {
+		return err
+	}
 	if err != nil {
 		return errors.New("Parameter copy failed: " + wsn + ": " + name + ": " + err.Error())
 	}
 	return nil
 }
 
+// CreateWorksetFromRun create new workset from model run parameters: parameter values, sub-values and value notes.
+// If list of parameter names is empty then all model run parameters copied into new workset,
+// otherwise only listed parameters copied and the rest is inherited from the base run.
+// Model run is the base run of new workset, it must be completed, run status one of: s=success, x=exit, e=error.
+// Workset with the same name must not exist.
+func (mc *ModelCatalog) CreateWorksetFromRun(dn, rdsn, wsn string, paramNames []string, isReadonly bool, txt []db.DescrNote) (*db.WorksetRow, error) {
+
+	// validate parameters
+	if dn == "" {
+		return nil, errors.New("Workset create failed: invalid (empty) model digest and name")
+	}
+	if rdsn == "" {
+		return nil, errors.New("Workset create failed: invalid (empty) model run digest or stamp or name")
+	}
+	if wsn == "" {
+		return nil, errors.New("Workset create failed: invalid (empty) workset name")
+	}
+
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		return nil, errors.New("Model digest or name not found: " + dn)
+	}
+	langMeta := mc.modelLangMeta(dn)
+	if langMeta == nil {
+		return nil, errors.New("Error: invalid (empty) model language list: " + dn)
+	}
+
+	// find run by digest or stamp or name: it must be completed
+	r, ok := mc.CompletedRunByDigestOrStampOrName(dn, rdsn)
+	if !ok || r == nil {
+		return nil, errors.New("Model run not found or not completed: " + dn + ": " + rdsn)
+	}
+
+	// workset text: description and notes, match languages from request into model languages
+	wm := db.WorksetMeta{
+		Set: db.WorksetRow{Name: wsn, IsReadonly: isReadonly},
+		Txt: make([]db.WorksetTxtRow, len(txt)),
+	}
+	for k := range txt {
+		wm.Txt[k] = db.WorksetTxtRow{LangCode: txt[k].LangCode, Descr: txt[k].Descr, Note: txt[k].Note}
+
+		if lc := mc.languageCodeMatch(dn, txt[k].LangCode); lc != "" {
+			wm.Txt[k].LangCode = lc
+		}
+	}
+
+	// read-only workset parameters values must satisfy validation rules of the model
+	if isReadonly {
+		rules, err := mc.ParamRules(dn, "")
+		if err != nil {
+			return nil, errors.New("Error at get parameter validation rules: " + dn + ": " + err.Error())
+		}
+		wm.ParamRules = rules
+	}
+
+	// create new workset and copy parameters from model run
+	if err := db.CreateWorksetFromRun(dbConn, meta, langMeta, r, &wm, paramNames); err != nil {
+		return nil, errors.New("Workset create failed: " + wsn + " from model run: " + rdsn + ": " + err.Error())
+	}
+	return &wm.Set, nil
+}
+
 // CopyParameterBetweenWs copy parameter metadata and values into workset from other workset.
 // If isReplace is true and parameter already exist in destination workset then error returned
 // If isReplace is false then existing parameter values and metadata deleted and new inserted from source workset.
 // Destination workset must be in read-write state.
 // Source workset must be read-only.
-func (mc *ModelCatalog) CopyParameterBetweenWs(dn, dstWsName, name string, isReplace bool, srcWsName string) error {
+// If ifUpdateDt is not empty then destination workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+// If versionNote is not empty then current parameter values saved as new workset version.
+func (mc *ModelCatalog) CopyParameterBetweenWs(dn, dstWsName, name string, isReplace bool, srcWsName string, ifUpdateDt string, versionNote string) error {
 
 	// validate parameters
 	if dn == "" {
@@ -691,10 +867,116 @@ func (mc *ModelCatalog) CopyParameterBetweenWs(dn, dstWsName, name string, isRep
 		return errors.New("Parameter copy failed, source workset must be read-only: " + srcWsName + ": " + name)
 	}
 
+	// parameter values must satisfy validation rules of the model
+	rules, err := mc.ParamRules(dn, "")
+	if err != nil {
+		return errors.New("Error at get parameter validation rules: " + dn + ": " + err.Error())
+	}
+
 	// copy parameter from one workset to another
-	err := db.CopyParameterFromWorkset(dbConn, meta, dstWs, name, isReplace, srcWs)
+	err = db.CopyParameterFromWorkset(dbConn, meta, dstWs, name, isReplace, srcWs, rules, ifUpdateDt, versionNote)
+	if err == db.ErrWorksetChanged {
+		return err
+	}
 	if err != nil {
 		return errors.New("Parameter copy failed: " + dstWsName + ": " + name + ": " + err.Error())
 	}
 	return nil
 }
+
+// CreateWorksetFromBase create new read-only workset from base model run or base workset and assign new values to parameter cells.
+// Workset created by model digest-or-name, new workset name, base run digest-or-stamp-or-name and base workset name.
+// Return false if model not found or new workset name is empty.
+func (mc *ModelCatalog) CreateWorksetFromBase(dn, wsn, baseRdsn, baseWsn string, pvLst []db.ParamCellValue, txt []db.DescrNote) (bool, error) {
+
+	// if model digest-or-name or workset name is empty then return empty results
+	if dn == "" {
+		omppLog.Log("Warning: invalid (empty) model digest and name")
+		return false, nil
+	}
+	if wsn == "" {
+		omppLog.Log("Warning: invalid (empty) workset name")
+		return false, nil
+	}
+
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		omppLog.Log("Warning: model digest or name not found: ", dn)
+		return false, nil
+	}
+
+	langMeta := mc.modelLangMeta(dn)
+	if langMeta == nil {
+		omppLog.Log("Error: invalid (empty) model language list: ", dn)
+		return false, errors.New("Error: invalid (empty) model language list: " + dn)
+	}
+
+	// match languages from request into model languages
+	for k := range txt {
+		lc := mc.languageCodeMatch(dn, txt[k].LangCode)
+		if lc != "" {
+			txt[k].LangCode = lc
+		}
+	}
+
+	// create read-only workset and assign new parameter values, parameter values must satisfy validation rules of the model
+	rules, err := mc.ParamRules(dn, "")
+	if err != nil {
+		omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+		return false, err
+	}
+	err = db.CreateWorksetFromBase(dbConn, meta, langMeta, wsn, baseRdsn, baseWsn, pvLst, txt, rules)
+	if err != nil {
+		omppLog.Log("Error at create workset: ", dn, ": ", wsn, ": ", err.Error())
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RestoreWorksetVersion restore workset parameters to the values of specified workset version.
+// If parameter name is not empty then only that parameter restored.
+// Current values of restored parameters saved as new version.
+// If ifUpdateDt is not empty then workset must not be changed since that update date-time, otherwise db.ErrWorksetChanged returned.
+// Return new version id or zero if there are no parameters to restore.
+func (mc *ModelCatalog) RestoreWorksetVersion(dn, wsn string, verId int, name string, ifUpdateDt string, note string) (int, error) {
+
+	// if model digest-or-name or workset name is empty then return empty results
+	if dn == "" {
+		omppLog.Log("Warning: invalid (empty) model digest and name")
+		return 0, errors.New("Error: invalid (empty) model digest and name")
+	}
+	if wsn == "" {
+		omppLog.Log("Warning: invalid (empty) workset name")
+		return 0, errors.New("Error: invalid (empty) workset name")
+	}
+
+	meta, dbConn, ok := mc.modelMeta(dn)
+	if !ok {
+		omppLog.Log("Warning: model digest or name not found: ", dn)
+		return 0, errors.New("Error: model digest or name not found: " + dn)
+	}
+
+	langMeta := mc.modelLangMeta(dn)
+	if langMeta == nil {
+		omppLog.Log("Error: invalid (empty) model language list: ", dn)
+		return 0, errors.New("Error: invalid (empty) model language list: " + dn)
+	}
+
+	// restored parameter values must satisfy validation rules of the model
+	rules, err := mc.ParamRules(dn, "")
+	if err != nil {
+		omppLog.Log("Error at get parameter validation rules: ", dn, ": ", err.Error())
+		return 0, err
+	}
+
+	newId, err := db.RestoreWorksetVersion(dbConn, meta, langMeta, wsn, verId, name, rules, ifUpdateDt, note)
+	if err == db.ErrWorksetChanged {
+		return 0, err
+	}
+	if err != nil {
+		omppLog.Log("Error at restore workset version: ", dn, ": ", wsn, ": ", verId, ": ", err.Error())
+		return 0, err
+	}
+	return newId, nil
+}
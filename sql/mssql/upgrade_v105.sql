@@ -0,0 +1,48 @@
+--
+-- Copyright (c) 2016 OpenM++
+-- This code is licensed under the MIT license (see LICENSE.txt for details)
+--
+-- Upgrade openM++ database schema from version 104 to 105: add workset version tables.
+-- Use this script for Microsoft SQL Server.
+--
+
+--
+-- Workset version: values of workset parameters as it was before workset update
+--
+CREATE TABLE workset_version
+(
+  set_id      INT            NOT NULL, -- master key: workset_lst.set_id
+  version_id  INT            NOT NULL, -- version id, one-based, unique inside of workset
+  update_dt   VARCHAR(32)    NOT NULL, -- version date-time
+  author_note TEXT           NULL,     -- version note
+  PRIMARY KEY (set_id, version_id)
+);
+
+--
+-- Workset version parameters: parameters metadata as it was before workset update
+--
+CREATE TABLE workset_version_param
+(
+  set_id         INT      NOT NULL, -- master key: workset_lst.set_id
+  version_id     INT      NOT NULL, -- master key: workset_version.version_id
+  parameter_hid  INT      NOT NULL, -- parameter_dic.parameter_hid
+  is_in_set      SMALLINT NOT NULL, -- if non-zero then parameter included in workset
+  sub_count      INT      NOT NULL, -- number of parameter sub-values
+  default_sub_id INT      NOT NULL, -- default sub-value id
+  PRIMARY KEY (set_id, version_id, parameter_hid)
+);
+
+--
+-- Workset version values: parameter values as text, cell key is sub-value id and dimension(s) items id: 0,12,3
+--
+CREATE TABLE workset_version_value
+(
+  set_id        INT           NOT NULL, -- master key: workset_lst.set_id
+  version_id    INT           NOT NULL, -- master key: workset_version.version_id
+  parameter_hid INT           NOT NULL, -- parameter_dic.parameter_hid
+  cell_key      VARCHAR(255)  NOT NULL, -- sub-value id and dimension(s) items id
+  param_value   VARCHAR(4000) NULL,     -- parameter value as text
+  PRIMARY KEY (set_id, version_id, parameter_hid, cell_key)
+);
+
+UPDATE id_lst SET id_value = 105 WHERE id_key = 'openmpp';